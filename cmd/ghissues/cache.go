@@ -0,0 +1,452 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags for the cache commands
+var purgeForce bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clean up local issue caches",
+	Long: `Inspect and clean up the SQLite caches kept in the cache directory
+(~/.cache/ghissues by default, see "ghissues config show").`,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached repositories",
+	Long: `List every cached repository with its size, issue and comment counts,
+local changes not yet pushed to GitHub, and the time of the last full sync.`,
+	Args: cobra.NoArgs,
+	RunE: runCacheList,
+}
+
+var cacheInspectCmd = &cobra.Command{
+	Use:   "inspect <owner/repo>",
+	Short: "Show the content of a repository's cache",
+	Long: `Show the content of a repository's cache, including every dirty
+and pending item waiting to be pushed to GitHub.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCacheInspect,
+}
+
+var cacheVacuumCmd = &cobra.Command{
+	Use:   "vacuum [owner/repo]",
+	Short: "Reclaim unused space in caches",
+	Long: `Rebuild cache files to reclaim the space of deleted rows.
+
+Without a repository, every cache is vacuumed. Caches in use by a running
+mount or sync are skipped.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCacheVacuum,
+}
+
+var cachePurgeCmd = &cobra.Command{
+	Use:   "purge <owner/repo>",
+	Short: "Delete a repository's cache",
+	Long: `Delete a repository's cache file. The next mount fetches everything again.
+
+Purge refuses to delete a cache holding dirty or pending items that were
+never pushed to GitHub, unless --force is given. A cache in use by a running
+mount or sync is never purged.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCachePurge,
+}
+
+func init() {
+	cachePurgeCmd.Flags().BoolVar(&purgeForce, "force", false, "Delete the cache even if it holds unpushed changes")
+
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheInspectCmd)
+	cacheCmd.AddCommand(cacheVacuumCmd)
+	cacheCmd.AddCommand(cachePurgeCmd)
+}
+
+// cacheEntry is one repository found in a cache file.
+type cacheEntry struct {
+	repo  string
+	path  string
+	size  int64
+	stats *cache.Stats
+}
+
+// cacheDirs returns the directories that may hold caches: the global
+// cache_dir and those of repositories with their own cache_dir.
+func cacheDirs() ([]string, error) {
+	path, err := config.Path()
+	if err != nil {
+		return nil, err
+	}
+	file, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, repo := range append([]string{""}, sortedKeys(file.Repos)...) {
+		settings, err := file.Resolve(repo, os.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+		if err := applyHost(&settings); err != nil {
+			return nil, err
+		}
+		dir := hostCacheDir(settings.CacheDir, settings.Host)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// cacheFileSize returns the size of a cache on disk, including its write-ahead log.
+func cacheFileSize(path string) int64 {
+	var size int64
+	for _, p := range []string{path, path + "-wal"} {
+		if info, err := os.Stat(p); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// cacheFileRepos returns the repositories held by the cache db, or the
+// name of its file if it holds none yet.
+func cacheFileRepos(db *cache.DB) ([]string, error) {
+	repos, err := db.Repos()
+	if err != nil {
+		return nil, err
+	}
+	if len(repos) == 0 {
+		repos = []string{strings.TrimSuffix(filepath.Base(db.Path()), ".db")}
+	}
+	return repos, nil
+}
+
+// readCacheEntries reads the stats of every repository in the cache file at path.
+func readCacheEntries(path string) ([]cacheEntry, error) {
+	db, err := cache.OpenReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	repos, err := cacheFileRepos(db)
+	if err != nil {
+		return nil, err
+	}
+
+	size := cacheFileSize(path)
+	entries := make([]cacheEntry, 0, len(repos))
+	for _, repo := range repos {
+		stats, err := db.Stats(repo)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, cacheEntry{repo: repo, path: path, size: size, stats: stats})
+	}
+	return entries, nil
+}
+
+// listCacheEntries reads every cache file in dirs. Files that cannot be
+// read are reported as warnings and skipped.
+func listCacheEntries(dirs []string, warn io.Writer) ([]cacheEntry, error) {
+	var entries []cacheEntry
+	for _, dir := range dirs {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.db"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list caches in %s: %w", dir, err)
+		}
+		for _, path := range paths {
+			fileEntries, err := readCacheEntries(path)
+			if err != nil {
+				fmt.Fprintf(warn, "warning: skipping %s: %v\n", path, err)
+				continue
+			}
+			entries = append(entries, fileEntries...)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].repo < entries[j].repo })
+	return entries, nil
+}
+
+// formatSize renders a byte count for humans.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// formatLastSync renders a last sync time the way .status does.
+func formatLastSync(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+// writeCacheList prints cache entries as a table.
+func writeCacheList(w io.Writer, entries []cacheEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "no caches found")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPO\tSIZE\tISSUES\tCOMMENTS\tDIRTY\tPENDING\tLAST SYNC")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			e.repo,
+			formatSize(e.size),
+			e.stats.Issues,
+			e.stats.Comments,
+			e.stats.DirtyIssues+e.stats.DirtyComments,
+			e.stats.PendingIssues+e.stats.PendingComments,
+			formatLastSync(e.stats.LastSync),
+		)
+	}
+	tw.Flush()
+}
+
+func runCacheList(cmd *cobra.Command, args []string) error {
+	dirs, err := cacheDirs()
+	if err != nil {
+		return err
+	}
+
+	entries, err := listCacheEntries(dirs, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	writeCacheList(os.Stdout, entries)
+	return nil
+}
+
+// existingCachePath returns the path of the cache of repo, failing if it
+// was never created.
+func existingCachePath(repo string) (string, error) {
+	owner, repoName, err := validateRepo(repo)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := getCachePath(owner, repoName)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no cache for %s at %s", repo, path)
+		}
+		return "", fmt.Errorf("cannot access cache %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// openExistingCache opens the cache of repo, failing if it was never created.
+func openExistingCache(repo string) (*cache.DB, error) {
+	path, err := existingCachePath(repo)
+	if err != nil {
+		return nil, err
+	}
+	return cache.InitDB(path)
+}
+
+// writeCacheInspect prints the stats of repo followed by its unpushed items.
+func writeCacheInspect(w io.Writer, db *cache.DB, repo string) error {
+	stats, err := db.Stats(repo)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Repository: %s\n", repo)
+	fmt.Fprintf(w, "Path: %s\n", db.Path())
+	fmt.Fprintf(w, "Size: %s\n", formatSize(cacheFileSize(db.Path())))
+	fmt.Fprintf(w, "Last sync: %s\n", formatLastSync(stats.LastSync))
+	fmt.Fprintf(w, "Issues: %d\n", stats.Issues)
+	fmt.Fprintf(w, "Comments: %d\n", stats.Comments)
+
+	dirtyIssues, err := db.GetDirtyIssues(repo)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Dirty issues: %d\n", len(dirtyIssues))
+	for _, issue := range dirtyIssues {
+		fmt.Fprintf(w, "  #%d %s (edited %s)\n", issue.Number, issue.Title, issue.LocalUpdatedAt)
+	}
+
+	dirtyComments, err := db.GetDirtyComments(repo)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Dirty comments: %d\n", len(dirtyComments))
+	for _, comment := range dirtyComments {
+		fmt.Fprintf(w, "  comment %d on #%d\n", comment.ID, comment.IssueNumber)
+	}
+
+	pendingIssues, err := db.GetPendingIssues(repo)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Pending issues: %d\n", len(pendingIssues))
+	for _, issue := range pendingIssues {
+		fmt.Fprintf(w, "  %s (created %s)\n", issue.Title, issue.CreatedAt)
+	}
+
+	pendingComments, err := db.GetPendingComments(repo)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Pending comments: %d\n", len(pendingComments))
+	for _, comment := range pendingComments {
+		fmt.Fprintf(w, "  on #%d (created %s)\n", comment.IssueNumber, comment.CreatedAt)
+	}
+
+	return nil
+}
+
+func runCacheInspect(cmd *cobra.Command, args []string) error {
+	path, err := existingCachePath(args[0])
+	if err != nil {
+		return err
+	}
+	db, err := cache.OpenReadOnly(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return writeCacheInspect(os.Stdout, db, args[0])
+}
+
+// vacuumCache vacuums the cache at path and returns its size before and after.
+// It fails with cache.ErrLocked if the cache is in use.
+func vacuumCache(path string) (before, after int64, err error) {
+	db, err := cache.InitDBLocked(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer db.Close()
+
+	before = cacheFileSize(path)
+	if err := db.Vacuum(); err != nil {
+		return 0, 0, err
+	}
+	return before, cacheFileSize(path), nil
+}
+
+func runCacheVacuum(cmd *cobra.Command, args []string) error {
+	var paths []string
+	if len(args) == 1 {
+		path, err := existingCachePath(args[0])
+		if err != nil {
+			return err
+		}
+		paths = []string{path}
+	} else {
+		dirs, err := cacheDirs()
+		if err != nil {
+			return err
+		}
+		for _, dir := range dirs {
+			matches, err := filepath.Glob(filepath.Join(dir, "*.db"))
+			if err != nil {
+				return fmt.Errorf("failed to list caches in %s: %w", dir, err)
+			}
+			paths = append(paths, matches...)
+		}
+	}
+
+	failed := 0
+	for _, path := range paths {
+		before, after, err := vacuumCache(path)
+		switch {
+		case errors.Is(err, cache.ErrLocked):
+			fmt.Printf("%s: skipped, in use by a running mount or sync\n", path)
+		case err != nil:
+			fmt.Printf("%s: %v\n", path, err)
+			failed++
+		default:
+			fmt.Printf("%s: %s -> %s\n", path, formatSize(before), formatSize(after))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("vacuum failed for %d of %d caches", failed, len(paths))
+	}
+	return nil
+}
+
+// purgeCache deletes the cache of repo. Unless force is set, it refuses
+// when the cache holds changes that were never pushed to GitHub.
+func purgeCache(repo string, force bool) error {
+	path, err := existingCachePath(repo)
+	if err != nil {
+		return err
+	}
+	db, err := cache.InitDBLocked(path)
+	if err != nil {
+		if errors.Is(err, cache.ErrLocked) {
+			return fmt.Errorf("%s is in use by a running ghissues mount or sync", repo)
+		}
+		return err
+	}
+	defer db.Close()
+
+	stats, err := db.Stats(repo)
+	if err != nil {
+		return err
+	}
+	if n := stats.Unpushed(); n > 0 && !force {
+		return fmt.Errorf("%s has %d unpushed changes (%d dirty issues, %d dirty comments, %d pending issues, %d pending comments); run 'ghissues sync %s' first or pass --force to discard them",
+			repo, n, stats.DirtyIssues, stats.DirtyComments, stats.PendingIssues, stats.PendingComments, repo)
+	}
+	journalDir := getJournalDir(path)
+	if entries, _ := os.ReadDir(journalDir); len(entries) > 0 && !force {
+		return fmt.Errorf("%s has unsaved edits kept in %s; review them or pass --force to discard them", repo, journalDir)
+	}
+
+	// Remove the files while still holding the lock, so no mount can open the cache mid-purge
+	for _, p := range []string{path, path + "-wal", path + "-shm", path + ".lock"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+	}
+	if err := os.RemoveAll(journalDir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", journalDir, err)
+	}
+	return nil
+}
+
+func runCachePurge(cmd *cobra.Command, args []string) error {
+	if err := purgeCache(args[0], purgeForce); err != nil {
+		return err
+	}
+	fmt.Printf("purged cache of %s\n", args[0])
+	return nil
+}
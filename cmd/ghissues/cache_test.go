@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+)
+
+// createTestCache creates the cache of repo under the current HOME.
+func createTestCache(t *testing.T, owner, repoName string) *cache.DB {
+	t.Helper()
+	path, err := getCachePath(owner, repoName)
+	if err != nil {
+		t.Fatalf("getCachePath() error = %v", err)
+	}
+	db, err := cache.InitDB(path)
+	if err != nil {
+		t.Fatalf("InitDB() error = %v", err)
+	}
+	return db
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.size); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestListCacheEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	db := createTestCache(t, "owner", "repo")
+	db.UpsertIssue(cache.Issue{Number: 1, Repo: "owner/repo", Title: "One", State: "open"})
+	db.UpsertIssue(cache.Issue{Number: 2, Repo: "owner/repo", Title: "Two", State: "open"})
+	db.AddPendingComment("owner/repo", 1, "queued")
+	db.RecordSync("owner/repo", time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC))
+	db.Close()
+
+	// A cache that was created but never synced
+	createTestCache(t, "other", "empty").Close()
+
+	dirs, err := cacheDirs()
+	if err != nil {
+		t.Fatalf("cacheDirs() error = %v", err)
+	}
+	var warnings bytes.Buffer
+	entries, err := listCacheEntries(dirs, &warnings)
+	if err != nil {
+		t.Fatalf("listCacheEntries() error = %v", err)
+	}
+	if warnings.Len() > 0 {
+		t.Errorf("unexpected warnings: %s", warnings.String())
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].repo != "other_empty" || entries[1].repo != "owner/repo" {
+		t.Errorf("entries = %s, %s", entries[0].repo, entries[1].repo)
+	}
+	if entries[1].stats.Issues != 2 || entries[1].stats.PendingComments != 1 || entries[1].size == 0 {
+		t.Errorf("unexpected stats for owner/repo: %+v size %d", entries[1].stats, entries[1].size)
+	}
+
+	var buf bytes.Buffer
+	writeCacheList(&buf, entries)
+	out := buf.String()
+	for _, want := range []string{"REPO", "LAST SYNC", "owner/repo", "never"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("writeCacheList() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteCacheInspect(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	db := createTestCache(t, "owner", "repo")
+	defer db.Close()
+	db.UpsertIssue(cache.Issue{Number: 7, Repo: "owner/repo", Title: "Crash", State: "open"})
+	title := "Crash on startup"
+	db.MarkDirty("owner/repo", 7, cache.IssueUpdate{Title: &title})
+	db.AddPendingIssue("owner/repo", "Add dark mode", "", nil)
+
+	var buf bytes.Buffer
+	if err := writeCacheInspect(&buf, db, "owner/repo"); err != nil {
+		t.Fatalf("writeCacheInspect() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Repository: owner/repo\n",
+		"Issues: 1\n",
+		"Dirty issues: 1\n",
+		"#7 Crash on startup",
+		"Pending issues: 1\n",
+		"Add dark mode",
+		"Last sync: never\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("writeCacheInspect() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestOpenExistingCache_Missing(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	if _, err := openExistingCache("owner/missing"); err == nil || !strings.Contains(err.Error(), "no cache") {
+		t.Errorf("openExistingCache() error = %v, want no cache", err)
+	}
+}
+
+func TestPurgeCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	db := createTestCache(t, "owner", "repo")
+	path := db.Path()
+	db.UpsertIssue(cache.Issue{Number: 1, Repo: "owner/repo", Title: "One", State: "open"})
+	db.AddPendingComment("owner/repo", 1, "queued")
+
+	// In use by a mount
+	if err := db.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	if err := purgeCache("owner/repo", true); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("purgeCache() of a locked cache error = %v, want in use", err)
+	}
+	db.Close()
+
+	if err := purgeCache("owner/repo", false); err == nil || !strings.Contains(err.Error(), "1 unpushed changes") {
+		t.Errorf("purgeCache() error = %v, want unpushed changes", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("cache should still exist after a refused purge: %v", err)
+	}
+
+	if err := purgeCache("owner/repo", true); err != nil {
+		t.Fatalf("purgeCache(force) error = %v", err)
+	}
+	for _, p := range []string{path, path + ".lock"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s should have been removed, stat error = %v", filepath.Base(p), err)
+		}
+	}
+}
+
+func TestPurgeCache_KeptJournal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	db := createTestCache(t, "owner", "repo")
+	journalDir := getJournalDir(db.Path())
+	db.Close()
+	os.MkdirAll(journalDir, 0700)
+	os.WriteFile(filepath.Join(journalDir, "1-1.md"), []byte("unparsable edit"), 0600)
+
+	if err := purgeCache("owner/repo", false); err == nil || !strings.Contains(err.Error(), "unsaved edits") {
+		t.Errorf("purgeCache() error = %v, want unsaved edits", err)
+	}
+	if err := purgeCache("owner/repo", true); err != nil {
+		t.Fatalf("purgeCache(force) error = %v", err)
+	}
+	if _, err := os.Stat(journalDir); !os.IsNotExist(err) {
+		t.Errorf("journal should have been removed, stat error = %v", err)
+	}
+}
+
+func TestVacuumCache_SkipsLockedCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	db := createTestCache(t, "owner", "repo")
+	path := db.Path()
+	if err := db.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	if _, _, err := vacuumCache(path); !errors.Is(err, cache.ErrLocked) {
+		t.Errorf("vacuumCache() of a locked cache error = %v, want ErrLocked", err)
+	}
+	db.Close()
+
+	if _, after, err := vacuumCache(path); err != nil || after == 0 {
+		t.Errorf("vacuumCache() = %d, %v", after, err)
+	}
+}
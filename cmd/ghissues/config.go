@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/JohanCodinha/ghissues/internal/config"
+	"github.com/JohanCodinha/ghissues/internal/gh"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the ghissues configuration",
+	Long: `Inspect the ghissues configuration.
+
+Settings are read from ~/.config/ghissues/config.yaml (or $GHISSUES_CONFIG),
+which holds global defaults and per-repository overrides:
+
+  log_level: info
+  log_file: ""
+  quiet: false
+  debounce: 500ms
+  refresh_ttl: 30s
+  cache_dir: ~/.cache/ghissues
+  host: github.com
+  hide_pulls: false
+  repos:
+    org/busy-repo:
+      debounce: 2s
+
+host is the GitHub Enterprise Server to use instead of github.com, for
+every repository. hide_pulls leaves pull requests out of the cache and the
+mount.
+
+GHISSUES_LOG_LEVEL, GHISSUES_LOG_FILE, GHISSUES_QUIET, GHISSUES_DEBOUNCE,
+GHISSUES_REFRESH_TTL, GHISSUES_CACHE_DIR, GHISSUES_HOST and
+GHISSUES_HIDE_PULLS override the file, and command line flags override both.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show [owner/repo]",
+	Short: "Print the effective settings",
+	Long: `Print the effective settings, after applying the config file and environment.
+
+With a repository, its per-repository overrides are applied as well.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigShow,
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	repo := ""
+	if len(args) == 1 {
+		if _, _, err := validateRepo(args[0]); err != nil {
+			return err
+		}
+		repo = args[0]
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+
+	settings, err := loadSettings(cmd, repo)
+	if err != nil {
+		return err
+	}
+
+	writeSettings(os.Stdout, path, settings)
+	return nil
+}
+
+// loadSettings resolves the effective settings for repo, or the global ones
+// when repo is empty. Logging flags explicitly set on cmd take precedence
+// over the environment and the config file. cmd may be nil.
+func loadSettings(cmd *cobra.Command, repo string) (config.Settings, error) {
+	path, err := config.Path()
+	if err != nil {
+		return config.Settings{}, err
+	}
+
+	file, err := config.Load(path)
+	if err != nil {
+		return config.Settings{}, err
+	}
+
+	settings, err := file.Resolve(repo, os.Getenv)
+	if err != nil {
+		return config.Settings{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := applyHost(&settings); err != nil {
+		return config.Settings{}, err
+	}
+
+	if cmd != nil {
+		flags := cmd.Flags()
+		if flags.Changed("log-level") {
+			settings.LogLevel = logLevel
+		}
+		if flags.Changed("log-file") {
+			settings.LogFile = logFile
+		}
+		if flags.Changed("quiet") {
+			settings.Quiet = quiet
+		}
+	}
+
+	return settings, nil
+}
+
+// applyHost overrides the host of settings with --host, which every command
+// accepts, and normalizes it.
+func applyHost(settings *config.Settings) error {
+	if hostFlag != "" {
+		settings.Host = hostFlag
+	}
+	host, err := gh.NormalizeHost(settings.Host)
+	if err != nil {
+		return err
+	}
+	settings.Host = host
+	return nil
+}
+
+// hostCacheDir returns the directory holding the caches of repositories on
+// host. Caches of github.com sit directly in cacheDir, those of a GitHub
+// Enterprise Server in a subdirectory named after it, so that same-named
+// repositories on different hosts never share a cache.
+func hostCacheDir(cacheDir, host string) string {
+	if host == gh.DefaultHost {
+		return cacheDir
+	}
+	return filepath.Join(cacheDir, host)
+}
+
+// githubHost returns the host of the configuration and --host.
+func githubHost() (string, error) {
+	settings, err := loadSettings(nil, "")
+	if err != nil {
+		return "", err
+	}
+	return settings.Host, nil
+}
+
+// writeSettings prints settings in the config file format.
+func writeSettings(w io.Writer, path string, settings config.Settings) {
+	source := path
+	if _, err := os.Stat(path); err != nil {
+		source += " (not found, using defaults)"
+	}
+
+	fmt.Fprintf(w, "# config file: %s\n", source)
+	fmt.Fprintf(w, "log_level: %s\n", settings.LogLevel)
+	fmt.Fprintf(w, "log_file: %q\n", settings.LogFile)
+	fmt.Fprintf(w, "quiet: %t\n", settings.Quiet)
+	fmt.Fprintf(w, "debounce: %s\n", settings.Debounce)
+	fmt.Fprintf(w, "refresh_ttl: %s\n", settings.RefreshTTL)
+	fmt.Fprintf(w, "cache_dir: %s\n", settings.CacheDir)
+	fmt.Fprintf(w, "host: %s\n", settings.Host)
+	fmt.Fprintf(w, "hide_pulls: %t\n", settings.HidePulls)
+	if settings.AppID != 0 {
+		fmt.Fprintf(w, "app_id: %d\n", settings.AppID)
+		fmt.Fprintf(w, "app_installation_id: %d\n", settings.AppInstallationID)
+		fmt.Fprintf(w, "app_private_key: %s\n", settings.AppPrivateKey)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestConfig writes a config file under HOME/.config/ghissues.
+func writeTestConfig(t *testing.T, home, content string) string {
+	t.Helper()
+	path := filepath.Join(home, ".config", "ghissues", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadSettings_Precedence(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+	writeTestConfig(t, tmpDir, "log_level: warn\nlog_file: /tmp/from-file.log\nrepos:\n  org/a:\n    debounce: 2s\n")
+	t.Setenv("GHISSUES_LOG_FILE", "/tmp/from-env.log")
+
+	settings, err := loadSettings(nil, "org/a")
+	if err != nil {
+		t.Fatalf("loadSettings() error = %v", err)
+	}
+	if settings.LogLevel != "warn" || settings.LogFile != "/tmp/from-env.log" || settings.Debounce != 2*time.Second {
+		t.Errorf("unexpected settings without flags: %+v", settings)
+	}
+
+	// A flag given on the command line beats env and file
+	defer func() {
+		logLevel = ""
+		mountCmd.Flags().Lookup("log-level").Changed = false
+	}()
+	if err := mountCmd.Flags().Set("log-level", "debug"); err != nil {
+		t.Fatalf("failed to set flag: %v", err)
+	}
+
+	settings, err = loadSettings(mountCmd, "org/a")
+	if err != nil {
+		t.Fatalf("loadSettings() error = %v", err)
+	}
+	if settings.LogLevel != "debug" {
+		t.Errorf("LogLevel = %q, want the flag value debug", settings.LogLevel)
+	}
+	if settings.LogFile != "/tmp/from-env.log" {
+		t.Errorf("LogFile = %q, unset flags should not override env", settings.LogFile)
+	}
+}
+
+func TestGetCachePath_ConfiguredCacheDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+	cacheDir := filepath.Join(tmpDir, "elsewhere")
+	writeTestConfig(t, tmpDir, "cache_dir: "+cacheDir+"\n")
+
+	path, err := getCachePath("owner", "repo")
+	if err != nil {
+		t.Fatalf("getCachePath() error = %v", err)
+	}
+	if path != filepath.Join(cacheDir, "owner_repo.db") {
+		t.Errorf("getCachePath() = %q, want it under %s", path, cacheDir)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Errorf("cache dir was not created: %v", err)
+	}
+}
+
+func TestGetRunDir_ConfiguredCacheDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+	cacheDir := filepath.Join(tmpDir, "elsewhere")
+	writeTestConfig(t, tmpDir, "cache_dir: "+cacheDir+"\nrepos:\n  owner/repo:\n    cache_dir: "+filepath.Join(tmpDir, "repo")+"\n")
+
+	runDir, err := getRunDir()
+	if err != nil {
+		t.Fatalf("getRunDir() error = %v", err)
+	}
+	if runDir != filepath.Join(cacheDir, "run") {
+		t.Errorf("getRunDir() = %q, want it under %s", runDir, cacheDir)
+	}
+}
+
+func TestLoadSettings_InvalidConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+	writeTestConfig(t, tmpDir, "debounce: -1s\n")
+
+	if _, err := loadSettings(nil, ""); err == nil || !strings.Contains(err.Error(), "invalid configuration") {
+		t.Errorf("loadSettings() error = %v, want invalid configuration", err)
+	}
+}
+
+func TestWriteSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	settings, err := loadSettings(nil, "")
+	if err != nil {
+		t.Fatalf("loadSettings() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	writeSettings(&buf, filepath.Join(tmpDir, "missing.yaml"), settings)
+	out := buf.String()
+
+	for _, want := range []string{
+		"not found, using defaults",
+		"log_level: info\n",
+		"debounce: 500ms\n",
+		"refresh_ttl: 30s\n",
+		"hide_pulls: false\n",
+		"cache_dir: " + filepath.Join(tmpDir, ".cache", "ghissues") + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("writeSettings() missing %q:\n%s", want, out)
+		}
+	}
+}
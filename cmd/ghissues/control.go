@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/JohanCodinha/ghissues/internal/control"
+	"github.com/JohanCodinha/ghissues/internal/fs"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags for the control commands
+var (
+	statusJSON  bool
+	controlRepo string
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [mountpoint]",
+	Short: "Show the sync status of running mounts",
+	Long: `Show the sync status of a running mount, as reported by its control socket.
+
+Without a mountpoint, the status of every running mount is shown.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStatus,
+}
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh <mountpoint> [issue-number]",
+	Short: "Re-fetch issues of a running mount from GitHub",
+	Long: `Ask a running mount to re-fetch one issue, or every issue when no number is given.
+
+An issue that is not cached yet, e.g. one created on GitHub after the mount
+started, is fetched as well. When the mount serves several repositories,
+an issue number must be combined with --repo.
+
+The command blocks until the refresh has finished.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runRefresh,
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Print the raw status as JSON")
+	statusCmd.Flags().StringVar(&controlRepo, "repo", "", "Only show this owner/repo of a multi-repo mount")
+	refreshCmd.Flags().StringVar(&controlRepo, "repo", "", "Only refresh this owner/repo of a multi-repo mount (required with an issue number when the mount serves several repos)")
+}
+
+// startControlServer starts the control socket for a mount serving the given engines.
+func startControlServer(mountpoint string, engines map[string]control.Engine) (*control.Server, error) {
+	absMountpoint, err := filepath.Abs(mountpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	socketPath, err := getSocketPath(absMountpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	server := control.NewServer(socketPath, absMountpoint)
+	for repo, engine := range engines {
+		server.Register(repo, engine)
+	}
+	if err := server.Start(); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// controlClientFor returns a client for the mount at mountpoint.
+// It fails with a helpful message if nothing is mounted there.
+func controlClientFor(mountpoint string) (*control.Client, error) {
+	socketPath, err := getSocketPath(mountpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(socketPath); err != nil {
+		return nil, fmt.Errorf("no running ghissues mount at %q", mountpoint)
+	}
+
+	return control.NewClient(socketPath), nil
+}
+
+// printResponse prints the per-repo results of a control response.
+func printResponse(resp *control.Response) {
+	for _, r := range resp.Repos {
+		fmt.Printf("%s at %s\n", r.Repo, resp.Mountpoint)
+		fmt.Print(fs.FormatStatus(r.Status.SyncStatus()))
+		if r.Error != "" {
+			fmt.Printf("Error: %s\n", r.Error)
+		}
+		fmt.Println()
+	}
+}
+
+// checkResponse turns a failed control response into an error.
+func checkResponse(action string, resp *control.Response) error {
+	if resp.Error != "" {
+		return fmt.Errorf("%s failed: %s", action, resp.Error)
+	}
+
+	failed := 0
+	for _, r := range resp.Repos {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s failed for %d of %d repositories", action, failed, len(resp.Repos))
+	}
+	return nil
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	var sockets []string
+	if len(args) == 1 {
+		socketPath, err := getSocketPath(args[0])
+		if err != nil {
+			return err
+		}
+		sockets = []string{socketPath}
+	} else {
+		runDir, err := getRunDir()
+		if err != nil {
+			return err
+		}
+		sockets, err = control.ListSockets(runDir)
+		if err != nil {
+			return err
+		}
+		if len(sockets) == 0 {
+			fmt.Println("no running mounts")
+			return nil
+		}
+	}
+
+	var responses []*control.Response
+	for _, socketPath := range sockets {
+		resp, err := control.NewClient(socketPath).Status(controlRepo)
+		if err != nil {
+			if len(args) == 1 {
+				return fmt.Errorf("no running ghissues mount at %q: %w", args[0], err)
+			}
+			return err
+		}
+		if len(args) == 0 && controlRepo != "" && resp.Error != "" {
+			continue // this mount doesn't serve the requested repo
+		}
+		if err := checkResponse("status", resp); err != nil {
+			return err
+		}
+		responses = append(responses, resp)
+	}
+
+	if statusJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(responses)
+	}
+
+	for _, resp := range responses {
+		printResponse(resp)
+	}
+	return nil
+}
+
+func runRefresh(cmd *cobra.Command, args []string) error {
+	number := 0
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid issue number %q", args[1])
+		}
+		number = n
+	}
+
+	client, err := controlClientFor(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Issue numbers are per repository, so a numbered refresh of a
+	// multi-repo mount has to say which repository it means.
+	if number > 0 && controlRepo == "" {
+		status, err := client.Status("")
+		if err != nil {
+			return err
+		}
+		if len(status.Repos) > 1 {
+			return fmt.Errorf("%s serves %d repositories, use --repo to choose which one has issue #%d", args[0], len(status.Repos), number)
+		}
+	}
+
+	resp, err := client.Refresh(controlRepo, number)
+	if err != nil {
+		return err
+	}
+
+	printResponse(resp)
+	return checkResponse("refresh", resp)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/control"
+	"github.com/JohanCodinha/ghissues/internal/fs"
+)
+
+func TestGetSocketPath_UnderRunDir(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	runDir, err := getRunDir()
+	if err != nil {
+		t.Fatalf("getRunDir() unexpected error: %v", err)
+	}
+
+	socketPath, err := getSocketPath("/some/mount")
+	if err != nil {
+		t.Fatalf("getSocketPath() unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(socketPath, runDir) {
+		t.Errorf("getSocketPath() = %q, want path under %q", socketPath, runDir)
+	}
+}
+
+func TestRefreshCmd_RejectsInvalidNumber(t *testing.T) {
+	rootCmd.SetArgs([]string{"refresh", "/some/mount", "abc"})
+	err := rootCmd.Execute()
+
+	if err == nil {
+		t.Fatal("refresh should fail with a non-numeric issue number")
+	}
+	if !strings.Contains(err.Error(), "invalid issue number") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStatusCmd_NoRunningMounts(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	rootCmd.SetArgs([]string{"status"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Errorf("status with no mounts should succeed, got %v", err)
+	}
+}
+
+// fakeEngine is a control.Engine that records syncs and refreshes.
+type fakeEngine struct {
+	syncErr   error
+	syncCalls int
+	refreshed []int
+}
+
+func (f *fakeEngine) GetStatus() fs.SyncStatus          { return fs.SyncStatus{} }
+func (f *fakeEngine) InitialSync(context.Context) error { return nil }
+func (f *fakeEngine) SyncNow(context.Context) error {
+	f.syncCalls++
+	return f.syncErr
+}
+func (f *fakeEngine) RefreshIssue(_ context.Context, number int) (bool, error) {
+	f.refreshed = append(f.refreshed, number)
+	return true, nil
+}
+
+func TestRefreshCmd_NumberNeedsRepoOnMultiRepoMount(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+	defer func() { controlRepo = "" }()
+
+	a, b := &fakeEngine{}, &fakeEngine{}
+	server, err := startControlServer(tmpDir, map[string]control.Engine{"org/a": a, "org/b": b})
+	if err != nil {
+		t.Fatalf("startControlServer() error = %v", err)
+	}
+	defer server.Close()
+
+	rootCmd.SetArgs([]string{"refresh", tmpDir, "12"})
+	err = rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--repo") {
+		t.Fatalf("refresh without --repo should be rejected, got %v", err)
+	}
+	if len(a.refreshed)+len(b.refreshed) != 0 {
+		t.Fatal("no repo should have been refreshed")
+	}
+
+	rootCmd.SetArgs([]string{"refresh", tmpDir, "12", "--repo", "org/b"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("refresh --repo org/b error = %v", err)
+	}
+	if len(a.refreshed) != 0 || len(b.refreshed) != 1 || b.refreshed[0] != 12 {
+		t.Errorf("refreshed a=%v b=%v, want only b=[12]", a.refreshed, b.refreshed)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/control"
+	"github.com/JohanCodinha/ghissues/internal/fs"
+	"github.com/JohanCodinha/ghissues/internal/logger"
+)
+
+// daemonChildEnv is set in the environment of the process started by
+// "mount --daemon", which then runs the mount itself.
+const daemonChildEnv = "GHISSUES_DAEMON_CHILD"
+
+// readyFD is the file descriptor on which a daemon reports to the process
+// that started it whether the mount is ready.
+const readyFD = 3
+
+// getDaemonFiles returns the pidfile and default log file of a daemon
+// serving mountpoint. They sit next to its control socket.
+func getDaemonFiles(mountpoint string) (pidPath, logPath string, err error) {
+	socketPath, err := getSocketPath(mountpoint)
+	if err != nil {
+		return "", "", err
+	}
+	base := strings.TrimSuffix(socketPath, ".sock")
+	return base + ".pid", base + ".log", nil
+}
+
+// startDaemon runs the current command again in a new session with its
+// output going to logPath, and waits until the mount reports it is ready
+// or failed. The daemon keeps running after this process exits.
+func startDaemon(mountpoint, logPath string) error {
+	absMountpoint, err := filepath.Abs(mountpoint)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	_, defaultLog, err := getDaemonFiles(absMountpoint)
+	if err != nil {
+		return err
+	}
+	if logPath == "" {
+		logPath = defaultLog
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the ghissues executable: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0700); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logOut, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer logOut.Close()
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create readiness pipe: %w", err)
+	}
+	defer readyR.Close()
+
+	child := exec.Command(exe, os.Args[1:]...)
+	child.Env = append(os.Environ(), daemonChildEnv+"=1", "GHISSUES_LOG_FILE="+logPath)
+	child.Stdout = logOut
+	child.Stderr = logOut
+	child.ExtraFiles = []*os.File{readyW} // fd 3 in the child
+	child.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	if err := child.Start(); err != nil {
+		readyW.Close()
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+	readyW.Close()
+
+	if err := waitReady(readyR); err != nil {
+		child.Wait()
+		return fmt.Errorf("%w\nsee %s", err, logPath)
+	}
+
+	fmt.Printf("mounted at %s (pid %d)\nlog: %s\n", absMountpoint, child.Process.Pid, logPath)
+	return child.Process.Release()
+}
+
+// waitReady reads the readiness report of a daemon from r.
+func waitReady(r io.Reader) error {
+	line, _ := bufio.NewReader(r).ReadString('\n')
+	line = strings.TrimSpace(line)
+
+	switch {
+	case line == "ready":
+		return nil
+	case strings.HasPrefix(line, "error: "):
+		return errors.New(strings.TrimPrefix(line, "error: "))
+	default:
+		return fmt.Errorf("daemon exited before the mount was ready")
+	}
+}
+
+// daemonChild is the mounting side of "mount --daemon". A nil *daemonChild
+// is a foreground mount, on which every method is a no-op.
+type daemonChild struct {
+	ready   io.WriteCloser // nil once readiness was reported
+	pidPath string
+}
+
+// newDaemonChild writes the pidfile of the daemon serving mountpoint and
+// opens the readiness pipe inherited from startDaemon.
+func newDaemonChild(mountpoint string) (*daemonChild, error) {
+	pidPath, _, err := getDaemonFiles(mountpoint)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(pidPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	if err := os.WriteFile(pidPath, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write pidfile: %w", err)
+	}
+
+	return &daemonChild{ready: os.NewFile(readyFD, "ready"), pidPath: pidPath}, nil
+}
+
+// Ready tells the starting process that the mount is serving requests.
+func (d *daemonChild) Ready() {
+	d.report("ready")
+}
+
+// Fail tells the starting process that the mount failed with err.
+func (d *daemonChild) Fail(err error) {
+	d.report("error: " + strings.ReplaceAll(err.Error(), "\n", " "))
+}
+
+func (d *daemonChild) report(line string) {
+	if d == nil || d.ready == nil {
+		return
+	}
+	if _, err := fmt.Fprintln(d.ready, line); err != nil {
+		logger.Warn("daemon: failed to report readiness: %v", err)
+	}
+	d.ready.Close()
+	d.ready = nil
+}
+
+// Close removes the pidfile once the daemon is done.
+func (d *daemonChild) Close() {
+	if d == nil {
+		return
+	}
+	d.report("error: mount stopped before it was ready")
+	if err := os.Remove(d.pidPath); err != nil && !os.IsNotExist(err) {
+		logger.Warn("daemon: failed to remove pidfile: %v", err)
+	}
+}
+
+// mountReady returns the callback run once filesystem serves requests: from
+// then on it can be unmounted over the control socket, and a daemon reports
+// that it is ready.
+func mountReady(filesystem *fs.FS, controlServer *control.Server, daemon *daemonChild) func() {
+	return func() {
+		if controlServer != nil {
+			controlServer.SetUnmountFunc(filesystem.Unmount)
+		}
+		daemon.Ready()
+	}
+}
+
+// waitForExit waits until the daemon whose pidfile is pidPath has exited,
+// i.e. has closed its caches. It returns at once if there is no pidfile,
+// as for foreground mounts, and gives up after timeout when it is positive.
+func waitForExit(pidPath string, timeout time.Duration) error {
+	data, err := os.ReadFile(pidPath)
+	if err != nil {
+		return nil
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("invalid pidfile %s: %w", pidPath, err)
+	}
+
+	start := time.Now()
+	for {
+		if _, err := os.Stat(pidPath); os.IsNotExist(err) {
+			return nil
+		}
+		if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+			return nil
+		}
+		if timeout > 0 && time.Since(start) > timeout {
+			return fmt.Errorf("daemon (pid %d) is still running after %s", pid, timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
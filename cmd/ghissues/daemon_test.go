@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/control"
+)
+
+func TestWaitReady(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"ready", "ready\n", ""},
+		{"failed", "error: failed to get GitHub token\n", "failed to get GitHub token"},
+		{"exited", "", "exited before the mount was ready"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := waitReady(strings.NewReader(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("waitReady() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("waitReady() error = %v, expected %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDaemonChild_ReportsOnce(t *testing.T) {
+	pidPath := filepath.Join(t.TempDir(), "mount.pid")
+	os.WriteFile(pidPath, []byte("1\n"), 0644)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	defer r.Close()
+
+	d := &daemonChild{ready: w, pidPath: pidPath}
+	d.Ready()
+	d.Fail(errors.New("too late"))
+	d.Close()
+
+	if err := waitReady(r); err != nil {
+		t.Errorf("expected the first report to win, got %v", err)
+	}
+	if _, err := os.Stat(pidPath); !os.IsNotExist(err) {
+		t.Error("Close should remove the pidfile")
+	}
+
+	// A foreground mount has no daemon
+	var none *daemonChild
+	none.Ready()
+	none.Fail(errors.New("ignored"))
+	none.Close()
+}
+
+func TestDaemonChild_MultiLineFailure(t *testing.T) {
+	r, w, _ := os.Pipe()
+	defer r.Close()
+
+	d := &daemonChild{ready: w, pidPath: filepath.Join(t.TempDir(), "mount.pid")}
+	d.Fail(errors.New("failed to get GitHub token\nRun 'gh auth login' to authenticate"))
+
+	err := waitReady(r)
+	if err == nil || !strings.Contains(err.Error(), "gh auth login") {
+		t.Errorf("expected the whole error on one line, got %v", err)
+	}
+}
+
+func TestGetDaemonFiles_NextToSocket(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	socketPath, _ := getSocketPath("/some/mount")
+	pidPath, logPath, err := getDaemonFiles("/some/mount")
+	if err != nil {
+		t.Fatalf("getDaemonFiles() error = %v", err)
+	}
+	base := strings.TrimSuffix(socketPath, ".sock")
+	if pidPath != base+".pid" || logPath != base+".log" {
+		t.Errorf("getDaemonFiles() = %q, %q; expected them next to %q", pidPath, logPath, socketPath)
+	}
+}
+
+func TestWaitForExit(t *testing.T) {
+	dir := t.TempDir()
+
+	// Foreground mounts have no pidfile
+	if err := waitForExit(filepath.Join(dir, "none.pid"), time.Second); err != nil {
+		t.Errorf("waitForExit() without pidfile error = %v", err)
+	}
+
+	// This process is alive, so only the pidfile going away ends the wait
+	pidPath := filepath.Join(dir, "mount.pid")
+	os.WriteFile(pidPath, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+	if err := waitForExit(pidPath, 200*time.Millisecond); err == nil {
+		t.Error("expected a timeout while the daemon runs")
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		os.Remove(pidPath)
+	}()
+	if err := waitForExit(pidPath, 5*time.Second); err != nil {
+		t.Errorf("waitForExit() error = %v", err)
+	}
+}
+
+func TestUnmountCmd_ReportsFinalFlush(t *testing.T) {
+	tests := []struct {
+		name    string
+		syncErr error
+		wantErr string
+	}{
+		{"flushed", nil, ""},
+		{"push failed", errors.New("422 Unprocessable Entity"), "final flush failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("HOME", tmpDir)
+			mountpoint := filepath.Join(tmpDir, "mnt")
+			os.Mkdir(mountpoint, 0755)
+
+			engine := &fakeEngine{syncErr: tt.syncErr}
+			server, err := startControlServer(mountpoint, map[string]control.Engine{"owner/repo": engine})
+			if err != nil {
+				t.Fatalf("startControlServer() error = %v", err)
+			}
+			defer server.Close()
+			unmounted := false
+			server.SetUnmountFunc(func() error {
+				unmounted = true
+				return nil
+			})
+
+			rootCmd.SetArgs([]string{"unmount", mountpoint})
+			err = rootCmd.Execute()
+
+			if !unmounted || engine.syncCalls != 1 {
+				t.Errorf("unmounted = %v, syncCalls = %d; expected an unmount then one flush", unmounted, engine.syncCalls)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unmount error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("unmount error = %v, expected %q", err, tt.wantErr)
+			}
+		})
+	}
+}
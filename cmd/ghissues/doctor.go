@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/config"
+	"github.com/JohanCodinha/ghissues/internal/control"
+	"github.com/JohanCodinha/ghissues/internal/gh"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the environment and local caches",
+	Long: `Check everything ghissues depends on and print how to fix each problem found:
+
+  - FUSE: /dev/fuse and fusermount on Linux, macFUSE on macOS
+  - authentication: where the token comes from, whether GitHub accepts it
+    and whether it has the repo scope
+  - gh CLI logins on hosts other than github.com
+  - the integrity of every cache
+  - stale mounts ("transport endpoint is not connected") and leftover
+    control sockets and pidfiles of crashed mounts
+
+The command exits with an error if any check failed.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+	// Failed checks are not usage errors
+	SilenceUsage: true,
+}
+
+// findingStatus is the outcome of a doctor check.
+type findingStatus int
+
+const (
+	findingOK findingStatus = iota
+	findingWarn
+	findingFail
+)
+
+func (s findingStatus) String() string {
+	switch s {
+	case findingWarn:
+		return "warn"
+	case findingFail:
+		return "FAIL"
+	default:
+		return "ok"
+	}
+}
+
+// finding is the result of one doctor check, with a fix for anything not ok.
+type finding struct {
+	status findingStatus
+	check  string
+	detail string
+	fix    string
+}
+
+// writeFindings prints findings and returns how many failed.
+func writeFindings(w io.Writer, findings []finding) (failed int) {
+	for _, f := range findings {
+		fmt.Fprintf(w, "%-7s%s: %s\n", "["+f.status.String()+"]", f.check, f.detail)
+		if f.fix != "" && f.status != findingOK {
+			fmt.Fprintf(w, "       fix: %s\n", f.fix)
+		}
+		if f.status == findingFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// checkFuse checks that FUSE filesystems can be mounted and unmounted.
+// devFuse is the FUSE device on Linux, and lookPath finds commands on PATH.
+func checkFuse(goos, devFuse string, lookPath func(string) (string, error)) []finding {
+	if goos == "darwin" {
+		const macFUSE = "/Library/Filesystems/macfuse.fs"
+		if _, err := os.Stat(macFUSE); err != nil {
+			return []finding{{findingFail, "fuse", "macFUSE is not installed", "brew install --cask macfuse, then allow its system extension"}}
+		}
+		return []finding{{findingOK, "fuse", "macFUSE is installed", ""}}
+	}
+
+	var findings []finding
+	if _, err := os.Stat(devFuse); err != nil {
+		findings = append(findings, finding{findingFail, "fuse", devFuse + " does not exist", "sudo modprobe fuse (in a container, run it with --device /dev/fuse)"})
+	} else if err := unix.Access(devFuse, unix.W_OK); err != nil {
+		findings = append(findings, finding{findingFail, "fuse", devFuse + " is not writable: " + err.Error(), "check the permissions of " + devFuse + " or add yourself to the fuse group"})
+	} else {
+		findings = append(findings, finding{findingOK, "fuse", devFuse + " is available", ""})
+	}
+
+	_, errV2 := lookPath("fusermount")
+	_, errV3 := lookPath("fusermount3")
+	switch {
+	case errV2 != nil && errV3 != nil:
+		findings = append(findings, finding{findingFail, "fusermount", "neither fusermount nor fusermount3 is on PATH", "install fuse3 (e.g. sudo apt install fuse3)"})
+	case errV2 != nil:
+		findings = append(findings, finding{findingWarn, "fusermount", "only fusermount3 is on PATH; ghissues unmount runs fusermount and will fail", "install the fuse package, or unmount with fusermount3 -u <mountpoint>"})
+	default:
+		findings = append(findings, finding{findingOK, "fusermount", "fusermount is on PATH", ""})
+	}
+	return findings
+}
+
+// loginFix returns how to get a new token of host from source.
+func loginFix(source, host string) string {
+	if env := gh.TokenEnv(host); source == env {
+		return "set " + env + " to a token with the repo scope, or unset it and run gh auth login --hostname " + host
+	}
+	return "gh auth login --hostname " + host + " --scopes repo"
+}
+
+// checkToken reports where the token of client comes from and whether
+// host accepts it with the scopes ghissues needs.
+func checkToken(ctx context.Context, source, host string, client *gh.Client) []finding {
+	findings := []finding{{findingOK, "token", "found via " + source, ""}}
+	if env := gh.TokenEnv(host); source != env && os.Getenv(env) != "" {
+		findings = append(findings, finding{findingWarn, "token", env + " is set but ignored because gh is logged in", "unset " + env + ", or run gh auth logout to use it"})
+	}
+
+	info, err := client.TokenInfo(ctx)
+	if err != nil {
+		return append(findings, finding{findingFail, "token", host + " rejected the token: " + err.Error(), loginFix(source, host)})
+	}
+
+	switch {
+	case !info.ScopesKnown:
+		findings = append(findings, finding{findingOK, "scopes", "fine-grained token of " + info.Login + "; it needs read and write access to issues of the mounted repositories", ""})
+	case info.HasScope("repo"):
+		findings = append(findings, finding{findingOK, "scopes", "token of " + info.Login + " has the repo scope", ""})
+	case info.HasScope("public_repo"):
+		findings = append(findings, finding{findingWarn, "scopes", "token of " + info.Login + " only has public_repo: private repositories cannot be mounted", refreshFix(source, host)})
+	default:
+		findings = append(findings, finding{findingFail, "scopes", fmt.Sprintf("token of %s lacks the repo scope (has: %s): changes cannot be pushed", info.Login, strings.Join(info.Scopes, ", ")), refreshFix(source, host)})
+	}
+	return findings
+}
+
+// checkAppToken checks that the GitHub App of settings gets an installation
+// token. Its scopes are not checked: installation tokens cannot read /user,
+// the app's permissions are set when it is installed.
+func checkAppToken(ctx context.Context, settings config.Settings, app gh.CredentialProvider) finding {
+	name := fmt.Sprintf("GitHub App %d installation %d", settings.AppID, settings.AppInstallationID)
+	if _, err := app.Token(ctx); err != nil {
+		return finding{findingFail, "app", name + ": " + err.Error(), "check app_id and app_installation_id, see ghissues config show"}
+	}
+	return finding{findingOK, "app", name + " got an installation token; it needs read and write access to issues", ""}
+}
+
+// refreshFix returns how to add the repo scope to a token of host from source.
+func refreshFix(source, host string) string {
+	if env := gh.TokenEnv(host); source == env {
+		return "create a token with the repo scope and set " + env + " to it"
+	}
+	return "gh auth refresh --hostname " + host + " --scopes repo"
+}
+
+// checkGhLogins warns when the gh CLI is logged into other hosts but not
+// host, whose token ghissues uses.
+func checkGhLogins(logins []gh.GhLogin, host string) []finding {
+	if len(logins) == 0 {
+		return nil
+	}
+
+	var others []string
+	for _, login := range logins {
+		if login.Host == host {
+			return []finding{{findingOK, "gh", "logged into " + host + " as " + login.User, ""}}
+		}
+		others = append(others, login.Host)
+	}
+	return []finding{{findingWarn, "gh", "logged into " + strings.Join(others, ", ") + " but not " + host, "gh auth login --hostname " + host}}
+}
+
+// checkCaches runs an integrity check on every cache file in dirs.
+func checkCaches(dirs []string) []finding {
+	var findings []finding
+	for _, dir := range dirs {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.db"))
+		if err != nil {
+			findings = append(findings, finding{findingFail, "cache", "failed to list caches in " + dir + ": " + err.Error(), ""})
+			continue
+		}
+		for _, path := range paths {
+			findings = append(findings, checkCache(path))
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, finding{findingOK, "cache", "no caches yet", ""})
+	}
+	return findings
+}
+
+// checkCache runs an integrity check on the cache at path. The cache is
+// opened read-only, as a running mount may have it open.
+func checkCache(path string) finding {
+	check := "cache " + strings.TrimSuffix(filepath.Base(path), ".db")
+	fix := "rm " + path + "* (unpushed changes are lost)"
+
+	db, err := cache.OpenReadOnly(path)
+	if err != nil {
+		return finding{findingFail, check, "cannot open " + path + ": " + err.Error(), fix}
+	}
+	defer db.Close()
+
+	repos, err := db.Repos()
+	if err != nil {
+		return finding{findingFail, check, "cannot read " + path + ": " + err.Error(), fix}
+	}
+	if len(repos) > 0 {
+		check = "cache " + repos[0]
+		fix = "ghissues cache purge --force " + repos[0] + " (review ghissues pending list first: unpushed changes are lost)"
+	}
+
+	problems, err := db.IntegrityCheck()
+	if err != nil {
+		return finding{findingFail, check, err.Error(), fix}
+	}
+	if len(problems) > 0 {
+		return finding{findingFail, check, fmt.Sprintf("%s is corrupt (%d problems, first: %s)", path, len(problems), problems[0]), fix}
+	}
+	return finding{findingOK, check, "integrity check passed", ""}
+}
+
+// parseProcMounts returns the mountpoints of ghissues filesystems in a
+// /proc/mounts style table.
+func parseProcMounts(r io.Reader) []string {
+	var mountpoints []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[2] != "fuse.ghissues" {
+			continue
+		}
+		mountpoints = append(mountpoints, unescapeMountpoint(fields[1]))
+	}
+	return mountpoints
+}
+
+// unescapeMountpoint decodes the octal escapes (e.g. \040 for a space) of
+// a /proc/mounts field.
+func unescapeMountpoint(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// parseMountOutput returns the mountpoints of ghissues filesystems in the
+// output of mount(8) on macOS: "ghissues on /path (macfuse, ...)".
+func parseMountOutput(r io.Reader) []string {
+	var mountpoints []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		device, rest, ok := strings.Cut(scanner.Text(), " on ")
+		if !ok || device != "ghissues" {
+			continue
+		}
+		if i := strings.LastIndex(rest, " ("); i >= 0 {
+			rest = rest[:i]
+		}
+		mountpoints = append(mountpoints, rest)
+	}
+	return mountpoints
+}
+
+// ghissuesMounts returns the mountpoints of every mounted ghissues filesystem.
+func ghissuesMounts() ([]string, error) {
+	if f, err := os.Open("/proc/mounts"); err == nil {
+		defer f.Close()
+		return parseProcMounts(f), nil
+	}
+
+	out, err := exec.Command("mount").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mounts: %w", err)
+	}
+	return parseMountOutput(strings.NewReader(string(out))), nil
+}
+
+// checkMounts detects mounts whose process died, which fail every access
+// with "transport endpoint is not connected".
+func checkMounts(mountpoints []string, stat func(string) error) []finding {
+	var findings []finding
+	for _, mp := range mountpoints {
+		err := stat(mp)
+		switch {
+		case errors.Is(err, syscall.ENOTCONN):
+			findings = append(findings, finding{findingFail, "mount " + mp, "stale mount: transport endpoint is not connected", strings.Join(getLazyUnmountCommand(mp).Args, " ")})
+		case err != nil:
+			findings = append(findings, finding{findingWarn, "mount " + mp, "cannot access mountpoint: " + err.Error(), strings.Join(getUnmountCommand(mp).Args, " ")})
+		default:
+			findings = append(findings, finding{findingOK, "mount " + mp, "responding", ""})
+		}
+	}
+	return findings
+}
+
+// checkRunDir finds control sockets nobody listens on and pidfiles of
+// processes that are gone, both left behind by crashed mounts.
+func checkRunDir(runDir string) ([]finding, error) {
+	sockets, err := filepath.Glob(filepath.Join(runDir, "*.sock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list control sockets: %w", err)
+	}
+	live, err := control.ListSockets(runDir)
+	if err != nil {
+		return nil, err
+	}
+	isLive := make(map[string]bool)
+	for _, path := range live {
+		isLive[path] = true
+	}
+
+	var findings []finding
+	for _, path := range sockets {
+		if !isLive[path] {
+			findings = append(findings, finding{findingWarn, "run dir", "stale control socket " + path, "rm " + path})
+		}
+	}
+
+	pidfiles, err := filepath.Glob(filepath.Join(runDir, "*.pid"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pidfiles: %w", err)
+	}
+	for _, path := range pidfiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err == nil && !errors.Is(syscall.Kill(pid, 0), syscall.ESRCH) {
+			continue
+		}
+		findings = append(findings, finding{findingWarn, "run dir", "pidfile of a daemon that is gone: " + path, "rm " + path})
+	}
+	return findings, nil
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	findings := checkFuse(runtime.GOOS, "/dev/fuse", exec.LookPath)
+
+	settings, err := loadSettings(nil, "")
+	if err != nil {
+		return err
+	}
+	host := settings.Host
+
+	if settings.AppID != 0 {
+		if app, err := appCredentials(settings); err != nil {
+			findings = append(findings, finding{findingFail, "app", err.Error(), "fix app_private_key, see ghissues config show"})
+		} else {
+			findings = append(findings, checkAppToken(cmd.Context(), settings, app))
+		}
+	} else {
+		if logins, err := gh.GhLogins(); err != nil {
+			findings = append(findings, finding{findingWarn, "gh", err.Error(), "gh auth login --hostname " + host})
+		} else {
+			findings = append(findings, checkGhLogins(logins, host)...)
+		}
+
+		token, source, err := gh.GetTokenForHost(host)
+		if err != nil {
+			findings = append(findings, finding{findingFail, "token", err.Error(), "gh auth login --hostname " + host + " --scopes repo"})
+		} else {
+			findings = append(findings, checkToken(cmd.Context(), source, host, gh.NewForHost(token, host))...)
+		}
+	}
+
+	if dirs, err := cacheDirs(); err != nil {
+		findings = append(findings, finding{findingFail, "cache", err.Error(), "fix the configuration file, see ghissues config show"})
+	} else {
+		findings = append(findings, checkCaches(dirs)...)
+	}
+
+	if mountpoints, err := ghissuesMounts(); err != nil {
+		findings = append(findings, finding{findingWarn, "mounts", err.Error(), ""})
+	} else {
+		findings = append(findings, checkMounts(mountpoints, func(path string) error {
+			_, err := os.Stat(path)
+			return err
+		})...)
+	}
+
+	runDir, err := getRunDir()
+	if err != nil {
+		return err
+	}
+	runFindings, err := checkRunDir(runDir)
+	if err != nil {
+		return err
+	}
+	findings = append(findings, runFindings...)
+
+	if failed := writeFindings(os.Stdout, findings); failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/config"
+	"github.com/JohanCodinha/ghissues/internal/gh"
+)
+
+// statuses returns the status of each finding, for compact assertions.
+func statuses(findings []finding) []findingStatus {
+	out := make([]findingStatus, len(findings))
+	for i, f := range findings {
+		out[i] = f.status
+	}
+	return out
+}
+
+func TestCheckFuse(t *testing.T) {
+	devFuse := filepath.Join(t.TempDir(), "fuse")
+	os.WriteFile(devFuse, nil, 0600)
+
+	lookPath := func(found ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			for _, f := range found {
+				if f == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", exec.ErrNotFound
+		}
+	}
+
+	tests := []struct {
+		name     string
+		devFuse  string
+		lookPath func(string) (string, error)
+		want     []findingStatus
+	}{
+		{"all present", devFuse, lookPath("fusermount", "fusermount3"), []findingStatus{findingOK, findingOK}},
+		{"no device", filepath.Join(t.TempDir(), "missing"), lookPath("fusermount"), []findingStatus{findingFail, findingOK}},
+		{"only fusermount3", devFuse, lookPath("fusermount3"), []findingStatus{findingOK, findingWarn}},
+		{"no fusermount", devFuse, lookPath(), []findingStatus{findingOK, findingFail}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := checkFuse("linux", tt.devFuse, tt.lookPath)
+			if got := statuses(findings); len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Errorf("checkFuse() = %+v, expected statuses %v", findings, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+
+	tests := []struct {
+		name   string
+		scopes *string
+		reject bool
+		want   findingStatus
+		fix    string
+	}{
+		{name: "fine-grained", want: findingOK},
+		{name: "repo scope", scopes: strPtr("read:org, repo"), want: findingOK},
+		{name: "public_repo only", scopes: strPtr("public_repo"), want: findingWarn, fix: "gh auth refresh"},
+		{name: "no repo scope", scopes: strPtr("gist"), want: findingFail, fix: "gh auth refresh"},
+		{name: "rejected", reject: true, want: findingFail, fix: "gh auth login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGH := gh.NewMockServer()
+			defer mockGH.Close()
+			if tt.scopes != nil {
+				mockGH.SetTokenScopes(*tt.scopes)
+			}
+			if tt.reject {
+				mockGH.SetNextError(401, `{"message": "Bad credentials"}`)
+			}
+
+			findings := checkToken(t.Context(), gh.TokenSourceGhCLI, "github.com", gh.NewWithBaseURL("test-token", mockGH.URL))
+			if findings[0].detail != "found via gh auth token" {
+				t.Errorf("first finding = %+v, expected the token source", findings[0])
+			}
+			last := findings[len(findings)-1]
+			if last.status != tt.want || !strings.Contains(last.fix, tt.fix) {
+				t.Errorf("last finding = %+v, expected status %v with fix %q", last, tt.want, tt.fix)
+			}
+		})
+	}
+}
+
+func TestCheckToken_IgnoredEnvToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "env-token")
+	mockGH := gh.NewMockServer()
+	defer mockGH.Close()
+
+	findings := checkToken(t.Context(), gh.TokenSourceGhConfig, "github.com", gh.NewWithBaseURL("test-token", mockGH.URL))
+	if findings[1].status != findingWarn || !strings.Contains(findings[1].detail, "GITHUB_TOKEN is set but ignored") {
+		t.Errorf("expected a warning about the ignored GITHUB_TOKEN, got %+v", findings)
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+// failingCredentials is a credential provider that never gets a token.
+type failingCredentials struct{}
+
+func (failingCredentials) Token(context.Context) (string, error) {
+	return "", errors.New("app not installed")
+}
+func (failingCredentials) Invalidate(string) {}
+
+func TestCheckAppToken(t *testing.T) {
+	settings := config.Settings{AppID: 12, AppInstallationID: 34}
+
+	if f := checkAppToken(t.Context(), settings, gh.StaticToken("installation-token")); f.status != findingOK || !strings.Contains(f.detail, "GitHub App 12 installation 34") {
+		t.Errorf("checkAppToken() = %+v, expected ok", f)
+	}
+	if f := checkAppToken(t.Context(), settings, failingCredentials{}); f.status != findingFail || !strings.Contains(f.detail, "app not installed") {
+		t.Errorf("checkAppToken() = %+v, expected a failure", f)
+	}
+}
+
+func TestCheckGhLogins(t *testing.T) {
+	if findings := checkGhLogins(nil, "github.com"); len(findings) != 0 {
+		t.Errorf("checkGhLogins(nil) = %+v, expected nothing", findings)
+	}
+
+	findings := checkGhLogins([]gh.GhLogin{{Host: "ghe.example.com", User: "alice"}}, "github.com")
+	if len(findings) != 1 || findings[0].status != findingWarn || !strings.Contains(findings[0].detail, "ghe.example.com") {
+		t.Errorf("expected a warning about ghe.example.com, got %+v", findings)
+	}
+
+	findings = checkGhLogins([]gh.GhLogin{{Host: "ghe.example.com"}, {Host: "github.com", User: "alice"}}, "github.com")
+	if len(findings) != 1 || findings[0].status != findingOK {
+		t.Errorf("expected github.com login to be ok, got %+v", findings)
+	}
+
+	// With --host, the enterprise login is the one that counts
+	findings = checkGhLogins([]gh.GhLogin{{Host: "github.com", User: "alice"}}, "ghe.example.com")
+	if len(findings) != 1 || findings[0].status != findingWarn || !strings.Contains(findings[0].fix, "--hostname ghe.example.com") {
+		t.Errorf("expected a warning to log into ghe.example.com, got %+v", findings)
+	}
+}
+
+func TestCheckCaches(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := cache.InitDB(filepath.Join(dir, "owner_good.db"))
+	if err != nil {
+		t.Fatalf("InitDB() error = %v", err)
+	}
+	db.UpsertIssue(cache.Issue{Number: 1, Repo: "owner/good", Title: "One", State: "open"})
+	db.Close()
+	os.WriteFile(filepath.Join(dir, "owner_broken.db"), bytes.Repeat([]byte("not a database"), 512), 0644)
+
+	findings := checkCaches([]string{dir, filepath.Join(dir, "missing")})
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", findings)
+	}
+	for _, f := range findings {
+		switch f.check {
+		case "cache owner/good":
+			if f.status != findingOK {
+				t.Errorf("owner/good: %+v, expected ok", f)
+			}
+		case "cache owner_broken":
+			if f.status != findingFail || !strings.Contains(f.fix, filepath.Join(dir, "owner_broken.db")) {
+				t.Errorf("owner_broken: %+v, expected a failure with a fix removing the file", f)
+			}
+		default:
+			t.Errorf("unexpected finding %+v", f)
+		}
+	}
+
+	if findings := checkCaches([]string{filepath.Join(dir, "missing")}); len(findings) != 1 || findings[0].status != findingOK {
+		t.Errorf("checkCaches() without caches = %+v", findings)
+	}
+}
+
+func TestParseProcMounts(t *testing.T) {
+	input := `proc /proc proc rw,nosuid 0 0
+ghissues /mnt/issues fuse.ghissues rw,nosuid,nodev,user_id=1000 0 0
+sshfs /mnt/remote fuse.sshfs rw 0 0
+ghissues /home/me/my\040issues fuse.ghissues rw 0 0
+`
+	got := parseProcMounts(strings.NewReader(input))
+	want := []string{"/mnt/issues", "/home/me/my issues"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("parseProcMounts() = %q, expected %q", got, want)
+	}
+}
+
+func TestParseMountOutput(t *testing.T) {
+	input := `/dev/disk3s1s1 on / (apfs, sealed, local, read-only, journaled)
+ghissues on /Users/me/my issues (macfuse, nodev, nosuid, synchronous, mounted by me)
+`
+	got := parseMountOutput(strings.NewReader(input))
+	if len(got) != 1 || got[0] != "/Users/me/my issues" {
+		t.Errorf("parseMountOutput() = %q", got)
+	}
+}
+
+func TestCheckMounts(t *testing.T) {
+	stat := func(path string) error {
+		switch path {
+		case "/mnt/stale":
+			return &os.PathError{Op: "stat", Path: path, Err: syscall.ENOTCONN}
+		case "/mnt/gone":
+			return errors.New("permission denied")
+		}
+		return nil
+	}
+
+	findings := checkMounts([]string{"/mnt/live", "/mnt/stale", "/mnt/gone"}, stat)
+	want := []findingStatus{findingOK, findingFail, findingWarn}
+	if got := statuses(findings); len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("checkMounts() = %+v, expected statuses %v", findings, want)
+	}
+	if !strings.Contains(findings[1].fix, "/mnt/stale") {
+		t.Errorf("stale mount fix %q should unmount /mnt/stale", findings[1].fix)
+	}
+}
+
+func TestCheckRunDir(t *testing.T) {
+	runDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(runDir, "dead.pid"), []byte("999999999\n"), 0644)
+	os.WriteFile(filepath.Join(runDir, "self.pid"), []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+	os.WriteFile(filepath.Join(runDir, "stale.sock"), nil, 0600)
+
+	findings, err := checkRunDir(runDir)
+	if err != nil {
+		t.Fatalf("checkRunDir() error = %v", err)
+	}
+
+	var details []string
+	for _, f := range findings {
+		details = append(details, f.detail)
+	}
+	joined := strings.Join(details, "\n")
+	if !strings.Contains(joined, "stale.sock") || !strings.Contains(joined, "dead.pid") {
+		t.Errorf("expected stale.sock and dead.pid to be reported, got:\n%s", joined)
+	}
+	if strings.Contains(joined, "self.pid") {
+		t.Errorf("the pidfile of a running process was reported:\n%s", joined)
+	}
+}
+
+func TestWriteFindings(t *testing.T) {
+	var buf bytes.Buffer
+	failed := writeFindings(&buf, []finding{
+		{findingOK, "fuse", "/dev/fuse is available", "unused"},
+		{findingFail, "scopes", "lacks repo", "gh auth refresh"},
+	})
+
+	if failed != 1 {
+		t.Errorf("writeFindings() = %d failed, expected 1", failed)
+	}
+	want := "[ok]   fuse: /dev/fuse is available\n[FAIL] scopes: lacks repo\n       fix: gh auth refresh\n"
+	if buf.String() != want {
+		t.Errorf("writeFindings() wrote:\n%s\nexpected:\n%s", buf.String(), want)
+	}
+}
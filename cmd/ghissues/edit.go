@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/JohanCodinha/ghissues/internal/fs"
+	"github.com/JohanCodinha/ghissues/internal/logger"
+	"github.com/JohanCodinha/ghissues/internal/md"
+	"github.com/spf13/cobra"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit [owner/repo] <issue-number>",
+	Short: "Edit an issue in $EDITOR without mounting",
+	Long: `Open an issue in $EDITOR as the same markdown file a mount shows, and push
+the changes to GitHub once the editor exits: title, body, state, labels and
+parent, edited comments, and new comments added under a "### new" heading.
+
+Without a repository, the GitHub repository of the current directory's
+"origin" remote is used. The issue is fetched from GitHub first; when that
+fails, the cached version is edited. If a mount serves the repository, the
+changes are pushed through it.
+
+If the edited file cannot be parsed, nothing is queued and the file is kept
+so the edit is not lost.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runEdit,
+}
+
+var newCmd = &cobra.Command{
+	Use:   "new <owner/repo> [title]",
+	Short: "Create an issue in $EDITOR without mounting",
+	Long: `Open a new issue template in $EDITOR and create the issue on GitHub once the
+editor exits, as if the file had been written to a mount.
+
+Saving an empty file aborts. If a mount serves the repository, the issue is
+created through it.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runNew,
+}
+
+// editorCommand returns the user's editor: $VISUAL, then $EDITOR, then vi.
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(env); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// editInEditor writes content to a temporary file named after pattern, opens
+// it in the user's editor and returns what was saved along with the file's
+// path. The editor runs through the shell so values like "code --wait" work.
+// The caller removes the file.
+func editInEditor(pattern, content string) (edited, path string, err error) {
+	f, err := os.CreateTemp("", pattern+"-*.md")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path = f.Name()
+	_, err = f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(path)
+		return "", "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		os.Remove(path)
+		return "", "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+	return string(data), path, nil
+}
+
+// parseGitHubRemote returns "owner/repo" for a remote URL of host in its
+// HTTPS, SSH or scp-like form.
+func parseGitHubRemote(url, host string) (string, bool) {
+	for _, prefix := range []string{
+		"https://" + host + "/",
+		"http://" + host + "/",
+		"ssh://git@" + host + "/",
+		"git://" + host + "/",
+		"git@" + host + ":",
+	} {
+		if rest, ok := strings.CutPrefix(url, prefix); ok {
+			repo := strings.TrimSuffix(strings.TrimSuffix(rest, "/"), ".git")
+			if _, _, err := validateRepo(repo); err != nil {
+				return "", false
+			}
+			return repo, true
+		}
+	}
+	return "", false
+}
+
+// repoFromGitRemote returns the repository on the configured host of the
+// "origin" remote of the git repository in the current directory.
+func repoFromGitRemote() (string, error) {
+	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return "", fmt.Errorf("no repository given and the current directory has no git \"origin\" remote")
+	}
+
+	host, err := githubHost()
+	if err != nil {
+		return "", err
+	}
+
+	url := strings.TrimSpace(string(out))
+	repo, ok := parseGitHubRemote(url, host)
+	if !ok {
+		return "", fmt.Errorf("no repository given and the origin remote %q is not a %s repository", url, host)
+	}
+	return repo, nil
+}
+
+// parseIssueNumber parses an issue number, with or without a leading "#".
+func parseIssueNumber(arg string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid issue number %q", arg)
+	}
+	return number, nil
+}
+
+// editIssue opens issue number of the session in the editor and queues and
+// pushes the changes. Messages go to w.
+func editIssue(ctx context.Context, w io.Writer, session *repoSession, number int) error {
+	if err := session.refresh(ctx, number); err != nil {
+		cached, _ := session.db.GetIssue(session.repo, number)
+		if cached == nil {
+			return fmt.Errorf("failed to fetch issue #%d: %w", number, err)
+		}
+		logger.Warn("edit: failed to refresh issue #%d, editing the cached version: %v", number, err)
+	}
+
+	issue, err := session.db.GetIssue(session.repo, number)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return fmt.Errorf("issue #%d not found in %s", number, session.repo)
+	}
+	comments, err := session.db.GetComments(session.repo, number)
+	if err != nil {
+		return err
+	}
+
+	content := md.ToMarkdown(issue, comments)
+	pattern := fmt.Sprintf("ghissues-%s-%d", strings.ReplaceAll(session.repo, "/", "-"), number)
+	edited, path, err := editInEditor(pattern, content)
+	if err != nil {
+		return err
+	}
+
+	if edited == content {
+		os.Remove(path)
+		fmt.Fprintf(w, "#%d: no changes\n", number)
+		return nil
+	}
+
+	queued, err := fs.ApplyIssueEdit(session.db, session.repo, number, edited)
+	if err != nil {
+		return fmt.Errorf("%w\nyour edit was kept in %s", err, path)
+	}
+	os.Remove(path)
+
+	if !queued {
+		fmt.Fprintf(w, "#%d: no changes\n", number)
+		return nil
+	}
+	return session.push(ctx)
+}
+
+// newIssue opens a new issue template in the editor and queues and pushes
+// the result. Messages go to w.
+func newIssue(ctx context.Context, w io.Writer, session *repoSession, title string) error {
+	pattern := fmt.Sprintf("ghissues-%s-new", strings.ReplaceAll(session.repo, "/", "-"))
+	edited, path, err := editInEditor(pattern, fs.NewIssueTemplate(session.repo, title))
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(edited) == "" {
+		os.Remove(path)
+		fmt.Fprintln(w, "aborted: the issue is empty")
+		return nil
+	}
+
+	if _, err := fs.ApplyNewIssue(session.db, session.repo, title, edited); err != nil {
+		return fmt.Errorf("%w\nyour issue was kept in %s", err, path)
+	}
+	os.Remove(path)
+
+	return session.push(ctx)
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	var repo string
+	if len(args) == 2 {
+		repo = args[0]
+	} else {
+		var err error
+		if repo, err = repoFromGitRemote(); err != nil {
+			return err
+		}
+	}
+	number, err := parseIssueNumber(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+
+	session, err := openRepoSession(repo)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	return editIssue(cmd.Context(), os.Stdout, session, number)
+}
+
+func runNew(cmd *cobra.Command, args []string) error {
+	title := ""
+	if len(args) == 2 {
+		title = args[1]
+	}
+
+	session, err := openRepoSession(args[0])
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	return newIssue(cmd.Context(), os.Stdout, session, title)
+}
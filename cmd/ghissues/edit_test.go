@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/gh"
+)
+
+func TestParseGitHubRemote(t *testing.T) {
+	tests := []struct {
+		url    string
+		want   string
+		wantOK bool
+	}{
+		{"https://github.com/owner/repo.git", "owner/repo", true},
+		{"https://github.com/owner/repo", "owner/repo", true},
+		{"git@github.com:owner/repo.git", "owner/repo", true},
+		{"ssh://git@github.com/owner/repo.git", "owner/repo", true},
+		{"https://gitlab.com/owner/repo.git", "", false},
+		{"https://github.com/owner", "", false},
+		{"git@ghe.example.com:owner/repo.git", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseGitHubRemote(tt.url, "github.com")
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseGitHubRemote(%q) = %q, %v; want %q, %v", tt.url, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if got, ok := parseGitHubRemote("git@ghe.example.com:owner/repo.git", "ghe.example.com"); got != "owner/repo" || !ok {
+		t.Errorf("parseGitHubRemote() of an enterprise remote = %q, %v", got, ok)
+	}
+}
+
+func TestParseIssueNumber(t *testing.T) {
+	if n, err := parseIssueNumber("#12"); err != nil || n != 12 {
+		t.Errorf("parseIssueNumber(#12) = %d, %v", n, err)
+	}
+	for _, arg := range []string{"0", "-1", "abc"} {
+		if _, err := parseIssueNumber(arg); err == nil {
+			t.Errorf("parseIssueNumber(%q) expected an error", arg)
+		}
+	}
+}
+
+// newTestSession opens a headless session of owner/repo against mockGH.
+func newTestSession(t *testing.T, mockGH *gh.MockServer) *repoSession {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GHISSUES_CONFIG", "")
+	t.Setenv("VISUAL", "")
+
+	db, engine, err := openRepo(gh.NewWithBaseURL("test-token", mockGH.URL), "owner", "repo")
+	if err != nil {
+		t.Fatalf("openRepo() error = %v", err)
+	}
+	return &repoSession{repo: "owner/repo", db: db, engine: engine}
+}
+
+func TestEditIssue(t *testing.T) {
+	mockGH := gh.NewMockServer()
+	defer mockGH.Close()
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Old", Body: "Body", State: "open"})
+
+	session := newTestSession(t, mockGH)
+	defer session.Close()
+
+	t.Setenv("EDITOR", `sed -i -e 's/^# Old$/# New/'`)
+	var out bytes.Buffer
+	if err := editIssue(t.Context(), &out, session, 1); err != nil {
+		t.Fatalf("editIssue(t.Context(), ) error = %v", err)
+	}
+
+	if got := mockGH.GetIssue(1).Title; got != "New" {
+		t.Errorf("GitHub title = %q, expected New", got)
+	}
+
+	// Saving without changes pushes nothing
+	t.Setenv("EDITOR", "true")
+	out.Reset()
+	if err := editIssue(t.Context(), &out, session, 1); err != nil {
+		t.Fatalf("editIssue(t.Context(), ) error = %v", err)
+	}
+	if !strings.Contains(out.String(), "#1: no changes") {
+		t.Errorf("expected no changes, got %q", out.String())
+	}
+}
+
+func TestEditIssue_KeepsUnparsableEdit(t *testing.T) {
+	mockGH := gh.NewMockServer()
+	defer mockGH.Close()
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Old", Body: "Body", State: "open"})
+
+	session := newTestSession(t, mockGH)
+	defer session.Close()
+
+	t.Setenv("EDITOR", `f() { printf -- '---\nlabels: [\n' > "$1"; }; f`)
+	err := editIssue(t.Context(), &bytes.Buffer{}, session, 1)
+	if err == nil || !strings.Contains(err.Error(), "your edit was kept in ") {
+		t.Fatalf("editIssue(t.Context(), ) error = %v, expected the edit to be kept", err)
+	}
+
+	path := err.Error()[strings.LastIndex(err.Error(), " ")+1:]
+	defer os.Remove(path)
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "labels: [") {
+		t.Errorf("kept file %s does not hold the edit: %q", path, data)
+	}
+	if dirty, _ := session.db.GetDirtyIssues("owner/repo"); len(dirty) != 0 {
+		t.Errorf("nothing should be queued, got %+v", dirty)
+	}
+}
+
+func TestNewIssue(t *testing.T) {
+	mockGH := gh.NewMockServer()
+	defer mockGH.Close()
+
+	session := newTestSession(t, mockGH)
+	defer session.Close()
+
+	t.Setenv("EDITOR", `sed -i -e '$a Details'`)
+	if err := newIssue(t.Context(), &bytes.Buffer{}, session, "Created"); err != nil {
+		t.Fatalf("newIssue(t.Context(), ) error = %v", err)
+	}
+
+	issue := mockGH.GetIssue(1)
+	if issue == nil || issue.Title != "Created" || issue.Body != "Details" {
+		t.Errorf("GitHub issue = %+v, expected Created with body Details", issue)
+	}
+
+	// An emptied file aborts
+	t.Setenv("EDITOR", `f() { : > "$1"; }; f`)
+	var out bytes.Buffer
+	if err := newIssue(t.Context(), &out, session, ""); err != nil {
+		t.Fatalf("newIssue(t.Context(), ) error = %v", err)
+	}
+	if !strings.Contains(out.String(), "aborted") {
+		t.Errorf("expected the empty issue to abort, got %q", out.String())
+	}
+	if pending, _ := session.db.GetPendingIssues("owner/repo"); len(pending) != 0 {
+		t.Errorf("nothing should be queued, got %+v", pending)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/export"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags for the export command
+var (
+	exportFormat string
+	exportOutput string
+	exportState  string
+	exportLabels []string
+	exportSince  string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <owner/repo>",
+	Short: "Export cached issues to JSON Lines, CSV or markdown files",
+	Long: `Export the cached issues of a repository with their comments, labels,
+parent and sub-issue counts. Only the local cache is read, so this works
+offline; run "ghissues sync owner/repo" first for fresh data.
+
+Formats:
+  jsonl   one JSON object per issue (default)
+  csv     one row per issue, comments embedded as a JSON array
+  md-dir  one markdown file per issue, as they appear in a mount
+
+jsonl and csv are written to stdout unless --output is given; md-dir
+requires --output to name the directory to write to.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", export.FormatJSONL, "Output format (jsonl, csv, md-dir)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file, or directory for md-dir")
+	exportCmd.Flags().StringVar(&exportState, "state", "", "Only export issues in this state (open, closed)")
+	exportCmd.Flags().StringSliceVar(&exportLabels, "label", nil, "Only export issues with this label (repeatable, all must match)")
+	exportCmd.Flags().StringVar(&exportSince, "since", "", "Only export issues updated since this date (YYYY-MM-DD or RFC3339)")
+}
+
+// parseSince parses a --since value as a date or an RFC3339 timestamp.
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: must be YYYY-MM-DD or RFC3339", value)
+}
+
+// exportFilter builds the issue filter from the export flags.
+func exportFilter() (export.Filter, error) {
+	filter := export.Filter{Labels: exportLabels}
+
+	switch exportState {
+	case "", "all":
+	case "open", "closed":
+		filter.State = exportState
+	default:
+		return export.Filter{}, fmt.Errorf("invalid state %q: must be open or closed", exportState)
+	}
+
+	if exportSince != "" {
+		since, err := parseSince(exportSince)
+		if err != nil {
+			return export.Filter{}, err
+		}
+		filter.Since = since
+	}
+
+	return filter, nil
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	repo := args[0]
+
+	switch exportFormat {
+	case export.FormatJSONL, export.FormatCSV:
+	case export.FormatMDDir:
+		if exportOutput == "" {
+			return fmt.Errorf("--format md-dir requires --output <directory>")
+		}
+	default:
+		return fmt.Errorf("unknown format %q: must be jsonl, csv or md-dir", exportFormat)
+	}
+
+	filter, err := exportFilter()
+	if err != nil {
+		return err
+	}
+
+	db, err := openExistingCache(repo)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	records, err := export.Load(db, repo, filter)
+	if err != nil {
+		return err
+	}
+
+	if exportFormat == export.FormatMDDir {
+		if err := export.WriteMarkdownDir(exportOutput, records); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "exported %d issues to %s\n", len(records), exportOutput)
+		return nil
+	}
+
+	var w io.Writer = os.Stdout
+	if exportOutput != "" {
+		f, err := os.Create(exportOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if exportFormat == export.FormatCSV {
+		err = export.WriteCSV(w, records)
+	} else {
+		err = export.WriteJSONL(w, records)
+	}
+	if err != nil {
+		return err
+	}
+
+	if exportOutput != "" {
+		fmt.Fprintf(os.Stderr, "exported %d issues to %s\n", len(records), exportOutput)
+	}
+	return nil
+}
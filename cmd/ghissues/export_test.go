@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+)
+
+func TestParseSince(t *testing.T) {
+	if got, err := parseSince("2026-01-13T10:00:00Z"); err != nil || !got.Equal(time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseSince(RFC3339) = %v, %v", got, err)
+	}
+	if got, err := parseSince("2026-01-13"); err != nil || got.Day() != 13 || got.Hour() != 0 {
+		t.Errorf("parseSince(date) = %v, %v", got, err)
+	}
+	if _, err := parseSince("last week"); err == nil {
+		t.Error("expected an error for an invalid date")
+	}
+}
+
+func TestExportCmd_WritesFromCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+	// No token and no network: export must only read the cache
+	t.Setenv("GITHUB_TOKEN", "")
+
+	db := createTestCache(t, "owner", "repo")
+	db.UpsertIssue(cache.Issue{Number: 1, Repo: "owner/repo", Title: "Open bug", State: "open", Labels: []string{"bug"}, UpdatedAt: "2026-01-10T10:00:00Z"})
+	db.UpsertIssue(cache.Issue{Number: 2, Repo: "owner/repo", Title: "Closed bug", State: "closed", Labels: []string{"bug"}, UpdatedAt: "2026-01-10T10:00:00Z"})
+	db.Close()
+
+	defer func() {
+		exportFormat, exportOutput, exportState, exportLabels, exportSince = "jsonl", "", "", nil, ""
+	}()
+
+	output := filepath.Join(tmpDir, "issues.csv")
+	rootCmd.SetArgs([]string{"export", "owner/repo", "--format", "csv", "--state", "open", "--label", "bug", "-o", output})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	if !strings.Contains(string(content), "Open bug") || strings.Contains(string(content), "Closed bug") {
+		t.Errorf("unexpected export:\n%s", content)
+	}
+}
+
+func TestExportCmd_RejectsBadFlags(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+	createTestCache(t, "owner", "repo").Close()
+
+	defer func() {
+		exportFormat, exportOutput, exportState, exportLabels, exportSince = "jsonl", "", "", nil, ""
+	}()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"unknown format", []string{"--format", "xml"}, "unknown format"},
+		{"md-dir without output", []string{"--format", "md-dir"}, "requires --output"},
+		{"bad state", []string{"--state", "pending"}, "invalid state"},
+		{"bad since", []string{"--since", "yesterday"}, "invalid date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exportFormat, exportOutput, exportState, exportLabels, exportSince = "jsonl", "", "", nil, ""
+			rootCmd.SetArgs(append([]string{"export", "owner/repo"}, tt.args...))
+			err := rootCmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("export error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
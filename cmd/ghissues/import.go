@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/importer"
+	"github.com/JohanCodinha/ghissues/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags for the import command
+var (
+	importFormat string
+	importDryRun bool
+	importPush   bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <owner/repo> <file>",
+	Short: "Queue new issues from a CSV or JSON file",
+	Long: `Queue one new issue per row of a CSV or JSON file. The issues are added
+to the same pending queue as files created in a mount, so the next sync
+creates them on GitHub.
+
+CSV files need a header row with a "title" column and may have "body",
+"labels" (separated by ";") and "id" columns. JSON files hold an array of
+objects, or one object per line, with the same fields.
+
+Every row is validated before anything is queued; if any row is invalid,
+all errors are reported and nothing is imported.
+
+Imported rows are recorded in the cache, so running the same import again
+only queues rows that were not imported before. Rows are matched by their
+"id" when they have one and by their title, body and labels otherwise.
+
+Use --dry-run to preview what would be queued, and --push to create the
+issues on GitHub right away instead of waiting for the next sync.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFormat, "format", "", "Input format (csv, json; defaults to the file extension)")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would be queued without changing anything")
+	importCmd.Flags().BoolVar(&importPush, "push", false, "Create the queued issues on GitHub after importing")
+}
+
+// readImportFile parses and validates the rows of path.
+func readImportFile(path, format string) ([]importer.Row, error) {
+	if format == "" {
+		var err error
+		format, err = importer.FormatFromPath(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer f.Close()
+
+	rows, err := importer.Parse(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid import file %s:\n%w", path, err)
+	}
+	return rows, nil
+}
+
+// describeImported says where an already imported row ended up.
+func describeImported(record cache.ImportRecord) string {
+	if record.IssueNumber > 0 {
+		return fmt.Sprintf("already created as #%d", record.IssueNumber)
+	}
+	return "already queued"
+}
+
+// importRows queues the rows that are not in the import ledger of repo and
+// writes one line per row to w: "+" for queued rows, "=" for skipped ones. With dryRun nothing is written to db, which
+// may then be nil if the repository has no cache yet.
+func importRows(w io.Writer, db *cache.DB, repo string, rows []importer.Row, dryRun bool) (imported, skipped int, err error) {
+	ledger := map[string]cache.ImportRecord{}
+	if db != nil {
+		ledger, err = db.GetImportLedger(repo)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	for _, row := range rows {
+		if record, ok := ledger[row.Key]; ok {
+			fmt.Fprintf(w, "  = row %d: %s (%s)\n", row.Line, row.Title, describeImported(record))
+			skipped++
+			continue
+		}
+
+		if dryRun {
+			fmt.Fprintf(w, "  + row %d: %s\n", row.Line, row.Title)
+			imported++
+			continue
+		}
+
+		_, added, err := db.AddImportedIssue(repo, row.Key, row.Title, row.Body, row.Labels)
+		if err != nil {
+			return imported, skipped, fmt.Errorf("row %d: %w", row.Line, err)
+		}
+		if !added {
+			// Imported by a concurrent run since the ledger was read
+			fmt.Fprintf(w, "  = row %d: %s (already queued)\n", row.Line, row.Title)
+			skipped++
+			continue
+		}
+		fmt.Fprintf(w, "  + row %d: %s\n", row.Line, row.Title)
+		imported++
+	}
+
+	return imported, skipped, nil
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	repo, path := args[0], args[1]
+
+	owner, repoName, err := validateRepo(repo)
+	if err != nil {
+		return err
+	}
+	if importDryRun && importPush {
+		return fmt.Errorf("--dry-run and --push cannot be used together")
+	}
+
+	rows, err := readImportFile(path, importFormat)
+	if err != nil {
+		return err
+	}
+
+	cachePath, err := getCachePath(owner, repoName)
+	if err != nil {
+		return err
+	}
+
+	var db *cache.DB
+	if _, statErr := os.Stat(cachePath); statErr == nil || !importDryRun {
+		db, err = cache.InitDB(cachePath)
+		if err != nil {
+			return fmt.Errorf("failed to initialize cache: %w", err)
+		}
+	}
+
+	imported, skipped, err := importRows(os.Stdout, db, repo, rows, importDryRun)
+	if db != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			logger.Warn("failed to close cache: %v", closeErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if importDryRun {
+		fmt.Printf("%s: would queue %d issues, %d already imported\n", repo, imported, skipped)
+		return nil
+	}
+	fmt.Printf("%s: queued %d issues, %d already imported\n", repo, imported, skipped)
+
+	if !importPush {
+		if imported > 0 {
+			fmt.Printf("run \"ghissues sync %s\" to create them on GitHub\n", repo)
+		}
+		return nil
+	}
+
+	return pushImported(cmd.Context(), repo)
+}
+
+// pushImported creates queued issues on GitHub, through a running mount of
+// repo if there is one and headless otherwise.
+func pushImported(ctx context.Context, repo string) error {
+	session, err := openRepoSession(repo)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	return session.push(ctx)
+}
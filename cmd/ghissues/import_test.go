@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/importer"
+)
+
+func TestImportRows_ResumesFromLedger(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	db := createTestCache(t, "owner", "repo")
+	defer db.Close()
+
+	rows, err := importer.Parse(strings.NewReader("id,title\n1,First\n2,Second\n"), importer.FormatCSV)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// A dry run queues nothing
+	var out bytes.Buffer
+	imported, skipped, err := importRows(&out, db, "owner/repo", rows, true)
+	if err != nil || imported != 2 || skipped != 0 {
+		t.Fatalf("dry run = %d, %d, %v", imported, skipped, err)
+	}
+	if pending, _ := db.GetPendingIssues("owner/repo"); len(pending) != 0 {
+		t.Fatalf("dry run queued %d issues", len(pending))
+	}
+
+	// Import the first row, as if an earlier run was interrupted
+	if _, _, err := importRows(&out, db, "owner/repo", rows[:1], false); err != nil {
+		t.Fatalf("importRows() error = %v", err)
+	}
+	pending, _ := db.GetPendingIssues("owner/repo")
+	if err := db.RecordCreatedIssue(pending[0].ID, 12); err != nil {
+		t.Fatalf("RecordCreatedIssue() error = %v", err)
+	}
+	db.RemovePendingIssue(pending[0].ID)
+
+	out.Reset()
+	imported, skipped, err = importRows(&out, db, "owner/repo", rows, false)
+	if err != nil || imported != 1 || skipped != 1 {
+		t.Fatalf("re-run = %d, %d, %v", imported, skipped, err)
+	}
+	if !strings.Contains(out.String(), "= row 2: First (already created as #12)") || !strings.Contains(out.String(), "+ row 3: Second") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	pending, _ = db.GetPendingIssues("owner/repo")
+	if len(pending) != 1 || pending[0].Title != "Second" {
+		t.Errorf("expected only Second to be queued, got %+v", pending)
+	}
+}
+
+func TestImportCmd_DryRunWithoutCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	input := filepath.Join(tmpDir, "backlog.json")
+	os.WriteFile(input, []byte(`[{"title": "First", "labels": ["bug"]}]`), 0644)
+
+	defer func() { importFormat, importDryRun, importPush = "", false, false }()
+
+	rootCmd.SetArgs([]string{"import", "owner/repo", input, "--dry-run"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("import --dry-run error = %v", err)
+	}
+
+	cachePath, _ := getCachePath("owner", "repo")
+	if _, err := os.Stat(cachePath); !os.IsNotExist(err) {
+		t.Error("a dry run should not create the cache")
+	}
+}
+
+func TestImportCmd_InvalidFileImportsNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+	createTestCache(t, "owner", "repo").Close()
+
+	input := filepath.Join(tmpDir, "backlog.csv")
+	os.WriteFile(input, []byte("title,labels\nFine,\n,bug\n"), 0644)
+
+	defer func() { importFormat, importDryRun, importPush = "", false, false }()
+
+	rootCmd.SetArgs([]string{"import", "owner/repo", input})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "row 3: title is empty") {
+		t.Fatalf("expected a validation error for row 3, got %v", err)
+	}
+
+	db := createTestCache(t, "owner", "repo")
+	defer db.Close()
+	if pending, _ := db.GetPendingIssues("owner/repo"); len(pending) != 0 {
+		t.Errorf("an invalid file queued %d issues", len(pending))
+	}
+}
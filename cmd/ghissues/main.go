@@ -2,14 +2,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/config"
+	"github.com/JohanCodinha/ghissues/internal/control"
 	"github.com/JohanCodinha/ghissues/internal/fs"
 	"github.com/JohanCodinha/ghissues/internal/gh"
 	"github.com/JohanCodinha/ghissues/internal/logger"
@@ -24,6 +31,38 @@ var (
 	quiet    bool
 )
 
+// CLI flag selecting a GitHub Enterprise Server, for every command
+var hostFlag string
+
+// CLI flags for org mounts
+var (
+	mountOrg    string
+	rescanEvery time.Duration
+)
+
+// CLI flags for daemon mounts and unmount
+var (
+	mountDaemon    bool
+	unmountTimeout time.Duration
+)
+
+// CLI flag for read-only mounts
+var mountReadOnly bool
+
+// CLI flag for mounting without contacting GitHub
+var mountOffline bool
+
+// CLI flag for leaving out pull requests
+var mountHidePulls bool
+
+// Issue filter flags for mount
+var (
+	mountState    string
+	mountLabels   []string
+	mountSince    string
+	mountAssignee string
+)
+
 // validateRepo validates the repository format and returns the owner and repo name.
 // The format must be "owner/repo" where neither owner nor repo is empty.
 func validateRepo(repo string) (owner, name string, err error) {
@@ -44,6 +83,14 @@ func validateRepo(repo string) (owner, name string, err error) {
 // Returns true if the mountpoint was created, false if it already existed.
 func ensureMountpoint(path string) (created bool, err error) {
 	info, err := os.Stat(path)
+	if errors.Is(err, syscall.ENOTCONN) {
+		// A mount that crashed leaves its FUSE mount behind, unusable
+		logger.Warn("%s is a stale mount left by a crashed ghissues, unmounting it", path)
+		if out, err := getLazyUnmountCommand(path).CombinedOutput(); err != nil {
+			return false, fmt.Errorf("failed to unmount stale mountpoint %q: %w: %s", path, err, strings.TrimSpace(string(out)))
+		}
+		info, err = os.Stat(path)
+	}
 	if err != nil {
 		if os.IsNotExist(err) {
 			if err := os.MkdirAll(path, 0755); err != nil {
@@ -62,14 +109,16 @@ func ensureMountpoint(path string) (created bool, err error) {
 }
 
 // getCachePath returns the path to the cache database file for the given repository.
-// The cache is stored at ~/.cache/ghissues/{owner}_{repo}.db
+// The cache is stored at {cache_dir}/{owner}_{repo}.db, where cache_dir
+// defaults to ~/.cache/ghissues and can be set in the config file. Caches
+// of a GitHub Enterprise Server go to {cache_dir}/{host}/ instead.
 func getCachePath(owner, repoName string) (string, error) {
-	homeDir, err := os.UserHomeDir()
+	settings, err := loadSettings(nil, owner+"/"+repoName)
 	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+		return "", err
 	}
 
-	cacheDir := filepath.Join(homeDir, ".cache", "ghissues")
+	cacheDir := hostCacheDir(settings.CacheDir, settings.Host)
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create cache directory: %w", err)
 	}
@@ -77,6 +126,33 @@ func getCachePath(owner, repoName string) (string, error) {
 	return filepath.Join(cacheDir, fmt.Sprintf("%s_%s.db", owner, repoName)), nil
 }
 
+// getJournalDir returns the directory of the write-ahead journal kept next
+// to the cache at cachePath: {cache_dir}/{owner}_{repo}.journal
+func getJournalDir(cachePath string) string {
+	return strings.TrimSuffix(cachePath, ".db") + ".journal"
+}
+
+// getRunDir returns the directory holding control sockets of running mounts:
+// {cache_dir}/run. Only the global cache_dir is used, not a repository's
+// own, so that commands not naming a repository find every mount.
+func getRunDir() (string, error) {
+	settings, err := loadSettings(nil, "")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(settings.CacheDir, "run"), nil
+}
+
+// getSocketPath returns the control socket path for the given mountpoint.
+func getSocketPath(mountpoint string) (string, error) {
+	runDir, err := getRunDir()
+	if err != nil {
+		return "", err
+	}
+	return control.SocketPath(runDir, mountpoint)
+}
+
 // getUnmountCommand returns the appropriate system unmount command for the current OS.
 func getUnmountCommand(mountpoint string) *exec.Cmd {
 	if runtime.GOOS == "darwin" {
@@ -85,6 +161,15 @@ func getUnmountCommand(mountpoint string) *exec.Cmd {
 	return exec.Command("fusermount", "-u", mountpoint)
 }
 
+// getLazyUnmountCommand returns the command detaching a mount whose FUSE
+// server is gone.
+func getLazyUnmountCommand(mountpoint string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("umount", "-f", mountpoint)
+	}
+	return exec.Command("fusermount", "-uz", mountpoint)
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -100,13 +185,41 @@ using your favorite text editor.`,
 }
 
 var mountCmd = &cobra.Command{
-	Use:   "mount <owner/repo> <mountpoint>",
-	Short: "Mount a repository's issues as a filesystem",
-	Long: `Mount a GitHub repository's issues as markdown files at the specified mountpoint.
-
-The repository must be specified in the format "owner/repo".
-The mountpoint must be an existing directory.`,
-	Args: cobra.ExactArgs(2),
+	Use:   "mount <owner/repo>... <mountpoint>",
+	Short: "Mount one or more repositories' issues as a filesystem",
+	Long: `Mount GitHub repositories' issues as markdown files at the specified mountpoint.
+
+Repositories must be specified in the format "owner/repo".
+The mountpoint must be an existing directory.
+
+With a single repository, its issues appear directly in the mountpoint.
+With several, each gets an owner/repo subdirectory with its own sync
+engine, and the top-level .status aggregates all of them:
+
+  ghissues mount org/a org/b org/c ./issues
+
+To mount every repository of an organization (or user) that has issues
+enabled, use owner/* or --org. The repository list is rescanned
+periodically, so new repositories appear and archived or deleted ones
+disappear while mounted:
+
+  ghissues mount 'org/*' ./issues
+  ghissues mount --org org ./issues
+
+With --daemon, ghissues runs in the background and returns once the
+initial sync is done and the filesystem is mounted. Its pid and log are
+kept next to its control socket unless --log-file is given; stop it with
+"ghissues unmount <mountpoint>".
+
+With --read-only, files cannot be changed or created and nothing is pushed;
+issues are still refreshed from GitHub. A repository on which the token can
+neither push nor triage is mounted read-only automatically.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if mountOrg != "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(2)(cmd, args)
+	},
 	RunE: runMount,
 }
 
@@ -115,37 +228,229 @@ var unmountCmd = &cobra.Command{
 	Short: "Unmount a previously mounted filesystem",
 	Long: `Unmount a ghissues filesystem and flush any pending changes to GitHub.
 
-The mountpoint must be an existing directory where ghissues is mounted.`,
+The mountpoint must be an existing directory where ghissues is mounted.
+
+The running mount is asked to unmount over its control socket, then pushes
+everything still queued and reports the result. The command waits for that
+final flush, and for a daemon to exit, prints any push errors and exits
+non-zero if a change could not be pushed. Those changes stay in the cache
+and are retried by the next mount or sync.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runUnmount,
 }
 
 func init() {
 	// Add logging flags to mount command
-	mountCmd.Flags().StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
+	mountCmd.Flags().StringVar(&logLevel, "log-level", "", "Log level (debug, info, warn, error; defaults to the config file, then info)")
 	mountCmd.Flags().StringVar(&logFile, "log-file", "", "Path to log file (logs to stderr if not set)")
 	mountCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress non-error output")
+	mountCmd.Flags().StringVar(&mountOrg, "org", "", "Mount every repository of this organization or user")
+	mountCmd.Flags().DurationVar(&rescanEvery, "rescan", 10*time.Minute, "How often an org mount checks for added or removed repositories")
+	mountCmd.Flags().BoolVarP(&mountDaemon, "daemon", "d", false, "Run in the background once mounted and synced")
+	mountCmd.Flags().BoolVar(&mountReadOnly, "read-only", false, "Mount read-only: refresh issues but never push changes")
+	mountCmd.Flags().BoolVar(&mountHidePulls, "hide-pulls", false, "Leave pull requests out of the mount instead of serving them in pulls/")
+	mountCmd.Flags().BoolVar(&mountOffline, "offline", false, "Serve cached issues without contacting GitHub, queuing changes until the next online mount or sync")
+	mountCmd.Flags().StringVar(&mountState, "state", "", "Only mount issues in this state: open, closed or all (default all)")
+	mountCmd.Flags().StringArrayVar(&mountLabels, "label", nil, "Only mount issues with this label (repeatable, all must match)")
+	mountCmd.Flags().StringVar(&mountSince, "since", "", "Only mount issues updated within a period like 90d, 2w or 36h, or since a date like 2024-01-31")
+	mountCmd.Flags().StringVar(&mountAssignee, "assignee", "", "Only mount issues assigned to this login, @me, none or *")
+	unmountCmd.Flags().DurationVar(&unmountTimeout, "timeout", 0, "Give up waiting for the final flush after this long (0 waits forever)")
+	rootCmd.PersistentFlags().StringVar(&hostFlag, "host", "", "GitHub Enterprise Server hostname, like ghe.example.com (defaults to the config file, then github.com)")
 
 	rootCmd.AddCommand(mountCmd)
 	rootCmd.AddCommand(unmountCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(refreshCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(pendingCmd)
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(serviceCmd)
+}
+
+// mountedRepo is one repository served by a mount.
+type mountedRepo struct {
+	name    string
+	cache   *cache.DB
+	engine  *sync.Engine
+	journal *fs.Journal
+}
+
+// fsRepo describes m to a multi-repo filesystem.
+func (m mountedRepo) fsRepo() fs.Repo {
+	engine := m.engine
+	return fs.Repo{
+		Name:            m.name,
+		Cache:           m.cache,
+		OnDirty:         func() { engine.TriggerSync() },
+		StatusProvider:  engine,
+		RefreshProvider: engine,
+		ReadOnly:        engine.ReadOnly(),
+		HidePulls:       engine.HidePulls(),
+		Journal:         m.journal,
+	}
+}
+
+// recoverJournal opens the write-ahead journal of m and queues the edits a
+// crashed mount left in it. Without a journal the repository still mounts,
+// but writes not yet flushed do not survive a crash.
+func recoverJournal(m *mountedRepo) {
+	journal, err := fs.OpenJournal(getJournalDir(m.cache.Path()))
+	if err != nil {
+		logger.Warn("%s: %v, unsaved edits will not survive a crash", m.name, err)
+		return
+	}
+	m.journal = journal
+
+	recovered, kept, err := fs.RecoverJournal(journal, m.cache, m.name)
+	if err != nil {
+		logger.Warn("%s: failed to recover unsaved edits: %v", m.name, err)
+		return
+	}
+	if recovered > 0 {
+		logger.Info("%s: recovered %d unsaved edits from a previous mount", m.name, recovered)
+	}
+	if len(kept) > 0 {
+		logger.Warn("%s: %d unsaved edits could not be applied and were kept in %s", m.name, len(kept), journal.Dir())
+	}
 }
 
-func runMount(cmd *cobra.Command, args []string) error {
-	repo := args[0]
-	mountpoint := args[1]
+// applyReadOnly makes the engine of m read-only when forced, or when the
+// token may not edit the issues of the repository.
+func applyReadOnly(ctx context.Context, client *gh.Client, m mountedRepo, forced bool) {
+	if forced {
+		m.engine.SetReadOnly(true)
+		return
+	}
+	if m.engine.Offline() {
+		return
+	}
 
-	// Configure logging based on CLI flags
-	if err := configureLogging(); err != nil {
+	owner, repoName, _ := validateRepo(m.name)
+	repo, err := client.GetRepository(ctx, owner, repoName)
+	if err != nil {
+		logger.Debug("failed to check permissions on %s: %v", m.name, err)
+		return
+	}
+	if repo.Permissions != nil && !repo.Permissions.CanEditIssues() {
+		logger.Warn("token can neither push to nor triage %s, mounting it read-only", m.name)
+		m.engine.SetReadOnly(true)
+	}
+}
+
+// resolveIssueFilter checks filter and replaces the "@me" assignee with the
+// login of the token.
+func resolveIssueFilter(ctx context.Context, client *gh.Client, filter gh.IssueFilter) (gh.IssueFilter, error) {
+	if err := filter.Validate(); err != nil {
+		return filter, err
+	}
+	if filter.Assignee != "@me" {
+		return filter, nil
+	}
+
+	info, err := client.TokenInfo(ctx)
+	if err != nil {
+		return filter, fmt.Errorf("failed to resolve --assignee @me: %w", err)
+	}
+	filter.Assignee = info.Login
+	return filter, nil
+}
+
+// parseMountArgs splits mount arguments into repositories and the mountpoint,
+// validating each repository and rejecting duplicates.
+// An org mount, from the --org flag or an owner/* argument, returns the owner
+// in org and no repositories.
+func parseMountArgs(args []string, orgFlag string) (repos []string, org, mountpoint string, err error) {
+	repos = args[:len(args)-1]
+	mountpoint = args[len(args)-1]
+
+	if orgFlag != "" {
+		if len(repos) > 0 {
+			return nil, "", "", fmt.Errorf("--org cannot be combined with repositories")
+		}
+		return nil, orgFlag, mountpoint, nil
+	}
+
+	seen := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		if owner, ok := strings.CutSuffix(repo, "/*"); ok {
+			if len(repos) != 1 {
+				return nil, "", "", fmt.Errorf("%s must be the only repository when mounting a whole owner", repo)
+			}
+			if owner == "" || strings.Contains(owner, "/") {
+				return nil, "", "", fmt.Errorf("invalid owner in %q: must be in the format owner/*", repo)
+			}
+			return nil, owner, mountpoint, nil
+		}
+		if _, _, err := validateRepo(repo); err != nil {
+			return nil, "", "", err
+		}
+		if seen[repo] {
+			return nil, "", "", fmt.Errorf("repository %s given more than once", repo)
+		}
+		seen[repo] = true
+	}
+
+	return repos, "", mountpoint, nil
+}
+
+func runMount(cmd *cobra.Command, args []string) (err error) {
+	ctx := cmd.Context()
+	repos, org, mountpoint, err := parseMountArgs(args, mountOrg)
+	if err != nil {
+		return err
+	}
+	if org != "" && mountOffline {
+		return fmt.Errorf("--offline cannot be combined with an org mount, which lists its repositories on GitHub")
+	}
+	filter := gh.IssueFilter{State: mountState, Labels: mountLabels, Since: mountSince, Assignee: mountAssignee}
+	if err := filter.Validate(); err != nil {
 		return err
 	}
-	defer logger.Close()
 
-	// Validate repo format
-	owner, repoName, err := validateRepo(repo)
+	// Configure logging from flags, env and config file. Repo-specific
+	// logging overrides only apply when a single repository is mounted.
+	logRepo := ""
+	if len(repos) == 1 {
+		logRepo = repos[0]
+	}
+	settings, err := loadSettings(cmd, logRepo)
 	if err != nil {
 		return err
 	}
 
+	// With --daemon, start a copy of this command in the background and
+	// return once it is mounted; that copy runs the mount below.
+	var daemon *daemonChild
+	if mountDaemon {
+		if os.Getenv(daemonChildEnv) == "" {
+			if _, err := ensureMountpoint(mountpoint); err != nil {
+				return err
+			}
+			return startDaemon(mountpoint, settings.LogFile)
+		}
+		daemon, err = newDaemonChild(mountpoint)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				daemon.Fail(err)
+			}
+			daemon.Close()
+		}()
+	}
+
+	if err := configureLogging(settings); err != nil {
+		return err
+	}
+	defer logger.Close()
+
 	// Create mountpoint if it doesn't exist
 	created, err := ensureMountpoint(mountpoint)
 	if err != nil {
@@ -155,74 +460,105 @@ func runMount(cmd *cobra.Command, args []string) error {
 		logger.Info("created mountpoint %s", mountpoint)
 	}
 
-	// 1. Get GitHub auth token
-	token, err := gh.GetToken()
+	// 1-2. Authenticate and create the GitHub client shared by all repos.
+	// An offline mount never uses it, so it works without a token.
+	client, err := newGitHubClient()
 	if err != nil {
-		return fmt.Errorf("failed to get GitHub token: %w\nRun 'gh auth login' to authenticate", err)
+		if !mountOffline {
+			return err
+		}
+		logger.Debug("offline mount without a token: %v", err)
+		client = gh.NewForHost("", settings.Host)
 	}
-	logger.Info("authenticated with GitHub")
-
-	// 2. Create GitHub client
-	client := gh.New(token)
 
-	// 3. Determine cache path: ~/.cache/ghissues/{owner}_{repo}.db
-	cachePath, err := getCachePath(owner, repoName)
-	if err != nil {
-		return err
+	// The filter applies at the next sync, so an offline mount keeps the
+	// cached one
+	if !mountOffline {
+		if filter, err = resolveIssueFilter(ctx, client, filter); err != nil {
+			return err
+		}
+	} else if !filter.IsZero() {
+		logger.Warn("the issue filter applies at the next online mount, serving the cached issues")
 	}
 
-	// 4. Initialize cache
-	cacheDB, err := cache.InitDB(cachePath)
-	if err != nil {
-		return fmt.Errorf("failed to initialize cache: %w", err)
+	if org != "" {
+		return runOrgMount(ctx, client, org, mountpoint, daemon, mountReadOnly, mountHidePulls, filter)
 	}
-	logger.Info("cache initialized at %s", cachePath)
 
-	// 5. Create sync engine with 500ms debounce
-	engine, err := sync.NewEngine(cacheDB, client, repo, 500)
-	if err != nil {
-		cacheDB.Close()
-		return fmt.Errorf("failed to create sync engine: %w", err)
+	// 3-5. Open the cache and create a sync engine for each repo
+	var mounted []mountedRepo
+	for _, repo := range repos {
+		owner, repoName, _ := validateRepo(repo)
+		cacheDB, engine, err := openRepo(client, owner, repoName)
+		if err != nil {
+			closeRepos(mounted)
+			return err
+		}
+		m := mountedRepo{name: repo, cache: cacheDB, engine: engine}
+		recoverJournal(&m)
+		engine.SetOffline(mountOffline)
+		if !mountOffline {
+			engine.SetFilter(filter)
+		}
+		if mountHidePulls {
+			engine.SetHidePulls(true)
+		}
+		applyReadOnly(ctx, client, m, mountReadOnly)
+		mounted = append(mounted, m)
 	}
 
 	// 6. Run initial sync
-	logger.Info("syncing issues from %s...", repo)
-	if err := engine.InitialSync(); err != nil {
-		// Log warning but continue in offline mode
-		logger.Warn("initial sync failed: %v", err)
-		logger.Warn("continuing in offline mode with cached data")
+	for _, m := range mounted {
+		startupSync(ctx, m)
 	}
 
-	// 6b. Retry any pending items from previous session
-	if err := engine.SyncNow(); err != nil {
-		logger.Warn("failed to sync pending items: %v", err)
+	// 7. Create FS with onDirty callback to trigger sync, status provider, and refresh provider
+	var filesystem *fs.FS
+	if len(mounted) == 1 {
+		engine := mounted[0].engine
+		filesystem = fs.NewFS(mounted[0].cache, mounted[0].name, mountpoint, func() {
+			engine.TriggerSync()
+		}, engine, engine)
+		filesystem.SetReadOnly(engine.ReadOnly())
+		filesystem.SetHidePulls(engine.HidePulls())
+		filesystem.SetJournal(mounted[0].journal)
+	} else {
+		fsRepos := make([]fs.Repo, len(mounted))
+		for i, m := range mounted {
+			fsRepos[i] = m.fsRepo()
+		}
+		filesystem = fs.NewMultiFS(fsRepos, mountpoint)
+		filesystem.SetReadOnly(mountReadOnly)
 	}
 
-	// 7. Create FS with onDirty callback to trigger sync, status provider, and refresh provider
-	filesystem := fs.NewFS(cacheDB, repo, mountpoint, func() {
-		engine.TriggerSync()
-	}, engine, engine)
+	// 7b. Expose the control socket so status/sync/refresh work while mounted
+	engines := make(map[string]control.Engine, len(mounted))
+	for _, m := range mounted {
+		engines[m.name] = m.engine
+	}
+	controlServer, err := startControlServer(mountpoint, engines)
+	if err != nil {
+		logger.Warn("control socket disabled: %v", err)
+	}
+	filesystem.OnMounted(mountReady(filesystem, controlServer, daemon))
 
 	// 8. Mount (blocks until unmount)
-	logger.Info("mounting %s to %s", repo, mountpoint)
-	logger.Info("press Ctrl+C to unmount")
+	logger.Info("mounting %s to %s", strings.Join(repos, ", "), mountpoint)
+	if daemon == nil {
+		logger.Info("press Ctrl+C to unmount")
+	}
 	mountErr := filesystem.Mount()
 
 	// 9. Cleanup on return (after unmount)
 	logger.Info("unmounting...")
 
-	// Flush any pending changes
-	if err := engine.SyncNow(); err != nil {
-		logger.Warn("failed to sync pending changes: %v", err)
+	if controlServer != nil {
+		controlServer.Close()
 	}
 
-	// Stop the sync engine
-	engine.Stop()
-
-	// Close the cache
-	if err := cacheDB.Close(); err != nil {
-		logger.Warn("failed to close cache: %v", err)
-	}
+	// Flush any pending changes of every repo, then stop engines and close caches
+	flushOnUnmount(ctx, mounted)
+	closeRepos(mounted)
 
 	if mountErr != nil {
 		return fmt.Errorf("mount error: %w", mountErr)
@@ -232,24 +568,169 @@ func runMount(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// configureLogging sets up the logger based on CLI flags.
-func configureLogging() error {
+// startupSync pulls the issues of a freshly opened repo and retries
+// the pending items left over from a previous session.
+func startupSync(ctx context.Context, m mountedRepo) {
+	if m.engine.Offline() {
+		logger.Info("%s is offline, serving cached issues", m.name)
+		return
+	}
+
+	logger.Info("syncing issues from %s...", m.name)
+	if err := m.engine.InitialSync(ctx); err != nil {
+		logger.Warn("initial sync of %s failed: %v", m.name, err)
+		if m.engine.Offline() {
+			// The engine probes GitHub and pushes the queue once it is back
+			logger.Warn("continuing offline with cached data")
+			return
+		}
+	}
+
+	if err := m.engine.SyncNow(ctx); err != nil {
+		logger.Warn("failed to sync pending items of %s: %v", m.name, err)
+	}
+}
+
+// flushRepos pushes the pending changes of mounted repos.
+func flushRepos(ctx context.Context, mounted []mountedRepo) {
+	for _, m := range mounted {
+		if err := m.engine.SyncNow(ctx); errors.Is(err, sync.ErrOffline) {
+			logger.Info("%s is offline, its changes stay queued for the next sync", m.name)
+		} else if errors.Is(err, context.Canceled) {
+			logger.Info("interrupted, the changes of %s stay queued for the next sync", m.name)
+		} else if err != nil {
+			logger.Warn("failed to sync pending changes of %s: %v", m.name, err)
+		}
+	}
+}
+
+// flushOnUnmount flushes mounted repos once the filesystem is unmounted.
+// Another Ctrl+C interrupts the flush instead of waiting on GitHub.
+func flushOnUnmount(ctx context.Context, mounted []mountedRepo) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	flushRepos(ctx, mounted)
+}
+
+// closeRepos stops the sync engines and closes the caches of mounted repos.
+func closeRepos(mounted []mountedRepo) {
+	for _, m := range mounted {
+		m.engine.Stop()
+		if err := m.cache.Close(); err != nil {
+			logger.Warn("failed to close cache of %s: %v", m.name, err)
+		}
+	}
+}
+
+// newGitHubClient authenticates with the configured GitHub host, as the
+// configured GitHub App or else the user, and creates an API client.
+func newGitHubClient() (*gh.Client, error) {
+	settings, err := loadSettings(nil, "")
+	if err != nil {
+		return nil, err
+	}
+	host := settings.Host
+	client := gh.NewForHost("", host)
+
+	app, err := appCredentials(settings)
+	if err != nil {
+		return nil, err
+	}
+	if app != nil {
+		client.SetCredentials(app)
+		logger.Info("authenticated with %s as GitHub App %d installation %d", host, settings.AppID, settings.AppInstallationID)
+		return client, nil
+	}
+
+	creds, _, err := gh.CredentialsForHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub token: %w\nRun 'gh auth login --hostname %s' to authenticate", err, host)
+	}
+	client.SetCredentials(creds)
+	logger.Info("authenticated with %s", host)
+
+	return client, nil
+}
+
+// appCredentials returns the credentials of the GitHub App configured in
+// settings, or nil when there is none.
+func appCredentials(settings config.Settings) (*gh.AppCredentials, error) {
+	if settings.AppID == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(settings.AppPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	key, err := gh.ParseAppPrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return gh.NewAppCredentials(settings.Host, settings.AppID, settings.AppInstallationID, key), nil
+}
+
+// openRepo opens the cache for owner/repoName and creates its sync engine.
+// The caller owns both and must Stop/Close them.
+func openRepo(client *gh.Client, owner, repoName string) (*cache.DB, *sync.Engine, error) {
+	repo := owner + "/" + repoName
+
+	// Determine cache path: ~/.cache/ghissues/{owner}_{repo}.db
+	cachePath, err := getCachePath(owner, repoName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Initialize cache
+	cacheDB, err := cache.InitDB(cachePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+	logger.Info("cache initialized at %s", cachePath)
+
+	// Only one process may push this cache's queues at a time
+	if err := cacheDB.Lock(); err != nil {
+		cacheDB.Close()
+		if errors.Is(err, cache.ErrLocked) {
+			return nil, nil, fmt.Errorf("%s is already in use by another ghissues mount or sync", repo)
+		}
+		return nil, nil, err
+	}
+
+	settings, err := loadSettings(nil, repo)
+	if err != nil {
+		cacheDB.Close()
+		return nil, nil, err
+	}
+
+	engine, err := sync.NewEngine(cacheDB, client, repo, int(settings.Debounce.Milliseconds()))
+	if err != nil {
+		cacheDB.Close()
+		return nil, nil, fmt.Errorf("failed to create sync engine: %w", err)
+	}
+	engine.SetRefreshTTL(settings.RefreshTTL)
+	engine.SetHidePulls(settings.HidePulls)
+
+	return cacheDB, engine, nil
+}
+
+// configureLogging sets up the logger from the effective settings.
+func configureLogging(settings config.Settings) error {
 	// Parse and set log level
-	level, err := logger.ParseLevel(logLevel)
+	level, err := logger.ParseLevel(settings.LogLevel)
 	if err != nil {
 		return err
 	}
 
 	// If quiet mode, only show errors
-	if quiet {
+	if settings.Quiet {
 		level = logger.LevelError
 	}
 
 	logger.SetLevel(level)
 
 	// Set up log file if specified
-	if logFile != "" {
-		if err := logger.SetLogFile(logFile); err != nil {
+	if settings.LogFile != "" {
+		if err := logger.SetLogFile(settings.LogFile); err != nil {
 			return fmt.Errorf("failed to set log file: %w", err)
 		}
 	}
@@ -281,8 +762,43 @@ func runUnmount(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("unmounting %s\n", absMountpoint)
 
-	// Call the appropriate system unmount command based on platform
-	unmountCommand := getUnmountCommand(absMountpoint)
+	// A running ghissues mount unmounts itself and reports its final flush
+	client, err := controlClientFor(absMountpoint)
+	if err != nil {
+		return systemUnmount(absMountpoint)
+	}
+	client.Timeout = unmountTimeout
+
+	resp, err := client.Unmount()
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("unmount failed: %s", resp.Error)
+	}
+	printResponse(resp)
+
+	pidPath, _, err := getDaemonFiles(absMountpoint)
+	if err != nil {
+		return err
+	}
+	if err := waitForExit(pidPath, unmountTimeout); err != nil {
+		return err
+	}
+
+	if err := checkResponse("final flush", resp); err != nil {
+		return fmt.Errorf("unmounted, but %w; the changes stay queued in the cache, see \"ghissues pending list\"", err)
+	}
+
+	fmt.Println("unmounted successfully")
+	return nil
+}
+
+// systemUnmount unmounts mountpoint with the system unmount command, for
+// mounts without a reachable control socket. Whether their pending changes
+// were pushed is only known to the mounting process.
+func systemUnmount(mountpoint string) error {
+	unmountCommand := getUnmountCommand(mountpoint)
 	unmountCommand.Stdout = os.Stdout
 	unmountCommand.Stderr = os.Stderr
 
@@ -290,6 +806,6 @@ func runUnmount(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to unmount: %w", err)
 	}
 
-	fmt.Println("unmounted successfully")
+	fmt.Println("unmounted; no control socket was found, so the final flush could not be checked")
 	return nil
 }
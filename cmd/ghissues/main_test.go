@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/gh"
 )
 
 func TestValidateRepo(t *testing.T) {
@@ -248,6 +250,34 @@ func TestGetCachePath_ValidRepo(t *testing.T) {
 	}
 }
 
+func TestGetCachePath_PerHost(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+	t.Cleanup(func() { hostFlag = "" })
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"", filepath.Join(tmpDir, ".cache", "ghissues", "owner_repo.db")},
+		{"https://GHE.example.com/", filepath.Join(tmpDir, ".cache", "ghissues", "ghe.example.com", "owner_repo.db")},
+	}
+
+	for _, tt := range tests {
+		hostFlag = tt.host
+		got, err := getCachePath("owner", "repo")
+		if err != nil || got != tt.want {
+			t.Errorf("getCachePath() with --host %q = %q, %v; want %q", tt.host, got, err, tt.want)
+		}
+	}
+
+	hostFlag = "ghe.example.com/api/v3"
+	if _, err := getCachePath("owner", "repo"); err == nil {
+		t.Error("expected an invalid host to be rejected")
+	}
+}
+
 func TestGetCachePath_SpecialCharactersInRepoName(t *testing.T) {
 	// Save original HOME and restore after test
 	originalHome := os.Getenv("HOME")
@@ -473,3 +503,154 @@ func BenchmarkEnsureMountpoint_ExistingDir(b *testing.B) {
 		ensureMountpoint(tmpDir)
 	}
 }
+
+func TestParseMountArgs(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		org            string
+		wantRepos      []string
+		wantOrg        string
+		wantMountpoint string
+		wantErr        string
+	}{
+		{
+			name:           "single repo",
+			args:           []string{"owner/repo", "./issues"},
+			wantRepos:      []string{"owner/repo"},
+			wantMountpoint: "./issues",
+		},
+		{
+			name:           "several repos",
+			args:           []string{"org/a", "org/b", "other/c", "./issues"},
+			wantRepos:      []string{"org/a", "org/b", "other/c"},
+			wantMountpoint: "./issues",
+		},
+		{
+			name:    "invalid repo",
+			args:    []string{"org/a", "invalid", "./issues"},
+			wantErr: "invalid repository format",
+		},
+		{
+			name:    "duplicate repo",
+			args:    []string{"org/a", "org/a", "./issues"},
+			wantErr: "more than once",
+		},
+		{
+			name:           "whole owner",
+			args:           []string{"org/*", "./issues"},
+			wantOrg:        "org",
+			wantMountpoint: "./issues",
+		},
+		{
+			name:    "whole owner with other repos",
+			args:    []string{"org/*", "other/c", "./issues"},
+			wantErr: "must be the only repository",
+		},
+		{
+			name:    "whole owner without owner",
+			args:    []string{"/*", "./issues"},
+			wantErr: "invalid owner",
+		},
+		{
+			name:           "org flag",
+			args:           []string{"./issues"},
+			org:            "org",
+			wantOrg:        "org",
+			wantMountpoint: "./issues",
+		},
+		{
+			name:    "org flag with repos",
+			args:    []string{"org/a", "./issues"},
+			org:     "org",
+			wantErr: "cannot be combined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos, org, mountpoint, err := parseMountArgs(tt.args, tt.org)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseMountArgs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMountArgs() unexpected error: %v", err)
+			}
+			if strings.Join(repos, ",") != strings.Join(tt.wantRepos, ",") {
+				t.Errorf("repos = %v, want %v", repos, tt.wantRepos)
+			}
+			if org != tt.wantOrg {
+				t.Errorf("org = %q, want %q", org, tt.wantOrg)
+			}
+			if mountpoint != tt.wantMountpoint {
+				t.Errorf("mountpoint = %q, want %q", mountpoint, tt.wantMountpoint)
+			}
+		})
+	}
+}
+
+func TestApplyReadOnly(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions *gh.RepoPermissions
+		forced      bool
+		offline     bool
+		want        bool
+	}{
+		{"unknown permissions", nil, false, false, false},
+		{"push", &gh.RepoPermissions{Pull: true, Push: true}, false, false, false},
+		{"triage", &gh.RepoPermissions{Pull: true, Triage: true}, false, false, false},
+		{"pull only", &gh.RepoPermissions{Pull: true}, false, false, true},
+		{"forced", &gh.RepoPermissions{Admin: true}, true, false, true},
+		{"offline skips the check", &gh.RepoPermissions{Pull: true}, false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("GHISSUES_CONFIG", "")
+
+			mockGH := gh.NewMockServer()
+			defer mockGH.Close()
+			mockGH.SetRepoPermissions(tt.permissions)
+
+			client := gh.NewWithBaseURL("test-token", mockGH.URL)
+			cacheDB, engine, err := openRepo(client, "owner", "repo")
+			if err != nil {
+				t.Fatalf("openRepo() error = %v", err)
+			}
+			m := mountedRepo{name: "owner/repo", cache: cacheDB, engine: engine}
+			defer closeRepos([]mountedRepo{m})
+
+			engine.SetOffline(tt.offline)
+			applyReadOnly(t.Context(), client, m, tt.forced)
+			if engine.ReadOnly() != tt.want {
+				t.Errorf("ReadOnly() = %v, want %v", engine.ReadOnly(), tt.want)
+			}
+			if m.fsRepo().ReadOnly != tt.want {
+				t.Errorf("fsRepo().ReadOnly = %v, want %v", m.fsRepo().ReadOnly, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveIssueFilter(t *testing.T) {
+	mockGH := gh.NewMockServer()
+	defer mockGH.Close()
+	client := gh.NewWithBaseURL("test-token", mockGH.URL)
+
+	filter, err := resolveIssueFilter(t.Context(), client, gh.IssueFilter{State: "open", Assignee: "@me"})
+	if err != nil {
+		t.Fatalf("resolveIssueFilter() error = %v", err)
+	}
+	if filter.Assignee != "mock-user" || filter.State != "open" {
+		t.Errorf("resolveIssueFilter() = %+v, expected @me replaced by the token's login", filter)
+	}
+
+	if _, err := resolveIssueFilter(t.Context(), client, gh.IssueFilter{State: "merged"}); err == nil {
+		t.Error("expected an error for an invalid state")
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/control"
+	"github.com/JohanCodinha/ghissues/internal/fs"
+	"github.com/JohanCodinha/ghissues/internal/gh"
+	"github.com/JohanCodinha/ghissues/internal/logger"
+)
+
+// orgWatcher keeps the repositories of an org mount in step with GitHub.
+// Each rescan mounts repositories that gained issues or were created, and
+// unmounts those that were archived, disabled or deleted.
+type orgWatcher struct {
+	org        string
+	client     *gh.Client
+	filesystem *fs.FS
+	control    *control.Server // nil when the control socket is disabled
+	readOnly   bool            // mount every repository read-only
+	hidePulls  bool            // leave pull requests out of every repository
+	filter     gh.IssueFilter  // issues synced for every repository
+
+	mu    sync.Mutex
+	repos map[string]mountedRepo
+
+	ctx     context.Context // cancelled by Stop
+	cancel  context.CancelFunc
+	done    chan struct{}
+	started bool
+}
+
+// newOrgWatcher creates a watcher serving the repositories of org on filesystem.
+func newOrgWatcher(ctx context.Context, client *gh.Client, org string, filesystem *fs.FS, controlServer *control.Server) *orgWatcher {
+	ctx, cancel := context.WithCancel(ctx)
+	return &orgWatcher{
+		org:        org,
+		client:     client,
+		filesystem: filesystem,
+		control:    controlServer,
+		repos:      make(map[string]mountedRepo),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+}
+
+// rescan lists the repositories of the org and mounts or unmounts
+// repositories so that exactly the ones with issues enabled are served.
+func (w *orgWatcher) rescan() error {
+	listed, err := w.client.ListOwnerRepos(w.ctx, w.org)
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(listed))
+	for _, repo := range listed {
+		if !repo.HasIssues || repo.Archived || repo.Disabled {
+			continue
+		}
+		wanted[w.org+"/"+repo.Name] = true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, name := range sortedKeys(wanted) {
+		if _, ok := w.repos[name]; !ok {
+			w.add(name)
+		}
+	}
+	for _, name := range sortedKeys(w.repos) {
+		if !wanted[name] {
+			w.remove(name)
+		}
+	}
+
+	return nil
+}
+
+// add opens, syncs and mounts a repository. Failures are logged and the
+// repository is skipped so that the next rescan can try again.
+func (w *orgWatcher) add(name string) {
+	owner, repoName, _ := strings.Cut(name, "/")
+	cacheDB, engine, err := openRepo(w.client, owner, repoName)
+	if err != nil {
+		logger.Warn("org: skipping %s: %v", name, err)
+		return
+	}
+	m := mountedRepo{name: name, cache: cacheDB, engine: engine}
+	recoverJournal(&m)
+	engine.SetFilter(w.filter)
+	if w.hidePulls {
+		engine.SetHidePulls(true)
+	}
+	applyReadOnly(w.ctx, w.client, m, w.readOnly)
+
+	startupSync(w.ctx, m)
+
+	if err := w.filesystem.AddRepo(m.fsRepo()); err != nil {
+		logger.Warn("org: skipping %s: %v", name, err)
+		closeRepos([]mountedRepo{m})
+		return
+	}
+	if w.control != nil {
+		w.control.Register(name, engine)
+	}
+
+	w.repos[name] = m
+	logger.Info("org: mounted %s", name)
+}
+
+// remove unmounts a repository, pushing its pending changes first.
+func (w *orgWatcher) remove(name string) {
+	m := w.repos[name]
+	delete(w.repos, name)
+
+	if err := w.filesystem.RemoveRepo(name); err != nil {
+		logger.Warn("org: %v", err)
+	}
+	if w.control != nil {
+		w.control.Unregister(name)
+	}
+
+	flushRepos(w.ctx, []mountedRepo{m})
+	closeRepos([]mountedRepo{m})
+	logger.Info("org: unmounted %s, it is archived, deleted or has issues disabled", name)
+}
+
+// Start rescans every interval in the background until Stop is called.
+func (w *orgWatcher) Start(interval time.Duration) {
+	w.started = true
+	go w.run(interval)
+}
+
+// run is the rescan loop started by Start.
+func (w *orgWatcher) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.rescan(); err != nil {
+				logger.Warn("org: rescan of %s failed: %v", w.org, err)
+			}
+		}
+	}
+}
+
+// Stop ends the rescan loop, interrupting a rescan in progress, and returns
+// the repositories still mounted.
+// The caller owns them and must flush and close them.
+func (w *orgWatcher) Stop() []mountedRepo {
+	w.cancel()
+	if w.started {
+		<-w.done
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	mounted := make([]mountedRepo, 0, len(w.repos))
+	for _, name := range sortedKeys(w.repos) {
+		mounted = append(mounted, w.repos[name])
+	}
+	w.repos = make(map[string]mountedRepo)
+	return mounted
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runOrgMount mounts every repository of org with issues enabled and keeps
+// the set up to date until unmounted.
+func runOrgMount(ctx context.Context, client *gh.Client, org, mountpoint string, daemon *daemonChild, readOnly, hidePulls bool, filter gh.IssueFilter) error {
+	filesystem := fs.NewMultiFS(nil, mountpoint)
+	filesystem.SetReadOnly(readOnly)
+
+	controlServer, err := startControlServer(mountpoint, nil)
+	if err != nil {
+		logger.Warn("control socket disabled: %v", err)
+	}
+
+	watcher := newOrgWatcher(ctx, client, org, filesystem, controlServer)
+	watcher.readOnly = readOnly
+	watcher.hidePulls = hidePulls
+	watcher.filter = filter
+	logger.Info("listing repositories of %s...", org)
+	if err := watcher.rescan(); err != nil {
+		if controlServer != nil {
+			controlServer.Close()
+		}
+		return fmt.Errorf("failed to list repositories of %s: %w", org, err)
+	}
+	watcher.Start(rescanEvery)
+
+	filesystem.OnMounted(mountReady(filesystem, controlServer, daemon))
+
+	logger.Info("mounting repositories of %s to %s", org, mountpoint)
+	if daemon == nil {
+		logger.Info("press Ctrl+C to unmount")
+	}
+	mountErr := filesystem.Mount()
+
+	logger.Info("unmounting...")
+
+	// Stop rescanning before the final flush so no repo is added behind it
+	mounted := watcher.Stop()
+	if controlServer != nil {
+		controlServer.Close()
+	}
+	flushOnUnmount(ctx, mounted)
+	closeRepos(mounted)
+
+	if mountErr != nil {
+		return fmt.Errorf("mount error: %w", mountErr)
+	}
+
+	logger.Info("unmounted successfully")
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/control"
+	"github.com/JohanCodinha/ghissues/internal/fs"
+	"github.com/JohanCodinha/ghissues/internal/gh"
+)
+
+// servedRepos returns the repos the control server answers for, in order.
+func servedRepos(t *testing.T, server *control.Server) string {
+	t.Helper()
+	resp := server.Handle(t.Context(), control.Request{Op: control.OpStatus})
+	if resp.Error != "" {
+		t.Fatalf("status error: %s", resp.Error)
+	}
+	names := make([]string, len(resp.Repos))
+	for i, repo := range resp.Repos {
+		names[i] = repo.Repo
+	}
+	return strings.Join(names, ",")
+}
+
+func TestOrgWatcher_Rescan(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	mockGH := gh.NewMockServer()
+	defer mockGH.Close()
+
+	mockGH.AddRepository(&gh.Repository{Name: "a", FullName: "org/a", HasIssues: true})
+	mockGH.AddRepository(&gh.Repository{Name: "b", FullName: "org/b", HasIssues: false})
+	mockGH.AddRepository(&gh.Repository{Name: "c", FullName: "org/c", HasIssues: true, Archived: true})
+
+	server := control.NewServer(filepath.Join(tmpDir, "ctl.sock"), "/mnt/issues")
+	watcher := newOrgWatcher(t.Context(), gh.NewWithBaseURL("test-token", mockGH.URL), "org",
+		fs.NewMultiFS(nil, "/mnt/issues"), server)
+
+	if err := watcher.rescan(); err != nil {
+		t.Fatalf("rescan() error = %v", err)
+	}
+	if got := servedRepos(t, server); got != "org/a" {
+		t.Errorf("served repos = %q, want only org/a", got)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".cache", "ghissues", "org_a.db")); err != nil {
+		t.Errorf("cache of org/a was not created: %v", err)
+	}
+
+	// org/d is created and org/a archived while mounted
+	mockGH.AddRepository(&gh.Repository{Name: "d", FullName: "org/d", HasIssues: true})
+	mockGH.AddRepository(&gh.Repository{Name: "a", FullName: "org/a", HasIssues: true, Archived: true})
+
+	if err := watcher.rescan(); err != nil {
+		t.Fatalf("rescan() error = %v", err)
+	}
+	if got := servedRepos(t, server); got != "org/d" {
+		t.Errorf("served repos = %q, want only org/d", got)
+	}
+
+	mounted := watcher.Stop()
+	if len(mounted) != 1 || mounted[0].name != "org/d" {
+		t.Fatalf("Stop() returned %+v, want org/d", mounted)
+	}
+	closeRepos(mounted)
+
+	// The cache of the unmounted repo was released and can be reopened
+	cacheDB, engine, err := openRepo(gh.NewWithBaseURL("test-token", mockGH.URL), "org", "a")
+	if err != nil {
+		t.Fatalf("openRepo(org/a) after unmount error = %v", err)
+	}
+	closeRepos([]mountedRepo{{name: "org/a", cache: cacheDB, engine: engine}})
+}
+
+func TestOrgWatcher_RescanError(t *testing.T) {
+	mockGH := gh.NewMockServer()
+	defer mockGH.Close()
+
+	mockGH.SetNextError(500, "boom")
+
+	watcher := newOrgWatcher(t.Context(), gh.NewWithBaseURL("test-token", mockGH.URL), "org",
+		fs.NewMultiFS(nil, "/mnt/issues"), nil)
+	if err := watcher.rescan(); err == nil {
+		t.Error("expected rescan to fail when the repository list cannot be fetched")
+	}
+}
+
+func TestMountCmd_OrgFlagTakesOnlyMountpoint(t *testing.T) {
+	defer func() { mountOrg = "" }()
+
+	rootCmd.SetArgs([]string{"mount", "--org", "org", "org/a", "./issues"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("mount --org should reject repository arguments")
+	}
+}
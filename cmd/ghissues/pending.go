@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags for the pending commands
+var (
+	pendingLocal    bool
+	pendingTitle    string
+	pendingBody     string
+	pendingBodyFile string
+	pendingLabels   []string
+)
+
+var pendingCmd = &cobra.Command{
+	Use:   "pending",
+	Short: "Inspect, edit and discard changes waiting to be pushed",
+	Long: `Inspect, edit and discard the local outbox of a repository: new issues and
+comments, and edits of existing issues and comments, that were not pushed to
+GitHub yet.
+
+Every queued operation has a reference such as "new-issue:3" or
+"issue-edit:42", shown by "ghissues pending list", that the edit and discard
+commands take.`,
+}
+
+var pendingListCmd = &cobra.Command{
+	Use:   "list <owner/repo>",
+	Short: "List queued operations and why they failed",
+	Long: `List every operation queued for a repository with the last error GitHub
+returned for it, if any, and how many pushes in a row failed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPendingList,
+}
+
+var pendingDiscardCmd = &cobra.Command{
+	Use:   "discard <owner/repo> <ref>...",
+	Short: "Drop queued operations",
+	Long: `Drop queued operations instead of pushing them.
+
+New issues and comments are deleted. An edited issue is reset to its current
+version on GitHub; with --local it is instead removed from the cache and
+fetched again on the next sync, which works offline. An edited comment is
+removed from the cache until the next sync fetches it again.
+
+The repository must not be mounted or syncing while its outbox is changed.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runPendingDiscard,
+}
+
+var pendingEditCmd = &cobra.Command{
+	Use:   "edit <owner/repo> <ref>",
+	Short: "Change a queued operation before it is pushed",
+	Long: `Change a queued operation before it is pushed, for example to remove a
+label GitHub rejects. Only the given fields change; --label replaces all
+labels and may be repeated. Titles and labels only apply to issues.
+
+The repository must not be mounted or syncing while its outbox is changed.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPendingEdit,
+}
+
+func init() {
+	pendingDiscardCmd.Flags().BoolVar(&pendingLocal, "local", false, "Reset edited issues without contacting GitHub")
+
+	pendingEditCmd.Flags().StringVar(&pendingTitle, "title", "", "New title")
+	pendingEditCmd.Flags().StringVar(&pendingBody, "body", "", "New body")
+	pendingEditCmd.Flags().StringVar(&pendingBodyFile, "body-file", "", "Read the new body from a file (- for stdin)")
+	pendingEditCmd.Flags().StringSliceVar(&pendingLabels, "label", nil, "New labels, replacing the current ones (repeatable)")
+
+	pendingCmd.AddCommand(pendingListCmd)
+	pendingCmd.AddCommand(pendingDiscardCmd)
+	pendingCmd.AddCommand(pendingEditCmd)
+}
+
+// outboxOp is one operation waiting to be pushed to GitHub.
+type outboxOp struct {
+	key     cache.PushErrorKey
+	summary string
+	err     *cache.PushError // nil if no push failed yet
+}
+
+// ref returns the reference users pass to identify op.
+func (op outboxOp) ref() string {
+	return fmt.Sprintf("%s:%d", op.key.Kind, op.key.ID)
+}
+
+// parseOutboxRef parses a reference such as "new-issue:3".
+func parseOutboxRef(ref string) (cache.PushErrorKey, error) {
+	kind, id, ok := strings.Cut(ref, ":")
+	n, err := strconv.ParseInt(strings.TrimPrefix(id, "#"), 10, 64)
+	if !ok || err != nil || n <= 0 {
+		return cache.PushErrorKey{}, fmt.Errorf("invalid reference %q: expected kind:id, e.g. new-issue:3", ref)
+	}
+
+	switch kind {
+	case cache.KindNewIssue, cache.KindNewComment, cache.KindIssueEdit, cache.KindCommentEdit:
+		return cache.PushErrorKey{Kind: kind, ID: n}, nil
+	default:
+		return cache.PushErrorKey{}, fmt.Errorf("invalid reference %q: kind must be %s, %s, %s or %s",
+			ref, cache.KindNewIssue, cache.KindNewComment, cache.KindIssueEdit, cache.KindCommentEdit)
+	}
+}
+
+// listOutbox returns the queued operations of repo in push order: new
+// issues, new comments, comment edits, then issue edits.
+func listOutbox(db *cache.DB, repo string) ([]outboxOp, error) {
+	pushErrors, err := db.GetPushErrors(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []outboxOp
+	add := func(kind string, id int64, summary string) {
+		op := outboxOp{key: cache.PushErrorKey{Kind: kind, ID: id}, summary: summary}
+		if pe, ok := pushErrors[op.key]; ok {
+			op.err = &pe
+		}
+		ops = append(ops, op)
+	}
+
+	pendingIssues, err := db.GetPendingIssues(repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range pendingIssues {
+		summary := fmt.Sprintf("new issue %q", issue.Title)
+		if len(issue.Labels) > 0 {
+			summary += fmt.Sprintf(" [%s]", strings.Join(issue.Labels, ", "))
+		}
+		add(cache.KindNewIssue, issue.ID, summary)
+	}
+
+	pendingComments, err := db.GetPendingComments(repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range pendingComments {
+		add(cache.KindNewComment, comment.ID, fmt.Sprintf("new comment on #%d: %s", comment.IssueNumber, firstLine(comment.Body)))
+	}
+
+	dirtyComments, err := db.GetDirtyComments(repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range dirtyComments {
+		add(cache.KindCommentEdit, comment.ID, fmt.Sprintf("edited comment on #%d: %s", comment.IssueNumber, firstLine(comment.Body)))
+	}
+
+	dirtyIssues, err := db.GetDirtyIssues(repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range dirtyIssues {
+		add(cache.KindIssueEdit, int64(issue.Number), fmt.Sprintf("edited issue #%d %q", issue.Number, issue.Title))
+	}
+
+	return ops, nil
+}
+
+// firstLine returns the first line of s, shortened for one-line listings.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	if runes := []rune(line); len(runes) > 60 {
+		return string(runes[:57]) + "..."
+	}
+	return line
+}
+
+// findOutboxOp returns the queued operation identified by key.
+func findOutboxOp(db *cache.DB, repo string, key cache.PushErrorKey) (outboxOp, error) {
+	ops, err := listOutbox(db, repo)
+	if err != nil {
+		return outboxOp{}, err
+	}
+	for _, op := range ops {
+		if op.key == key {
+			return op, nil
+		}
+	}
+	return outboxOp{}, fmt.Errorf("%s:%d is not queued for %s", key.Kind, key.ID, repo)
+}
+
+// writeOutbox writes ops, each followed by its last push error if any.
+func writeOutbox(w io.Writer, repo string, ops []outboxOp) {
+	if len(ops) == 0 {
+		fmt.Fprintf(w, "%s: nothing waiting to be pushed\n", repo)
+		return
+	}
+
+	failed := 0
+	for _, op := range ops {
+		fmt.Fprintf(w, "%-20s %s\n", op.ref(), op.summary)
+		if op.err != nil {
+			failed++
+			fmt.Fprintf(w, "%-20s last error (%d attempts, %s): %s\n", "", op.err.Attempts, op.err.FailedAt, op.err.Message)
+		}
+	}
+	fmt.Fprintf(w, "%s: %d queued, %d failing\n", repo, len(ops), failed)
+}
+
+// lockOutbox opens the existing cache of repo and takes its lock, so no mount
+// or sync pushes the outbox while it is being changed.
+func lockOutbox(repo string) (*cache.DB, error) {
+	db, err := openExistingCache(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Lock(); err != nil {
+		db.Close()
+		if errors.Is(err, cache.ErrLocked) {
+			return nil, fmt.Errorf("%s is in use by a running ghissues mount or sync; unmount it first", repo)
+		}
+		return nil, err
+	}
+	return db, nil
+}
+
+// discardOutboxOp drops one queued operation. Edited issues are reset through
+// engine, or deleted from the cache when engine is nil.
+func discardOutboxOp(ctx context.Context, db *cache.DB, engine *sync.Engine, repo string, key cache.PushErrorKey) error {
+	if _, err := findOutboxOp(db, repo, key); err != nil {
+		return err
+	}
+
+	var err error
+	switch key.Kind {
+	case cache.KindNewIssue:
+		err = db.RemovePendingIssue(key.ID)
+	case cache.KindNewComment:
+		err = db.RemovePendingComment(key.ID)
+	case cache.KindCommentEdit:
+		return db.DiscardCommentEdit(repo, key.ID)
+	case cache.KindIssueEdit:
+		if engine != nil {
+			return engine.DiscardIssueEdits(ctx, int(key.ID))
+		}
+		return db.DeleteIssue(repo, int(key.ID))
+	}
+	if err != nil {
+		return err
+	}
+	return db.ClearPushError(repo, key.Kind, key.ID)
+}
+
+// outboxEdit holds the fields to change in a queued operation. Nil fields
+// are kept.
+type outboxEdit struct {
+	title  *string
+	body   *string
+	labels *[]string
+}
+
+// editOutboxOp applies edit to a queued operation and forgets its last error,
+// so the next push is tried afresh.
+func editOutboxOp(db *cache.DB, repo string, key cache.PushErrorKey, edit outboxEdit) error {
+	if _, err := findOutboxOp(db, repo, key); err != nil {
+		return err
+	}
+	if edit.title == nil && edit.body == nil && edit.labels == nil {
+		return fmt.Errorf("nothing to change: use --title, --body, --body-file or --label")
+	}
+
+	var err error
+	switch key.Kind {
+	case cache.KindNewIssue:
+		err = editPendingIssue(db, repo, key.ID, edit)
+	case cache.KindIssueEdit:
+		err = db.MarkDirty(repo, int(key.ID), cache.IssueUpdate{Title: edit.title, Body: edit.body, Labels: edit.labels})
+	case cache.KindNewComment, cache.KindCommentEdit:
+		if edit.title != nil || edit.labels != nil {
+			return fmt.Errorf("comments only have a body")
+		}
+		if edit.body == nil {
+			return fmt.Errorf("nothing to change: use --body or --body-file")
+		}
+		if key.Kind == cache.KindNewComment {
+			err = db.UpdatePendingComment(key.ID, *edit.body)
+		} else {
+			err = db.MarkCommentDirty(repo, key.ID, *edit.body)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return db.ClearPushError(repo, key.Kind, key.ID)
+}
+
+// editPendingIssue applies edit to a pending new issue.
+func editPendingIssue(db *cache.DB, repo string, id int64, edit outboxEdit) error {
+	issues, err := db.GetPendingIssues(repo)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		if issue.ID != id {
+			continue
+		}
+		if edit.title != nil {
+			issue.Title = *edit.title
+		}
+		if edit.body != nil {
+			issue.Body = *edit.body
+		}
+		if edit.labels != nil {
+			issue.Labels = *edit.labels
+		}
+		if strings.TrimSpace(issue.Title) == "" {
+			return fmt.Errorf("title cannot be empty")
+		}
+		return db.UpdatePendingIssue(id, issue.Title, issue.Body, issue.Labels)
+	}
+	return fmt.Errorf("no pending issue with id=%d", id)
+}
+
+func runPendingList(cmd *cobra.Command, args []string) error {
+	repo := args[0]
+
+	db, err := openExistingCache(repo)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ops, err := listOutbox(db, repo)
+	if err != nil {
+		return err
+	}
+	writeOutbox(os.Stdout, repo, ops)
+	return nil
+}
+
+func runPendingDiscard(cmd *cobra.Command, args []string) error {
+	repo := args[0]
+
+	var keys []cache.PushErrorKey
+	needsGitHub := false
+	for _, ref := range args[1:] {
+		key, err := parseOutboxRef(ref)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		needsGitHub = needsGitHub || (key.Kind == cache.KindIssueEdit && !pendingLocal)
+	}
+
+	db, err := lockOutbox(repo)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var engine *sync.Engine
+	if needsGitHub {
+		client, err := newGitHubClient()
+		if err != nil {
+			return err
+		}
+		engine, err = sync.NewEngine(db, client, repo, 0)
+		if err != nil {
+			return fmt.Errorf("failed to create sync engine: %w", err)
+		}
+		defer engine.Stop()
+	}
+
+	var errs []error
+	for i, key := range keys {
+		if err := discardOutboxOp(cmd.Context(), db, engine, repo, key); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", args[i+1], err))
+			continue
+		}
+		fmt.Printf("discarded %s\n", args[i+1])
+	}
+	return errors.Join(errs...)
+}
+
+func runPendingEdit(cmd *cobra.Command, args []string) error {
+	repo := args[0]
+
+	key, err := parseOutboxRef(args[1])
+	if err != nil {
+		return err
+	}
+
+	var edit outboxEdit
+	if cmd.Flags().Changed("title") {
+		edit.title = &pendingTitle
+	}
+	if cmd.Flags().Changed("body") && cmd.Flags().Changed("body-file") {
+		return fmt.Errorf("--body and --body-file cannot be used together")
+	}
+	if cmd.Flags().Changed("body") {
+		edit.body = &pendingBody
+	}
+	if cmd.Flags().Changed("body-file") {
+		body, err := readBodyFile(pendingBodyFile)
+		if err != nil {
+			return err
+		}
+		edit.body = &body
+	}
+	if cmd.Flags().Changed("label") {
+		edit.labels = &pendingLabels
+	}
+
+	db, err := lockOutbox(repo)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := editOutboxOp(db, repo, key, edit); err != nil {
+		return err
+	}
+	fmt.Printf("edited %s\n", args[1])
+	return nil
+}
+
+// readBodyFile reads a body from path, or from stdin when path is "-".
+func readBodyFile(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read body: %w", err)
+	}
+	return string(data), nil
+}
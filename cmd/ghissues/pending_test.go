@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+)
+
+func TestParseOutboxRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    cache.PushErrorKey
+		wantErr bool
+	}{
+		{"new-issue:3", cache.PushErrorKey{Kind: cache.KindNewIssue, ID: 3}, false},
+		{"issue-edit:#42", cache.PushErrorKey{Kind: cache.KindIssueEdit, ID: 42}, false},
+		{"comment-edit:123456", cache.PushErrorKey{Kind: cache.KindCommentEdit, ID: 123456}, false},
+		{"new-issue", cache.PushErrorKey{}, true},
+		{"new-issue:0", cache.PushErrorKey{}, true},
+		{"issue:3", cache.PushErrorKey{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseOutboxRef(tt.ref)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseOutboxRef(%q) = %+v, %v", tt.ref, got, err)
+		}
+	}
+}
+
+// createTestOutbox queues one operation of each kind for owner/repo.
+func createTestOutbox(t *testing.T, db *cache.DB) {
+	t.Helper()
+	db.UpsertIssue(cache.Issue{Number: 7, Repo: "owner/repo", Title: "Existing", State: "open"})
+	db.UpsertComments("owner/repo", 7, []cache.Comment{{ID: 70, Author: "alice", Body: "remote"}})
+
+	title := "Edited"
+	if err := db.MarkDirty("owner/repo", 7, cache.IssueUpdate{Title: &title}); err != nil {
+		t.Fatalf("MarkDirty() error = %v", err)
+	}
+	if err := db.MarkCommentDirty("owner/repo", 70, "edited comment"); err != nil {
+		t.Fatalf("MarkCommentDirty() error = %v", err)
+	}
+	if _, err := db.AddPendingIssue("owner/repo", "New issue", "", []string{"no-such-label"}); err != nil {
+		t.Fatalf("AddPendingIssue() error = %v", err)
+	}
+	if err := db.AddPendingComment("owner/repo", 7, "new comment"); err != nil {
+		t.Fatalf("AddPendingComment() error = %v", err)
+	}
+}
+
+func TestListOutbox(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	db := createTestCache(t, "owner", "repo")
+	defer db.Close()
+	createTestOutbox(t, db)
+	db.RecordPushError("owner/repo", cache.KindNewIssue, 1, errors.New("422 Unprocessable Entity"))
+
+	ops, err := listOutbox(db, "owner/repo")
+	if err != nil {
+		t.Fatalf("listOutbox() error = %v", err)
+	}
+
+	var refs []string
+	for _, op := range ops {
+		refs = append(refs, op.ref())
+	}
+	want := "new-issue:1 new-comment:1 comment-edit:70 issue-edit:7"
+	if strings.Join(refs, " ") != want {
+		t.Errorf("refs = %q, expected %q", strings.Join(refs, " "), want)
+	}
+
+	var out bytes.Buffer
+	writeOutbox(&out, "owner/repo", ops)
+	for _, line := range []string{
+		`new-issue:1          new issue "New issue" [no-such-label]`,
+		"last error (1 attempts, ",
+		"): 422 Unprocessable Entity",
+		"owner/repo: 4 queued, 1 failing",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("output missing %q:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestEditOutboxOp(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	db := createTestCache(t, "owner", "repo")
+	defer db.Close()
+	createTestOutbox(t, db)
+	db.RecordPushError("owner/repo", cache.KindNewIssue, 1, errors.New("422 Unprocessable Entity"))
+
+	labels := []string{"bug"}
+	if err := editOutboxOp(db, "owner/repo", cache.PushErrorKey{Kind: cache.KindNewIssue, ID: 1}, outboxEdit{labels: &labels}); err != nil {
+		t.Fatalf("editOutboxOp(new-issue) error = %v", err)
+	}
+	issues, _ := db.GetPendingIssues("owner/repo")
+	if issues[0].Title != "New issue" || len(issues[0].Labels) != 1 || issues[0].Labels[0] != "bug" {
+		t.Errorf("pending issue after edit = %+v", issues[0])
+	}
+	if errs, _ := db.GetPushErrors("owner/repo"); len(errs) != 0 {
+		t.Errorf("editing should clear the last error, got %+v", errs)
+	}
+
+	body := "fixed comment"
+	if err := editOutboxOp(db, "owner/repo", cache.PushErrorKey{Kind: cache.KindCommentEdit, ID: 70}, outboxEdit{body: &body}); err != nil {
+		t.Fatalf("editOutboxOp(comment-edit) error = %v", err)
+	}
+	if dirty, _ := db.GetDirtyComments("owner/repo"); dirty[0].Body != "fixed comment" {
+		t.Errorf("dirty comment after edit = %+v", dirty[0])
+	}
+
+	title := "x"
+	tests := []struct {
+		name string
+		key  cache.PushErrorKey
+		edit outboxEdit
+	}{
+		{"not queued", cache.PushErrorKey{Kind: cache.KindIssueEdit, ID: 99}, outboxEdit{title: &title}},
+		{"nothing to change", cache.PushErrorKey{Kind: cache.KindNewIssue, ID: 1}, outboxEdit{}},
+		{"title on a comment", cache.PushErrorKey{Kind: cache.KindNewComment, ID: 1}, outboxEdit{title: &title}},
+	}
+	for _, tt := range tests {
+		if err := editOutboxOp(db, "owner/repo", tt.key, tt.edit); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestDiscardOutboxOp(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	db := createTestCache(t, "owner", "repo")
+	defer db.Close()
+	createTestOutbox(t, db)
+
+	for _, key := range []cache.PushErrorKey{
+		{Kind: cache.KindNewIssue, ID: 1},
+		{Kind: cache.KindNewComment, ID: 1},
+		{Kind: cache.KindCommentEdit, ID: 70},
+		{Kind: cache.KindIssueEdit, ID: 7}, // without an engine: dropped locally
+	} {
+		if err := discardOutboxOp(t.Context(), db, nil, "owner/repo", key); err != nil {
+			t.Fatalf("discardOutboxOp(%+v) error = %v", key, err)
+		}
+	}
+
+	ops, _ := listOutbox(db, "owner/repo")
+	if len(ops) != 0 {
+		t.Errorf("expected an empty outbox, got %+v", ops)
+	}
+	if err := discardOutboxOp(t.Context(), db, nil, "owner/repo", cache.PushErrorKey{Kind: cache.KindNewIssue, ID: 1}); err == nil {
+		t.Error("expected an error discarding an operation twice")
+	}
+}
+
+func TestPendingDiscard_RefusesLockedCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	// A running mount holds the lock
+	mount := createTestCache(t, "owner", "repo")
+	defer mount.Close()
+	if err := mount.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	if _, err := lockOutbox("owner/repo"); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("lockOutbox() error = %v, expected an in-use error", err)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/gh"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags for the service commands
+var (
+	serviceNoStart     bool
+	serviceStopTimeout time.Duration
+)
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Run mounts as systemd user services",
+	Long: `Install mounts as systemd user services, so they start at login and come
+back after a reboot. Services are identified by their mountpoint.`,
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install <owner/repo>... <mountpoint> [-- mount flags]",
+	Short: "Install, enable and start a systemd user service for a mount",
+	Long: `Write a systemd user unit that runs "ghissues mount" in the foreground,
+then enable and start it. Flags after "--" are passed to the mount, for
+example "-- --state open --read-only".
+
+The unit keeps the current PATH, so the gh CLI is found for authentication,
+and GHISSUES_CONFIG if it is set. Stopping the service unmounts with
+"fusermount -u" and lets the mount push its pending changes for up to
+--stop-timeout before it is killed.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runServiceInstall,
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall <mountpoint>",
+	Short: "Stop, disable and remove the service of a mount",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runServiceUninstall,
+}
+
+var serviceStatusCmd = &cobra.Command{
+	Use:   "status [mountpoint]",
+	Short: "Show the state of mount services",
+	Long: `Show systemd's status of the service of a mount. Without a mountpoint,
+list every installed ghissues service and whether it is active.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runServiceStatus,
+}
+
+func init() {
+	serviceInstallCmd.Flags().BoolVar(&serviceNoStart, "no-start", false, "Enable the service without starting it now")
+	serviceInstallCmd.Flags().DurationVar(&serviceStopTimeout, "stop-timeout", 5*time.Minute, "How long stopping waits for pending changes to be pushed")
+
+	serviceCmd.AddCommand(serviceInstallCmd)
+	serviceCmd.AddCommand(serviceUninstallCmd)
+	serviceCmd.AddCommand(serviceStatusCmd)
+}
+
+// runSystemctl runs "systemctl --user" with args, writing its output to w.
+// Tests replace it.
+var runSystemctl = func(w io.Writer, args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	cmd.Stdout, cmd.Stderr = w, w
+	return cmd.Run()
+}
+
+// serviceUnitDir returns the directory of systemd user units.
+func serviceUnitDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+var unitNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.]+`)
+
+// serviceUnitName returns the unit name of the service mounting the absolute
+// path mountpoint, such as ghissues-home-me-issues.service.
+func serviceUnitName(mountpoint string) string {
+	name := strings.Trim(unitNameUnsafe.ReplaceAllString(mountpoint, "-"), "-")
+	if name == "" {
+		name = "root"
+	}
+	return "ghissues-" + name + ".service"
+}
+
+// systemdQuote quotes arg for a systemd command line or assignment, doubling
+// the "%" of specifiers and the "$" of variable expansion.
+func systemdQuote(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	arg = strings.ReplaceAll(arg, `\`, `\\`)
+	arg = strings.ReplaceAll(arg, `"`, `\"`)
+	return `"` + arg + `"`
+}
+
+// serviceUnit describes the systemd unit of a mount.
+type serviceUnit struct {
+	description string
+	execStart   []string
+	execStop    []string
+	environment []string // KEY=value
+	stopTimeout time.Duration
+}
+
+// String renders the unit file.
+func (u serviceUnit) String() string {
+	quoteAll := func(args []string) string {
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = systemdQuote(arg)
+		}
+		return strings.Join(quoted, " ")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Generated by \"ghissues service install\"; reinstall rather than edit.\n")
+	sb.WriteString("[Unit]\n")
+	fmt.Fprintf(&sb, "Description=%s\n", strings.ReplaceAll(u.description, "%", "%%"))
+	sb.WriteString("Wants=network-online.target\n")
+	sb.WriteString("After=network-online.target\n")
+	sb.WriteString("\n[Service]\n")
+	sb.WriteString("Type=simple\n")
+	fmt.Fprintf(&sb, "ExecStart=%s\n", quoteAll(u.execStart))
+	// "-": the mount may already be gone, e.g. after a crash
+	fmt.Fprintf(&sb, "ExecStop=-%s\n", quoteAll(u.execStop))
+	// The mount pushes its pending changes once unmounted and ignores the
+	// SIGTERM that follows ExecStop, so this bounds the final flush
+	fmt.Fprintf(&sb, "TimeoutStopSec=%d\n", int(u.stopTimeout.Seconds()))
+	sb.WriteString("Restart=on-failure\n")
+	sb.WriteString("RestartSec=10\n")
+	for _, env := range u.environment {
+		fmt.Fprintf(&sb, "Environment=%s\n", systemdQuote(env))
+	}
+	sb.WriteString("\n[Install]\n")
+	sb.WriteString("WantedBy=default.target\n")
+	return sb.String()
+}
+
+// newServiceUnit builds the unit running exe with the mount arguments args,
+// the last of which is the absolute mountpoint, followed by mountFlags.
+func newServiceUnit(exe string, args, mountFlags []string, stopTimeout time.Duration) serviceUnit {
+	mountpoint := args[len(args)-1]
+
+	fusermount, err := exec.LookPath("fusermount")
+	if err != nil {
+		if fusermount, err = exec.LookPath("fusermount3"); err != nil {
+			fusermount = "/usr/bin/fusermount"
+		}
+	}
+
+	environment := []string{"PATH=" + os.Getenv("PATH")}
+	if path := os.Getenv("GHISSUES_CONFIG"); path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		environment = append(environment, "GHISSUES_CONFIG="+path)
+	}
+
+	return serviceUnit{
+		description: fmt.Sprintf("ghissues mount of %s at %s", strings.Join(args[:len(args)-1], ", "), mountpoint),
+		execStart:   append(append([]string{exe, "mount"}, args...), mountFlags...),
+		execStop:    []string{fusermount, "-u", mountpoint},
+		environment: environment,
+		stopTimeout: stopTimeout,
+	}
+}
+
+// unitPathForMountpoint returns the unit name and file of the service of
+// mountpoint.
+func unitPathForMountpoint(mountpoint string) (name, path string, err error) {
+	absMountpoint, err := filepath.Abs(mountpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	dir, err := serviceUnitDir()
+	if err != nil {
+		return "", "", err
+	}
+	name = serviceUnitName(absMountpoint)
+	return name, filepath.Join(dir, name), nil
+}
+
+// installService writes the unit of a mount and enables it, starting it
+// unless noStart. args are the mount's repositories and mountpoint.
+func installService(w io.Writer, exe string, args, mountFlags []string, stopTimeout time.Duration, noStart bool) error {
+	if _, _, _, err := parseMountArgs(args, ""); err != nil {
+		return err
+	}
+	for _, flag := range mountFlags {
+		if flag == "--daemon" || flag == "-d" || strings.HasPrefix(flag, "--daemon=") {
+			return fmt.Errorf("%s cannot be used in a service, which runs the mount in the foreground", flag)
+		}
+	}
+
+	absMountpoint, err := filepath.Abs(args[len(args)-1])
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if _, err := ensureMountpoint(absMountpoint); err != nil {
+		return err
+	}
+	args = append(append([]string{}, args[:len(args)-1]...), absMountpoint)
+
+	name, path, err := unitPathForMountpoint(absMountpoint)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create unit directory: %w", err)
+	}
+	unit := newServiceUnit(exe, args, mountFlags, stopTimeout)
+	if err := os.WriteFile(path, []byte(unit.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write unit: %w", err)
+	}
+	fmt.Fprintf(w, "wrote %s\n", path)
+
+	if err := runSystemctl(w, "daemon-reload"); err != nil {
+		return fmt.Errorf("systemctl daemon-reload failed: %w", err)
+	}
+	enable := []string{"enable", name}
+	if !noStart {
+		enable = []string{"enable", "--now", name}
+	}
+	if err := runSystemctl(w, enable...); err != nil {
+		return fmt.Errorf("failed to enable %s: %w\nsee: journalctl --user -u %s", name, err, name)
+	}
+
+	if noStart {
+		fmt.Fprintf(w, "enabled %s, it starts at the next login\n", name)
+	} else {
+		fmt.Fprintf(w, "started %s\n", name)
+	}
+	fmt.Fprintf(w, "logs: journalctl --user -u %s\n", name)
+	return nil
+}
+
+// uninstallService stops and disables the service of mountpoint, which
+// pushes its pending changes, and removes its unit.
+func uninstallService(w io.Writer, mountpoint string) error {
+	name, path, err := unitPathForMountpoint(mountpoint)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no service is installed for %s", mountpoint)
+	}
+
+	if err := runSystemctl(w, "disable", "--now", name); err != nil {
+		return fmt.Errorf("failed to stop %s: %w", name, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove unit: %w", err)
+	}
+	if err := runSystemctl(w, "daemon-reload"); err != nil {
+		return fmt.Errorf("systemctl daemon-reload failed: %w", err)
+	}
+
+	fmt.Fprintf(w, "removed %s\n", name)
+	return nil
+}
+
+// serviceStatus shows systemd's status of the service of mountpoint, or
+// lists every installed service when mountpoint is empty.
+func serviceStatus(w io.Writer, mountpoint string) error {
+	if mountpoint == "" {
+		dir, err := serviceUnitDir()
+		if err != nil {
+			return err
+		}
+		units, _ := filepath.Glob(filepath.Join(dir, "ghissues-*.service"))
+		if len(units) == 0 {
+			fmt.Fprintln(w, "no ghissues services installed")
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, unit := range units {
+			// is-active fails for inactive units while still printing their state
+			var state bytes.Buffer
+			runSystemctl(&state, "is-active", filepath.Base(unit))
+			fmt.Fprintf(tw, "%s\t%s\n", filepath.Base(unit), strings.TrimSpace(state.String()))
+		}
+		return tw.Flush()
+	}
+
+	name, path, err := unitPathForMountpoint(mountpoint)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no service is installed for %s", mountpoint)
+	}
+
+	fmt.Fprintf(w, "unit: %s\n", path)
+	// status exits non-zero for inactive units, which its output already says
+	var exitErr *exec.ExitError
+	if err := runSystemctl(w, "status", "--no-pager", name); err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("systemctl status failed: %w", err)
+	}
+	return nil
+}
+
+// requireSystemd rejects the service commands outside Linux.
+func requireSystemd() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("services need systemd, which is only available on Linux")
+	}
+	return nil
+}
+
+func runServiceInstall(cmd *cobra.Command, args []string) error {
+	if err := requireSystemd(); err != nil {
+		return err
+	}
+
+	var mountFlags []string
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		args, mountFlags = args[:dash], args[dash:]
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("expected at least one repository and a mountpoint")
+	}
+
+	// The service does not inherit GHISSUES_HOST or --host from this shell
+	host, err := githubHost()
+	if err != nil {
+		return err
+	}
+	if host != gh.DefaultHost {
+		mountFlags = append([]string{"--host", host}, mountFlags...)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the ghissues executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	return installService(os.Stdout, exe, args, mountFlags, serviceStopTimeout, serviceNoStart)
+}
+
+func runServiceUninstall(cmd *cobra.Command, args []string) error {
+	if err := requireSystemd(); err != nil {
+		return err
+	}
+	return uninstallService(os.Stdout, args[0])
+}
+
+func runServiceStatus(cmd *cobra.Command, args []string) error {
+	if err := requireSystemd(); err != nil {
+		return err
+	}
+	mountpoint := ""
+	if len(args) == 1 {
+		mountpoint = args[0]
+	}
+	return serviceStatus(os.Stdout, mountpoint)
+}
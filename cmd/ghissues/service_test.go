@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSystemdQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"/usr/bin/ghissues", "/usr/bin/ghissues"},
+		{"/home/me/my issues", `"/home/me/my issues"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"100%", "100%%"},
+		{"PATH=$HOME/bin", "PATH=$$HOME/bin"},
+		{"", `""`},
+	}
+
+	for _, tt := range tests {
+		if got := systemdQuote(tt.arg); got != tt.want {
+			t.Errorf("systemdQuote(%q) = %s, want %s", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestServiceUnitName(t *testing.T) {
+	tests := []struct {
+		mountpoint string
+		want       string
+	}{
+		{"/home/me/issues", "ghissues-home-me-issues.service"},
+		{"/home/me/my issues", "ghissues-home-me-my-issues.service"},
+		{"/", "ghissues-root.service"},
+	}
+
+	for _, tt := range tests {
+		if got := serviceUnitName(tt.mountpoint); got != tt.want {
+			t.Errorf("serviceUnitName(%q) = %q, want %q", tt.mountpoint, got, tt.want)
+		}
+	}
+}
+
+// fakeSystemctl replaces runSystemctl with one recording its calls and
+// reporting every unit inactive.
+func fakeSystemctl(t *testing.T) *[]string {
+	t.Helper()
+	var calls []string
+	original := runSystemctl
+	runSystemctl = func(w io.Writer, args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		if args[0] == "is-active" {
+			fmt.Fprintln(w, "inactive")
+			return fmt.Errorf("exit status 3")
+		}
+		return nil
+	}
+	t.Cleanup(func() { runSystemctl = original })
+	return &calls
+}
+
+func TestInstallService(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GHISSUES_CONFIG", "")
+	calls := fakeSystemctl(t)
+	mountpoint := filepath.Join(t.TempDir(), "my issues")
+
+	var out bytes.Buffer
+	err := installService(&out, "/opt/ghissues", []string{"owner/repo", mountpoint}, []string{"--state", "open"}, 2*time.Minute, false)
+	if err != nil {
+		t.Fatalf("installService() error = %v", err)
+	}
+
+	name := serviceUnitName(mountpoint)
+	data, err := os.ReadFile(filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "systemd", "user", name))
+	if err != nil {
+		t.Fatalf("unit not written: %v", err)
+	}
+	unit := string(data)
+	for _, want := range []string{
+		fmt.Sprintf("ExecStart=/opt/ghissues mount owner/repo %q --state open\n", mountpoint),
+		fmt.Sprintf(" -u %q\n", mountpoint),
+		"ExecStop=-/",
+		"TimeoutStopSec=120\n",
+		"Environment=PATH=",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit does not contain %q:\n%s", want, unit)
+		}
+	}
+	if _, err := os.Stat(mountpoint); err != nil {
+		t.Errorf("mountpoint was not created: %v", err)
+	}
+
+	want := []string{"daemon-reload", "enable --now " + name}
+	if strings.Join(*calls, "|") != strings.Join(want, "|") {
+		t.Errorf("systemctl calls = %q, want %q", *calls, want)
+	}
+
+	if err := installService(&out, "/opt/ghissues", []string{"owner/repo", mountpoint}, []string{"--daemon"}, time.Minute, false); err == nil {
+		t.Error("expected --daemon to be rejected")
+	}
+	if err := installService(&out, "/opt/ghissues", []string{"invalid", mountpoint}, nil, time.Minute, false); err == nil {
+		t.Error("expected an invalid repository to be rejected")
+	}
+}
+
+func TestUninstallService(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	calls := fakeSystemctl(t)
+	mountpoint := t.TempDir()
+
+	var out bytes.Buffer
+	if err := installService(&out, "/opt/ghissues", []string{"owner/repo", mountpoint}, nil, time.Minute, true); err != nil {
+		t.Fatalf("installService() error = %v", err)
+	}
+
+	out.Reset()
+	if err := serviceStatus(&out, ""); err != nil {
+		t.Fatalf("serviceStatus() error = %v", err)
+	}
+	if !strings.Contains(out.String(), serviceUnitName(mountpoint)) || !strings.Contains(out.String(), "inactive") {
+		t.Errorf("serviceStatus() = %q, expected the unit listed as inactive", out.String())
+	}
+
+	*calls = nil
+	if err := uninstallService(&out, mountpoint); err != nil {
+		t.Fatalf("uninstallService() error = %v", err)
+	}
+	name := serviceUnitName(mountpoint)
+	want := []string{"disable --now " + name, "daemon-reload"}
+	if strings.Join(*calls, "|") != strings.Join(want, "|") {
+		t.Errorf("systemctl calls = %q, want %q", *calls, want)
+	}
+
+	if err := uninstallService(&out, mountpoint); err == nil || !strings.Contains(err.Error(), "no service is installed") {
+		t.Errorf("second uninstallService() error = %v", err)
+	}
+	if err := serviceStatus(&out, mountpoint); err == nil {
+		t.Error("serviceStatus() of an uninstalled service expected an error")
+	}
+}
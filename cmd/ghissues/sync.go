@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/control"
+	"github.com/JohanCodinha/ghissues/internal/fs"
+	"github.com/JohanCodinha/ghissues/internal/logger"
+	"github.com/JohanCodinha/ghissues/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags for the sync command
+var (
+	syncTimeout time.Duration
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync <owner/repo|mountpoint>",
+	Short: "Push pending changes to GitHub now",
+	Long: `Push all pending and dirty changes to GitHub immediately.
+
+Given a mountpoint, the running mount at that path is asked to flush.
+
+Given "owner/repo", the flush is routed through a running mount of that
+repository if there is one. Otherwise ghissues runs headless: it opens the
+same cache a mount would use, pulls issues from GitHub, pushes every queued
+edit and exits, which makes it suitable for cron jobs and CI. A headless sync
+refuses to run while another ghissues process holds the repository's cache.
+
+An existing directory is always treated as a mountpoint.
+
+The command blocks until the flush has finished, prints a summary and exits
+non-zero if any change failed to sync.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSync,
+}
+
+func init() {
+	syncCmd.Flags().DurationVar(&syncTimeout, "timeout", 0, "Give up waiting on a running mount after this long (0 waits forever)")
+	syncCmd.Flags().StringVar(&controlRepo, "repo", "", "Only flush this owner/repo of a multi-repo mount")
+	syncCmd.Flags().StringVar(&logLevel, "log-level", "", "Log level for headless syncs (debug, info, warn, error; defaults to the config file, then info)")
+	syncCmd.Flags().StringVar(&logFile, "log-file", "", "Path to log file for headless syncs (logs to stderr if not set)")
+	syncCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress non-error output")
+}
+
+// looksLikeRepo reports whether arg names a repository ("owner/repo")
+// rather than a path. Paths are absolute, relative with a leading dot,
+// or nested deeper than one slash.
+func looksLikeRepo(arg string) bool {
+	if strings.HasPrefix(arg, "/") || strings.HasPrefix(arg, ".") || strings.HasPrefix(arg, "~") {
+		return false
+	}
+	if strings.Count(arg, "/") != 1 {
+		return false
+	}
+	_, _, err := validateRepo(arg)
+	return err == nil
+}
+
+// isMountpointArg reports whether arg refers to a mountpoint: an existing
+// directory, or a path a running mount is serving. This is checked before
+// looksLikeRepo so that a relative path like "mnt/issues" is never synced
+// headless as a repository.
+func isMountpointArg(arg string) bool {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		return true
+	}
+
+	socketPath, err := getSocketPath(arg)
+	if err != nil {
+		return false
+	}
+	conn, err := net.DialTimeout("unix", socketPath, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// findMountForRepo returns a control client for a running mount serving repo,
+// or nil if no running mount serves it.
+func findMountForRepo(repo string) (*control.Client, error) {
+	runDir, err := getRunDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sockets, err := control.ListSockets(runDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, socketPath := range sockets {
+		client := control.NewClient(socketPath)
+		resp, err := client.Status(repo)
+		if err != nil || resp.Failed() {
+			continue
+		}
+		return client, nil
+	}
+	return nil, nil
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	target := args[0]
+
+	if isMountpointArg(target) || !looksLikeRepo(target) {
+		client, err := controlClientFor(target)
+		if err != nil {
+			return err
+		}
+		return syncMount(client, controlRepo)
+	}
+
+	// A running mount holds the cache lock while it is up; let it do the push.
+	// If its control socket is unavailable, openRepo fails on the lock instead
+	// of draining the same queue from a second process.
+	client, err := findMountForRepo(target)
+	if err != nil {
+		return err
+	}
+	if client != nil {
+		return syncMount(client, target)
+	}
+
+	settings, err := loadSettings(cmd, target)
+	if err != nil {
+		return err
+	}
+	if err := configureLogging(settings); err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	owner, repoName, err := validateRepo(target)
+	if err != nil {
+		return err
+	}
+
+	ghClient, err := newGitHubClient()
+	if err != nil {
+		return err
+	}
+
+	cacheDB, engine, err := openRepo(ghClient, owner, repoName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		engine.Stop()
+		if err := cacheDB.Close(); err != nil {
+			logger.Warn("failed to close cache: %v", err)
+		}
+	}()
+
+	return headlessSync(cmd.Context(), engine, target, os.Stdout)
+}
+
+// syncMount asks a running mount to flush repo (every repo when empty).
+func syncMount(client *control.Client, repo string) error {
+	client.Timeout = syncTimeout
+
+	resp, err := client.Sync(repo)
+	if err != nil {
+		return err
+	}
+
+	printResponse(resp)
+	return checkResponse("sync", resp)
+}
+
+// headlessSync pulls issues into the cache, pushes everything queued locally
+// and writes a summary to w. It returns an error if either step failed.
+func headlessSync(ctx context.Context, engine control.Engine, repo string, w io.Writer) error {
+	logger.Info("syncing issues from %s...", repo)
+	pullErr := engine.InitialSync(ctx)
+	if pullErr != nil {
+		logger.Warn("pull failed: %v", pullErr)
+	}
+
+	before := engine.GetStatus()
+	pushErr := engine.SyncNow(ctx)
+	after := engine.GetStatus()
+
+	writeSyncSummary(w, repo, before, after)
+
+	var errs []error
+	if pullErr != nil {
+		errs = append(errs, fmt.Errorf("pull failed: %w", pullErr))
+	}
+	if pushErr != nil {
+		errs = append(errs, fmt.Errorf("push failed: %w", pushErr))
+	}
+	return errors.Join(errs...)
+}
+
+// repoSession is a repository's cache opened by a one-shot command, along
+// with whatever pushes it: a running mount serving the repository, or a
+// headless sync engine holding the cache lock.
+type repoSession struct {
+	repo   string
+	db     *cache.DB
+	mount  *control.Client // nil when headless
+	engine *sync.Engine    // nil when a mount owns the cache
+}
+
+// openRepoSession opens the cache of repo through a running mount of it if
+// there is one, and headless otherwise.
+func openRepoSession(repo string) (*repoSession, error) {
+	owner, repoName, err := validateRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := findMountForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	if client != nil {
+		db, err := openExistingCache(repo)
+		if err != nil {
+			return nil, err
+		}
+		return &repoSession{repo: repo, db: db, mount: client}, nil
+	}
+
+	settings, err := loadSettings(nil, repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := configureLogging(settings); err != nil {
+		return nil, err
+	}
+
+	ghClient, err := newGitHubClient()
+	if err != nil {
+		logger.Close()
+		return nil, err
+	}
+
+	db, engine, err := openRepo(ghClient, owner, repoName)
+	if err != nil {
+		logger.Close()
+		return nil, err
+	}
+	return &repoSession{repo: repo, db: db, engine: engine}, nil
+}
+
+// refresh fetches issue number from GitHub into the cache.
+func (s *repoSession) refresh(ctx context.Context, number int) error {
+	if s.mount != nil {
+		resp, err := s.mount.Refresh(s.repo, number)
+		if err != nil {
+			return err
+		}
+		return checkResponse("refresh", resp)
+	}
+
+	_, err := s.engine.RefreshIssue(ctx, number)
+	return err
+}
+
+// push pushes everything queued in the cache to GitHub and prints a summary.
+func (s *repoSession) push(ctx context.Context) error {
+	if s.mount != nil {
+		return syncMount(s.mount, s.repo)
+	}
+
+	before := s.engine.GetStatus()
+	pushErr := s.engine.SyncNow(ctx)
+	writeSyncSummary(os.Stdout, s.repo, before, s.engine.GetStatus())
+	if pushErr != nil {
+		return fmt.Errorf("push failed: %w", pushErr)
+	}
+	return nil
+}
+
+// Close stops the engine and closes the cache.
+func (s *repoSession) Close() {
+	if s.engine != nil {
+		s.engine.Stop()
+		defer logger.Close()
+	}
+	if err := s.db.Close(); err != nil {
+		logger.Warn("failed to close cache: %v", err)
+	}
+}
+
+// writeSyncSummary writes what a push sent to GitHub and what is still queued,
+// based on the queue sizes before and after it.
+func writeSyncSummary(w io.Writer, repo string, before, after fs.SyncStatus) {
+	rows := []struct {
+		name          string
+		before, after int
+	}{
+		{"new issues", before.PendingIssues, after.PendingIssues},
+		{"new comments", before.PendingComments, after.PendingComments},
+		{"issue edits", before.DirtyIssues, after.DirtyIssues},
+		{"comment edits", before.DirtyComments, after.DirtyComments},
+	}
+
+	pushed, failed := 0, 0
+	for _, row := range rows {
+		pushed += max(row.before-row.after, 0)
+		failed += row.after
+	}
+
+	fmt.Fprintf(w, "%s: pushed %d, failed %d\n", repo, pushed, failed)
+	for _, row := range rows {
+		if row.before == 0 && row.after == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "  %-14s %d pushed, %d failed\n", row.name+":", max(row.before-row.after, 0), row.after)
+	}
+	if after.LastError != "" {
+		fmt.Fprintf(w, "Error: %s\n", after.LastError)
+	}
+}
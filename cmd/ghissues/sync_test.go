@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/control"
+	"github.com/JohanCodinha/ghissues/internal/fs"
+	"github.com/JohanCodinha/ghissues/internal/gh"
+	"github.com/JohanCodinha/ghissues/internal/sync"
+)
+
+func TestLooksLikeRepo(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want bool
+	}{
+		{"owner/repo", true},
+		{"my-org/my.repo", true},
+		{"./issues", false},
+		{"../issues", false},
+		{"/mnt/issues", false},
+		{"~/issues", false},
+		{"issues", false},
+		{"a/b/c", false},
+		{"owner/", false},
+		{"/repo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			if got := looksLikeRepo(tt.arg); got != tt.want {
+				t.Errorf("looksLikeRepo(%q) = %v, want %v", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncCmd_NoRunningMount(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	rootCmd.SetArgs([]string{"sync", tmpDir})
+	err := rootCmd.Execute()
+
+	if err == nil {
+		t.Fatal("sync should fail when nothing is mounted")
+	}
+	if !strings.Contains(err.Error(), "no running ghissues mount") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSyncCmd_RequiresOneArg(t *testing.T) {
+	rootCmd.SetArgs([]string{"sync"})
+	err := rootCmd.Execute()
+
+	if err == nil {
+		t.Error("sync command should fail with no arguments")
+	}
+}
+
+func TestSyncCmd_RelativeMountpointWithSlash(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+	t.Chdir(tmpDir)
+
+	// Looks like owner/repo, but is a directory
+	if err := os.MkdirAll(filepath.Join("mnt", "issues"), 0755); err != nil {
+		t.Fatalf("failed to create mountpoint: %v", err)
+	}
+
+	rootCmd.SetArgs([]string{"sync", "mnt/issues"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "no running ghissues mount") {
+		t.Fatalf("sync of an unmounted directory should fail as a mountpoint, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, ".cache", "ghissues", "mnt_issues.db")); !os.IsNotExist(err) {
+		t.Error("a directory argument must not be synced headless as a repository")
+	}
+}
+
+func TestSyncCmd_RepoRoutedThroughRunningMount(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	engine := &fakeEngine{}
+	server, err := startControlServer(filepath.Join(tmpDir, "mnt"), map[string]control.Engine{"owner/repo": engine})
+	if err != nil {
+		t.Fatalf("startControlServer() error = %v", err)
+	}
+	defer server.Close()
+
+	rootCmd.SetArgs([]string{"sync", "owner/repo"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("sync owner/repo error = %v", err)
+	}
+
+	if engine.syncCalls != 1 {
+		t.Errorf("expected the running mount to flush once, got %d", engine.syncCalls)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".cache", "ghissues", "owner_repo.db")); !os.IsNotExist(err) {
+		t.Error("sync should go through the running mount instead of opening the cache")
+	}
+}
+
+func TestSyncCmd_HeadlessRefusesWhileCacheLocked(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+	t.Setenv("GITHUB_TOKEN", "test-token")
+
+	// A mount whose control socket failed to start still holds the lock
+	cachePath, err := getCachePath("owner", "repo")
+	if err != nil {
+		t.Fatalf("getCachePath() error = %v", err)
+	}
+	holder, err := cache.InitDB(cachePath)
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer holder.Close()
+	if err := holder.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	rootCmd.SetArgs([]string{"sync", "owner/repo"})
+	err = rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Errorf("headless sync should refuse a locked cache, got %v", err)
+	}
+}
+
+func TestWriteSyncSummary(t *testing.T) {
+	before := fs.SyncStatus{PendingComments: 2, DirtyIssues: 3}
+	after := fs.SyncStatus{DirtyIssues: 1, LastError: "dirty issues: 422 Unprocessable Entity"}
+
+	var buf bytes.Buffer
+	writeSyncSummary(&buf, "owner/repo", before, after)
+	out := buf.String()
+
+	for _, want := range []string{
+		"owner/repo: pushed 4, failed 1",
+		"new comments:  2 pushed, 0 failed",
+		"issue edits:   2 pushed, 1 failed",
+		"Error: dirty issues: 422",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "new issues") {
+		t.Errorf("summary should skip empty queues:\n%s", out)
+	}
+}
+
+func TestHeadlessSync_PullsAndPushes(t *testing.T) {
+	cacheDB, err := cache.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer cacheDB.Close()
+
+	mockGH := gh.NewMockServer()
+	defer mockGH.Close()
+
+	baseTime := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	mockGH.AddIssue(&gh.Issue{
+		Number:    1,
+		Title:     "Issue 1",
+		Body:      "Remote body",
+		State:     "open",
+		User:      gh.User{Login: "user1"},
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	})
+
+	engine, err := sync.NewEngine(cacheDB, gh.NewWithBaseURL("test-token", mockGH.URL), "owner/repo", 100)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Stop()
+
+	// Queued from an earlier session
+	if err := cacheDB.AddPendingComment("owner/repo", 1, "Queued comment"); err != nil {
+		t.Fatalf("AddPendingComment() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := headlessSync(t.Context(), engine, "owner/repo", &buf); err != nil {
+		t.Fatalf("headlessSync() error = %v\n%s", err, buf.String())
+	}
+
+	if issue, _ := cacheDB.GetIssue("owner/repo", 1); issue == nil {
+		t.Error("issue should have been pulled into the cache")
+	}
+	if comments := mockGH.GetComments(1); len(comments) != 1 || comments[0].Body != "Queued comment" {
+		t.Errorf("queued comment should have been pushed, got %+v", comments)
+	}
+	if !strings.Contains(buf.String(), "pushed 1, failed 0") {
+		t.Errorf("unexpected summary:\n%s", buf.String())
+	}
+}
+
+func TestHeadlessSync_ReportsPullFailure(t *testing.T) {
+	cacheDB, err := cache.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer cacheDB.Close()
+
+	mockGH := gh.NewMockServer()
+	defer mockGH.Close()
+	mockGH.SetNextError(500, `{"message": "boom"}`)
+
+	engine, err := sync.NewEngine(cacheDB, gh.NewWithBaseURL("test-token", mockGH.URL), "owner/repo", 100)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Stop()
+
+	var buf bytes.Buffer
+	err = headlessSync(t.Context(), engine, "owner/repo", &buf)
+	if err == nil || !strings.Contains(err.Error(), "pull failed") {
+		t.Errorf("headlessSync() error = %v, want pull failure", err)
+	}
+}
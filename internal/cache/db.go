@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 type DB struct {
 	path string
 	conn *sql.DB
+	lock *os.File // held by Lock, released by Close
 }
 
 // Issue represents a cached issue.
@@ -35,8 +38,23 @@ type Issue struct {
 	ParentIssueNumber  int // 0 if no parent
 	SubIssuesTotal     int
 	SubIssuesCompleted int
+	URL                string // web page of the issue, empty until synced
+	Kind               string // KindIssue or KindPull, empty is stored as KindIssue
+
+	// Set for pull requests only
+	HeadRef    string // branch the pull request merges
+	BaseRef    string // branch the pull request merges into
+	MergeState string // merged, mergeable, conflicting or unknown
+	Draft      bool
 }
 
+// Kinds of cached issues: pull requests share the issues table, and their
+// numbers, with plain issues.
+const (
+	KindIssue = "issue"
+	KindPull  = "pull"
+)
+
 // Comment represents a cached issue comment.
 type Comment struct {
 	ID          int64
@@ -67,6 +85,12 @@ CREATE TABLE IF NOT EXISTS issues (
     parent_issue_number INTEGER DEFAULT 0,
     sub_issues_total INTEGER DEFAULT 0,
     sub_issues_completed INTEGER DEFAULT 0,
+    url TEXT DEFAULT '',
+    kind TEXT DEFAULT 'issue',
+    head_ref TEXT DEFAULT '',
+    base_ref TEXT DEFAULT '',
+    merge_state TEXT DEFAULT '',
+    draft INTEGER DEFAULT 0,
     UNIQUE(repo, number)
 );
 `
@@ -93,6 +117,22 @@ CREATE TABLE IF NOT EXISTS comments (
 );
 `
 
+// createReviewCommentsTableSQL defines the schema for the review comments of
+// pull requests, which are read-only.
+const createReviewCommentsTableSQL = `
+CREATE TABLE IF NOT EXISTS review_comments (
+    id INTEGER PRIMARY KEY,
+    pull_number INTEGER NOT NULL,
+    repo TEXT NOT NULL,
+    author TEXT NOT NULL,
+    path TEXT,
+    line INTEGER DEFAULT 0,
+    body TEXT,
+    created_at TEXT,
+    updated_at TEXT
+);
+`
+
 // createPendingCommentsTableSQL defines the schema for pending new comments.
 const createPendingCommentsTableSQL = `
 CREATE TABLE IF NOT EXISTS pending_comments (
@@ -117,6 +157,56 @@ CREATE TABLE IF NOT EXISTS pending_issues (
 );
 `
 
+// createSyncStateTableSQL defines the schema for per-repository sync bookkeeping.
+const createSyncStateTableSQL = `
+CREATE TABLE IF NOT EXISTS sync_state (
+    repo TEXT PRIMARY KEY,
+    last_sync_at TEXT,
+    issue_filter TEXT DEFAULT '',
+    sync_cursor TEXT DEFAULT '',
+    full_sync_at TEXT DEFAULT ''
+);
+`
+
+// createImportLedgerTableSQL defines the schema for the record of imported rows,
+// so that re-running an import never queues the same row twice.
+const createImportLedgerTableSQL = `
+CREATE TABLE IF NOT EXISTS import_ledger (
+    repo TEXT NOT NULL,
+    row_key TEXT NOT NULL,
+    pending_issue_id INTEGER NOT NULL,
+    issue_number INTEGER DEFAULT 0,
+    imported_at TEXT,
+    UNIQUE(repo, row_key)
+);
+`
+
+// createPushErrorsTableSQL defines the schema for the last push error of each
+// queued operation, so failing items can be inspected after the fact.
+const createPushErrorsTableSQL = `
+CREATE TABLE IF NOT EXISTS push_errors (
+    repo TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    item_id INTEGER NOT NULL,
+    message TEXT NOT NULL,
+    attempts INTEGER DEFAULT 1,
+    failed_at TEXT,
+    PRIMARY KEY(repo, kind, item_id)
+);
+`
+
+// createHTTPResponsesTableSQL defines the schema for the GitHub API
+// responses kept to make later requests for the same URL conditional.
+const createHTTPResponsesTableSQL = `
+CREATE TABLE IF NOT EXISTS http_responses (
+    url TEXT PRIMARY KEY,
+    header TEXT NOT NULL,
+    body BLOB NOT NULL,
+    used_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_http_responses_used_at ON http_responses(used_at);
+`
+
 // InitDB creates or opens a SQLite database at the given path and initializes the schema.
 func InitDB(path string) (*DB, error) {
 	conn, err := sql.Open("sqlite", path)
@@ -145,6 +235,13 @@ func InitDB(path string) (*DB, error) {
 		return nil, fmt.Errorf("failed to create comments table: %w", err)
 	}
 
+	// Create the review_comments table if it doesn't exist
+	_, err = conn.Exec(createReviewCommentsTableSQL)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create review_comments table: %w", err)
+	}
+
 	// Create the pending_comments table if it doesn't exist
 	_, err = conn.Exec(createPendingCommentsTableSQL)
 	if err != nil {
@@ -159,11 +256,52 @@ func InitDB(path string) (*DB, error) {
 		return nil, fmt.Errorf("failed to create pending_issues table: %w", err)
 	}
 
+	// Create the sync_state table if it doesn't exist
+	_, err = conn.Exec(createSyncStateTableSQL)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create sync_state table: %w", err)
+	}
+
+	// Create the import_ledger table if it doesn't exist
+	_, err = conn.Exec(createImportLedgerTableSQL)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create import_ledger table: %w", err)
+	}
+
+	// Create the push_errors table if it doesn't exist
+	_, err = conn.Exec(createPushErrorsTableSQL)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create push_errors table: %w", err)
+	}
+
+	// Create the http_responses table if it doesn't exist
+	_, err = conn.Exec(createHTTPResponsesTableSQL)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create http_responses table: %w", err)
+	}
+
 	// Migrate: add sub-issues columns if they don't exist
 	// We run each ALTER TABLE separately and ignore errors (column may already exist)
 	conn.Exec("ALTER TABLE issues ADD COLUMN parent_issue_number INTEGER DEFAULT 0")
 	conn.Exec("ALTER TABLE issues ADD COLUMN sub_issues_total INTEGER DEFAULT 0")
 	conn.Exec("ALTER TABLE issues ADD COLUMN sub_issues_completed INTEGER DEFAULT 0")
+	conn.Exec("ALTER TABLE sync_state ADD COLUMN issue_filter TEXT DEFAULT ''")
+	conn.Exec("ALTER TABLE issues ADD COLUMN url TEXT DEFAULT ''")
+	conn.Exec("ALTER TABLE sync_state ADD COLUMN sync_cursor TEXT DEFAULT ''")
+	conn.Exec("ALTER TABLE sync_state ADD COLUMN full_sync_at TEXT DEFAULT ''")
+	if _, err := conn.Exec("ALTER TABLE issues ADD COLUMN kind TEXT DEFAULT 'issue'"); err == nil {
+		// Pull requests synced before kinds were recorded pass for issues
+		// until a full sync stores them again, so make the next sync one
+		conn.Exec("UPDATE sync_state SET sync_cursor = ''")
+	}
+	conn.Exec("ALTER TABLE issues ADD COLUMN head_ref TEXT DEFAULT ''")
+	conn.Exec("ALTER TABLE issues ADD COLUMN base_ref TEXT DEFAULT ''")
+	conn.Exec("ALTER TABLE issues ADD COLUMN merge_state TEXT DEFAULT ''")
+	conn.Exec("ALTER TABLE issues ADD COLUMN draft INTEGER DEFAULT 0")
 
 	return &DB{
 		path: path,
@@ -171,8 +309,50 @@ func InitDB(path string) (*DB, error) {
 	}, nil
 }
 
-// Close closes the database connection.
+// InitDBLocked is InitDB taking the lock of the cache first, so that its
+// tables are never migrated under a mount or sync that has it open. It
+// fails with ErrLocked if the cache is in use.
+func InitDBLocked(path string) (*DB, error) {
+	locked := &DB{path: path}
+	if err := locked.Lock(); err != nil {
+		return nil, err
+	}
+
+	db, err := InitDB(path)
+	if err != nil {
+		locked.Unlock()
+		return nil, err
+	}
+	db.lock = locked.lock
+	return db, nil
+}
+
+// OpenReadOnly opens an existing cache for reading. Unlike InitDB it neither
+// creates nor migrates tables, so it is safe on a cache that a running mount
+// has open.
+func OpenReadOnly(path string) (*DB, error) {
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro"}).String()
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	conn.SetMaxOpenConns(1)
+
+	// sql.Open is lazy: connect now so that a missing file fails here
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return &DB{
+		path: path,
+		conn: conn,
+	}, nil
+}
+
+// Close closes the database connection and releases the lock, if held.
 func (db *DB) Close() error {
+	db.Unlock()
 	if db.conn != nil {
 		return db.conn.Close()
 	}
@@ -193,13 +373,22 @@ func (db *DB) UpsertIssue(issue Issue) error {
 	if issue.Dirty {
 		dirtyInt = 1
 	}
+	draftInt := 0
+	if issue.Draft {
+		draftInt = 1
+	}
+	kind := issue.Kind
+	if kind == "" {
+		kind = KindIssue
+	}
 
 	query := `
 		INSERT OR REPLACE INTO issues (
 			number, repo, title, body, state, author, labels,
 			created_at, updated_at, etag, dirty, local_updated_at,
-			parent_issue_number, sub_issues_total, sub_issues_completed
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			parent_issue_number, sub_issues_total, sub_issues_completed, url,
+			kind, head_ref, base_ref, merge_state, draft
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err = db.conn.Exec(query,
@@ -218,6 +407,12 @@ func (db *DB) UpsertIssue(issue Issue) error {
 		issue.ParentIssueNumber,
 		issue.SubIssuesTotal,
 		issue.SubIssuesCompleted,
+		issue.URL,
+		kind,
+		issue.HeadRef,
+		issue.BaseRef,
+		issue.MergeState,
+		draftInt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to upsert issue: %w", err)
@@ -231,7 +426,8 @@ func (db *DB) GetIssue(repo string, number int) (*Issue, error) {
 	query := `
 		SELECT id, number, repo, title, body, state, author, labels,
 		       created_at, updated_at, etag, dirty, local_updated_at,
-		       parent_issue_number, sub_issues_total, sub_issues_completed
+		       parent_issue_number, sub_issues_total, sub_issues_completed, url,
+		       kind, head_ref, base_ref, merge_state, draft
 		FROM issues
 		WHERE repo = ? AND number = ?
 	`
@@ -240,18 +436,29 @@ func (db *DB) GetIssue(repo string, number int) (*Issue, error) {
 	return scanIssueFrom(row)
 }
 
-// ListIssues retrieves all issues for a repository.
+// ListIssues retrieves all issues for a repository, leaving out pull requests.
 func (db *DB) ListIssues(repo string) ([]Issue, error) {
+	return db.listIssues(repo, KindIssue)
+}
+
+// ListPulls retrieves all pull requests for a repository.
+func (db *DB) ListPulls(repo string) ([]Issue, error) {
+	return db.listIssues(repo, KindPull)
+}
+
+// listIssues retrieves the issues of a repository of the given kind.
+func (db *DB) listIssues(repo, kind string) ([]Issue, error) {
 	query := `
 		SELECT id, number, repo, title, body, state, author, labels,
 		       created_at, updated_at, etag, dirty, local_updated_at,
-		       parent_issue_number, sub_issues_total, sub_issues_completed
+		       parent_issue_number, sub_issues_total, sub_issues_completed, url,
+		       kind, head_ref, base_ref, merge_state, draft
 		FROM issues
-		WHERE repo = ?
+		WHERE repo = ? AND kind = ?
 		ORDER BY number ASC
 	`
 
-	rows, err := db.conn.Query(query, repo)
+	rows, err := db.conn.Query(query, repo, kind)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query issues: %w", err)
 	}
@@ -273,6 +480,41 @@ func (db *DB) ListIssues(repo string) ([]Issue, error) {
 	return issues, nil
 }
 
+// RemoveIssue drops an issue or pull request and its comments from the
+// cache, unless the issue or one of its comments has local changes. It
+// reports whether the issue was removed.
+func (db *DB) RemoveIssue(repo string, number int) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		DELETE FROM issues
+		WHERE repo = ? AND number = ? AND dirty = 0
+		  AND NOT EXISTS (SELECT 1 FROM comments WHERE repo = ? AND issue_number = ? AND dirty = 1)
+	`, repo, number, repo, number)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove issue #%d: %w", number, err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM comments WHERE repo = ? AND issue_number = ?", repo, number); err != nil {
+		return false, fmt.Errorf("failed to remove comments of issue #%d: %w", number, err)
+	}
+	if _, err := tx.Exec("DELETE FROM review_comments WHERE repo = ? AND pull_number = ?", repo, number); err != nil {
+		return false, fmt.Errorf("failed to remove review comments of pull request #%d: %w", number, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
 // IssueUpdate contains optional fields for updating an issue in the cache.
 // Nil fields are not updated.
 type IssueUpdate struct {
@@ -350,7 +592,8 @@ func (db *DB) GetDirtyIssues(repo string) ([]Issue, error) {
 	query := `
 		SELECT id, number, repo, title, body, state, author, labels,
 		       created_at, updated_at, etag, dirty, local_updated_at,
-		       parent_issue_number, sub_issues_total, sub_issues_completed
+		       parent_issue_number, sub_issues_total, sub_issues_completed, url,
+		       kind, head_ref, base_ref, merge_state, draft
 		FROM issues
 		WHERE repo = ? AND dirty = 1
 		ORDER BY number ASC
@@ -415,6 +658,9 @@ func scanIssueFrom(s scanner) (*Issue, error) {
 	var body, state, author, labels, createdAt, updatedAt, etag, localUpdatedAt sql.NullString
 	var dirty int
 	var parentIssueNumber, subIssuesTotal, subIssuesCompleted sql.NullInt64
+	var url sql.NullString
+	var kind, headRef, baseRef, mergeState sql.NullString
+	var draft sql.NullInt64
 
 	err := s.Scan(
 		&issue.ID,
@@ -433,6 +679,12 @@ func scanIssueFrom(s scanner) (*Issue, error) {
 		&parentIssueNumber,
 		&subIssuesTotal,
 		&subIssuesCompleted,
+		&url,
+		&kind,
+		&headRef,
+		&baseRef,
+		&mergeState,
+		&draft,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -453,6 +705,15 @@ func scanIssueFrom(s scanner) (*Issue, error) {
 	issue.ParentIssueNumber = int(parentIssueNumber.Int64)
 	issue.SubIssuesTotal = int(subIssuesTotal.Int64)
 	issue.SubIssuesCompleted = int(subIssuesCompleted.Int64)
+	issue.URL = url.String
+	issue.Kind = kind.String
+	if issue.Kind == "" {
+		issue.Kind = KindIssue
+	}
+	issue.HeadRef = headRef.String
+	issue.BaseRef = baseRef.String
+	issue.MergeState = mergeState.String
+	issue.Draft = draft.Int64 == 1
 
 	// Parse labels JSON
 	if labels.Valid && labels.String != "" {
@@ -465,7 +726,10 @@ func scanIssueFrom(s scanner) (*Issue, error) {
 }
 
 // UpsertComments inserts or updates comments for an issue in the cache.
-// This replaces all existing comments for the issue with the provided comments.
+// This replaces all existing comments for the issue with the provided comments,
+// except comments with unsynced local edits (dirty=1), which are kept as-is.
+// A dirty comment missing from the provided list was deleted on GitHub and is
+// dropped, since its edit can no longer be pushed.
 func (db *DB) UpsertComments(repo string, issueNumber int, comments []Comment) error {
 	// Start a transaction to ensure atomicity
 	tx, err := db.conn.Begin()
@@ -474,12 +738,31 @@ func (db *DB) UpsertComments(repo string, issueNumber int, comments []Comment) e
 	}
 	defer tx.Rollback()
 
-	// Delete existing comments for this issue
-	_, err = tx.Exec("DELETE FROM comments WHERE repo = ? AND issue_number = ?", repo, issueNumber)
+	// Delete existing comments for this issue, keeping local edits
+	_, err = tx.Exec("DELETE FROM comments WHERE repo = ? AND issue_number = ? AND dirty = 0", repo, issueNumber)
 	if err != nil {
 		return fmt.Errorf("failed to delete existing comments: %w", err)
 	}
 
+	dirtyIDs, err := dirtyCommentIDs(tx, repo, issueNumber)
+	if err != nil {
+		return err
+	}
+
+	remoteIDs := make(map[int64]bool, len(comments))
+	for _, comment := range comments {
+		remoteIDs[comment.ID] = true
+	}
+	for id := range dirtyIDs {
+		if remoteIDs[id] {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM comments WHERE repo = ? AND id = ?", repo, id); err != nil {
+			return fmt.Errorf("failed to delete remotely deleted comment %d: %w", id, err)
+		}
+		delete(dirtyIDs, id)
+	}
+
 	// Insert new comments
 	query := `
 		INSERT INTO comments (id, issue_number, repo, author, body, created_at, updated_at)
@@ -487,6 +770,9 @@ func (db *DB) UpsertComments(repo string, issueNumber int, comments []Comment) e
 	`
 
 	for _, comment := range comments {
+		if dirtyIDs[comment.ID] {
+			continue
+		}
 		_, err = tx.Exec(query,
 			comment.ID,
 			issueNumber,
@@ -508,6 +794,56 @@ func (db *DB) UpsertComments(repo string, issueNumber int, comments []Comment) e
 	return nil
 }
 
+// UpsertComment inserts or updates a single comment, leaving the other
+// comments of its issue alone. A comment with unsynced local edits is kept
+// as-is.
+func (db *DB) UpsertComment(comment Comment) error {
+	query := `
+		INSERT INTO comments (id, issue_number, repo, author, body, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			issue_number = excluded.issue_number,
+			author = excluded.author,
+			body = excluded.body,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
+		WHERE dirty = 0
+	`
+
+	_, err := db.conn.Exec(query,
+		comment.ID,
+		comment.IssueNumber,
+		comment.Repo,
+		comment.Author,
+		sql.NullString{String: comment.Body, Valid: comment.Body != ""},
+		sql.NullString{String: comment.CreatedAt, Valid: comment.CreatedAt != ""},
+		sql.NullString{String: comment.UpdatedAt, Valid: comment.UpdatedAt != ""},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert comment %d: %w", comment.ID, err)
+	}
+	return nil
+}
+
+// dirtyCommentIDs returns the IDs of locally edited comments on an issue.
+func dirtyCommentIDs(tx *sql.Tx, repo string, issueNumber int) (map[int64]bool, error) {
+	rows, err := tx.Query("SELECT id FROM comments WHERE repo = ? AND issue_number = ? AND dirty = 1", repo, issueNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dirty comments: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan dirty comment: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
 // GetComments retrieves all comments for an issue from the cache.
 // Comments are ordered by created_at ascending.
 func (db *DB) GetComments(repo string, issueNumber int) ([]Comment, error) {
@@ -719,6 +1055,17 @@ func (db *DB) ClearCommentDirty(repo string, commentID int64) error {
 
 // AddPendingIssue adds a new pending issue to be synced to GitHub.
 func (db *DB) AddPendingIssue(repo, title, body string, labels []string) (int64, error) {
+	return addPendingIssue(db.conn, repo, title, body, labels)
+}
+
+// execer is an interface that both *sql.DB and *sql.Tx implement.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// addPendingIssue inserts a pending issue through ex, so it can run inside a
+// caller's transaction.
+func addPendingIssue(ex execer, repo, title, body string, labels []string) (int64, error) {
 	createdAt := time.Now().UTC().Format(time.RFC3339)
 
 	labelsJSON, err := json.Marshal(labels)
@@ -731,7 +1078,7 @@ func (db *DB) AddPendingIssue(repo, title, body string, labels []string) (int64,
 		VALUES (?, ?, ?, ?, ?)
 	`
 
-	result, err := db.conn.Exec(query, repo, title, body, string(labelsJSON), createdAt)
+	result, err := ex.Exec(query, repo, title, body, string(labelsJSON), createdAt)
 	if err != nil {
 		return 0, fmt.Errorf("failed to add pending issue: %w", err)
 	}
@@ -95,6 +95,38 @@ func TestInitDB_CanReopenExistingDB(t *testing.T) {
 	}
 }
 
+func TestOpenReadOnly(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "with space", "test.db")
+	os.MkdirAll(filepath.Dir(dbPath), 0755)
+
+	if _, err := OpenReadOnly(dbPath); err == nil {
+		t.Fatal("OpenReadOnly() of a missing cache should fail")
+	}
+
+	db, err := InitDB(dbPath)
+	if err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	if err := db.UpsertIssue(Issue{Number: 1, Repo: "owner/repo", Title: "Test Issue"}); err != nil {
+		t.Fatalf("failed to insert issue: %v", err)
+	}
+
+	// Opened while another connection still has the cache open
+	ro, err := OpenReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("OpenReadOnly() error = %v", err)
+	}
+	defer ro.Close()
+	db.Close()
+
+	if issue, err := ro.GetIssue("owner/repo", 1); err != nil || issue == nil || issue.Title != "Test Issue" {
+		t.Errorf("GetIssue() = %+v, %v", issue, err)
+	}
+	if err := ro.UpsertIssue(Issue{Number: 2, Repo: "owner/repo", Title: "Other"}); err == nil {
+		t.Error("UpsertIssue() on a read-only cache should fail")
+	}
+}
+
 func TestUpsertIssue_InsertsNewIssue(t *testing.T) {
 	db, cleanup := createTestDB(t)
 	defer cleanup()
@@ -111,6 +143,7 @@ func TestUpsertIssue_InsertsNewIssue(t *testing.T) {
 		UpdatedAt: "2024-01-02T00:00:00Z",
 		ETag:      `"abc123"`,
 		Dirty:     false,
+		URL:       "https://ghe.example.com/owner/repo/issues/42",
 	}
 
 	err := db.UpsertIssue(issue)
@@ -161,6 +194,9 @@ func TestUpsertIssue_InsertsNewIssue(t *testing.T) {
 	if retrieved.Dirty {
 		t.Error("expected dirty to be false")
 	}
+	if retrieved.URL != issue.URL {
+		t.Errorf("expected url %q, got %q", issue.URL, retrieved.URL)
+	}
 }
 
 func TestUpsertIssue_UpdatesExistingIssue(t *testing.T) {
@@ -489,6 +525,49 @@ func TestClearDirty_ReturnsErrorForNonExistentIssue(t *testing.T) {
 	}
 }
 
+func TestRemoveIssue(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	for n := 1; n <= 3; n++ {
+		db.UpsertIssue(Issue{Number: n, Repo: "owner/repo", Title: "Issue"})
+		db.UpsertComments("owner/repo", n, []Comment{{ID: int64(n * 10), IssueNumber: n, Author: "alice", Body: "Comment"}})
+	}
+	title := "Edited"
+	db.MarkDirty("owner/repo", 2, IssueUpdate{Title: &title})
+	db.MarkCommentDirty("owner/repo", 30, "Edited comment")
+
+	tests := []struct {
+		number int
+		want   bool
+	}{
+		{1, true},   // clean
+		{2, false},  // dirty issue
+		{3, false},  // dirty comment
+		{99, false}, // not cached
+	}
+
+	for _, tt := range tests {
+		removed, err := db.RemoveIssue("owner/repo", tt.number)
+		if err != nil {
+			t.Fatalf("RemoveIssue(%d) error = %v", tt.number, err)
+		}
+		if removed != tt.want {
+			t.Errorf("RemoveIssue(%d) = %v, want %v", tt.number, removed, tt.want)
+		}
+	}
+
+	if issue, _ := db.GetIssue("owner/repo", 1); issue != nil {
+		t.Error("issue #1 should be removed")
+	}
+	if comments, _ := db.GetComments("owner/repo", 1); len(comments) != 0 {
+		t.Errorf("comments of issue #1 should be removed, got %+v", comments)
+	}
+	if comments, _ := db.GetComments("owner/repo", 3); len(comments) != 1 {
+		t.Errorf("comments of issue #3 should be kept, got %+v", comments)
+	}
+}
+
 func TestWorkflow_MarkDirtyThenClear(t *testing.T) {
 	db, cleanup := createTestDB(t)
 	defer cleanup()
@@ -786,6 +865,47 @@ func TestUpsertComments_ReplacesExistingComments(t *testing.T) {
 	}
 }
 
+func TestUpsertComment(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	initial := []Comment{
+		{ID: 100, Author: "alice", Body: "First", CreatedAt: "2026-01-01T00:00:00Z"},
+		{ID: 101, Author: "bob", Body: "Second", CreatedAt: "2026-01-02T00:00:00Z"},
+	}
+	if err := db.UpsertComments("owner/repo", 1, initial); err != nil {
+		t.Fatalf("UpsertComments() error = %v", err)
+	}
+	if err := db.MarkCommentDirty("owner/repo", 101, "Local edit"); err != nil {
+		t.Fatalf("MarkCommentDirty() error = %v", err)
+	}
+
+	// An edit and a new comment are applied, the other comments kept
+	for _, c := range []Comment{
+		{ID: 100, IssueNumber: 1, Repo: "owner/repo", Author: "alice", Body: "First, edited", CreatedAt: "2026-01-01T00:00:00Z"},
+		{ID: 101, IssueNumber: 1, Repo: "owner/repo", Author: "bob", Body: "Remote edit", CreatedAt: "2026-01-02T00:00:00Z"},
+		{ID: 102, IssueNumber: 1, Repo: "owner/repo", Author: "carol", Body: "Third", CreatedAt: "2026-01-03T00:00:00Z"},
+	} {
+		if err := db.UpsertComment(c); err != nil {
+			t.Fatalf("UpsertComment(%d) error = %v", c.ID, err)
+		}
+	}
+
+	got, err := db.GetComments("owner/repo", 1)
+	if err != nil {
+		t.Fatalf("GetComments() error = %v", err)
+	}
+	want := []string{"First, edited", "Local edit", "Third"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d comments, want %d", len(got), len(want))
+	}
+	for i, c := range got {
+		if c.Body != want[i] {
+			t.Errorf("comment %d body = %q, want %q", c.ID, c.Body, want[i])
+		}
+	}
+}
+
 func TestGetComments_ReturnsEmptySliceForNoComments(t *testing.T) {
 	db, cleanup := createTestDB(t)
 	defer cleanup()
@@ -1345,3 +1465,101 @@ func TestUpsertComments_UnicodeAndSpecialChars(t *testing.T) {
 		}
 	}
 }
+
+func TestUpsertComments_KeepsDirtyComments(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	issue := Issue{Number: 1, Repo: "owner/repo", Title: "Test Issue"}
+	if err := db.UpsertIssue(issue); err != nil {
+		t.Fatalf("failed to insert issue: %v", err)
+	}
+
+	comments := []Comment{
+		{ID: 100, Author: "alice", Body: "First", CreatedAt: "2026-01-10T10:00:00Z"},
+		{ID: 101, Author: "bob", Body: "Second", CreatedAt: "2026-01-10T11:00:00Z"},
+	}
+	if err := db.UpsertComments("owner/repo", 1, comments); err != nil {
+		t.Fatalf("failed to insert comments: %v", err)
+	}
+
+	if err := db.MarkCommentDirty("owner/repo", 100, "Edited locally"); err != nil {
+		t.Fatalf("MarkCommentDirty failed: %v", err)
+	}
+
+	remote := []Comment{
+		{ID: 100, Author: "alice", Body: "First (remote)", CreatedAt: "2026-01-10T10:00:00Z"},
+		{ID: 101, Author: "bob", Body: "Second (remote)", CreatedAt: "2026-01-10T11:00:00Z"},
+	}
+	if err := db.UpsertComments("owner/repo", 1, remote); err != nil {
+		t.Fatalf("UpsertComments failed: %v", err)
+	}
+
+	retrieved, err := db.GetComments("owner/repo", 1)
+	if err != nil {
+		t.Fatalf("GetComments failed: %v", err)
+	}
+	if len(retrieved) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(retrieved))
+	}
+	if retrieved[0].Body != "Edited locally" {
+		t.Errorf("dirty comment body = %q, want local edit kept", retrieved[0].Body)
+	}
+	if retrieved[1].Body != "Second (remote)" {
+		t.Errorf("clean comment body = %q, want remote version", retrieved[1].Body)
+	}
+
+	dirty, err := db.GetDirtyComments("owner/repo")
+	if err != nil {
+		t.Fatalf("GetDirtyComments failed: %v", err)
+	}
+	if len(dirty) != 1 || dirty[0].ID != 100 {
+		t.Errorf("expected comment 100 to stay dirty, got %+v", dirty)
+	}
+}
+
+func TestUpsertComments_DropsDirtyCommentDeletedRemotely(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	issue := Issue{Number: 1, Repo: "owner/repo", Title: "Test Issue"}
+	if err := db.UpsertIssue(issue); err != nil {
+		t.Fatalf("failed to insert issue: %v", err)
+	}
+
+	comments := []Comment{
+		{ID: 100, Author: "alice", Body: "First", CreatedAt: "2026-01-10T10:00:00Z"},
+		{ID: 101, Author: "bob", Body: "Second", CreatedAt: "2026-01-10T11:00:00Z"},
+	}
+	if err := db.UpsertComments("owner/repo", 1, comments); err != nil {
+		t.Fatalf("failed to insert comments: %v", err)
+	}
+
+	if err := db.MarkCommentDirty("owner/repo", 100, "Edited locally"); err != nil {
+		t.Fatalf("MarkCommentDirty failed: %v", err)
+	}
+
+	// Comment 100 was deleted on GitHub
+	remote := []Comment{
+		{ID: 101, Author: "bob", Body: "Second", CreatedAt: "2026-01-10T11:00:00Z"},
+	}
+	if err := db.UpsertComments("owner/repo", 1, remote); err != nil {
+		t.Fatalf("UpsertComments failed: %v", err)
+	}
+
+	retrieved, err := db.GetComments("owner/repo", 1)
+	if err != nil {
+		t.Fatalf("GetComments failed: %v", err)
+	}
+	if len(retrieved) != 1 || retrieved[0].ID != 101 {
+		t.Errorf("expected only comment 101 to remain, got %+v", retrieved)
+	}
+
+	dirty, err := db.GetDirtyComments("owner/repo")
+	if err != nil {
+		t.Fatalf("GetDirtyComments failed: %v", err)
+	}
+	if len(dirty) != 0 {
+		t.Errorf("deleted comment should no longer be queued for sync, got %+v", dirty)
+	}
+}
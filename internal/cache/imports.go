@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// ImportRecord is the ledger entry of an imported row.
+type ImportRecord struct {
+	Key            string
+	PendingIssueID int64
+	IssueNumber    int // 0 until the issue is created on GitHub
+	ImportedAt     string
+}
+
+// AddImportedIssue queues a new issue as AddPendingIssue does and records key in
+// the import ledger, in a single transaction. If key was already imported for
+// repo, nothing is queued and added is false.
+func (db *DB) AddImportedIssue(repo, key, title, body string, labels []string) (id int64, added bool, err error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRow("SELECT COUNT(*) FROM import_ledger WHERE repo = ? AND row_key = ?", repo, key).Scan(&exists)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to check import ledger: %w", err)
+	}
+	if exists > 0 {
+		return 0, false, nil
+	}
+
+	id, err = addPendingIssue(tx, repo, title, body, labels)
+	if err != nil {
+		return 0, false, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO import_ledger (repo, row_key, pending_issue_id, imported_at)
+		VALUES (?, ?, ?, ?)
+	`, repo, key, id, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to record import: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return id, true, nil
+}
+
+// GetImportLedger returns the imported rows of repo, keyed by row key.
+func (db *DB) GetImportLedger(repo string) (map[string]ImportRecord, error) {
+	rows, err := db.conn.Query(`
+		SELECT row_key, pending_issue_id, issue_number, imported_at
+		FROM import_ledger
+		WHERE repo = ?
+	`, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query import ledger: %w", err)
+	}
+	defer rows.Close()
+
+	ledger := make(map[string]ImportRecord)
+	for rows.Next() {
+		var r ImportRecord
+		if err := rows.Scan(&r.Key, &r.PendingIssueID, &r.IssueNumber, &r.ImportedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan import record: %w", err)
+		}
+		ledger[r.Key] = r
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating import ledger: %w", err)
+	}
+
+	return ledger, nil
+}
+
+// RecordCreatedIssue stores the number GitHub gave to the issue created from
+// a pending issue. It is a no-op for pending issues that were not imported.
+func (db *DB) RecordCreatedIssue(pendingID int64, number int) error {
+	_, err := db.conn.Exec("UPDATE import_ledger SET issue_number = ? WHERE pending_issue_id = ?", number, pendingID)
+	if err != nil {
+		return fmt.Errorf("failed to record created issue: %w", err)
+	}
+	return nil
+}
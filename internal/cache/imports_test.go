@@ -0,0 +1,62 @@
+package cache
+
+import "testing"
+
+func TestAddImportedIssue_SkipsKnownKeys(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	id, added, err := db.AddImportedIssue("owner/repo", "row-1", "First", "body", []string{"bug"})
+	if err != nil || !added {
+		t.Fatalf("AddImportedIssue() = %d, %v, %v", id, added, err)
+	}
+
+	// Same key again, even with different content
+	if _, added, err := db.AddImportedIssue("owner/repo", "row-1", "First (edited)", "", nil); err != nil || added {
+		t.Errorf("re-import added = %v, err = %v; want skipped", added, err)
+	}
+
+	// Same key in another repo is a different import
+	if _, added, err := db.AddImportedIssue("owner/other", "row-1", "First", "", nil); err != nil || !added {
+		t.Errorf("import into another repo added = %v, err = %v", added, err)
+	}
+
+	pending, err := db.GetPendingIssues("owner/repo")
+	if err != nil {
+		t.Fatalf("GetPendingIssues() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != id || pending[0].Title != "First" || len(pending[0].Labels) != 1 {
+		t.Errorf("unexpected pending issues: %+v", pending)
+	}
+}
+
+func TestRecordCreatedIssue(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	id, _, err := db.AddImportedIssue("owner/repo", "row-1", "First", "", nil)
+	if err != nil {
+		t.Fatalf("AddImportedIssue() error = %v", err)
+	}
+
+	ledger, err := db.GetImportLedger("owner/repo")
+	if err != nil {
+		t.Fatalf("GetImportLedger() error = %v", err)
+	}
+	if r := ledger["row-1"]; r.PendingIssueID != id || r.IssueNumber != 0 {
+		t.Errorf("ledger entry before push = %+v", r)
+	}
+
+	if err := db.RecordCreatedIssue(id, 42); err != nil {
+		t.Fatalf("RecordCreatedIssue() error = %v", err)
+	}
+	// Pending issues that were not imported are ignored
+	if err := db.RecordCreatedIssue(id+100, 43); err != nil {
+		t.Fatalf("RecordCreatedIssue() error = %v", err)
+	}
+
+	ledger, _ = db.GetImportLedger("owner/repo")
+	if len(ledger) != 1 || ledger["row-1"].IssueNumber != 42 {
+		t.Errorf("ledger after push = %+v", ledger)
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// ErrLocked is returned by Lock when another process holds the cache lock.
+var ErrLocked = errors.New("cache is locked by another process")
+
+// Lock takes an exclusive lock on the cache without blocking.
+// Only the process holding it may push the cache's queues to GitHub, so that
+// a mount and a headless sync never create the same pending issue twice.
+// The lock lives in a "<db>.lock" file next to the database; the kernel
+// releases it if the process dies, so a crash never leaves it stuck.
+func (db *DB) Lock() error {
+	if db.lock != nil {
+		return nil
+	}
+
+	f, err := os.OpenFile(db.path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open cache lock: %w", err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return ErrLocked
+		}
+		return fmt.Errorf("failed to lock cache: %w", err)
+	}
+
+	// Record the holder for humans; the flock is what matters
+	f.Truncate(0)
+	f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+
+	db.lock = f
+	return nil
+}
+
+// Unlock releases the lock taken by Lock. It is a no-op if the lock isn't held.
+func (db *DB) Unlock() error {
+	if db.lock == nil {
+		return nil
+	}
+	f := db.lock
+	db.lock = nil
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return f.Close()
+}
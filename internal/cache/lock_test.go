@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLock_ExclusiveUntilClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	first, err := InitDB(path)
+	if err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	second, err := InitDB(path)
+	if err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	defer second.Close()
+
+	if err := first.Lock(); err != nil {
+		t.Fatalf("first Lock() error = %v", err)
+	}
+	if err := first.Lock(); err != nil {
+		t.Errorf("Lock() should be idempotent for the holder, got %v", err)
+	}
+
+	if err := second.Lock(); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second Lock() error = %v, want ErrLocked", err)
+	}
+
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if err := second.Lock(); err != nil {
+		t.Errorf("Lock() after the holder closed should succeed, got %v", err)
+	}
+}
+
+func TestUnlock_WithoutLock(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	if err := db.Unlock(); err != nil {
+		t.Errorf("Unlock() without Lock() should be a no-op, got %v", err)
+	}
+}
+
+func TestInitDBLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	holder, err := InitDBLocked(path)
+	if err != nil {
+		t.Fatalf("InitDBLocked() error = %v", err)
+	}
+	if _, err := InitDBLocked(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("InitDBLocked() of a locked cache error = %v, want ErrLocked", err)
+	}
+	holder.Close()
+
+	db, err := InitDBLocked(path)
+	if err != nil {
+		t.Fatalf("InitDBLocked() after the holder closed error = %v", err)
+	}
+	db.Close()
+}
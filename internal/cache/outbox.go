@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Kinds of queued operations, as recorded in push_errors.
+const (
+	KindNewIssue    = "new-issue"    // pending_issues row, by id
+	KindNewComment  = "new-comment"  // pending_comments row, by id
+	KindIssueEdit   = "issue-edit"   // dirty issue, by number
+	KindCommentEdit = "comment-edit" // dirty comment, by comment id
+)
+
+// PushErrorKey identifies a queued operation.
+type PushErrorKey struct {
+	Kind string
+	ID   int64
+}
+
+// PushError is the last error pushing a queued operation to GitHub.
+type PushError struct {
+	Message  string
+	Attempts int
+	FailedAt string
+}
+
+// RecordPushError stores the error of a failed push of the given operation,
+// counting how many times in a row it failed.
+func (db *DB) RecordPushError(repo, kind string, id int64, pushErr error) error {
+	query := `
+		INSERT INTO push_errors (repo, kind, item_id, message, attempts, failed_at)
+		VALUES (?, ?, ?, ?, 1, ?)
+		ON CONFLICT(repo, kind, item_id) DO UPDATE SET
+			message = excluded.message,
+			attempts = attempts + 1,
+			failed_at = excluded.failed_at
+	`
+
+	_, err := db.conn.Exec(query, repo, kind, id, pushErr.Error(), time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to record push error: %w", err)
+	}
+	return nil
+}
+
+// ClearPushError forgets the last error of an operation, once it was pushed,
+// discarded or edited.
+func (db *DB) ClearPushError(repo, kind string, id int64) error {
+	_, err := db.conn.Exec("DELETE FROM push_errors WHERE repo = ? AND kind = ? AND item_id = ?", repo, kind, id)
+	if err != nil {
+		return fmt.Errorf("failed to clear push error: %w", err)
+	}
+	return nil
+}
+
+// GetPushErrors returns the last push error of every operation of repo that
+// failed since it was queued.
+func (db *DB) GetPushErrors(repo string) (map[PushErrorKey]PushError, error) {
+	rows, err := db.conn.Query(`
+		SELECT kind, item_id, message, attempts, failed_at
+		FROM push_errors
+		WHERE repo = ?
+	`, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push errors: %w", err)
+	}
+	defer rows.Close()
+
+	errs := make(map[PushErrorKey]PushError)
+	for rows.Next() {
+		var key PushErrorKey
+		var pe PushError
+		var failedAt sql.NullString
+		if err := rows.Scan(&key.Kind, &key.ID, &pe.Message, &pe.Attempts, &failedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan push error: %w", err)
+		}
+		pe.FailedAt = failedAt.String
+		errs[key] = pe
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating push errors: %w", err)
+	}
+
+	return errs, nil
+}
+
+// UpdatePendingIssue replaces the title, body and labels of a pending issue
+// that was not pushed yet.
+func (db *DB) UpdatePendingIssue(id int64, title, body string, labels []string) error {
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return fmt.Errorf("failed to marshal labels: %w", err)
+	}
+
+	result, err := db.conn.Exec("UPDATE pending_issues SET title = ?, body = ?, labels = ? WHERE id = ?",
+		title, body, string(labelsJSON), id)
+	if err != nil {
+		return fmt.Errorf("failed to update pending issue: %w", err)
+	}
+	return requireRow(result, fmt.Sprintf("no pending issue with id=%d", id))
+}
+
+// UpdatePendingComment replaces the body of a pending comment that was not
+// pushed yet.
+func (db *DB) UpdatePendingComment(id int64, body string) error {
+	result, err := db.conn.Exec("UPDATE pending_comments SET body = ? WHERE id = ?", body, id)
+	if err != nil {
+		return fmt.Errorf("failed to update pending comment: %w", err)
+	}
+	return requireRow(result, fmt.Sprintf("no pending comment with id=%d", id))
+}
+
+// ResetIssue replaces a cached issue with its remote version, discarding any
+// local edits that were waiting to be pushed.
+func (db *DB) ResetIssue(remote Issue) error {
+	remote.Dirty = false
+	remote.LocalUpdatedAt = ""
+	if err := db.UpsertIssue(remote); err != nil {
+		return err
+	}
+	return db.ClearPushError(remote.Repo, KindIssueEdit, int64(remote.Number))
+}
+
+// DeleteIssue removes an issue, with any local edits, from the cache. Its
+// comments are kept. The issue is fetched again on the next sync.
+func (db *DB) DeleteIssue(repo string, number int) error {
+	result, err := db.conn.Exec("DELETE FROM issues WHERE repo = ? AND number = ?", repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to delete issue: %w", err)
+	}
+	if err := requireRow(result, fmt.Sprintf("no issue found with repo=%s and number=%d", repo, number)); err != nil {
+		return err
+	}
+	return db.ClearPushError(repo, KindIssueEdit, int64(number))
+}
+
+// DiscardCommentEdit drops the local edit of a comment. The comment is
+// removed from the cache until the next sync fetches its remote version.
+func (db *DB) DiscardCommentEdit(repo string, commentID int64) error {
+	result, err := db.conn.Exec("DELETE FROM comments WHERE repo = ? AND id = ? AND dirty = 1", repo, commentID)
+	if err != nil {
+		return fmt.Errorf("failed to discard comment edit: %w", err)
+	}
+	if err := requireRow(result, fmt.Sprintf("no edited comment with repo=%s and id=%d", repo, commentID)); err != nil {
+		return err
+	}
+	return db.ClearPushError(repo, KindCommentEdit, commentID)
+}
+
+// requireRow returns an error with the given message if result changed no row.
+func requireRow(result sql.Result, message string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s", message)
+	}
+	return nil
+}
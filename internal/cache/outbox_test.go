@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPushErrors_RecordCountAndClear(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	key := PushErrorKey{Kind: KindNewIssue, ID: 3}
+	db.RecordPushError("owner/repo", key.Kind, key.ID, errors.New("first failure"))
+	db.RecordPushError("owner/repo", key.Kind, key.ID, errors.New("422 Unprocessable Entity"))
+	db.RecordPushError("owner/other", key.Kind, key.ID, errors.New("other repo"))
+
+	errs, err := db.GetPushErrors("owner/repo")
+	if err != nil {
+		t.Fatalf("GetPushErrors() error = %v", err)
+	}
+	pe, ok := errs[key]
+	if len(errs) != 1 || !ok {
+		t.Fatalf("expected one push error for %+v, got %+v", key, errs)
+	}
+	if pe.Message != "422 Unprocessable Entity" || pe.Attempts != 2 || pe.FailedAt == "" {
+		t.Errorf("unexpected push error: %+v", pe)
+	}
+
+	if err := db.ClearPushError("owner/repo", key.Kind, key.ID); err != nil {
+		t.Fatalf("ClearPushError() error = %v", err)
+	}
+	if errs, _ := db.GetPushErrors("owner/repo"); len(errs) != 0 {
+		t.Errorf("expected no push errors after clearing, got %+v", errs)
+	}
+}
+
+func TestUpdatePendingItems(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	id, _ := db.AddPendingIssue("owner/repo", "Title", "Body", []string{"bad label"})
+	if err := db.UpdatePendingIssue(id, "New title", "New body", []string{"bug"}); err != nil {
+		t.Fatalf("UpdatePendingIssue() error = %v", err)
+	}
+	issues, _ := db.GetPendingIssues("owner/repo")
+	if len(issues) != 1 || issues[0].Title != "New title" || issues[0].Body != "New body" || issues[0].Labels[0] != "bug" {
+		t.Errorf("unexpected pending issue: %+v", issues)
+	}
+	if err := db.UpdatePendingIssue(id+1, "x", "", nil); err == nil {
+		t.Error("expected an error updating a missing pending issue")
+	}
+
+	db.AddPendingComment("owner/repo", 1, "typo")
+	comments, _ := db.GetPendingComments("owner/repo")
+	if err := db.UpdatePendingComment(comments[0].ID, "fixed"); err != nil {
+		t.Fatalf("UpdatePendingComment() error = %v", err)
+	}
+	comments, _ = db.GetPendingComments("owner/repo")
+	if comments[0].Body != "fixed" {
+		t.Errorf("pending comment body = %q, expected %q", comments[0].Body, "fixed")
+	}
+}
+
+func TestResetAndDeleteIssue(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	remote := Issue{Number: 1, Repo: "owner/repo", Title: "Remote title", State: "open"}
+	db.UpsertIssue(remote)
+	title := "Local title"
+	db.MarkDirty("owner/repo", 1, IssueUpdate{Title: &title})
+	db.RecordPushError("owner/repo", KindIssueEdit, 1, errors.New("failed"))
+
+	if err := db.ResetIssue(remote); err != nil {
+		t.Fatalf("ResetIssue() error = %v", err)
+	}
+	issue, _ := db.GetIssue("owner/repo", 1)
+	if issue.Dirty || issue.Title != "Remote title" {
+		t.Errorf("issue after reset = %+v", issue)
+	}
+	if errs, _ := db.GetPushErrors("owner/repo"); len(errs) != 0 {
+		t.Errorf("reset should clear the push error, got %+v", errs)
+	}
+
+	if err := db.DeleteIssue("owner/repo", 1); err != nil {
+		t.Fatalf("DeleteIssue() error = %v", err)
+	}
+	if issue, _ := db.GetIssue("owner/repo", 1); issue != nil {
+		t.Errorf("issue should be gone, got %+v", issue)
+	}
+	if err := db.DeleteIssue("owner/repo", 1); err == nil {
+		t.Error("expected an error deleting a missing issue")
+	}
+}
+
+func TestDiscardCommentEdit(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	db.UpsertComments("owner/repo", 1, []Comment{
+		{ID: 10, Author: "alice", Body: "remote"},
+		{ID: 11, Author: "bob", Body: "untouched"},
+	})
+	db.MarkCommentDirty("owner/repo", 10, "local edit")
+
+	if err := db.DiscardCommentEdit("owner/repo", 11); err == nil {
+		t.Error("expected an error discarding a comment without local edits")
+	}
+	if err := db.DiscardCommentEdit("owner/repo", 10); err != nil {
+		t.Fatalf("DiscardCommentEdit() error = %v", err)
+	}
+
+	dirty, _ := db.GetDirtyComments("owner/repo")
+	if len(dirty) != 0 {
+		t.Errorf("expected no dirty comments, got %+v", dirty)
+	}
+
+	// The next sync brings back the remote version
+	db.UpsertComments("owner/repo", 1, []Comment{
+		{ID: 10, Author: "alice", Body: "remote"},
+		{ID: 11, Author: "bob", Body: "untouched"},
+	})
+	comments, _ := db.GetComments("owner/repo", 1)
+	if len(comments) != 2 || comments[0].Body != "remote" {
+		t.Errorf("unexpected comments after sync: %+v", comments)
+	}
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ReviewComment represents a cached comment on the diff of a pull request.
+type ReviewComment struct {
+	ID         int64
+	PullNumber int
+	Repo       string
+	Author     string
+	Path       string
+	Line       int // 0 when the line is gone from the diff
+	Body       string
+	CreatedAt  string
+	UpdatedAt  string
+}
+
+// UpsertReviewComments replaces the cached review comments of a pull request
+// with comments.
+func (db *DB) UpsertReviewComments(repo string, pullNumber int, comments []ReviewComment) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM review_comments WHERE repo = ? AND pull_number = ?", repo, pullNumber); err != nil {
+		return fmt.Errorf("failed to delete existing review comments: %w", err)
+	}
+	for _, comment := range comments {
+		comment.Repo, comment.PullNumber = repo, pullNumber
+		if err := upsertReviewComment(tx, comment); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UpsertReviewComment inserts or updates a single review comment, leaving
+// the other comments of its pull request alone.
+func (db *DB) UpsertReviewComment(comment ReviewComment) error {
+	return upsertReviewComment(db.conn, comment)
+}
+
+func upsertReviewComment(ex execer, comment ReviewComment) error {
+	_, err := ex.Exec(`
+		INSERT OR REPLACE INTO review_comments (id, pull_number, repo, author, path, line, body, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		comment.ID,
+		comment.PullNumber,
+		comment.Repo,
+		comment.Author,
+		comment.Path,
+		comment.Line,
+		sql.NullString{String: comment.Body, Valid: comment.Body != ""},
+		sql.NullString{String: comment.CreatedAt, Valid: comment.CreatedAt != ""},
+		sql.NullString{String: comment.UpdatedAt, Valid: comment.UpdatedAt != ""},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert review comment %d: %w", comment.ID, err)
+	}
+	return nil
+}
+
+// GetReviewComments retrieves the review comments of a pull request,
+// ordered by file, line and creation time.
+func (db *DB) GetReviewComments(repo string, pullNumber int) ([]ReviewComment, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, pull_number, repo, author, path, line, body, created_at, updated_at
+		FROM review_comments
+		WHERE repo = ? AND pull_number = ?
+		ORDER BY path ASC, line ASC, created_at ASC
+	`, repo, pullNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []ReviewComment{}
+	for rows.Next() {
+		var comment ReviewComment
+		var path, body, createdAt, updatedAt sql.NullString
+		err := rows.Scan(
+			&comment.ID,
+			&comment.PullNumber,
+			&comment.Repo,
+			&comment.Author,
+			&path,
+			&comment.Line,
+			&body,
+			&createdAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review comment: %w", err)
+		}
+		comment.Path = path.String
+		comment.Body = body.String
+		comment.CreatedAt = createdAt.String
+		comment.UpdatedAt = updatedAt.String
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review comment rows: %w", err)
+	}
+	return comments, nil
+}
@@ -0,0 +1,97 @@
+package cache
+
+import "testing"
+
+func TestListPulls_SeparatesPullsFromIssues(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	repo := "owner/repo"
+	records := []Issue{
+		{Repo: repo, Number: 1, Title: "Issue", State: "open"},
+		{Repo: repo, Number: 2, Title: "Pull", State: "open", Kind: KindPull, HeadRef: "feature", BaseRef: "main", MergeState: "conflicting", Draft: true},
+	}
+	for _, issue := range records {
+		if err := db.UpsertIssue(issue); err != nil {
+			t.Fatalf("UpsertIssue failed: %v", err)
+		}
+	}
+
+	issues, err := db.ListIssues(repo)
+	if err != nil {
+		t.Fatalf("ListIssues failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Number != 1 || issues[0].Kind != KindIssue {
+		t.Errorf("ListIssues = %+v, want only issue #1", issues)
+	}
+
+	pulls, err := db.ListPulls(repo)
+	if err != nil {
+		t.Fatalf("ListPulls failed: %v", err)
+	}
+	if len(pulls) != 1 || pulls[0].Number != 2 {
+		t.Fatalf("ListPulls = %+v, want only pull request #2", pulls)
+	}
+	got := pulls[0]
+	if got.Kind != KindPull || got.HeadRef != "feature" || got.BaseRef != "main" || got.MergeState != "conflicting" || !got.Draft {
+		t.Errorf("pull request = %+v, want its branches and merge state", got)
+	}
+}
+
+func TestReviewComments(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	repo := "owner/repo"
+	if err := db.UpsertIssue(Issue{Repo: repo, Number: 2, Title: "Pull", Kind: KindPull}); err != nil {
+		t.Fatalf("UpsertIssue failed: %v", err)
+	}
+
+	err := db.UpsertReviewComments(repo, 2, []ReviewComment{
+		{ID: 11, Author: "bob", Path: "b.go", Line: 3, Body: "Second file", CreatedAt: "2024-01-15T10:00:00Z"},
+		{ID: 10, Author: "alice", Path: "a.go", Line: 9, Body: "Later line", CreatedAt: "2024-01-15T09:00:00Z"},
+		{ID: 12, Author: "carol", Path: "a.go", Line: 1, Body: "First line", CreatedAt: "2024-01-15T11:00:00Z"},
+	})
+	if err != nil {
+		t.Fatalf("UpsertReviewComments failed: %v", err)
+	}
+
+	comments, err := db.GetReviewComments(repo, 2)
+	if err != nil {
+		t.Fatalf("GetReviewComments failed: %v", err)
+	}
+	var ids []int64
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	if len(ids) != 3 || ids[0] != 12 || ids[1] != 10 || ids[2] != 11 {
+		t.Errorf("review comment order = %v, want [12 10 11] by path and line", ids)
+	}
+
+	// A single comment is updated in place
+	if err := db.UpsertReviewComment(ReviewComment{ID: 10, PullNumber: 2, Repo: repo, Author: "alice", Path: "a.go", Line: 9, Body: "Edited"}); err != nil {
+		t.Fatalf("UpsertReviewComment failed: %v", err)
+	}
+	comments, _ = db.GetReviewComments(repo, 2)
+	if len(comments) != 3 || comments[1].Body != "Edited" {
+		t.Errorf("review comments after edit = %+v", comments)
+	}
+
+	// Replacing drops comments that are gone
+	if err := db.UpsertReviewComments(repo, 2, []ReviewComment{{ID: 11, Author: "bob", Path: "b.go"}}); err != nil {
+		t.Fatalf("UpsertReviewComments failed: %v", err)
+	}
+	comments, _ = db.GetReviewComments(repo, 2)
+	if len(comments) != 1 || comments[0].ID != 11 {
+		t.Errorf("review comments after replace = %+v, want only #11", comments)
+	}
+
+	// Removing the pull request removes its review comments
+	if _, err := db.RemoveIssue(repo, 2); err != nil {
+		t.Fatalf("RemoveIssue failed: %v", err)
+	}
+	comments, _ = db.GetReviewComments(repo, 2)
+	if len(comments) != 0 {
+		t.Errorf("review comments after RemoveIssue = %+v, want none", comments)
+	}
+}
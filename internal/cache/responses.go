@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// responseMaxAge is how long a stored response may go unused before it is
+// dropped, so that responses of URLs no longer requested do not pile up.
+const responseMaxAge = 30 * 24 * time.Hour
+
+// responseTouchInterval is how stale the last use of a response may get
+// before reading it records a new one. It keeps reads from being writes,
+// while staying far below responseMaxAge.
+const responseTouchInterval = 24 * time.Hour
+
+// GetResponse returns the header and body stored for a GitHub API url, or a
+// nil header when there is none.
+func (db *DB) GetResponse(url string) (http.Header, []byte, error) {
+	var headerJSON, usedAt string
+	var body []byte
+	err := db.conn.QueryRow("SELECT header, body, used_at FROM http_responses WHERE url = ?", url).Scan(&headerJSON, &body, &usedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cached response: %w", err)
+	}
+
+	var header http.Header
+	if err := json.Unmarshal([]byte(headerJSON), &header); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode cached response header: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if used, err := time.Parse(time.RFC3339, usedAt); err != nil || now.Sub(used) > responseTouchInterval {
+		if _, err := db.conn.Exec("UPDATE http_responses SET used_at = ? WHERE url = ?", now.Format(time.RFC3339), url); err != nil {
+			return nil, nil, fmt.Errorf("failed to update cached response: %w", err)
+		}
+	}
+	return header, body, nil
+}
+
+// PutResponse stores the header and body of a response to url, and drops
+// responses left unused for a month.
+func (db *DB) PutResponse(url string, header http.Header, body []byte) error {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to encode response header: %w", err)
+	}
+
+	if body == nil {
+		body = []byte{}
+	}
+
+	now := time.Now().UTC()
+	_, err = db.conn.Exec(`
+		INSERT INTO http_responses (url, header, body, used_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET header = excluded.header, body = excluded.body, used_at = excluded.used_at
+	`, url, string(headerJSON), body, now.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to store response: %w", err)
+	}
+
+	cutoff := now.Add(-responseMaxAge).Format(time.RFC3339)
+	if _, err := db.conn.Exec("DELETE FROM http_responses WHERE used_at < ?", cutoff); err != nil {
+		return fmt.Errorf("failed to drop unused responses: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestResponses(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	url := "https://api.github.com/repos/owner/repo/issues/1/comments"
+	header, body, err := db.GetResponse(url)
+	if err != nil || header != nil || body != nil {
+		t.Fatalf("GetResponse of an unknown url = %v, %q, %v, want nothing", header, body, err)
+	}
+
+	stored := http.Header{"Etag": {`"abc"`}, "Link": {`<next>; rel="next"`}}
+	if err := db.PutResponse(url, stored, []byte(`[{"id": 1}]`)); err != nil {
+		t.Fatalf("PutResponse failed: %v", err)
+	}
+	header, body, err = db.GetResponse(url)
+	if err != nil {
+		t.Fatalf("GetResponse failed: %v", err)
+	}
+	if header.Get("ETag") != `"abc"` || header.Get("Link") != `<next>; rel="next"` || string(body) != `[{"id": 1}]` {
+		t.Errorf("GetResponse = %v, %q, want the stored response", header, body)
+	}
+
+	// Storing again replaces the response
+	if err := db.PutResponse(url, http.Header{"Etag": {`"def"`}}, nil); err != nil {
+		t.Fatalf("PutResponse failed: %v", err)
+	}
+	header, body, _ = db.GetResponse(url)
+	if header.Get("ETag") != `"def"` || len(body) != 0 {
+		t.Errorf("GetResponse after replace = %v, %q", header, body)
+	}
+
+	// Reading only records the use once a day
+	usedAt := func() string {
+		var at string
+		db.conn.QueryRow("SELECT used_at FROM http_responses WHERE url = ?", url).Scan(&at)
+		return at
+	}
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	db.conn.Exec("UPDATE http_responses SET used_at = ?", recent)
+	db.GetResponse(url)
+	if got := usedAt(); got != recent {
+		t.Errorf("used_at = %s after a read within a day, want it kept at %s", got, recent)
+	}
+	yesterday := time.Now().Add(-2 * responseTouchInterval).UTC().Format(time.RFC3339)
+	db.conn.Exec("UPDATE http_responses SET used_at = ?", yesterday)
+	db.GetResponse(url)
+	if got := usedAt(); got == yesterday {
+		t.Error("used_at was not updated by a read after a day")
+	}
+
+	// Responses unused for too long are dropped on the next store
+	old := time.Now().Add(-2 * responseMaxAge).UTC().Format(time.RFC3339)
+	if _, err := db.conn.Exec("UPDATE http_responses SET used_at = ?", old); err != nil {
+		t.Fatalf("failed to age responses: %v", err)
+	}
+	if err := db.PutResponse(url+"?page=2", http.Header{}, []byte("[]")); err != nil {
+		t.Fatalf("PutResponse failed: %v", err)
+	}
+	if header, _, _ := db.GetResponse(url); header != nil {
+		t.Errorf("stale response was kept: %v", header)
+	}
+	if header, _, _ := db.GetResponse(url + "?page=2"); header == nil {
+		t.Error("fresh response was dropped")
+	}
+}
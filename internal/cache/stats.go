@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stats summarizes what the cache holds for one repository.
+type Stats struct {
+	Issues          int
+	Comments        int
+	DirtyIssues     int
+	DirtyComments   int
+	PendingIssues   int
+	PendingComments int
+	LastSync        time.Time // zero if the repository was never fully synced
+}
+
+// Unpushed returns the number of local changes not yet pushed to GitHub.
+func (s *Stats) Unpushed() int {
+	return s.DirtyIssues + s.DirtyComments + s.PendingIssues + s.PendingComments
+}
+
+// Path returns the path of the database file.
+func (db *DB) Path() string {
+	return db.path
+}
+
+// RecordSync records that repo was fully synced from GitHub at the given time.
+func (db *DB) RecordSync(repo string, at time.Time) error {
+	query := `
+		INSERT INTO sync_state (repo, last_sync_at) VALUES (?, ?)
+		ON CONFLICT(repo) DO UPDATE SET last_sync_at = excluded.last_sync_at
+	`
+
+	if _, err := db.conn.Exec(query, repo, at.UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("failed to record sync time: %w", err)
+	}
+	return nil
+}
+
+// SetIssueFilter records which issues of repo the cache holds, as encoded
+// by the sync engine. An empty filter means every issue.
+func (db *DB) SetIssueFilter(repo, filter string) error {
+	query := `
+		INSERT INTO sync_state (repo, issue_filter) VALUES (?, ?)
+		ON CONFLICT(repo) DO UPDATE SET issue_filter = excluded.issue_filter
+	`
+
+	if _, err := db.conn.Exec(query, repo, filter); err != nil {
+		return fmt.Errorf("failed to record issue filter: %w", err)
+	}
+	return nil
+}
+
+// IssueFilter returns the filter recorded with SetIssueFilter, or "" if none.
+func (db *DB) IssueFilter(repo string) (string, error) {
+	var filter string
+	err := db.conn.QueryRow("SELECT COALESCE(MAX(issue_filter), '') FROM sync_state WHERE repo = ?", repo).Scan(&filter)
+	if err != nil {
+		return "", fmt.Errorf("failed to get issue filter: %w", err)
+	}
+	return filter, nil
+}
+
+// SetSyncCursor records that every change of repo made on GitHub before
+// cursor is in the cache, so that the next sync only fetches what changed
+// since. full records that the sync fetched every issue.
+func (db *DB) SetSyncCursor(repo string, cursor time.Time, full bool) error {
+	query := `
+		INSERT INTO sync_state (repo, sync_cursor, full_sync_at) VALUES (?, ?, ?)
+		ON CONFLICT(repo) DO UPDATE SET
+			sync_cursor = excluded.sync_cursor,
+			full_sync_at = CASE WHEN ? THEN excluded.full_sync_at ELSE full_sync_at END
+	`
+
+	at := cursor.UTC().Format(time.RFC3339)
+	fullAt := ""
+	if full {
+		fullAt = at
+	}
+	if _, err := db.conn.Exec(query, repo, at, fullAt, full); err != nil {
+		return fmt.Errorf("failed to record sync cursor: %w", err)
+	}
+	return nil
+}
+
+// ClearSyncCursor forgets the sync cursor of repo, so that its next sync
+// fetches every issue again.
+func (db *DB) ClearSyncCursor(repo string) error {
+	if _, err := db.conn.Exec("UPDATE sync_state SET sync_cursor = '' WHERE repo = ?", repo); err != nil {
+		return fmt.Errorf("failed to clear sync cursor: %w", err)
+	}
+	return nil
+}
+
+// SyncCursor returns the cursor recorded with SetSyncCursor and the cursor
+// of the last full sync. Both are zero if repo was never synced.
+func (db *DB) SyncCursor(repo string) (cursor, fullSync time.Time, err error) {
+	var c, f string
+	err = db.conn.QueryRow("SELECT COALESCE(MAX(sync_cursor), ''), COALESCE(MAX(full_sync_at), '') FROM sync_state WHERE repo = ?", repo).Scan(&c, &f)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to get sync cursor: %w", err)
+	}
+	if c != "" {
+		if cursor, err = time.Parse(time.RFC3339, c); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse sync cursor %q: %w", c, err)
+		}
+	}
+	if f != "" {
+		if fullSync, err = time.Parse(time.RFC3339, f); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse full sync time %q: %w", f, err)
+		}
+	}
+	return cursor, fullSync, nil
+}
+
+// Repos returns the repositories that have issues, comments, pending items
+// or sync state in the cache, sorted by name.
+func (db *DB) Repos() ([]string, error) {
+	query := `
+		SELECT repo FROM issues
+		UNION SELECT repo FROM comments
+		UNION SELECT repo FROM pending_issues
+		UNION SELECT repo FROM pending_comments
+		UNION SELECT repo FROM sync_state
+		ORDER BY repo
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []string
+	for rows.Next() {
+		var repo string
+		if err := rows.Scan(&repo); err != nil {
+			return nil, fmt.Errorf("failed to scan repository: %w", err)
+		}
+		repos = append(repos, repo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating repositories: %w", err)
+	}
+
+	return repos, nil
+}
+
+// Stats returns the counts of cached and unpushed items for repo.
+func (db *DB) Stats(repo string) (*Stats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM issues WHERE repo = ?),
+			(SELECT COUNT(*) FROM comments WHERE repo = ?),
+			(SELECT COUNT(*) FROM issues WHERE repo = ? AND dirty = 1),
+			(SELECT COUNT(*) FROM comments WHERE repo = ? AND dirty = 1),
+			(SELECT COUNT(*) FROM pending_issues WHERE repo = ?),
+			(SELECT COUNT(*) FROM pending_comments WHERE repo = ?),
+			(SELECT COALESCE(MAX(last_sync_at), '') FROM sync_state WHERE repo = ?)
+	`
+
+	var stats Stats
+	var lastSync string
+	err := db.conn.QueryRow(query, repo, repo, repo, repo, repo, repo, repo).Scan(
+		&stats.Issues,
+		&stats.Comments,
+		&stats.DirtyIssues,
+		&stats.DirtyComments,
+		&stats.PendingIssues,
+		&stats.PendingComments,
+		&lastSync,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache stats for %s: %w", repo, err)
+	}
+
+	if lastSync != "" {
+		stats.LastSync, err = time.Parse(time.RFC3339, lastSync)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last sync time %q: %w", lastSync, err)
+		}
+	}
+
+	return &stats, nil
+}
+
+// Vacuum rebuilds the database file to reclaim the space of deleted rows.
+func (db *DB) Vacuum() error {
+	if _, err := db.conn.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum cache: %w", err)
+	}
+	return nil
+}
+
+// IntegrityCheck runs SQLite's integrity check and returns the problems it
+// found, or nil if the database is intact.
+func (db *DB) IntegrityCheck() ([]string, error) {
+	rows, err := db.conn.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cache integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check cache integrity: %w", err)
+	}
+	return problems, nil
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	for _, number := range []int{1, 2, 3} {
+		if err := db.UpsertIssue(Issue{Number: number, Repo: "owner/repo", Title: "Issue", State: "open"}); err != nil {
+			t.Fatalf("UpsertIssue() error = %v", err)
+		}
+	}
+	if err := db.UpsertIssue(Issue{Number: 1, Repo: "other/repo", Title: "Other", State: "open"}); err != nil {
+		t.Fatalf("UpsertIssue() error = %v", err)
+	}
+	if err := db.UpsertComments("owner/repo", 1, []Comment{
+		{ID: 10, IssueNumber: 1, Repo: "owner/repo", Author: "alice", Body: "one"},
+		{ID: 11, IssueNumber: 1, Repo: "owner/repo", Author: "bob", Body: "two"},
+	}); err != nil {
+		t.Fatalf("UpsertComments() error = %v", err)
+	}
+
+	title := "Edited"
+	if err := db.MarkDirty("owner/repo", 2, IssueUpdate{Title: &title}); err != nil {
+		t.Fatalf("MarkDirty() error = %v", err)
+	}
+	if err := db.MarkCommentDirty("owner/repo", 10, "edited"); err != nil {
+		t.Fatalf("MarkCommentDirty() error = %v", err)
+	}
+	if err := db.AddPendingComment("owner/repo", 1, "new"); err != nil {
+		t.Fatalf("AddPendingComment() error = %v", err)
+	}
+	if _, err := db.AddPendingIssue("owner/repo", "New", "body", nil); err != nil {
+		t.Fatalf("AddPendingIssue() error = %v", err)
+	}
+
+	stats, err := db.Stats("owner/repo")
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	want := Stats{Issues: 3, Comments: 2, DirtyIssues: 1, DirtyComments: 1, PendingIssues: 1, PendingComments: 1}
+	if *stats != want {
+		t.Errorf("Stats() = %+v, want %+v", *stats, want)
+	}
+	if stats.Unpushed() != 4 {
+		t.Errorf("Unpushed() = %d, want 4", stats.Unpushed())
+	}
+}
+
+func TestRecordSync(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	stats, err := db.Stats("owner/repo")
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if !stats.LastSync.IsZero() {
+		t.Errorf("LastSync = %v, want zero before any sync", stats.LastSync)
+	}
+
+	first := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	for _, at := range []time.Time{first, second} {
+		if err := db.RecordSync("owner/repo", at); err != nil {
+			t.Fatalf("RecordSync() error = %v", err)
+		}
+	}
+
+	stats, err = db.Stats("owner/repo")
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if !stats.LastSync.Equal(second) {
+		t.Errorf("LastSync = %v, want %v", stats.LastSync, second)
+	}
+}
+
+func TestIssueFilter(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	if filter, err := db.IssueFilter("owner/repo"); err != nil || filter != "" {
+		t.Fatalf("IssueFilter() = %q, %v; want empty before any filter", filter, err)
+	}
+
+	// The filter and the sync time are kept independently
+	db.RecordSync("owner/repo", time.Now())
+	if err := db.SetIssueFilter("owner/repo", `{"state":"open"}`); err != nil {
+		t.Fatalf("SetIssueFilter() error = %v", err)
+	}
+	if filter, _ := db.IssueFilter("owner/repo"); filter != `{"state":"open"}` {
+		t.Errorf("IssueFilter() = %q", filter)
+	}
+	if stats, _ := db.Stats("owner/repo"); stats.LastSync.IsZero() {
+		t.Error("SetIssueFilter() cleared the last sync time")
+	}
+	if filter, _ := db.IssueFilter("owner/other"); filter != "" {
+		t.Errorf("IssueFilter(other repo) = %q, want empty", filter)
+	}
+}
+
+func TestSyncCursor(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	if cursor, full, err := db.SyncCursor("owner/repo"); err != nil || !cursor.IsZero() || !full.IsZero() {
+		t.Fatalf("SyncCursor() = %v, %v, %v; want zero before any sync", cursor, full, err)
+	}
+
+	first := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	if err := db.SetSyncCursor("owner/repo", first, true); err != nil {
+		t.Fatalf("SetSyncCursor() error = %v", err)
+	}
+	// An incremental sync moves the cursor but not the full sync time
+	if err := db.SetSyncCursor("owner/repo", second, false); err != nil {
+		t.Fatalf("SetSyncCursor() error = %v", err)
+	}
+
+	cursor, full, err := db.SyncCursor("owner/repo")
+	if err != nil {
+		t.Fatalf("SyncCursor() error = %v", err)
+	}
+	if !cursor.Equal(second) || !full.Equal(first) {
+		t.Errorf("SyncCursor() = %v, %v; want %v, %v", cursor, full, second, first)
+	}
+
+	// A cleared cursor forces the next sync to be a full one
+	if err := db.ClearSyncCursor("owner/repo"); err != nil {
+		t.Fatalf("ClearSyncCursor() error = %v", err)
+	}
+	if cursor, _, err := db.SyncCursor("owner/repo"); err != nil || !cursor.IsZero() {
+		t.Errorf("SyncCursor() after ClearSyncCursor() = %v, %v; want zero", cursor, err)
+	}
+}
+
+func TestRepos(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	repos, err := db.Repos()
+	if err != nil {
+		t.Fatalf("Repos() error = %v", err)
+	}
+	if len(repos) != 0 {
+		t.Errorf("Repos() = %v, want none", repos)
+	}
+
+	db.UpsertIssue(Issue{Number: 1, Repo: "owner/repo", Title: "Issue", State: "open"})
+	db.AddPendingIssue("owner/new", "New", "", nil)
+
+	repos, err = db.Repos()
+	if err != nil {
+		t.Fatalf("Repos() error = %v", err)
+	}
+	if len(repos) != 2 || repos[0] != "owner/new" || repos[1] != "owner/repo" {
+		t.Errorf("Repos() = %v, want [owner/new owner/repo]", repos)
+	}
+}
+
+func TestVacuum(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	db.UpsertIssue(Issue{Number: 1, Repo: "owner/repo", Title: "Issue", State: "open"})
+	if err := db.Vacuum(); err != nil {
+		t.Fatalf("Vacuum() error = %v", err)
+	}
+	if issue, _ := db.GetIssue("owner/repo", 1); issue == nil {
+		t.Error("Vacuum() lost data")
+	}
+}
+
+func TestIntegrityCheck(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	db.UpsertIssue(Issue{Number: 1, Repo: "owner/repo", Title: "Issue", State: "open"})
+	problems, err := db.IntegrityCheck()
+	if err != nil {
+		t.Fatalf("IntegrityCheck() error = %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("IntegrityCheck() = %v, expected no problems", problems)
+	}
+}
@@ -0,0 +1,298 @@
+// Package config loads ghissues settings from the config file and the environment.
+//
+// The config file lives at ~/.config/ghissues/config.yaml (or $GHISSUES_CONFIG)
+// and holds global defaults plus per-repository overrides:
+//
+//	host: ghe.example.com
+//	app_id: 123456
+//	app_installation_id: 7890123
+//	app_private_key: ~/.config/ghissues/app.pem
+//	log_level: info
+//	debounce: 500ms
+//	repos:
+//	  org/busy-repo:
+//	    debounce: 2s
+//	    refresh_ttl: 1m
+//	    hide_pulls: true
+//
+// Settings are layered, each layer overriding the ones before it: built-in
+// defaults, the file's global settings, the file's settings for the repository,
+// then GHISSUES_* environment variables. Command line flags go on top.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults for settings that are not configured anywhere.
+const (
+	DefaultLogLevel   = "info"
+	DefaultDebounce   = 500 * time.Millisecond
+	DefaultRefreshTTL = 30 * time.Second
+	DefaultCacheDir   = "~/.cache/ghissues"
+	DefaultHost       = "github.com"
+)
+
+// Settings are the effective settings for a repository.
+type Settings struct {
+	LogLevel   string
+	LogFile    string
+	Quiet      bool
+	Debounce   time.Duration // delay before local edits are pushed
+	RefreshTTL time.Duration // minimum time between background refreshes of an issue
+	CacheDir   string        // absolute directory holding the cache databases
+	Host       string        // github.com or a GitHub Enterprise Server hostname
+	HidePulls  bool          // neither sync nor show pull requests
+
+	// GitHub App to authenticate as instead of the user, when AppID is set
+	AppID             int64
+	AppInstallationID int64
+	AppPrivateKey     string // absolute path of the app's PEM private key
+}
+
+// Options is one layer of settings. Nil fields are unset and leave the
+// value of the layers below untouched.
+type Options struct {
+	LogLevel   *string        `yaml:"log_level"`
+	LogFile    *string        `yaml:"log_file"`
+	Quiet      *bool          `yaml:"quiet"`
+	Debounce   *time.Duration `yaml:"debounce"`
+	RefreshTTL *time.Duration `yaml:"refresh_ttl"`
+	CacheDir   *string        `yaml:"cache_dir"`
+	Host       *string        `yaml:"host"`
+	HidePulls  *bool          `yaml:"hide_pulls"`
+
+	AppID             *int64  `yaml:"app_id"`
+	AppInstallationID *int64  `yaml:"app_installation_id"`
+	AppPrivateKey     *string `yaml:"app_private_key"`
+}
+
+// File is the content of the config file.
+type File struct {
+	Options `yaml:",inline"`
+	Repos   map[string]Options `yaml:"repos"` // "owner/repo" -> overrides
+}
+
+// Path returns the location of the config file.
+// $GHISSUES_CONFIG takes precedence over ~/.config/ghissues/config.yaml.
+func Path() (string, error) {
+	if path := os.Getenv("GHISSUES_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "ghissues", "config.yaml"), nil
+}
+
+// Load reads the config file at path. A missing file is not an error and
+// yields an empty File, so that every setting takes its default.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &File{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var f File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	// Reject misspelled keys instead of silently ignoring them
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for repo := range f.Repos {
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid repository %q in config file %s: must be in the format owner/repo", repo, path)
+		}
+		o := f.Repos[repo]
+		if o.Host != nil {
+			return nil, fmt.Errorf("invalid repository %q in config file %s: host applies to every repository and cannot be set per repository", repo, path)
+		}
+		if o.AppID != nil || o.AppInstallationID != nil || o.AppPrivateKey != nil {
+			return nil, fmt.Errorf("invalid repository %q in config file %s: GitHub App settings apply to every repository and cannot be set per repository", repo, path)
+		}
+	}
+
+	return &f, nil
+}
+
+// Resolve returns the effective settings for repo, or the global settings
+// when repo is empty. getenv is used to read GHISSUES_* variables.
+func (f *File) Resolve(repo string, getenv func(string) string) (Settings, error) {
+	s := Settings{
+		LogLevel:   DefaultLogLevel,
+		Debounce:   DefaultDebounce,
+		RefreshTTL: DefaultRefreshTTL,
+		CacheDir:   DefaultCacheDir,
+		Host:       DefaultHost,
+	}
+
+	s.Apply(f.Options)
+	if repo != "" {
+		s.Apply(f.Repos[repo])
+	}
+
+	env, err := EnvOptions(getenv)
+	if err != nil {
+		return Settings{}, err
+	}
+	s.Apply(env)
+
+	if s.Debounce < 0 {
+		return Settings{}, fmt.Errorf("invalid debounce %s: must not be negative", s.Debounce)
+	}
+	if s.RefreshTTL < 0 {
+		return Settings{}, fmt.Errorf("invalid refresh_ttl %s: must not be negative", s.RefreshTTL)
+	}
+
+	cacheDir, err := expandHome(s.CacheDir)
+	if err != nil {
+		return Settings{}, err
+	}
+	s.CacheDir = cacheDir
+
+	if s.AppID != 0 || s.AppInstallationID != 0 || s.AppPrivateKey != "" {
+		if s.AppID == 0 || s.AppInstallationID == 0 || s.AppPrivateKey == "" {
+			return Settings{}, fmt.Errorf("invalid GitHub App settings: app_id, app_installation_id and app_private_key must all be set")
+		}
+		key, err := expandHome(s.AppPrivateKey)
+		if err != nil {
+			return Settings{}, err
+		}
+		s.AppPrivateKey = key
+	}
+
+	return s, nil
+}
+
+// Apply overrides the settings that are set in o.
+func (s *Settings) Apply(o Options) {
+	if o.LogLevel != nil {
+		s.LogLevel = *o.LogLevel
+	}
+	if o.LogFile != nil {
+		s.LogFile = *o.LogFile
+	}
+	if o.Quiet != nil {
+		s.Quiet = *o.Quiet
+	}
+	if o.Debounce != nil {
+		s.Debounce = *o.Debounce
+	}
+	if o.RefreshTTL != nil {
+		s.RefreshTTL = *o.RefreshTTL
+	}
+	if o.CacheDir != nil {
+		s.CacheDir = *o.CacheDir
+	}
+	if o.Host != nil {
+		s.Host = *o.Host
+	}
+	if o.HidePulls != nil {
+		s.HidePulls = *o.HidePulls
+	}
+	if o.AppID != nil {
+		s.AppID = *o.AppID
+	}
+	if o.AppInstallationID != nil {
+		s.AppInstallationID = *o.AppInstallationID
+	}
+	if o.AppPrivateKey != nil {
+		s.AppPrivateKey = *o.AppPrivateKey
+	}
+}
+
+// EnvOptions reads the GHISSUES_* environment variables into an Options layer.
+func EnvOptions(getenv func(string) string) (Options, error) {
+	var o Options
+
+	if v := getenv("GHISSUES_LOG_LEVEL"); v != "" {
+		o.LogLevel = &v
+	}
+	if v := getenv("GHISSUES_LOG_FILE"); v != "" {
+		o.LogFile = &v
+	}
+	if v := getenv("GHISSUES_QUIET"); v != "" {
+		quiet, err := strconv.ParseBool(v)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid GHISSUES_QUIET %q: %w", v, err)
+		}
+		o.Quiet = &quiet
+	}
+	if v := getenv("GHISSUES_DEBOUNCE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid GHISSUES_DEBOUNCE %q: %w", v, err)
+		}
+		o.Debounce = &d
+	}
+	if v := getenv("GHISSUES_REFRESH_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid GHISSUES_REFRESH_TTL %q: %w", v, err)
+		}
+		o.RefreshTTL = &d
+	}
+	if v := getenv("GHISSUES_CACHE_DIR"); v != "" {
+		o.CacheDir = &v
+	}
+	if v := getenv("GHISSUES_HOST"); v != "" {
+		o.Host = &v
+	}
+	if v := getenv("GHISSUES_HIDE_PULLS"); v != "" {
+		hide, err := strconv.ParseBool(v)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid GHISSUES_HIDE_PULLS %q: %w", v, err)
+		}
+		o.HidePulls = &hide
+	}
+	if v := getenv("GHISSUES_APP_ID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid GHISSUES_APP_ID %q: %w", v, err)
+		}
+		o.AppID = &id
+	}
+	if v := getenv("GHISSUES_APP_INSTALLATION_ID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid GHISSUES_APP_INSTALLATION_ID %q: %w", v, err)
+		}
+		o.AppInstallationID = &id
+	}
+	if v := getenv("GHISSUES_APP_PRIVATE_KEY"); v != "" {
+		o.AppPrivateKey = &v
+	}
+
+	return o, nil
+}
+
+// expandHome replaces a leading ~ with the home directory.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~")), nil
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes content to a config file in a temp dir and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+// envMap returns a getenv function backed by a map.
+func envMap(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+const testConfig = `
+log_level: warn
+debounce: 1s
+cache_dir: /var/cache/ghissues
+host: ghe.example.com
+repos:
+  org/busy:
+    debounce: 5s
+    refresh_ttl: 2m
+    log_level: debug
+    hide_pulls: true
+`
+
+func TestResolve(t *testing.T) {
+	f, err := Load(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		repo string
+		env  map[string]string
+		want Settings
+	}{
+		{
+			name: "global settings",
+			repo: "",
+			want: Settings{LogLevel: "warn", Debounce: time.Second, RefreshTTL: DefaultRefreshTTL, CacheDir: "/var/cache/ghissues", Host: "ghe.example.com"},
+		},
+		{
+			name: "repo without overrides",
+			repo: "org/quiet",
+			want: Settings{LogLevel: "warn", Debounce: time.Second, RefreshTTL: DefaultRefreshTTL, CacheDir: "/var/cache/ghissues", Host: "ghe.example.com"},
+		},
+		{
+			name: "repo overrides",
+			repo: "org/busy",
+			want: Settings{LogLevel: "debug", Debounce: 5 * time.Second, RefreshTTL: 2 * time.Minute, CacheDir: "/var/cache/ghissues", Host: "ghe.example.com", HidePulls: true},
+		},
+		{
+			name: "env beats repo overrides",
+			repo: "org/busy",
+			env: map[string]string{
+				"GHISSUES_DEBOUNCE":   "100ms",
+				"GHISSUES_QUIET":      "true",
+				"GHISSUES_LOG_FILE":   "/tmp/ghissues.log",
+				"GHISSUES_CACHE_DIR":  "/srv/cache",
+				"GHISSUES_HOST":       "github.com",
+				"GHISSUES_HIDE_PULLS": "false",
+			},
+			want: Settings{LogLevel: "debug", LogFile: "/tmp/ghissues.log", Quiet: true, Debounce: 100 * time.Millisecond, RefreshTTL: 2 * time.Minute, CacheDir: "/srv/cache", Host: "github.com"},
+		},
+		{
+			name: "GitHub App from env",
+			env: map[string]string{
+				"GHISSUES_APP_ID":              "12",
+				"GHISSUES_APP_INSTALLATION_ID": "34",
+				"GHISSUES_APP_PRIVATE_KEY":     "/etc/ghissues/app.pem",
+			},
+			want: Settings{LogLevel: "warn", Debounce: time.Second, RefreshTTL: DefaultRefreshTTL, CacheDir: "/var/cache/ghissues", Host: "ghe.example.com", AppID: 12, AppInstallationID: 34, AppPrivateKey: "/etc/ghissues/app.pem"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.Resolve(tt.repo, envMap(tt.env))
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve_Defaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	f, err := Load(filepath.Join(tmpDir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load() of a missing file error = %v", err)
+	}
+
+	got, err := f.Resolve("owner/repo", envMap(nil))
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	want := Settings{
+		LogLevel:   DefaultLogLevel,
+		Debounce:   DefaultDebounce,
+		RefreshTTL: DefaultRefreshTTL,
+		CacheDir:   filepath.Join(tmpDir, ".cache", "ghissues"),
+		Host:       DefaultHost,
+	}
+	if got != want {
+		t.Errorf("Resolve() = %+v, want %+v", got, want)
+	}
+}
+
+func TestResolve_InvalidValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "bad quiet env", env: map[string]string{"GHISSUES_QUIET": "sometimes"}, wantErr: "GHISSUES_QUIET"},
+		{name: "bad debounce env", env: map[string]string{"GHISSUES_DEBOUNCE": "soon"}, wantErr: "GHISSUES_DEBOUNCE"},
+		{name: "bad refresh env", env: map[string]string{"GHISSUES_REFRESH_TTL": "1"}, wantErr: "GHISSUES_REFRESH_TTL"},
+		{name: "bad hide pulls env", env: map[string]string{"GHISSUES_HIDE_PULLS": "maybe"}, wantErr: "GHISSUES_HIDE_PULLS"},
+		{name: "negative debounce", config: "debounce: -1s\n", wantErr: "must not be negative"},
+		{name: "bad app id env", env: map[string]string{"GHISSUES_APP_ID": "my-app"}, wantErr: "GHISSUES_APP_ID"},
+		{name: "incomplete app", config: "app_id: 12\napp_private_key: /etc/app.pem\n", wantErr: "must all be set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Load(writeConfig(t, tt.config))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			_, err = f.Resolve("owner/repo", envMap(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "unknown key", config: "debunce: 1s\n", wantErr: "debunce"},
+		{name: "bad duration", config: "refresh_ttl: later\n", wantErr: "failed to parse"},
+		{name: "bad repo key", config: "repos:\n  just-a-name:\n    debounce: 1s\n", wantErr: "just-a-name"},
+		{name: "host per repo", config: "repos:\n  org/repo:\n    host: ghe.example.com\n", wantErr: "cannot be set per repository"},
+		{name: "app per repo", config: "repos:\n  org/repo:\n    app_id: 12\n", wantErr: "cannot be set per repository"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.config))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("HOME", "/home/alice")
+	t.Setenv("GHISSUES_CONFIG", "")
+
+	path, err := Path()
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	if path != "/home/alice/.config/ghissues/config.yaml" {
+		t.Errorf("Path() = %q", path)
+	}
+
+	t.Setenv("GHISSUES_CONFIG", "/etc/ghissues.yaml")
+	if path, _ := Path(); path != "/etc/ghissues.yaml" {
+		t.Errorf("Path() with GHISSUES_CONFIG = %q", path)
+	}
+}
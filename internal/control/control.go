@@ -0,0 +1,434 @@
+// Package control provides a local unix-socket API for inspecting and driving a running mount.
+package control
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/fs"
+	"github.com/JohanCodinha/ghissues/internal/logger"
+)
+
+// Operations understood by the control server.
+const (
+	OpStatus  = "status"
+	OpSync    = "sync"
+	OpRefresh = "refresh"
+	OpUnmount = "unmount"
+)
+
+// Engine is implemented by sync.Engine and exposes the operations
+// available over the control socket.
+type Engine interface {
+	GetStatus() fs.SyncStatus
+	SyncNow(ctx context.Context) error
+	RefreshIssue(ctx context.Context, number int) (bool, error)
+	InitialSync(ctx context.Context) error
+}
+
+// Request is a single command sent to the control server.
+type Request struct {
+	Op     string `json:"op"`
+	Repo   string `json:"repo,omitempty"`   // empty means every repo served by the mount
+	Number int    `json:"number,omitempty"` // refresh only: issue number, 0 for the whole repo
+}
+
+// RepoStatus is the JSON form of fs.SyncStatus for one repository.
+type RepoStatus struct {
+	LastSyncTime    time.Time `json:"last_sync_time"`
+	LastError       string    `json:"last_error,omitempty"`
+	PendingIssues   int       `json:"pending_issues"`
+	PendingComments int       `json:"pending_comments"`
+	DirtyIssues     int       `json:"dirty_issues"`
+	DirtyComments   int       `json:"dirty_comments"`
+}
+
+// SyncStatus converts the status back to the fs representation.
+func (s RepoStatus) SyncStatus() fs.SyncStatus {
+	return fs.SyncStatus{
+		LastSyncTime:    s.LastSyncTime,
+		LastError:       s.LastError,
+		PendingIssues:   s.PendingIssues,
+		PendingComments: s.PendingComments,
+		DirtyIssues:     s.DirtyIssues,
+		DirtyComments:   s.DirtyComments,
+	}
+}
+
+// newRepoStatus converts an fs.SyncStatus to its JSON form.
+func newRepoStatus(s fs.SyncStatus) RepoStatus {
+	return RepoStatus{
+		LastSyncTime:    s.LastSyncTime,
+		LastError:       s.LastError,
+		PendingIssues:   s.PendingIssues,
+		PendingComments: s.PendingComments,
+		DirtyIssues:     s.DirtyIssues,
+		DirtyComments:   s.DirtyComments,
+	}
+}
+
+// RepoResult is the outcome of a request for one repository.
+type RepoResult struct {
+	Repo   string     `json:"repo"`
+	Status RepoStatus `json:"status"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// Response is the server's reply to a Request.
+type Response struct {
+	Mountpoint string       `json:"mountpoint"`
+	Repos      []RepoResult `json:"repos,omitempty"`
+	Error      string       `json:"error,omitempty"` // request-level failure (bad op, unknown repo)
+}
+
+// Failed reports whether the request or any per-repo operation failed.
+func (r *Response) Failed() bool {
+	if r.Error != "" {
+		return true
+	}
+	for _, repo := range r.Repos {
+		if repo.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// SocketPath returns the control socket path for a mountpoint inside dir.
+// The name is derived from a hash of the absolute mountpoint so that it stays
+// well under the unix socket path limit regardless of how deep the mount is.
+func SocketPath(dir, mountpoint string) (string, error) {
+	abs, err := filepath.Abs(mountpoint)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(abs)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".sock"), nil
+}
+
+// Server serves control requests for the engines of a single mount.
+type Server struct {
+	path       string
+	mountpoint string
+
+	mu       sync.Mutex
+	engines  map[string]Engine // repo -> engine
+	unmount  func() error      // unmounts the filesystem, nil if unsupported
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// NewServer creates a control server that will listen on path.
+// mountpoint is reported back to clients so they can tell mounts apart.
+func NewServer(path, mountpoint string) *Server {
+	return &Server{
+		path:       path,
+		mountpoint: mountpoint,
+		engines:    make(map[string]Engine),
+	}
+}
+
+// Register makes an engine available under the given "owner/repo" name.
+func (s *Server) Register(repo string, engine Engine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.engines[repo] = engine
+}
+
+// Unregister stops serving requests for repo.
+func (s *Server) Unregister(repo string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.engines, repo)
+}
+
+// SetUnmountFunc sets how the server unmounts its filesystem for OpUnmount.
+// fn must return once nothing can write to the mount anymore.
+func (s *Server) SetUnmountFunc(fn func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unmount = fn
+}
+
+// Start begins listening on the socket and serving requests in the background.
+// A leftover socket from a crashed process is removed; a socket that still
+// accepts connections means another mount owns it and an error is returned.
+func (s *Server) Start() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if _, err := os.Stat(s.path); err == nil {
+		if conn, err := net.DialTimeout("unix", s.path, time.Second); err == nil {
+			conn.Close()
+			return fmt.Errorf("control socket %s is already in use", s.path)
+		}
+		if err := os.Remove(s.path); err != nil {
+			return fmt.Errorf("failed to remove stale control socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	if err := os.Chmod(s.path, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict control socket permissions: %w", err)
+	}
+
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.acceptLoop(listener)
+
+	logger.Debug("control: listening on %s", s.path)
+	return nil
+}
+
+// Close stops accepting requests, waits for in-flight ones and removes the socket.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	listener := s.listener
+	s.listener = nil
+	s.mu.Unlock()
+
+	if listener == nil {
+		return nil
+	}
+
+	err := listener.Close()
+	s.wg.Wait()
+	os.Remove(s.path)
+	return err
+}
+
+// acceptLoop accepts connections until the listener is closed.
+func (s *Server) acceptLoop(listener net.Listener) {
+	defer s.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+// handleConn reads one request from conn and writes one response.
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	var req Request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		logger.Debug("control: failed to decode request: %v", err)
+		return
+	}
+
+	resp := s.Handle(context.Background(), req)
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		logger.Debug("control: failed to write response: %v", err)
+	}
+}
+
+// Handle executes a request against the registered engines.
+func (s *Server) Handle(ctx context.Context, req Request) *Response {
+	resp := &Response{Mountpoint: s.mountpoint}
+
+	switch req.Op {
+	case OpStatus, OpSync, OpRefresh:
+	case OpUnmount:
+		// Unmount first so that the flush below is the last one and
+		// its result is what the mount leaves behind.
+		if err := s.unmountFS(req.Repo); err != nil {
+			resp.Error = err.Error()
+			return resp
+		}
+	default:
+		resp.Error = fmt.Sprintf("unknown operation %q", req.Op)
+		return resp
+	}
+
+	repos, err := s.selectRepos(req.Repo)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	for _, repo := range repos {
+		s.mu.Lock()
+		engine := s.engines[repo]
+		s.mu.Unlock()
+		if engine == nil {
+			// Unregistered while the request was being served
+			continue
+		}
+
+		result := RepoResult{Repo: repo}
+		switch req.Op {
+		case OpSync, OpUnmount:
+			logger.Info("control: flushing %s", repo)
+			if err := engine.SyncNow(ctx); err != nil {
+				result.Error = err.Error()
+			}
+		case OpRefresh:
+			if req.Number > 0 {
+				logger.Info("control: refreshing %s#%d", repo, req.Number)
+				if _, err := engine.RefreshIssue(ctx, req.Number); err != nil {
+					result.Error = err.Error()
+				}
+			} else {
+				logger.Info("control: refreshing %s", repo)
+				if err := engine.InitialSync(ctx); err != nil {
+					result.Error = err.Error()
+				}
+			}
+		}
+		result.Status = newRepoStatus(engine.GetStatus())
+		resp.Repos = append(resp.Repos, result)
+	}
+
+	return resp
+}
+
+// unmountFS unmounts the filesystem of the mount for OpUnmount.
+func (s *Server) unmountFS(repo string) error {
+	if repo != "" {
+		return fmt.Errorf("unmount applies to the whole mount, not to %s", repo)
+	}
+
+	s.mu.Lock()
+	unmount := s.unmount
+	s.mu.Unlock()
+	if unmount == nil {
+		return fmt.Errorf("this mount cannot be unmounted over its control socket")
+	}
+
+	logger.Info("control: unmounting %s", s.mountpoint)
+	if err := unmount(); err != nil {
+		return fmt.Errorf("failed to unmount: %w", err)
+	}
+	return nil
+}
+
+// selectRepos returns the repos a request applies to, sorted by name.
+func (s *Server) selectRepos(repo string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if repo != "" {
+		if _, ok := s.engines[repo]; !ok {
+			return nil, fmt.Errorf("repository %s is not served by this mount", repo)
+		}
+		return []string{repo}, nil
+	}
+
+	repos := make([]string, 0, len(s.engines))
+	for name := range s.engines {
+		repos = append(repos, name)
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
+
+// statusTimeout bounds status requests when the client has no Timeout set,
+// so that a wedged mount cannot hang commands that only want to look at it.
+var statusTimeout = 5 * time.Second
+
+// Client talks to a control server over its unix socket.
+type Client struct {
+	path string
+	// Timeout bounds a whole request, including a flush. Zero means no limit
+	// for sync and refresh, and statusTimeout for status requests.
+	Timeout time.Duration
+}
+
+// NewClient creates a client for the socket at path.
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+// Status returns the sync status of repo, or of every repo when repo is empty.
+func (c *Client) Status(repo string) (*Response, error) {
+	return c.Do(Request{Op: OpStatus, Repo: repo})
+}
+
+// Sync flushes pending changes and blocks until the flush has finished.
+func (c *Client) Sync(repo string) (*Response, error) {
+	return c.Do(Request{Op: OpSync, Repo: repo})
+}
+
+// Refresh re-fetches one issue, or the whole repo when number is 0.
+func (c *Client) Refresh(repo string, number int) (*Response, error) {
+	return c.Do(Request{Op: OpRefresh, Repo: repo, Number: number})
+}
+
+// Unmount unmounts the filesystem, then flushes every repo one last time and
+// blocks until the flush has finished.
+func (c *Client) Unmount() (*Response, error) {
+	return c.Do(Request{Op: OpUnmount})
+}
+
+// Do sends a request and waits for the response.
+func (c *Client) Do(req Request) (*Response, error) {
+	conn, err := net.DialTimeout("unix", c.path, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to control socket %s: %w", c.path, err)
+	}
+	defer conn.Close()
+
+	timeout := c.Timeout
+	if timeout == 0 && req.Op == OpStatus {
+		timeout = statusTimeout
+	}
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// ListSockets returns every control socket in dir that is accepting connections.
+func ListSockets(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.sock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list control sockets: %w", err)
+	}
+
+	var live []string
+	for _, path := range matches {
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err != nil {
+			continue
+		}
+		conn.Close()
+		live = append(live, path)
+	}
+	return live, nil
+}
@@ -0,0 +1,367 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/fs"
+)
+
+// fakeEngine records calls made through the control server.
+type fakeEngine struct {
+	mu          sync.Mutex
+	status      fs.SyncStatus
+	syncErr     error
+	syncCalls   int
+	refreshed   []int
+	fullRefresh int
+}
+
+func (f *fakeEngine) GetStatus() fs.SyncStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.status
+}
+
+func (f *fakeEngine) SyncNow(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.syncCalls++
+	if f.syncErr == nil {
+		f.status.DirtyIssues = 0
+		f.status.LastSyncTime = time.Now()
+	}
+	return f.syncErr
+}
+
+func (f *fakeEngine) RefreshIssue(_ context.Context, number int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refreshed = append(f.refreshed, number)
+	return true, nil
+}
+
+func (f *fakeEngine) InitialSync(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fullRefresh++
+	return nil
+}
+
+// startTestServer starts a server in a temp dir and returns it with a client.
+func startTestServer(t *testing.T, engines map[string]Engine) (*Server, *Client) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ctl.sock")
+	server := NewServer(path, "/mnt/issues")
+	for repo, engine := range engines {
+		server.Register(repo, engine)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	return server, NewClient(path)
+}
+
+func TestSocketPath_StableAndDistinct(t *testing.T) {
+	dir := "/tmp/run"
+
+	a1, err := SocketPath(dir, "/home/user/issues")
+	if err != nil {
+		t.Fatalf("SocketPath() error = %v", err)
+	}
+	a2, _ := SocketPath(dir, "/home/user/issues/")
+	b, _ := SocketPath(dir, "/home/user/other")
+
+	if a1 != a2 {
+		t.Errorf("SocketPath() should ignore trailing slash: %q != %q", a1, a2)
+	}
+	if a1 == b {
+		t.Errorf("SocketPath() should differ for different mountpoints, both %q", a1)
+	}
+	if filepath.Dir(a1) != dir || !strings.HasSuffix(a1, ".sock") {
+		t.Errorf("SocketPath() = %q, want a .sock file in %s", a1, dir)
+	}
+}
+
+func TestServer_Status(t *testing.T) {
+	engine := &fakeEngine{status: fs.SyncStatus{DirtyIssues: 2, PendingComments: 1}}
+	_, client := startTestServer(t, map[string]Engine{"owner/repo": engine})
+
+	resp, err := client.Status("")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if resp.Mountpoint != "/mnt/issues" {
+		t.Errorf("Mountpoint = %q, want /mnt/issues", resp.Mountpoint)
+	}
+	if len(resp.Repos) != 1 {
+		t.Fatalf("expected 1 repo, got %d", len(resp.Repos))
+	}
+	got := resp.Repos[0]
+	if got.Repo != "owner/repo" || got.Status.DirtyIssues != 2 || got.Status.PendingComments != 1 {
+		t.Errorf("unexpected status result: %+v", got)
+	}
+	if engine.syncCalls != 0 {
+		t.Errorf("status should not trigger a sync, got %d calls", engine.syncCalls)
+	}
+}
+
+func TestServer_SyncWaitsForFlush(t *testing.T) {
+	engine := &fakeEngine{status: fs.SyncStatus{DirtyIssues: 3}}
+	_, client := startTestServer(t, map[string]Engine{"owner/repo": engine})
+
+	resp, err := client.Sync("owner/repo")
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if resp.Failed() {
+		t.Fatalf("Sync() response failed: %+v", resp)
+	}
+	if engine.syncCalls != 1 {
+		t.Errorf("expected 1 SyncNow call, got %d", engine.syncCalls)
+	}
+	if resp.Repos[0].Status.DirtyIssues != 0 {
+		t.Errorf("status after sync should reflect the flush, got %d dirty issues", resp.Repos[0].Status.DirtyIssues)
+	}
+}
+
+func TestServer_SyncReportsError(t *testing.T) {
+	engine := &fakeEngine{syncErr: errors.New("422 Unprocessable Entity")}
+	_, client := startTestServer(t, map[string]Engine{"owner/repo": engine})
+
+	resp, err := client.Sync("")
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if !resp.Failed() {
+		t.Fatal("expected response to report failure")
+	}
+	if !strings.Contains(resp.Repos[0].Error, "422") {
+		t.Errorf("error = %q, want it to contain the engine error", resp.Repos[0].Error)
+	}
+}
+
+func TestServer_Refresh(t *testing.T) {
+	engine := &fakeEngine{}
+	_, client := startTestServer(t, map[string]Engine{"owner/repo": engine})
+
+	if _, err := client.Refresh("owner/repo", 42); err != nil {
+		t.Fatalf("Refresh(42) error = %v", err)
+	}
+	if _, err := client.Refresh("owner/repo", 0); err != nil {
+		t.Fatalf("Refresh(0) error = %v", err)
+	}
+
+	if len(engine.refreshed) != 1 || engine.refreshed[0] != 42 {
+		t.Errorf("refreshed = %v, want [42]", engine.refreshed)
+	}
+	if engine.fullRefresh != 1 {
+		t.Errorf("expected 1 full refresh, got %d", engine.fullRefresh)
+	}
+}
+
+func TestServer_UnknownRepoAndOp(t *testing.T) {
+	_, client := startTestServer(t, map[string]Engine{"owner/repo": &fakeEngine{}})
+
+	resp, err := client.Status("other/repo")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !strings.Contains(resp.Error, "other/repo") {
+		t.Errorf("Error = %q, want it to mention the unknown repo", resp.Error)
+	}
+
+	resp, err = client.Do(Request{Op: "explode"})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if !strings.Contains(resp.Error, "unknown operation") {
+		t.Errorf("Error = %q, want unknown operation", resp.Error)
+	}
+}
+
+func TestServer_Unregister(t *testing.T) {
+	server, client := startTestServer(t, map[string]Engine{
+		"org/a": &fakeEngine{},
+		"org/b": &fakeEngine{},
+	})
+
+	server.Unregister("org/a")
+
+	resp, err := client.Status("")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(resp.Repos) != 1 || resp.Repos[0].Repo != "org/b" {
+		t.Errorf("Repos = %+v, want only org/b", resp.Repos)
+	}
+
+	resp, err = client.Status("org/a")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if resp.Error == "" {
+		t.Error("expected an error for an unregistered repo")
+	}
+}
+
+func TestServer_StartRemovesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ctl.sock")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("failed to create stale socket: %v", err)
+	}
+
+	server := NewServer(path, "/mnt")
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() should replace a stale socket, got %v", err)
+	}
+	defer server.Close()
+}
+
+func TestServer_StartRejectsLiveSocket(t *testing.T) {
+	server, _ := startTestServer(t, nil)
+
+	second := NewServer(server.path, "/mnt")
+	if err := second.Start(); err == nil {
+		second.Close()
+		t.Fatal("Start() should fail when another server owns the socket")
+	}
+}
+
+func TestServer_CloseRemovesSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ctl.sock")
+	server := NewServer(path, "/mnt")
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if err := server.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("socket file should be removed after Close()")
+	}
+}
+
+func TestListSockets_SkipsDeadSockets(t *testing.T) {
+	dir := t.TempDir()
+
+	live := NewServer(filepath.Join(dir, "live.sock"), "/mnt/a")
+	if err := live.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer live.Close()
+
+	if err := os.WriteFile(filepath.Join(dir, "dead.sock"), nil, 0600); err != nil {
+		t.Fatalf("failed to create dead socket: %v", err)
+	}
+
+	sockets, err := ListSockets(dir)
+	if err != nil {
+		t.Fatalf("ListSockets() error = %v", err)
+	}
+	if len(sockets) != 1 || filepath.Base(sockets[0]) != "live.sock" {
+		t.Errorf("ListSockets() = %v, want only live.sock", sockets)
+	}
+}
+
+func TestClient_StatusTimesOutOnWedgedServer(t *testing.T) {
+	original := statusTimeout
+	statusTimeout = 100 * time.Millisecond
+	defer func() { statusTimeout = original }()
+
+	// Accepts connections but never answers
+	path := filepath.Join(t.TempDir(), "wedged.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewClient(path).Status("")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Status() should fail when the server never answers")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Status() hung on a wedged server")
+	}
+}
+
+func TestServer_UnmountThenFlushes(t *testing.T) {
+	engine := &fakeEngine{syncErr: errors.New("422 Unprocessable Entity")}
+	server, client := startTestServer(t, map[string]Engine{"owner/repo": engine})
+
+	// Not supported until the mount sets how to unmount
+	resp, err := client.Unmount()
+	if err != nil {
+		t.Fatalf("Unmount() error = %v", err)
+	}
+	if resp.Error == "" || engine.syncCalls != 0 {
+		t.Fatalf("expected an error and no flush without an unmount func, got %+v", resp)
+	}
+
+	unmounted := false
+	server.SetUnmountFunc(func() error {
+		if engine.syncCalls != 0 {
+			t.Error("the flush must happen after the unmount")
+		}
+		unmounted = true
+		return nil
+	})
+
+	resp, err = client.Unmount()
+	if err != nil {
+		t.Fatalf("Unmount() error = %v", err)
+	}
+	if !unmounted || engine.syncCalls != 1 {
+		t.Errorf("unmounted = %v, syncCalls = %d", unmounted, engine.syncCalls)
+	}
+	if !resp.Failed() || !strings.Contains(resp.Repos[0].Error, "422") {
+		t.Errorf("expected the flush error in the response, got %+v", resp)
+	}
+}
+
+func TestServer_UnmountFailureSkipsFlush(t *testing.T) {
+	engine := &fakeEngine{}
+	server, client := startTestServer(t, map[string]Engine{"owner/repo": engine})
+	server.SetUnmountFunc(func() error { return errors.New("device or resource busy") })
+
+	resp, err := client.Unmount()
+	if err != nil {
+		t.Fatalf("Unmount() error = %v", err)
+	}
+	if !strings.Contains(resp.Error, "busy") || engine.syncCalls != 0 {
+		t.Errorf("expected a busy error and no flush, got %+v (syncCalls = %d)", resp, engine.syncCalls)
+	}
+
+	resp, _ = client.Do(Request{Op: OpUnmount, Repo: "owner/repo"})
+	if resp.Error == "" {
+		t.Error("expected an error unmounting a single repo")
+	}
+}
@@ -0,0 +1,236 @@
+// Package export writes cached issues and their comments to files for use
+// outside of ghissues. It only reads the cache and never talks to GitHub.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/fs"
+	"github.com/JohanCodinha/ghissues/internal/md"
+)
+
+// Supported export formats.
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+	FormatMDDir = "md-dir"
+)
+
+// Filter selects the issues to export. Zero fields match everything.
+type Filter struct {
+	State  string    // "open" or "closed"
+	Labels []string  // issues must carry every label
+	Since  time.Time // issues updated at or after this time
+}
+
+// Match reports whether issue passes the filter.
+func (f Filter) Match(issue cache.Issue) bool {
+	if f.State != "" && !strings.EqualFold(issue.State, f.State) {
+		return false
+	}
+
+	for _, want := range f.Labels {
+		found := false
+		for _, label := range issue.Labels {
+			if strings.EqualFold(label, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if !f.Since.IsZero() {
+		updated, err := time.Parse(time.RFC3339, issue.UpdatedAt)
+		if err != nil || updated.Before(f.Since) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Comment is an exported issue comment.
+type Comment struct {
+	ID        int64  `json:"id"`
+	Author    string `json:"author"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Record is an exported issue with its comments.
+type Record struct {
+	Repo               string    `json:"repo"`
+	Number             int       `json:"number"`
+	Title              string    `json:"title"`
+	State              string    `json:"state"`
+	Author             string    `json:"author"`
+	Labels             []string  `json:"labels"`
+	CreatedAt          string    `json:"created_at"`
+	UpdatedAt          string    `json:"updated_at"`
+	ParentIssue        int       `json:"parent_issue,omitempty"`
+	SubIssuesTotal     int       `json:"sub_issues_total"`
+	SubIssuesCompleted int       `json:"sub_issues_completed"`
+	Dirty              bool      `json:"dirty"` // has local edits not yet pushed to GitHub
+	Body               string    `json:"body"`
+	Comments           []Comment `json:"comments"`
+
+	issue    cache.Issue
+	comments []cache.Comment
+}
+
+// Load reads the issues of repo that match filter, with their comments.
+func Load(db *cache.DB, repo string, filter Filter) ([]Record, error) {
+	issues, err := db.ListIssues(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cached issues: %w", err)
+	}
+
+	records := []Record{}
+	for _, issue := range issues {
+		if !filter.Match(issue) {
+			continue
+		}
+
+		comments, err := db.GetComments(repo, issue.Number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get comments for issue #%d: %w", issue.Number, err)
+		}
+
+		records = append(records, newRecord(issue, comments))
+	}
+
+	return records, nil
+}
+
+// newRecord converts a cached issue and its comments to a Record.
+func newRecord(issue cache.Issue, comments []cache.Comment) Record {
+	labels := issue.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	r := Record{
+		Repo:               issue.Repo,
+		Number:             issue.Number,
+		Title:              issue.Title,
+		State:              issue.State,
+		Author:             issue.Author,
+		Labels:             labels,
+		CreatedAt:          issue.CreatedAt,
+		UpdatedAt:          issue.UpdatedAt,
+		ParentIssue:        issue.ParentIssueNumber,
+		SubIssuesTotal:     issue.SubIssuesTotal,
+		SubIssuesCompleted: issue.SubIssuesCompleted,
+		Dirty:              issue.Dirty,
+		Body:               issue.Body,
+		Comments:           make([]Comment, len(comments)),
+		issue:              issue,
+		comments:           comments,
+	}
+	for i, c := range comments {
+		r.Comments[i] = Comment{
+			ID:        c.ID,
+			Author:    c.Author,
+			Body:      c.Body,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+		}
+	}
+	return r
+}
+
+// WriteJSONL writes one JSON object per issue.
+func WriteJSONL(w io.Writer, records []Record) error {
+	encoder := json.NewEncoder(w)
+	for _, r := range records {
+		if err := encoder.Encode(r); err != nil {
+			return fmt.Errorf("failed to write issue #%d: %w", r.Number, err)
+		}
+	}
+	return nil
+}
+
+// csvHeader lists the columns written by WriteCSV.
+var csvHeader = []string{
+	"repo", "number", "title", "state", "author", "labels",
+	"created_at", "updated_at", "parent_issue", "sub_issues_total", "sub_issues_completed",
+	"dirty", "body", "comment_count", "comments",
+}
+
+// WriteCSV writes one row per issue. Labels are separated by semicolons and
+// comments are embedded as a JSON array so that no data is lost.
+func WriteCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, r := range records {
+		comments, err := json.Marshal(r.Comments)
+		if err != nil {
+			return fmt.Errorf("failed to encode comments of issue #%d: %w", r.Number, err)
+		}
+
+		parent := ""
+		if r.ParentIssue > 0 {
+			parent = strconv.Itoa(r.ParentIssue)
+		}
+
+		row := []string{
+			r.Repo,
+			strconv.Itoa(r.Number),
+			r.Title,
+			r.State,
+			r.Author,
+			strings.Join(r.Labels, ";"),
+			r.CreatedAt,
+			r.UpdatedAt,
+			parent,
+			strconv.Itoa(r.SubIssuesTotal),
+			strconv.Itoa(r.SubIssuesCompleted),
+			strconv.FormatBool(r.Dirty),
+			r.Body,
+			strconv.Itoa(len(r.Comments)),
+			string(comments),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write issue #%d: %w", r.Number, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdownDir writes each issue to dir as a markdown file, named and
+// formatted exactly as it appears in a mount. dir is created if needed.
+func WriteMarkdownDir(dir string, records []Record) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	for _, r := range records {
+		path := filepath.Join(dir, fs.IssueFilename(r.Title, r.Number))
+		content := md.ToMarkdown(&r.issue, r.comments)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write issue #%d: %w", r.Number, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+)
+
+// setupTestCache creates a cache with a few issues and comments.
+func setupTestCache(t *testing.T) *cache.DB {
+	t.Helper()
+
+	db, err := cache.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	issues := []cache.Issue{
+		{Number: 1, Repo: "owner/repo", Title: "Crash on startup", Body: "It crashes", State: "open", Author: "alice",
+			Labels: []string{"bug", "p1"}, CreatedAt: "2026-01-01T10:00:00Z", UpdatedAt: "2026-01-10T10:00:00Z", SubIssuesTotal: 2, SubIssuesCompleted: 1},
+		{Number: 2, Repo: "owner/repo", Title: "Add dark mode", Body: "Please", State: "closed", Author: "bob",
+			Labels: []string{"enhancement"}, CreatedAt: "2026-01-02T10:00:00Z", UpdatedAt: "2026-01-03T10:00:00Z"},
+		{Number: 3, Repo: "owner/repo", Title: "Crash on exit", Body: "Also crashes", State: "open", Author: "carol",
+			Labels: []string{"bug"}, CreatedAt: "2026-01-05T10:00:00Z", UpdatedAt: "2026-01-06T10:00:00Z", ParentIssueNumber: 1},
+	}
+	for _, issue := range issues {
+		if err := db.UpsertIssue(issue); err != nil {
+			t.Fatalf("UpsertIssue() error = %v", err)
+		}
+	}
+	if err := db.UpsertComments("owner/repo", 1, []cache.Comment{
+		{ID: 100, IssueNumber: 1, Repo: "owner/repo", Author: "bob", Body: "Same here, with \"quotes\", commas\nand newlines", CreatedAt: "2026-01-02T10:00:00Z", UpdatedAt: "2026-01-02T10:00:00Z"},
+	}); err != nil {
+		t.Fatalf("UpsertComments() error = %v", err)
+	}
+
+	return db
+}
+
+func TestFilter_Match(t *testing.T) {
+	issue := cache.Issue{State: "open", Labels: []string{"bug", "P1"}, UpdatedAt: "2026-01-10T10:00:00Z"}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty filter", Filter{}, true},
+		{"matching state", Filter{State: "open"}, true},
+		{"other state", Filter{State: "closed"}, false},
+		{"all labels present", Filter{Labels: []string{"bug", "p1"}}, true},
+		{"missing label", Filter{Labels: []string{"bug", "docs"}}, false},
+		{"updated after since", Filter{Since: time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)}, true},
+		{"updated before since", Filter{Since: time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(issue); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	db := setupTestCache(t)
+
+	records, err := Load(db, "owner/repo", Filter{State: "open", Labels: []string{"bug"}})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(records) != 2 || records[0].Number != 1 || records[1].Number != 3 {
+		t.Fatalf("Load() returned %+v, want issues #1 and #3", records)
+	}
+	if len(records[0].Comments) != 1 || records[0].Comments[0].Author != "bob" {
+		t.Errorf("issue #1 comments = %+v", records[0].Comments)
+	}
+	if records[0].SubIssuesTotal != 2 || records[1].ParentIssue != 1 {
+		t.Errorf("sub-issue data lost: %+v / %+v", records[0], records[1])
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	db := setupTestCache(t)
+	records, err := Load(db, "owner/repo", Filter{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, records); err != nil {
+		t.Fatalf("WriteJSONL() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+
+	var first Record
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("line is not valid JSON: %v", err)
+	}
+	if first.Number != 1 || first.Title != "Crash on startup" || len(first.Labels) != 2 || len(first.Comments) != 1 {
+		t.Errorf("unexpected first record: %+v", first)
+	}
+
+	var second map[string]any
+	json.Unmarshal([]byte(lines[1]), &second)
+	if comments, ok := second["comments"].([]any); !ok || len(comments) != 0 {
+		t.Errorf("issues without comments should have an empty comments array, got %v", second["comments"])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	db := setupTestCache(t)
+	records, err := Load(db, "owner/repo", Filter{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, records); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected header and 3 rows, got %d", len(rows))
+	}
+
+	col := make(map[string]int)
+	for i, name := range rows[0] {
+		col[name] = i
+	}
+	first := rows[1]
+	if first[col["labels"]] != "bug;p1" || first[col["comment_count"]] != "1" || first[col["sub_issues_total"]] != "2" {
+		t.Errorf("unexpected first row: %v", first)
+	}
+
+	var comments []Comment
+	if err := json.Unmarshal([]byte(first[col["comments"]]), &comments); err != nil {
+		t.Fatalf("comments column is not JSON: %v", err)
+	}
+	if len(comments) != 1 || !strings.Contains(comments[0].Body, "commas\nand newlines") {
+		t.Errorf("comment did not round-trip: %+v", comments)
+	}
+	if rows[3][col["parent_issue"]] != "1" || rows[1][col["parent_issue"]] != "" {
+		t.Errorf("unexpected parent_issue values: %q, %q", rows[3][col["parent_issue"]], rows[1][col["parent_issue"]])
+	}
+}
+
+func TestWriteMarkdownDir(t *testing.T) {
+	db := setupTestCache(t)
+	records, err := Load(db, "owner/repo", Filter{State: "open"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := WriteMarkdownDir(dir, records); err != nil {
+		t.Fatalf("WriteMarkdownDir() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read export dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(entries))
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "crash-on-startup[1].md"))
+	if err != nil {
+		t.Fatalf("missing issue file: %v", err)
+	}
+	for _, want := range []string{"# Crash on startup", "It crashes", "Same here"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("markdown missing %q:\n%s", want, content)
+		}
+	}
+}
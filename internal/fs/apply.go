@@ -0,0 +1,120 @@
+package fs
+
+import (
+	"fmt"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/logger"
+	"github.com/JohanCodinha/ghissues/internal/md"
+)
+
+// NewIssueTemplate returns the initial content of a new issue file.
+func NewIssueTemplate(repo, title string) string {
+	return fmt.Sprintf(`---
+repo: %s
+state: open
+labels: []
+---
+
+# %s
+
+## Body
+
+`, repo, title)
+}
+
+// ApplyIssueEdit queues the differences between the cached issue number and
+// its edited markdown content: field changes mark the issue dirty, new
+// comments become pending and edited comments are marked dirty. It reports
+// whether anything was queued for the sync engine.
+func ApplyIssueEdit(db *cache.DB, repo string, number int, content string) (bool, error) {
+	parsed, err := md.FromMarkdown(content)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse markdown for issue #%d: %w", number, err)
+	}
+
+	original, err := db.GetIssue(repo, number)
+	if err != nil {
+		return false, fmt.Errorf("failed to get original issue #%d: %w", number, err)
+	}
+	if original == nil {
+		return false, fmt.Errorf("issue #%d not found in cache", number)
+	}
+
+	changes := md.DetectChanges(original, parsed)
+	queued := false
+
+	// Check if any issue fields changed (title, body, state, labels, parent)
+	if changes.TitleChanged || changes.BodyChanged || changes.StateChanged || changes.LabelsChanged || changes.ParentIssueChanged {
+		update := cache.IssueUpdate{}
+		if changes.TitleChanged {
+			update.Title = &changes.NewTitle
+		}
+		if changes.BodyChanged {
+			update.Body = &changes.NewBody
+		}
+		if changes.StateChanged {
+			update.State = &changes.NewState
+		}
+		if changes.LabelsChanged {
+			update.Labels = &changes.NewLabels
+		}
+		if changes.ParentIssueChanged {
+			update.ParentIssueNumber = &changes.NewParentIssue
+		}
+		if err := db.MarkDirty(repo, number, update); err != nil {
+			return false, fmt.Errorf("failed to mark issue #%d as dirty: %w", number, err)
+		}
+		queued = true
+	}
+
+	// Handle comment changes
+	originalComments, err := db.GetComments(repo, number)
+	if err != nil {
+		originalComments = []cache.Comment{}
+	}
+
+	newComments, editedComments := md.DetectCommentChanges(originalComments, parsed.Comments)
+
+	// Add new comments to pending
+	for _, nc := range newComments {
+		if err := db.AddPendingComment(repo, number, nc.Body); err != nil {
+			logger.Warn("failed to add pending comment for issue #%d: %v", number, err)
+		} else {
+			queued = true
+		}
+	}
+
+	// Mark edited comments as dirty
+	for _, ec := range editedComments {
+		if err := db.MarkCommentDirty(repo, ec.ID, ec.NewBody); err != nil {
+			logger.Warn("failed to mark comment %d as dirty: %v", ec.ID, err)
+		} else {
+			queued = true
+		}
+	}
+
+	return queued, nil
+}
+
+// ApplyNewIssue queues the markdown content of a new issue for creation and
+// returns its pending id. title is used when the content has no title.
+func ApplyNewIssue(db *cache.DB, repo, title, content string) (int64, error) {
+	parsed, err := md.FromMarkdown(content)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse new issue markdown: %w", err)
+	}
+
+	if parsed.Title != "" {
+		title = parsed.Title
+	}
+	if title == "" {
+		return 0, fmt.Errorf("new issue has no title")
+	}
+
+	id, err := db.AddPendingIssue(repo, title, parsed.Body, parsed.Labels)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add pending issue: %w", err)
+	}
+	return id, nil
+}
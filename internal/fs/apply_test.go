@@ -0,0 +1,93 @@
+package fs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/md"
+)
+
+func TestApplyIssueEdit(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+
+	populateTestIssues(t, db, "owner/repo", []cache.Issue{{Number: 1, Title: "Old", Body: "Body", State: "open"}})
+	db.UpsertComments("owner/repo", 1, []cache.Comment{{ID: 10, IssueNumber: 1, Body: "First", Author: "alice"}})
+
+	issue, _ := db.GetIssue("owner/repo", 1)
+	comments, _ := db.GetComments("owner/repo", 1)
+	content := md.ToMarkdown(issue, comments)
+
+	// Unchanged content queues nothing
+	queued, err := ApplyIssueEdit(db, "owner/repo", 1, content)
+	if err != nil || queued {
+		t.Fatalf("ApplyIssueEdit(unchanged) = %v, %v; expected nothing queued", queued, err)
+	}
+
+	edited := strings.Replace(content, "# Old", "# New", 1)
+	edited = strings.Replace(edited, "First", "First, edited", 1)
+	edited += "\n### new\n\nA new comment\n"
+
+	queued, err = ApplyIssueEdit(db, "owner/repo", 1, edited)
+	if err != nil || !queued {
+		t.Fatalf("ApplyIssueEdit(edited) = %v, %v; expected changes queued", queued, err)
+	}
+
+	if dirty, _ := db.GetDirtyIssues("owner/repo"); len(dirty) != 1 || dirty[0].Title != "New" {
+		t.Errorf("expected issue #1 dirty with title New, got %+v", dirty)
+	}
+	if dirty, _ := db.GetDirtyComments("owner/repo"); len(dirty) != 1 || dirty[0].Body != "First, edited" {
+		t.Errorf("expected comment 10 dirty, got %+v", dirty)
+	}
+	if pending, _ := db.GetPendingComments("owner/repo"); len(pending) != 1 || pending[0].Body != "A new comment" {
+		t.Errorf("expected one pending comment, got %+v", pending)
+	}
+
+	if _, err := ApplyIssueEdit(db, "owner/repo", 2, content); err == nil || !strings.Contains(err.Error(), "not found in cache") {
+		t.Errorf("ApplyIssueEdit(missing issue) error = %v", err)
+	}
+}
+
+func TestApplyNewIssue(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+
+	tests := []struct {
+		name      string
+		title     string
+		content   string
+		wantTitle string
+		wantErr   string
+	}{
+		{"title from content", "Fallback", NewIssueTemplate("owner/repo", "From content") + "Details\n", "From content", ""},
+		{"fallback title", "Fallback", "---\nrepo: owner/repo\nstate: open\n---\n\n## Body\n\nDetails\n", "Fallback", ""},
+		{"no title", "", "---\nrepo: owner/repo\nstate: open\n---\n\n## Body\n\nDetails\n", "", "no title"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ApplyNewIssue(db, "owner/repo", tt.title, tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ApplyNewIssue() error = %v, expected %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyNewIssue() error = %v", err)
+			}
+
+			pending, _ := db.GetPendingIssues("owner/repo")
+			for _, p := range pending {
+				if p.ID == id {
+					if p.Title != tt.wantTitle || p.Body != "Details" {
+						t.Errorf("pending issue = %+v, expected title %q and body Details", p, tt.wantTitle)
+					}
+					return
+				}
+			}
+			t.Errorf("pending issue %d not found", id)
+		})
+	}
+}
@@ -26,6 +26,11 @@ const (
 	maxFileSize = 10 * 1024 * 1024
 	// statusFileIno is the reserved inode number for the .status file.
 	statusFileIno = 0xFFFFFFFF
+	// pullsDirIno is the reserved inode number for the pulls directory.
+	pullsDirIno = 0xFFFFFFFE
+	// repoInoShift spaces out the inode ranges of repositories in a multi-repo
+	// mount so that issue #1 of one repo never shares an inode with #1 of another.
+	repoInoShift = 32
 )
 
 // SyncStatus contains information about the current sync state.
@@ -36,6 +41,9 @@ type SyncStatus struct {
 	PendingComments int
 	DirtyIssues     int
 	DirtyComments   int
+	ReadOnly        bool   // changes are never pushed
+	Offline         bool   // GitHub is not contacted, changes stay queued
+	Filter          string // which issues are synced, empty for all
 }
 
 // StatusProvider is implemented by sync.Engine to provide status information.
@@ -116,6 +124,11 @@ func makeFilename(title string, number int) string {
 	return fmt.Sprintf("%s[%d].md", sanitized, number)
 }
 
+// IssueFilename returns the name under which an issue appears in a mount.
+func IssueFilename(title string, number int) string {
+	return makeFilename(title, number)
+}
+
 // parseFilename extracts the issue number from a filename.
 // Returns the issue number and true if successful, or 0 and false if the filename doesn't match.
 func parseFilename(name string) (int, bool) {
@@ -167,6 +180,23 @@ type FS struct {
 	onDirty         func() // called when an issue is marked dirty
 	statusProvider  StatusProvider
 	refreshProvider RefreshProvider
+	multi           *multiRootNode // set for multi-repo mounts, which ignore the single-repo fields above
+	onMounted       func()         // called once the filesystem serves requests
+	readOnly        bool
+	hidePulls       bool
+	journal         *Journal
+}
+
+// Repo describes one repository served by a multi-repo mount.
+type Repo struct {
+	Name            string // "owner/repo"
+	Cache           *cache.DB
+	OnDirty         func()
+	StatusProvider  StatusProvider
+	RefreshProvider RefreshProvider
+	ReadOnly        bool     // reject every change to the repository's files
+	HidePulls       bool     // leave out the pulls directory
+	Journal         *Journal // logs writes not flushed yet, nil for none
 }
 
 // NewFS creates a new FUSE filesystem instance.
@@ -185,16 +215,51 @@ func NewFS(cacheDB *cache.DB, repo, mountpoint string, onDirty func(), statusPro
 	}
 }
 
+// NewMultiFS creates a FUSE filesystem serving several repositories.
+// Each repository appears as an owner/repo subdirectory of the mountpoint,
+// and the top-level .status aggregates the status of all of them.
+func NewMultiFS(repos []Repo, mountpoint string) *FS {
+	return &FS{
+		mountpoint: mountpoint,
+		multi:      newMultiRootNode(repos),
+	}
+}
+
+// AddRepo starts serving another repository on a multi-repo mount.
+// It may be called before or while the filesystem is mounted.
+func (f *FS) AddRepo(repo Repo) error {
+	if f.multi == nil {
+		return fmt.Errorf("failed to add %s: not a multi-repo mount", repo.Name)
+	}
+	return f.multi.addRepo(repo)
+}
+
+// RemoveRepo stops serving a repository of a multi-repo mount.
+func (f *FS) RemoveRepo(name string) error {
+	if f.multi == nil {
+		return fmt.Errorf("failed to remove %s: not a multi-repo mount", name)
+	}
+	return f.multi.removeRepo(name)
+}
+
 // Mount starts the FUSE server and blocks until unmounted.
 // It sets up signal handlers for graceful shutdown on SIGINT/SIGTERM.
 func (f *FS) Mount() error {
 	// Create the root node
-	root := &rootNode{
-		cache:           f.cache,
-		repo:            f.repo,
-		onDirty:         f.onDirty,
-		statusProvider:  f.statusProvider,
-		refreshProvider: f.refreshProvider,
+	var root fs.InodeEmbedder
+	if f.multi != nil {
+		root = f.multi
+	} else {
+		root = &rootNode{
+			cache:           f.cache,
+			repo:            f.repo,
+			onDirty:         f.onDirty,
+			statusProvider:  f.statusProvider,
+			refreshProvider: f.refreshProvider,
+			readOnly:        f.readOnly,
+			showPulls:       !f.hidePulls,
+			journal:         f.journal,
+		}
 	}
 
 	// Create FUSE server options
@@ -209,6 +274,9 @@ func (f *FS) Mount() error {
 		UID: uint32(os.Getuid()),
 		GID: uint32(os.Getgid()),
 	}
+	if f.readOnly {
+		opts.MountOptions.Options = append(opts.MountOptions.Options, "ro")
+	}
 
 	// Mount the filesystem
 	server, err := fs.Mount(f.mountpoint, root, opts)
@@ -216,6 +284,12 @@ func (f *FS) Mount() error {
 		return fmt.Errorf("failed to mount FUSE filesystem: %w", err)
 	}
 	f.server = server
+	if f.multi != nil {
+		f.multi.setLive()
+	}
+	if f.onMounted != nil {
+		f.onMounted()
+	}
 
 	// Set up signal handler for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -233,6 +307,31 @@ func (f *FS) Mount() error {
 	return nil
 }
 
+// OnMounted registers fn to be called by Mount once the filesystem is
+// serving requests.
+func (f *FS) OnMounted(fn func()) {
+	f.onMounted = fn
+}
+
+// SetReadOnly makes Mount mount the filesystem read-only. Repositories of
+// a multi-repo mount must also set Repo.ReadOnly.
+func (f *FS) SetReadOnly(readOnly bool) {
+	f.readOnly = readOnly
+}
+
+// SetHidePulls leaves the pulls directory out of the mount. Repositories of
+// a multi-repo mount set Repo.HidePulls instead.
+func (f *FS) SetHidePulls(hide bool) {
+	f.hidePulls = hide
+}
+
+// SetJournal makes open files log their unflushed writes to j, so that
+// RecoverJournal can restore them after a crash. Repositories of a
+// multi-repo mount set Repo.Journal instead.
+func (f *FS) SetJournal(j *Journal) {
+	f.journal = j
+}
+
 // Unmount stops the FUSE server gracefully.
 func (f *FS) Unmount() error {
 	if f.server != nil {
@@ -250,6 +349,10 @@ type rootNode struct {
 	onDirty         func()
 	statusProvider  StatusProvider
 	refreshProvider RefreshProvider
+	inoBase         uint64 // added to every inode number, see repoInoShift
+	readOnly        bool
+	showPulls       bool // serve pull requests in the pulls directory
+	journal         *Journal
 }
 
 var _ = (fs.NodeReaddirer)((*rootNode)(nil))
@@ -276,23 +379,31 @@ func (r *rootNode) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
 		return nil, syscall.EIO
 	}
 
-	// +1 for .status file
-	entries := make([]fuse.DirEntry, 0, len(issues)+1)
+	// +2 for .status file and pulls directory
+	entries := make([]fuse.DirEntry, 0, len(issues)+2)
 
 	// Add .status file if status provider is available
 	if r.statusProvider != nil {
 		entries = append(entries, fuse.DirEntry{
 			Name: ".status",
-			Ino:  statusFileIno,
+			Ino:  r.inoBase + statusFileIno,
 			Mode: fuse.S_IFREG,
 		})
 	}
 
+	if r.showPulls {
+		entries = append(entries, fuse.DirEntry{
+			Name: pullsDirName,
+			Ino:  r.inoBase + pullsDirIno,
+			Mode: fuse.S_IFDIR,
+		})
+	}
+
 	for _, issue := range issues {
 		filename := makeFilename(issue.Title, issue.Number)
 		entries = append(entries, fuse.DirEntry{
 			Name: filename,
-			Ino:  uint64(issue.Number),
+			Ino:  r.inoBase + uint64(issue.Number),
 			Mode: fuse.S_IFREG,
 		})
 	}
@@ -307,16 +418,32 @@ func (r *rootNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut)
 		content := r.generateStatusContent()
 		out.Mode = 0444 // Read-only
 		out.Size = uint64(len(content))
-		out.Ino = statusFileIno
+		out.Ino = r.inoBase + statusFileIno
 		now := time.Now()
 		out.SetTimes(&now, &now, &now)
 
 		node := &statusFileNode{
 			statusProvider: r.statusProvider,
+			ino:            r.inoBase + statusFileIno,
 		}
 		return r.NewInode(ctx, node, fs.StableAttr{
 			Mode: fuse.S_IFREG,
-			Ino:  statusFileIno,
+			Ino:  r.inoBase + statusFileIno,
+		}), 0
+	}
+
+	if name == pullsDirName && r.showPulls {
+		out.Mode = 0555
+		out.Ino = r.inoBase + pullsDirIno
+		dir := &pullsDirNode{
+			cache:           r.cache,
+			repo:            r.repo,
+			refreshProvider: r.refreshProvider,
+			inoBase:         r.inoBase,
+		}
+		return r.NewInode(ctx, dir, fs.StableAttr{
+			Mode: fuse.S_IFDIR,
+			Ino:  r.inoBase + pullsDirIno,
 		}), 0
 	}
 
@@ -337,7 +464,8 @@ func (r *rootNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut)
 		logger.Warn("fuse: failed to get issue #%d from cache: %v", number, err)
 		return nil, syscall.EIO
 	}
-	if issue == nil {
+	// Pull requests live in the pulls directory
+	if issue == nil || issue.Kind == cache.KindPull {
 		return nil, syscall.ENOENT
 	}
 
@@ -353,9 +481,9 @@ func (r *rootNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut)
 	content := md.ToMarkdown(issue, comments)
 
 	// Set up attributes
-	out.Mode = 0644
+	out.Mode = issueFileMode(r.readOnly)
 	out.Size = uint64(len(content))
-	out.Ino = uint64(issue.Number)
+	out.Ino = r.inoBase + uint64(issue.Number)
 
 	// Set times from issue timestamps
 	mtime := parseIssueTime(issue.UpdatedAt)
@@ -364,16 +492,19 @@ func (r *rootNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut)
 
 	// Create the file node
 	fileNode := &issueFileNode{
-		cache:   r.cache,
-		repo:    r.repo,
-		number:  issue.Number,
-		onDirty: r.onDirty,
+		cache:    r.cache,
+		repo:     r.repo,
+		number:   issue.Number,
+		onDirty:  r.onDirty,
+		inoBase:  r.inoBase,
+		readOnly: r.readOnly,
+		journal:  r.journal,
 	}
 
 	// Create a stable inode using the issue number
 	stable := fs.StableAttr{
 		Mode: fuse.S_IFREG,
-		Ino:  uint64(issue.Number),
+		Ino:  r.inoBase + uint64(issue.Number),
 	}
 
 	child := r.NewInode(ctx, fileNode, stable)
@@ -383,6 +514,10 @@ func (r *rootNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut)
 // Create creates a new file for a new issue.
 // The filename must be in the format: title[new].md
 func (r *rootNode) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (*fs.Inode, fs.FileHandle, uint32, syscall.Errno) {
+	if r.readOnly {
+		return nil, nil, 0, syscall.EROFS
+	}
+
 	// Check if this is a new issue file
 	titlePart, ok := parseNewIssueFilename(name)
 	if !ok {
@@ -402,28 +537,19 @@ func (r *rootNode) Create(ctx context.Context, name string, flags uint32, mode u
 		repo:    r.repo,
 		title:   title,
 		onDirty: r.onDirty,
+		journal: r.journal,
 	}
 
 	// Create the inode with a unique ID
 	stable := fs.StableAttr{
 		Mode: fuse.S_IFREG,
-		Ino:  uint64(uint32(pendingID) + 0x80000000), // High bit to avoid collision
+		Ino:  r.inoBase + uint64(uint32(pendingID)+0x80000000), // High bit to avoid collision
 	}
 
 	child := r.NewInode(ctx, fileNode, stable)
 
 	// Generate initial content template
-	template := fmt.Sprintf(`---
-repo: %s
-state: open
-labels: []
----
-
-# %s
-
-## Body
-
-`, r.repo, title)
+	template := NewIssueTemplate(r.repo, title)
 
 	handle := &newIssueFileHandle{
 		cache:   r.cache,
@@ -432,6 +558,7 @@ labels: []
 		buffer:  []byte(template),
 		dirty:   true,
 		onDirty: r.onDirty,
+		journal: r.journal.newEntry("new " + title),
 	}
 
 	// Set file attributes
@@ -450,6 +577,7 @@ type newIssueFileNode struct {
 	repo    string
 	title   string
 	onDirty func()
+	journal *Journal
 }
 
 var _ = (fs.NodeGetattrer)((*newIssueFileNode)(nil))
@@ -458,6 +586,7 @@ var _ = (fs.NodeReader)((*newIssueFileNode)(nil))
 var _ = (fs.NodeWriter)((*newIssueFileNode)(nil))
 var _ = (fs.NodeFlusher)((*newIssueFileNode)(nil))
 var _ = (fs.NodeSetattrer)((*newIssueFileNode)(nil))
+var _ = (fs.NodeReleaser)((*newIssueFileNode)(nil))
 
 // Getattr returns file attributes for a new issue file.
 func (f *newIssueFileNode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
@@ -473,11 +602,18 @@ func (f *newIssueFileNode) Getattr(ctx context.Context, fh fs.FileHandle, out *f
 func (f *newIssueFileNode) Setattr(ctx context.Context, fh fs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) syscall.Errno {
 	if handle, ok := fh.(*newIssueFileHandle); ok {
 		if sz, ok := in.GetSize(); ok {
+			if sz > maxFileSize {
+				return syscall.EFBIG
+			}
 			handle.mu.Lock()
+			handle.journal.logTruncate(handle.buffer, int64(sz))
 			if sz == 0 {
 				handle.buffer = []byte{}
 			} else if int(sz) < len(handle.buffer) {
 				handle.buffer = handle.buffer[:sz]
+			} else {
+				// Growing a file fills it with zeros, as ftruncate does
+				handle.buffer = zeroExtend(handle.buffer, int(sz))
 			}
 			handle.dirty = true
 			out.Size = uint64(len(handle.buffer))
@@ -494,17 +630,7 @@ func (f *newIssueFileNode) Setattr(ctx context.Context, fh fs.FileHandle, in *fu
 // Open opens a new issue file.
 func (f *newIssueFileNode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
 	// Generate initial template
-	template := fmt.Sprintf(`---
-repo: %s
-state: open
-labels: []
----
-
-# %s
-
-## Body
-
-`, f.repo, f.title)
+	template := NewIssueTemplate(f.repo, f.title)
 
 	handle := &newIssueFileHandle{
 		cache:   f.cache,
@@ -513,6 +639,7 @@ labels: []
 		buffer:  []byte(template),
 		dirty:   false,
 		onDirty: f.onDirty,
+		journal: f.journal.newEntry("new " + f.title),
 	}
 
 	return handle, fuse.FOPEN_DIRECT_IO, 0
@@ -555,6 +682,7 @@ func (f *newIssueFileNode) Write(ctx context.Context, fh fs.FileHandle, data []b
 		return 0, syscall.EFBIG
 	}
 
+	handle.journal.logWrite(handle.buffer, off, data)
 	if endPos > len(handle.buffer) {
 		newBuf := make([]byte, endPos)
 		copy(newBuf, handle.buffer)
@@ -581,28 +709,11 @@ func (f *newIssueFileNode) Flush(ctx context.Context, fh fs.FileHandle) syscall.
 		return 0
 	}
 
-	// Parse the content
-	content := string(handle.buffer)
-	parsed, err := md.FromMarkdown(content)
-	if err != nil {
-		logger.Warn("failed to parse new issue markdown: %v", err)
-		return syscall.EIO
-	}
-
-	// Use labels from frontmatter (parsed from YAML)
-	labels := parsed.Labels
+	handle.journal.sync()
 
-	// Get title and body from parsed content
-	title := parsed.Title
-	if title == "" {
-		title = f.title // Fallback to filename-derived title
-	}
-	body := parsed.Body
-
-	// Add to pending issues
-	_, err = f.cache.AddPendingIssue(f.repo, title, body, labels)
-	if err != nil {
-		logger.Warn("failed to add pending issue: %v", err)
+	// Fall back to the filename-derived title
+	if _, err := ApplyNewIssue(f.cache, f.repo, f.title, string(handle.buffer)); err != nil {
+		logger.Warn("fuse: Flush %v", err)
 		return syscall.EIO
 	}
 
@@ -612,6 +723,7 @@ func (f *newIssueFileNode) Flush(ctx context.Context, fh fs.FileHandle) syscall.
 	}
 
 	handle.dirty = false
+	handle.journal.done()
 	return 0
 }
 
@@ -623,18 +735,39 @@ type newIssueFileHandle struct {
 	buffer  []byte
 	dirty   bool
 	onDirty func()
+	journal *journalEntry
 	mu      sync.Mutex
 }
 
 var _ = (fs.FileHandle)((*newIssueFileHandle)(nil))
 
+// Release is called when the last reference to a new issue file handle is
+// closed. A journal left by a failed flush is kept for recovery.
+func (f *newIssueFileNode) Release(ctx context.Context, fh fs.FileHandle) syscall.Errno {
+	if handle, ok := fh.(*newIssueFileHandle); ok {
+		handle.journal.release()
+	}
+	return 0
+}
+
 // issueFileNode represents a single issue file.
 type issueFileNode struct {
 	fs.Inode
-	cache   *cache.DB
-	repo    string
-	number  int
-	onDirty func()
+	cache    *cache.DB
+	repo     string
+	number   int
+	onDirty  func()
+	inoBase  uint64
+	readOnly bool
+	journal  *Journal
+}
+
+// issueFileMode returns the permissions of issue files.
+func issueFileMode(readOnly bool) uint32 {
+	if readOnly {
+		return 0444
+	}
+	return 0644
 }
 
 var _ = (fs.NodeGetattrer)((*issueFileNode)(nil))
@@ -643,6 +776,7 @@ var _ = (fs.NodeReader)((*issueFileNode)(nil))
 var _ = (fs.NodeWriter)((*issueFileNode)(nil))
 var _ = (fs.NodeFlusher)((*issueFileNode)(nil))
 var _ = (fs.NodeSetattrer)((*issueFileNode)(nil))
+var _ = (fs.NodeReleaser)((*issueFileNode)(nil))
 
 // Getattr returns file attributes.
 func (f *issueFileNode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
@@ -666,9 +800,9 @@ func (f *issueFileNode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse
 
 	content := md.ToMarkdown(issue, comments)
 
-	out.Mode = 0644
+	out.Mode = issueFileMode(f.readOnly)
 	out.Size = uint64(len(content))
-	out.Ino = uint64(f.number)
+	out.Ino = f.inoBase + uint64(f.number)
 
 	// Set times from issue timestamps
 	mtime := parseIssueTime(issue.UpdatedAt)
@@ -682,13 +816,24 @@ func (f *issueFileNode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse
 func (f *issueFileNode) Setattr(ctx context.Context, fh fs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) syscall.Errno {
 	// Handle truncate if requested
 	if sz, ok := in.GetSize(); ok {
+		if f.readOnly {
+			return syscall.EROFS
+		}
+
 		// If there's an open file handle, update its buffer and report the new size
 		if handle, ok := fh.(*issueFileHandle); ok {
+			if sz > maxFileSize {
+				return syscall.EFBIG
+			}
 			handle.mu.Lock()
+			handle.journal.logTruncate(handle.buffer, int64(sz))
 			if sz == 0 {
 				handle.buffer = []byte{}
 			} else if int(sz) < len(handle.buffer) {
 				handle.buffer = handle.buffer[:sz]
+			} else {
+				// Growing a file fills it with zeros, as ftruncate does
+				handle.buffer = zeroExtend(handle.buffer, int(sz))
 			}
 			handle.dirty = true
 			// Use the buffer size we just set, not a cache lookup
@@ -696,7 +841,7 @@ func (f *issueFileNode) Setattr(ctx context.Context, fh fs.FileHandle, in *fuse.
 			handle.mu.Unlock()
 
 			out.Mode = 0644
-			out.Ino = uint64(f.number)
+			out.Ino = f.inoBase + uint64(f.number)
 			out.Size = newSize
 
 			now := time.Now()
@@ -706,7 +851,7 @@ func (f *issueFileNode) Setattr(ctx context.Context, fh fs.FileHandle, in *fuse.
 
 		// No file handle - use the requested size directly
 		out.Mode = 0644
-		out.Ino = uint64(f.number)
+		out.Ino = f.inoBase + uint64(f.number)
 		out.Size = sz
 
 		now := time.Now()
@@ -725,8 +870,8 @@ func (f *issueFileNode) Setattr(ctx context.Context, fh fs.FileHandle, in *fuse.
 		return syscall.EIO
 	}
 
-	out.Mode = 0644
-	out.Ino = uint64(f.number)
+	out.Mode = issueFileMode(f.readOnly)
+	out.Ino = f.inoBase + uint64(f.number)
 
 	// Set times from issue timestamps
 	mtime := parseIssueTime(issue.UpdatedAt)
@@ -748,6 +893,10 @@ func (f *issueFileNode) Setattr(ctx context.Context, fh fs.FileHandle, in *fuse.
 
 // Open opens the file and returns a file handle.
 func (f *issueFileNode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if f.readOnly && flags&(syscall.O_WRONLY|syscall.O_RDWR|syscall.O_TRUNC) != 0 {
+		return nil, 0, syscall.EROFS
+	}
+
 	// Get the issue from cache
 	issue, err := f.cache.GetIssue(f.repo, f.number)
 	if err != nil {
@@ -777,6 +926,7 @@ func (f *issueFileNode) Open(ctx context.Context, flags uint32) (fs.FileHandle,
 		buffer:  []byte(content),
 		dirty:   false,
 		onDirty: f.onDirty,
+		journal: f.journal.newEntry(fmt.Sprintf("issue %d", f.number)),
 	}
 
 	return handle, fuse.FOPEN_DIRECT_IO, 0
@@ -831,6 +981,10 @@ func (f *issueFileNode) Read(ctx context.Context, fh fs.FileHandle, dest []byte,
 
 // Write writes data to the file.
 func (f *issueFileNode) Write(ctx context.Context, fh fs.FileHandle, data []byte, off int64) (uint32, syscall.Errno) {
+	if f.readOnly {
+		return 0, syscall.EROFS
+	}
+
 	handle, ok := fh.(*issueFileHandle)
 	if !ok {
 		return 0, syscall.EBADF
@@ -845,6 +999,9 @@ func (f *issueFileNode) Write(ctx context.Context, fh fs.FileHandle, data []byte
 		return 0, syscall.EFBIG // File too large
 	}
 
+	// Log the write ahead of applying it, so it survives a crash
+	handle.journal.logWrite(handle.buffer, off, data)
+
 	// Extend buffer if necessary
 	if endPos > len(handle.buffer) {
 		newBuf := make([]byte, endPos)
@@ -872,93 +1029,24 @@ func (f *issueFileNode) Flush(ctx context.Context, fh fs.FileHandle) syscall.Err
 	if !handle.dirty {
 		return 0
 	}
-
-	// Parse the content to extract changes
-	content := string(handle.buffer)
-	parsed, err := md.FromMarkdown(content)
-	if err != nil {
-		// Can't parse the content, don't update
-		logger.Warn("failed to parse markdown for issue #%d: %v", f.number, err)
-		return syscall.EIO
+	if f.readOnly {
+		return syscall.EROFS
 	}
 
-	// Get the original issue
-	original, err := f.cache.GetIssue(f.repo, f.number)
+	handle.journal.sync()
+	queued, err := ApplyIssueEdit(f.cache, f.repo, f.number, string(handle.buffer))
 	if err != nil {
-		logger.Warn("fuse: Flush failed to get original issue #%d: %v", f.number, err)
+		logger.Warn("fuse: Flush %v", err)
 		return syscall.EIO
 	}
-	if original == nil {
-		logger.Warn("fuse: Flush issue #%d not found in cache", f.number)
-		return syscall.EIO
-	}
-
-	// Detect changes
-	changes := md.DetectChanges(original, parsed)
-
-	// Track if we need to trigger sync
-	needsSync := false
-
-	// Check if any issue fields changed (title, body, state, labels, parent)
-	if changes.TitleChanged || changes.BodyChanged || changes.StateChanged || changes.LabelsChanged || changes.ParentIssueChanged {
-		update := cache.IssueUpdate{}
-		if changes.TitleChanged {
-			update.Title = &changes.NewTitle
-		}
-		if changes.BodyChanged {
-			update.Body = &changes.NewBody
-		}
-		if changes.StateChanged {
-			update.State = &changes.NewState
-		}
-		if changes.LabelsChanged {
-			update.Labels = &changes.NewLabels
-		}
-		if changes.ParentIssueChanged {
-			update.ParentIssueNumber = &changes.NewParentIssue
-		}
-		err = f.cache.MarkDirty(f.repo, f.number, update)
-		if err != nil {
-			logger.Warn("fuse: Flush failed to mark issue #%d as dirty: %v", f.number, err)
-			return syscall.EIO
-		}
-		needsSync = true
-	}
-
-	// Handle comment changes
-	originalComments, err := f.cache.GetComments(f.repo, f.number)
-	if err != nil {
-		originalComments = []cache.Comment{}
-	}
-
-	newComments, editedComments := md.DetectCommentChanges(originalComments, parsed.Comments)
-
-	// Add new comments to pending
-	for _, nc := range newComments {
-		err := f.cache.AddPendingComment(f.repo, f.number, nc.Body)
-		if err != nil {
-			logger.Warn("failed to add pending comment for issue #%d: %v", f.number, err)
-		} else {
-			needsSync = true
-		}
-	}
-
-	// Mark edited comments as dirty
-	for _, ec := range editedComments {
-		err := f.cache.MarkCommentDirty(f.repo, ec.ID, ec.NewBody)
-		if err != nil {
-			logger.Warn("failed to mark comment %d as dirty: %v", ec.ID, err)
-		} else {
-			needsSync = true
-		}
-	}
 
 	// Trigger sync engine callback if changes were made
-	if needsSync && handle.onDirty != nil {
+	if queued && handle.onDirty != nil {
 		handle.onDirty()
 	}
 
 	handle.dirty = false
+	handle.journal.done()
 	return 0
 }
 
@@ -970,18 +1058,87 @@ type issueFileHandle struct {
 	buffer  []byte
 	dirty   bool
 	onDirty func()
+	journal *journalEntry
 	mu      sync.Mutex
 }
 
 var _ = (fs.FileHandle)((*issueFileHandle)(nil))
 
+// Release is called when the last reference to an issue file handle is
+// closed. A journal left by a failed flush is kept for recovery.
+func (f *issueFileNode) Release(ctx context.Context, fh fs.FileHandle) syscall.Errno {
+	if handle, ok := fh.(*issueFileHandle); ok {
+		handle.journal.release()
+	}
+	return 0
+}
+
 // generateStatusContent generates the content for the .status file.
 func (r *rootNode) generateStatusContent() string {
-	status := r.statusProvider.GetStatus()
+	return FormatStatus(r.statusProvider.GetStatus())
+}
+
+// FormatStatus renders a sync status in the human-readable .status format.
+func FormatStatus(status SyncStatus) string {
+	var sb strings.Builder
+	sb.WriteString("# ghissues status\n\n")
+	writeStatusLines(&sb, status)
+	return sb.String()
+}
+
+// NamedStatus is the sync status of one repository of a multi-repo mount.
+type NamedStatus struct {
+	Repo   string
+	Status SyncStatus
+}
+
+// FormatMultiStatus renders the aggregated .status of a multi-repo mount:
+// totals across all repositories, followed by the status of each one.
+func FormatMultiStatus(statuses []NamedStatus) string {
+	var total SyncStatus
+	errors := 0
+	for _, s := range statuses {
+		total.PendingIssues += s.Status.PendingIssues
+		total.PendingComments += s.Status.PendingComments
+		total.DirtyIssues += s.Status.DirtyIssues
+		total.DirtyComments += s.Status.DirtyComments
+		if s.Status.LastError != "" {
+			errors++
+		}
+	}
 
 	var sb strings.Builder
 	sb.WriteString("# ghissues status\n\n")
+	sb.WriteString(fmt.Sprintf("Repositories: %d\n", len(statuses)))
+	sb.WriteString(fmt.Sprintf("Pending issues: %d\n", total.PendingIssues))
+	sb.WriteString(fmt.Sprintf("Pending comments: %d\n", total.PendingComments))
+	sb.WriteString(fmt.Sprintf("Dirty issues: %d\n", total.DirtyIssues))
+	sb.WriteString(fmt.Sprintf("Dirty comments: %d\n", total.DirtyComments))
+	sb.WriteString(fmt.Sprintf("Repositories with errors: %d\n", errors))
 
+	for _, s := range statuses {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", s.Repo))
+		writeStatusLines(&sb, s.Status)
+	}
+
+	return sb.String()
+}
+
+// writeStatusLines writes the body of a single-repo status.
+func writeStatusLines(sb *strings.Builder, status SyncStatus) {
+	var modes []string
+	if status.ReadOnly {
+		modes = append(modes, "read-only")
+	}
+	if status.Offline {
+		modes = append(modes, "offline")
+	}
+	if len(modes) > 0 {
+		sb.WriteString(fmt.Sprintf("Mode: %s\n", strings.Join(modes, ", ")))
+	}
+	if status.Filter != "" {
+		sb.WriteString(fmt.Sprintf("Filter: %s\n", status.Filter))
+	}
 	if status.LastSyncTime.IsZero() {
 		sb.WriteString("Last sync: never\n")
 	} else {
@@ -998,14 +1155,14 @@ func (r *rootNode) generateStatusContent() string {
 	} else {
 		sb.WriteString(fmt.Sprintf("Last error: %s\n", status.LastError))
 	}
-
-	return sb.String()
 }
 
 // statusFileNode represents the virtual .status file.
 type statusFileNode struct {
 	fs.Inode
 	statusProvider StatusProvider
+	render         func() string // overrides statusProvider, used for the aggregated status
+	ino            uint64
 }
 
 var _ = (fs.NodeGetattrer)((*statusFileNode)(nil))
@@ -1014,12 +1171,11 @@ var _ = (fs.NodeReader)((*statusFileNode)(nil))
 
 // Getattr returns the file attributes for the status file.
 func (s *statusFileNode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
-	status := s.statusProvider.GetStatus()
-	content := s.generateContent(status)
+	content := s.generateContent()
 
 	out.Mode = 0444 // Read-only
 	out.Size = uint64(len(content))
-	out.Ino = statusFileIno
+	out.Ino = s.ino
 	now := time.Now()
 	out.SetTimes(&now, &now, &now)
 	return 0
@@ -1032,8 +1188,7 @@ func (s *statusFileNode) Open(ctx context.Context, flags uint32) (fs.FileHandle,
 		return nil, 0, syscall.EACCES
 	}
 
-	status := s.statusProvider.GetStatus()
-	content := s.generateContent(status)
+	content := s.generateContent()
 
 	return &statusFileHandle{content: []byte(content)}, fuse.FOPEN_DIRECT_IO, 0
 }
@@ -1043,8 +1198,7 @@ func (s *statusFileNode) Read(ctx context.Context, fh fs.FileHandle, dest []byte
 	handle, ok := fh.(*statusFileHandle)
 	if !ok {
 		// No handle, generate content directly
-		status := s.statusProvider.GetStatus()
-		content := []byte(s.generateContent(status))
+		content := []byte(s.generateContent())
 		if off >= int64(len(content)) {
 			return fuse.ReadResultData(nil), 0
 		}
@@ -1066,28 +1220,11 @@ func (s *statusFileNode) Read(ctx context.Context, fh fs.FileHandle, dest []byte
 }
 
 // generateContent generates the status file content.
-func (s *statusFileNode) generateContent(status SyncStatus) string {
-	var sb strings.Builder
-	sb.WriteString("# ghissues status\n\n")
-
-	if status.LastSyncTime.IsZero() {
-		sb.WriteString("Last sync: never\n")
-	} else {
-		sb.WriteString(fmt.Sprintf("Last sync: %s\n", status.LastSyncTime.Format(time.RFC3339)))
+func (s *statusFileNode) generateContent() string {
+	if s.render != nil {
+		return s.render()
 	}
-
-	sb.WriteString(fmt.Sprintf("Pending issues: %d\n", status.PendingIssues))
-	sb.WriteString(fmt.Sprintf("Pending comments: %d\n", status.PendingComments))
-	sb.WriteString(fmt.Sprintf("Dirty issues: %d\n", status.DirtyIssues))
-	sb.WriteString(fmt.Sprintf("Dirty comments: %d\n", status.DirtyComments))
-
-	if status.LastError == "" {
-		sb.WriteString("Last error: none\n")
-	} else {
-		sb.WriteString(fmt.Sprintf("Last error: %s\n", status.LastError))
-	}
-
-	return sb.String()
+	return FormatStatus(s.statusProvider.GetStatus())
 }
 
 // statusFileHandle holds the content of an open status file.
@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"path/filepath"
@@ -828,7 +829,7 @@ func TestIssueFileNode_Setattr_Truncate(t *testing.T) {
 	})
 }
 
-// TestIssueFileNode_Setattr_TruncateLarger tests truncating larger than current size (should be no-op for buffer).
+// TestIssueFileNode_Setattr_TruncateLarger tests truncating larger than current size pads with zeros.
 func TestIssueFileNode_Setattr_TruncateLarger(t *testing.T) {
 	db, _ := setupTestCache(t)
 	defer db.Close()
@@ -867,7 +868,48 @@ func TestIssueFileNode_Setattr_TruncateLarger(t *testing.T) {
 		t.Fatalf("Setattr returned error: %v", errno)
 	}
 
-	// Buffer should NOT have grown (truncate larger is a no-op)
+	// Truncating to a larger size pads with zeros, like ftruncate
+	if len(handle.buffer) != originalLen+100 {
+		t.Errorf("buffer length = %d, expected %d", len(handle.buffer), originalLen+100)
+	}
+	if !bytes.Equal(handle.buffer[originalLen:], make([]byte, 100)) {
+		t.Error("buffer was not padded with zeros")
+	}
+}
+
+// TestIssueFileNode_Setattr_TooLarge tests truncating past the maximum file size fails.
+func TestIssueFileNode_Setattr_TooLarge(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+
+	repo := "test/repo"
+	issues := []cache.Issue{
+		{Number: 1, Title: "Test", Body: "Short", State: "open"},
+	}
+	populateTestIssues(t, db, repo, issues)
+
+	fileNode := &issueFileNode{
+		cache:  db,
+		repo:   repo,
+		number: 1,
+	}
+
+	ctx := context.Background()
+
+	fh, _, errno := fileNode.Open(ctx, 0)
+	if errno != 0 {
+		t.Fatalf("Open returned error: %v", errno)
+	}
+	handle := fh.(*issueFileHandle)
+	originalLen := len(handle.buffer)
+
+	in := &fuse.SetAttrIn{}
+	in.Valid = fuse.FATTR_SIZE
+	in.Size = maxFileSize + 1
+
+	if errno := fileNode.Setattr(ctx, fh, in, &fuse.AttrOut{}); errno != syscall.EFBIG {
+		t.Errorf("Setattr errno = %v, want EFBIG", errno)
+	}
 	if len(handle.buffer) != originalLen {
 		t.Errorf("buffer length = %d, expected %d (unchanged)", len(handle.buffer), originalLen)
 	}
@@ -3318,3 +3360,72 @@ func TestRootNode_Create_InvalidFilename_Logic(t *testing.T) {
 		})
 	}
 }
+
+// TestReadOnly_RejectsChanges tests that a read-only repository rejects
+// every write with EROFS while reads keep working.
+func TestReadOnly_RejectsChanges(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+
+	repo := "test/repo"
+	populateTestIssues(t, db, repo, []cache.Issue{{Number: 1, Title: "Issue", Body: "Body", State: "open"}})
+
+	ctx := context.Background()
+	root := &rootNode{cache: db, repo: repo, readOnly: true}
+	if _, _, _, errno := root.Create(ctx, "Idea[new].md", 0, 0644, &fuse.EntryOut{}); errno != syscall.EROFS {
+		t.Errorf("Create() errno = %v, expected EROFS", errno)
+	}
+
+	fileNode := &issueFileNode{cache: db, repo: repo, number: 1, readOnly: true}
+
+	out := &fuse.AttrOut{}
+	if errno := fileNode.Getattr(ctx, nil, out); errno != 0 || out.Mode != 0444 {
+		t.Errorf("Getattr() = mode %o, errno %v; expected 0444", out.Mode, errno)
+	}
+	if _, _, errno := fileNode.Open(ctx, syscall.O_RDWR); errno != syscall.EROFS {
+		t.Errorf("Open(O_RDWR) errno = %v, expected EROFS", errno)
+	}
+
+	fh, _, errno := fileNode.Open(ctx, syscall.O_RDONLY)
+	if errno != 0 {
+		t.Fatalf("Open(O_RDONLY) errno = %v", errno)
+	}
+	if _, errno := fileNode.Write(ctx, fh, []byte("x"), 0); errno != syscall.EROFS {
+		t.Errorf("Write() errno = %v, expected EROFS", errno)
+	}
+	in := &fuse.SetAttrIn{}
+	in.Valid = fuse.FATTR_SIZE
+	if errno := fileNode.Setattr(ctx, fh, in, &fuse.AttrOut{}); errno != syscall.EROFS {
+		t.Errorf("Setattr(size) errno = %v, expected EROFS", errno)
+	}
+	if errno := fileNode.Flush(ctx, fh); errno != 0 {
+		t.Errorf("Flush() of an unchanged file errno = %v, expected 0", errno)
+	}
+
+	if !strings.Contains(FormatStatus(SyncStatus{ReadOnly: true}), "Mode: read-only\n") {
+		t.Error("status should show the read-only mode")
+	}
+}
+
+func TestFormatStatus_Modes(t *testing.T) {
+	tests := []struct {
+		status SyncStatus
+		want   string
+	}{
+		{SyncStatus{}, ""},
+		{SyncStatus{Offline: true}, "Mode: offline\n"},
+		{SyncStatus{ReadOnly: true, Offline: true}, "Mode: read-only, offline\n"},
+		{SyncStatus{Filter: "state=open label=bug"}, "Filter: state=open label=bug\n"},
+	}
+
+	for _, tt := range tests {
+		got := FormatStatus(tt.status)
+		if tt.want == "" {
+			if strings.Contains(got, "Mode:") {
+				t.Errorf("FormatStatus(%+v) should not show a mode:\n%s", tt.status, got)
+			}
+		} else if !strings.Contains(got, tt.want) {
+			t.Errorf("FormatStatus(%+v) = %q, expected it to contain %q", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package fs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/logger"
+)
+
+// journalMagic starts every journal file.
+const journalMagic = "ghissues-journal 1\n"
+
+// Journal operations. Each record is the operation byte, a big-endian
+// int64 offset, a big-endian uint32 length and that many bytes of data.
+const (
+	journalBase     byte = 'B' // the file content when the first write happened
+	journalWrite    byte = 'W' // data written at offset
+	journalTruncate byte = 'T' // the file truncated to offset
+)
+
+// Journal is a write-ahead log of writes to open issue files that were not
+// flushed to the cache yet. Each file handle logs to its own file, which is
+// removed once the handle is flushed; files left behind by a crash are
+// replayed by RecoverJournal. A nil *Journal logs nothing.
+type Journal struct {
+	dir string
+	seq atomic.Int64
+}
+
+// OpenJournal opens the journal kept in dir, creating it if needed.
+func OpenJournal(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	return &Journal{dir: dir}, nil
+}
+
+// Dir returns the directory of the journal.
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+// newEntry returns the log of a file handle writing to target: "issue N"
+// for an existing issue or "new <title>" for a new issue file.
+func (j *Journal) newEntry(target string) *journalEntry {
+	if j == nil {
+		return nil
+	}
+	return &journalEntry{journal: j, target: target}
+}
+
+// journalEntry logs the writes of one file handle. Its file is created by
+// the first write. A nil *journalEntry logs nothing.
+type journalEntry struct {
+	journal *Journal
+	target  string
+	mu      gosync.Mutex
+	path    string
+	file    *os.File
+	failed  bool // stop logging after an error, the edit is only in memory
+}
+
+// logWrite records that data is about to be written at off into a buffer
+// currently holding current.
+func (e *journalEntry) logWrite(current []byte, off int64, data []byte) {
+	e.append(current, journalWrite, off, data)
+}
+
+// logTruncate records that a buffer currently holding current is about to
+// be truncated to size.
+func (e *journalEntry) logTruncate(current []byte, size int64) {
+	e.append(current, journalTruncate, size, nil)
+}
+
+func (e *journalEntry) append(current []byte, op byte, off int64, data []byte) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failed {
+		return
+	}
+
+	if err := e.appendLocked(current, op, off, data); err != nil {
+		logger.Warn("fuse: journal %s: %v; unsaved changes will not survive a crash", e.target, err)
+		e.failed = true
+	}
+}
+
+func (e *journalEntry) appendLocked(current []byte, op byte, off int64, data []byte) error {
+	if e.file == nil {
+		name := fmt.Sprintf("%d-%d.wal", time.Now().UnixNano(), e.journal.seq.Add(1))
+		path := filepath.Join(e.journal.dir, name)
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create journal file: %w", err)
+		}
+		e.path, e.file = path, file
+
+		if _, err := io.WriteString(file, journalMagic+e.target+"\n"); err != nil {
+			return fmt.Errorf("failed to write journal: %w", err)
+		}
+		if err := writeJournalRecord(file, journalBase, 0, current); err != nil {
+			return err
+		}
+		// Later records are useless without the base, make sure it is on disk
+		if err := file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync journal: %w", err)
+		}
+	}
+	return writeJournalRecord(e.file, op, off, data)
+}
+
+// sync flushes the log to disk, so that it survives a crash of the machine.
+// It is called when the handle is flushed, before the edit is applied.
+func (e *journalEntry) sync() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil || e.failed {
+		return
+	}
+	if err := e.file.Sync(); err != nil {
+		logger.Warn("fuse: journal %s: failed to sync: %v", e.target, err)
+	}
+}
+
+// release syncs and closes the log of a handle that is closed without its
+// edit reaching the cache, keeping it on disk for RecoverJournal.
+func (e *journalEntry) release() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return
+	}
+	if err := e.file.Sync(); err != nil {
+		logger.Warn("fuse: journal %s: failed to sync: %v", e.target, err)
+	}
+	e.file.Close()
+	e.path, e.file = "", nil
+}
+
+// done removes the log once the handle's content reached the cache. Later
+// writes start a new log.
+func (e *journalEntry) done() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.file != nil {
+		e.file.Close()
+		if err := os.Remove(e.path); err != nil {
+			logger.Warn("fuse: failed to remove journal file: %v", err)
+		}
+	}
+	e.path, e.file, e.failed = "", nil, false
+}
+
+func writeJournalRecord(w io.Writer, op byte, off int64, data []byte) error {
+	record := make([]byte, 13+len(data))
+	record[0] = op
+	binary.BigEndian.PutUint64(record[1:9], uint64(off))
+	binary.BigEndian.PutUint32(record[9:13], uint32(len(data)))
+	copy(record[13:], data)
+	if _, err := w.Write(record); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// replayJournal reads a journal file and returns its target and the content
+// the file handle held after its last logged operation. A record cut short
+// by a crash is ignored.
+func replayJournal(data []byte) (target string, content []byte, err error) {
+	rest, ok := bytes.CutPrefix(data, []byte(journalMagic))
+	if !ok {
+		return "", nil, fmt.Errorf("not a ghissues journal")
+	}
+	line, rest, ok := bytes.Cut(rest, []byte("\n"))
+	if !ok {
+		return "", nil, fmt.Errorf("not a ghissues journal")
+	}
+	target = string(line)
+
+	for len(rest) >= 13 {
+		op := rest[0]
+		off := binary.BigEndian.Uint64(rest[1:9])
+		size := binary.BigEndian.Uint32(rest[9:13])
+		rest = rest[13:]
+		// The length is only trusted as far as the file goes
+		if uint64(size) > uint64(len(rest)) {
+			break
+		}
+		record := rest[:size]
+		rest = rest[size:]
+		if off > maxFileSize || off+uint64(size) > maxFileSize {
+			return "", nil, fmt.Errorf("journal record past the maximum file size")
+		}
+
+		switch op {
+		case journalBase:
+			content = bytes.Clone(record)
+		case journalWrite:
+			content = zeroExtend(content, int(off)+len(record))
+			copy(content[off:], record)
+		case journalTruncate:
+			if int(off) < len(content) {
+				content = content[:off]
+			} else {
+				content = zeroExtend(content, int(off))
+			}
+		default:
+			return "", nil, fmt.Errorf("unknown journal operation %q", op)
+		}
+	}
+	return target, content, nil
+}
+
+// zeroExtend returns buf grown with zero bytes to at least size bytes.
+func zeroExtend(buf []byte, size int) []byte {
+	if size <= len(buf) {
+		return buf
+	}
+	return append(buf, make([]byte, size-len(buf))...)
+}
+
+// RecoverJournal queues the unflushed edits left in the journal by a mount
+// that crashed, exactly as flushing the files would have. It returns how
+// many edits were recovered and the files holding edits that could not be
+// applied, such as unparsable markdown, which are kept for the user.
+func RecoverJournal(j *Journal, db *cache.DB, repo string) (recovered int, kept []string, err error) {
+	paths, err := filepath.Glob(filepath.Join(j.dir, "*.wal"))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to list journal: %w", err)
+	}
+	sort.Strings(paths) // oldest first, names start with the creation time
+
+	for _, path := range paths {
+		target, content, applyErr := recoverJournalFile(db, repo, path)
+		if applyErr == nil {
+			recovered++
+			os.Remove(path)
+			continue
+		}
+
+		keptPath := strings.TrimSuffix(path, ".wal") + ".md"
+		if content == nil {
+			keptPath = path
+		} else if err := os.WriteFile(keptPath, content, 0600); err != nil {
+			keptPath = path
+		} else {
+			os.Remove(path)
+		}
+		logger.Warn("fuse: failed to recover the unsaved edit of %s: %v; kept in %s", target, applyErr, keptPath)
+		kept = append(kept, keptPath)
+	}
+	return recovered, kept, nil
+}
+
+// recoverJournalFile replays one journal file and queues its edit.
+func recoverJournalFile(db *cache.DB, repo, path string) (target string, content []byte, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path, nil, err
+	}
+	target, content, err = replayJournal(data)
+	if err != nil {
+		return path, nil, err
+	}
+
+	kind, arg, _ := strings.Cut(target, " ")
+	switch kind {
+	case "issue":
+		number, err := strconv.Atoi(arg)
+		if err != nil {
+			return target, content, fmt.Errorf("invalid journal target %q", target)
+		}
+		_, err = ApplyIssueEdit(db, repo, number, string(content))
+		return target, content, err
+	case "new":
+		_, err = ApplyNewIssue(db, repo, arg, string(content))
+		return target, content, err
+	}
+	return target, content, fmt.Errorf("invalid journal target %q", target)
+}
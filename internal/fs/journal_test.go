@@ -0,0 +1,160 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+func TestReplayJournal(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  func(e *journalEntry)
+		want string
+	}{
+		{
+			name: "overwrite and extend",
+			ops: func(e *journalEntry) {
+				e.logWrite([]byte("hello world"), 6, []byte("there!"))
+			},
+			want: "hello there!",
+		},
+		{
+			name: "truncate then write",
+			ops: func(e *journalEntry) {
+				e.logTruncate([]byte("old content"), 0)
+				e.logWrite(nil, 0, []byte("new"))
+			},
+			want: "new",
+		},
+		{
+			name: "write past the end pads with zeros",
+			ops: func(e *journalEntry) {
+				e.logWrite([]byte("ab"), 3, []byte("c"))
+			},
+			want: "ab\x00c",
+		},
+		{
+			name: "truncate to a larger size pads with zeros",
+			ops: func(e *journalEntry) {
+				e.logTruncate([]byte("ab"), 4)
+				e.logWrite([]byte("ab\x00\x00"), 0, []byte("x"))
+			},
+			want: "xb\x00\x00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := OpenJournal(t.TempDir())
+			if err != nil {
+				t.Fatalf("OpenJournal() error = %v", err)
+			}
+			e := j.newEntry("issue 1")
+			tt.ops(e)
+
+			data, err := os.ReadFile(e.path)
+			if err != nil {
+				t.Fatalf("journal not written: %v", err)
+			}
+			target, content, err := replayJournal(data)
+			if err != nil || target != "issue 1" || string(content) != tt.want {
+				t.Errorf("replayJournal() = %q, %q, %v; want issue 1, %q", target, content, err, tt.want)
+			}
+
+			// A record cut short by a crash is ignored
+			_, content, err = replayJournal(data[:len(data)-1])
+			if err != nil || string(content) == tt.want {
+				t.Errorf("replayJournal(cut) = %q, %v; expected the last record ignored", content, err)
+			}
+		})
+	}
+
+	if _, _, err := replayJournal([]byte("not a journal")); err == nil {
+		t.Error("replayJournal() of garbage expected an error")
+	}
+
+	// A length past the end of the file is not trusted
+	corrupt := append([]byte(journalMagic+"issue 1\n"), journalBase, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff)
+	if _, content, err := replayJournal(corrupt); err != nil || content != nil {
+		t.Errorf("replayJournal() of a record longer than the file = %q, %v; want it ignored", content, err)
+	}
+}
+
+func TestRecoverJournal(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+	repo := "test/repo"
+	populateTestIssues(t, db, repo, []cache.Issue{
+		{Number: 1, Title: "First", Body: "Original body", State: "open"},
+		{Number: 2, Title: "Second", Body: "Body", State: "open"},
+	})
+
+	dir := filepath.Join(t.TempDir(), "journal")
+	j, err := OpenJournal(dir)
+	if err != nil {
+		t.Fatalf("OpenJournal() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// An edit left in an open file by a crash
+	edited := &issueFileNode{cache: db, repo: repo, number: 1, journal: j}
+	fh, _, _ := edited.Open(ctx, 0)
+	content := strings.Replace(string(fh.(*issueFileHandle).buffer), "Original body", "Edited body", 1)
+	truncate := &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{Valid: fuse.FATTR_SIZE}}
+	edited.Setattr(ctx, fh, truncate, &fuse.AttrOut{})
+	if _, errno := edited.Write(ctx, fh, []byte(content), 0); errno != 0 {
+		t.Fatalf("Write() errno = %v", errno)
+	}
+
+	// A flushed edit leaves nothing to recover
+	flushed := &issueFileNode{cache: db, repo: repo, number: 2, journal: j}
+	fh2, _, _ := flushed.Open(ctx, 0)
+	content2 := strings.Replace(string(fh2.(*issueFileHandle).buffer), "# Second", "# Second, renamed", 1)
+	flushed.Write(ctx, fh2, []byte(content2), 0)
+	if errno := flushed.Flush(ctx, fh2); errno != 0 {
+		t.Fatalf("Flush() errno = %v", errno)
+	}
+
+	// A new issue that was never saved
+	created := &newIssueFileNode{cache: db, repo: repo, title: "Created", journal: j}
+	fh3, _, _ := created.Open(ctx, 0)
+	created.Write(ctx, fh3, []byte("Details\n"), int64(len(fh3.(*newIssueFileHandle).buffer)))
+
+	// An edit that cannot be parsed is kept for the user
+	broken := &issueFileNode{cache: db, repo: repo, number: 2, journal: j}
+	fh4, _, _ := broken.Open(ctx, 0)
+	broken.Write(ctx, fh4, []byte("---\nlabels: [\n"), 0)
+
+	recovered, kept, err := RecoverJournal(j, db, repo)
+	if err != nil {
+		t.Fatalf("RecoverJournal() error = %v", err)
+	}
+	if recovered != 2 || len(kept) != 1 {
+		t.Fatalf("RecoverJournal() = %d, %v; want 2 recovered and 1 kept", recovered, kept)
+	}
+	if data, _ := os.ReadFile(kept[0]); !strings.HasPrefix(string(data), "---\nlabels: [\n") {
+		t.Errorf("kept file %s holds %q", kept[0], data)
+	}
+
+	issue, _ := db.GetIssue(repo, 1)
+	if !issue.Dirty || issue.Body != "Edited body" {
+		t.Errorf("issue #1 = %+v, expected the edit recovered", issue)
+	}
+	if pending, _ := db.GetPendingIssues(repo); len(pending) != 1 || pending[0].Title != "Created" {
+		t.Errorf("pending issues = %+v, expected Created", pending)
+	}
+	if wal, _ := filepath.Glob(filepath.Join(dir, "*.wal")); len(wal) != 0 {
+		t.Errorf("journal files left behind: %v", wal)
+	}
+
+	// Recovery runs once
+	if recovered, _, _ := RecoverJournal(j, db, repo); recovered != 0 {
+		t.Errorf("second RecoverJournal() recovered %d edits", recovered)
+	}
+}
@@ -0,0 +1,200 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// multiRootNode is the root directory of a multi-repo mount.
+// It holds one owner directory per repository owner, each containing
+// one directory per repository that behaves like a single-repo root.
+// Repositories can be added and removed while mounted.
+type multiRootNode struct {
+	fs.Inode
+
+	mu       sync.Mutex
+	repos    []Repo
+	slots    map[string]int // repo name -> inode range index
+	nextSlot int
+	added    bool // OnAdd has run, so repos have inodes
+	live     bool // served by a FUSE server, so the kernel must be told about changes
+}
+
+var _ = (fs.NodeOnAdder)((*multiRootNode)(nil))
+
+// newMultiRootNode creates a multi-repo root serving repos.
+func newMultiRootNode(repos []Repo) *multiRootNode {
+	m := &multiRootNode{slots: make(map[string]int)}
+	for _, repo := range repos {
+		m.slots[repo.Name] = m.nextSlot
+		m.nextSlot++
+		m.repos = append(m.repos, repo)
+	}
+	return m
+}
+
+// OnAdd builds the owner/repo directory tree once the root is mounted.
+func (m *multiRootNode) OnAdd(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.added = true
+	for _, repo := range m.repos {
+		m.addRepoInode(ctx, repo)
+	}
+}
+
+// addRepoInode creates the directory of repo, and its owner directory if needed.
+// It returns whether the owner directory was created. Callers hold m.mu.
+func (m *multiRootNode) addRepoInode(ctx context.Context, repo Repo) bool {
+	owner, name, _ := strings.Cut(repo.Name, "/")
+
+	newOwner := false
+	ownerDir := m.GetChild(owner)
+	if ownerDir == nil {
+		ownerDir = m.NewPersistentInode(ctx, &fs.Inode{}, fs.StableAttr{Mode: fuse.S_IFDIR})
+		m.AddChild(owner, ownerDir, false)
+		newOwner = true
+	}
+
+	root := &rootNode{
+		cache:           repo.Cache,
+		repo:            repo.Name,
+		onDirty:         repo.OnDirty,
+		statusProvider:  repo.StatusProvider,
+		refreshProvider: repo.RefreshProvider,
+		inoBase:         repoInoBase(m.slots[repo.Name]),
+		readOnly:        repo.ReadOnly,
+		showPulls:       !repo.HidePulls,
+		journal:         repo.Journal,
+	}
+	ownerDir.AddChild(name, m.NewPersistentInode(ctx, root, fs.StableAttr{Mode: fuse.S_IFDIR}), false)
+
+	if repo.StatusProvider != nil && m.GetChild(".status") == nil {
+		status := &statusFileNode{
+			render: m.generateStatusContent,
+			ino:    statusFileIno,
+		}
+		m.AddChild(".status", m.NewPersistentInode(ctx, status, fs.StableAttr{
+			Mode: fuse.S_IFREG,
+			Ino:  statusFileIno,
+		}), false)
+	}
+
+	return newOwner
+}
+
+// setLive records that the root is now served by a FUSE server.
+func (m *multiRootNode) setLive() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.live = true
+}
+
+// addRepo starts serving repo under its owner/repo directory.
+func (m *multiRootNode) addRepo(repo Repo) error {
+	m.mu.Lock()
+	if _, ok := m.slots[repo.Name]; ok {
+		m.mu.Unlock()
+		return fmt.Errorf("repository %s is already mounted", repo.Name)
+	}
+
+	// Slots are never reused so a re-added repo cannot inherit stale inodes
+	m.slots[repo.Name] = m.nextSlot
+	m.nextSlot++
+	m.repos = append(m.repos, repo)
+
+	if !m.added {
+		m.mu.Unlock()
+		return nil
+	}
+	newOwner := m.addRepoInode(context.Background(), repo)
+	live := m.live
+	m.mu.Unlock()
+
+	// Drop any negative lookups the kernel cached for the new names.
+	// Notifications are sent without holding m.mu, as the kernel may call back into us.
+	if live {
+		owner, name, _ := strings.Cut(repo.Name, "/")
+		if newOwner {
+			m.NotifyEntry(owner)
+		} else if ownerDir := m.GetChild(owner); ownerDir != nil {
+			ownerDir.NotifyEntry(name)
+		}
+	}
+	return nil
+}
+
+// removeRepo stops serving the repository with the given name.
+// The owner directory goes away with its last repository.
+func (m *multiRootNode) removeRepo(repoName string) error {
+	m.mu.Lock()
+	if _, ok := m.slots[repoName]; !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("repository %s is not mounted", repoName)
+	}
+
+	delete(m.slots, repoName)
+	for i, repo := range m.repos {
+		if repo.Name == repoName {
+			m.repos = append(m.repos[:i], m.repos[i+1:]...)
+			break
+		}
+	}
+
+	if !m.added {
+		m.mu.Unlock()
+		return nil
+	}
+
+	owner, name, _ := strings.Cut(repoName, "/")
+	ownerDir := m.GetChild(owner)
+	var repoDir *fs.Inode
+	ownerGone := false
+	if ownerDir != nil {
+		repoDir = ownerDir.GetChild(name)
+		ownerDir.RmChild(name)
+		if len(ownerDir.Children()) == 0 {
+			m.RmChild(owner)
+			ownerGone = true
+		}
+	}
+	live := m.live
+	m.mu.Unlock()
+
+	if live && ownerDir != nil {
+		if ownerGone {
+			m.NotifyDelete(owner, ownerDir)
+		} else if repoDir != nil {
+			ownerDir.NotifyDelete(name, repoDir)
+		}
+	}
+	return nil
+}
+
+// generateStatusContent generates the aggregated content for the top-level .status file.
+func (m *multiRootNode) generateStatusContent() string {
+	m.mu.Lock()
+	repos := append([]Repo(nil), m.repos...)
+	m.mu.Unlock()
+
+	statuses := make([]NamedStatus, 0, len(repos))
+	for _, repo := range repos {
+		if repo.StatusProvider == nil {
+			continue
+		}
+		statuses = append(statuses, NamedStatus{Repo: repo.Name, Status: repo.StatusProvider.GetStatus()})
+	}
+	return FormatMultiStatus(statuses)
+}
+
+// repoInoBase returns the inode offset for the i-th repository of a multi-repo mount.
+// Offsets start one range up so they never overlap a single-repo mount's inodes.
+func repoInoBase(i int) uint64 {
+	return uint64(i+1) << repoInoShift
+}
@@ -0,0 +1,203 @@
+package fs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/hanwen/go-fuse/v2/fs"
+)
+
+// fixedStatus is a StatusProvider returning a constant status.
+type fixedStatus SyncStatus
+
+func (s fixedStatus) GetStatus() SyncStatus { return SyncStatus(s) }
+
+func TestRepoInoBase_DisjointRanges(t *testing.T) {
+	// Single-repo mounts use inodes below 1<<repoInoShift, including .status
+	if repoInoBase(0) <= statusFileIno {
+		t.Errorf("repoInoBase(0) = %#x overlaps the single-repo range", repoInoBase(0))
+	}
+
+	for i := 0; i < 3; i++ {
+		lo, hi := repoInoBase(i), repoInoBase(i+1)
+		if lo+statusFileIno >= hi {
+			t.Errorf("repo %d inode range [%#x, %#x] overlaps repo %d", i, lo, lo+statusFileIno, i+1)
+		}
+	}
+}
+
+func TestFormatMultiStatus(t *testing.T) {
+	content := FormatMultiStatus([]NamedStatus{
+		{Repo: "org/a", Status: SyncStatus{DirtyIssues: 1, PendingComments: 2}},
+		{Repo: "org/b", Status: SyncStatus{DirtyIssues: 3, LastError: "422 Unprocessable Entity"}},
+	})
+
+	for _, want := range []string{
+		"Repositories: 2\n",
+		"Pending comments: 2\n",
+		"Dirty issues: 4\n",
+		"Repositories with errors: 1\n",
+		"## org/a\n",
+		"## org/b\n",
+		"Last error: 422 Unprocessable Entity\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("FormatMultiStatus() missing %q:\n%s", want, content)
+		}
+	}
+
+	if strings.Index(content, "## org/a") > strings.Index(content, "## org/b") {
+		t.Error("repositories should be listed in the given order")
+	}
+}
+
+func TestMultiRootNode_BuildsOwnerRepoTree(t *testing.T) {
+	dbA, _ := setupTestCache(t)
+	defer dbA.Close()
+	dbB, _ := setupTestCache(t)
+	defer dbB.Close()
+
+	// Same issue number in both repos
+	populateTestIssues(t, dbA, "org/a", []cache.Issue{{Number: 1, Title: "From A", State: "open"}})
+	populateTestIssues(t, dbB, "org/b", []cache.Issue{{Number: 1, Title: "From B", State: "open"}})
+
+	root := newMultiRootNode([]Repo{
+		{Name: "org/a", Cache: dbA, StatusProvider: fixedStatus{DirtyIssues: 1}},
+		{Name: "org/b", Cache: dbB, StatusProvider: fixedStatus{LastSyncTime: time.Now()}},
+		{Name: "other/c", Cache: dbB},
+	})
+	fs.NewNodeFS(root, &fs.Options{})
+
+	org := root.GetChild("org")
+	if org == nil {
+		t.Fatal("missing owner directory org")
+	}
+	if root.GetChild("other") == nil || root.GetChild("other").GetChild("c") == nil {
+		t.Error("missing other/c")
+	}
+
+	nodeA, ok := org.GetChild("a").Operations().(*rootNode)
+	if !ok {
+		t.Fatal("org/a should be a repo root")
+	}
+	nodeB, ok := org.GetChild("b").Operations().(*rootNode)
+	if !ok {
+		t.Fatal("org/b should be a repo root")
+	}
+
+	if nodeA.repo != "org/a" || nodeA.cache != dbA || nodeB.repo != "org/b" || nodeB.cache != dbB {
+		t.Error("repo roots are wired to the wrong repository")
+	}
+	if nodeA.inoBase == nodeB.inoBase {
+		t.Errorf("repos share inode base %#x", nodeA.inoBase)
+	}
+
+	// Issue #1 of each repo gets a distinct inode
+	inos := map[uint64]string{}
+	for _, node := range []*rootNode{nodeA, nodeB} {
+		stream, errno := node.Readdir(context.Background())
+		if errno != 0 {
+			t.Fatalf("Readdir(%s) errno = %v", node.repo, errno)
+		}
+		for stream.HasNext() {
+			entry, _ := stream.Next()
+			if other, dup := inos[entry.Ino]; dup {
+				t.Errorf("inode %#x used by %s and %s", entry.Ino, other, node.repo)
+			}
+			inos[entry.Ino] = node.repo
+		}
+	}
+
+	status, ok := root.GetChild(".status").Operations().(*statusFileNode)
+	if !ok {
+		t.Fatal("missing aggregated .status")
+	}
+	content := status.generateContent()
+	if !strings.Contains(content, "Repositories: 2\n") || !strings.Contains(content, "## org/a") {
+		t.Errorf("unexpected aggregated status:\n%s", content)
+	}
+}
+
+func TestNewMultiFS(t *testing.T) {
+	repos := []Repo{{Name: "org/a"}, {Name: "org/b"}}
+	fsys := NewMultiFS(repos, "/mnt/issues")
+
+	if fsys.mountpoint != "/mnt/issues" {
+		t.Errorf("mountpoint = %q, expected %q", fsys.mountpoint, "/mnt/issues")
+	}
+	if len(fsys.multi.repos) != 2 {
+		t.Errorf("expected 2 repos, got %d", len(fsys.multi.repos))
+	}
+}
+
+func TestMultiRootNode_AddRemoveRepo(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+
+	root := newMultiRootNode([]Repo{{Name: "org/a", Cache: db, StatusProvider: fixedStatus{}}})
+	fs.NewNodeFS(root, &fs.Options{})
+
+	if err := root.addRepo(Repo{Name: "org/b", Cache: db, StatusProvider: fixedStatus{}}); err != nil {
+		t.Fatalf("addRepo(org/b) error = %v", err)
+	}
+	if err := root.addRepo(Repo{Name: "org/b", Cache: db}); err == nil {
+		t.Error("expected an error adding org/b twice")
+	}
+	if err := root.addRepo(Repo{Name: "other/c", Cache: db}); err != nil {
+		t.Fatalf("addRepo(other/c) error = %v", err)
+	}
+
+	nodeB, ok := root.GetChild("org").GetChild("b").Operations().(*rootNode)
+	if !ok {
+		t.Fatal("org/b should be a repo root")
+	}
+	if nodeB.inoBase != repoInoBase(1) {
+		t.Errorf("org/b inoBase = %#x, expected %#x", nodeB.inoBase, repoInoBase(1))
+	}
+	if root.GetChild("other").GetChild("c") == nil {
+		t.Fatal("missing other/c")
+	}
+
+	if err := root.removeRepo("other/c"); err != nil {
+		t.Fatalf("removeRepo(other/c) error = %v", err)
+	}
+	if root.GetChild("other") != nil {
+		t.Error("owner directory should go away with its last repository")
+	}
+	if err := root.removeRepo("org/a"); err != nil {
+		t.Fatalf("removeRepo(org/a) error = %v", err)
+	}
+	if root.GetChild("org").GetChild("a") != nil || root.GetChild("org").GetChild("b") == nil {
+		t.Error("only org/a should have been removed")
+	}
+	if err := root.removeRepo("org/a"); err == nil {
+		t.Error("expected an error removing org/a twice")
+	}
+
+	// A re-added repository gets a fresh inode range
+	if err := root.addRepo(Repo{Name: "org/a", Cache: db}); err != nil {
+		t.Fatalf("addRepo(org/a) error = %v", err)
+	}
+	nodeA := root.GetChild("org").GetChild("a").Operations().(*rootNode)
+	if nodeA.inoBase == repoInoBase(0) {
+		t.Error("re-added repository reused its old inode range")
+	}
+
+	content := root.GetChild(".status").Operations().(*statusFileNode).generateContent()
+	if !strings.Contains(content, "## org/b") || strings.Contains(content, "## other/c") {
+		t.Errorf("aggregated status does not follow the served repositories:\n%s", content)
+	}
+}
+
+func TestFS_AddRepoRequiresMultiRepoMount(t *testing.T) {
+	fsys := NewFS(nil, "owner/repo", "/mnt/issues", nil, nil, nil)
+	if err := fsys.AddRepo(Repo{Name: "org/a"}); err == nil {
+		t.Error("expected AddRepo to fail on a single-repo mount")
+	}
+	if err := fsys.RemoveRepo("owner/repo"); err == nil {
+		t.Error("expected RemoveRepo to fail on a single-repo mount")
+	}
+}
@@ -0,0 +1,183 @@
+package fs
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/JohanCodinha/ghissues/internal/logger"
+	"github.com/JohanCodinha/ghissues/internal/md"
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// pullsDirName is the directory pull requests are served from.
+const pullsDirName = "pulls"
+
+// pullsDirNode is the directory of pull requests. Its files are read-only:
+// pull requests are browsed, not edited.
+type pullsDirNode struct {
+	fs.Inode
+	cache           *cache.DB
+	repo            string
+	refreshProvider RefreshProvider
+	inoBase         uint64
+}
+
+var _ = (fs.NodeReaddirer)((*pullsDirNode)(nil))
+var _ = (fs.NodeLookuper)((*pullsDirNode)(nil))
+
+// Readdir lists the cached pull requests.
+func (d *pullsDirNode) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	pulls, err := d.cache.ListPulls(d.repo)
+	if err != nil {
+		logger.Warn("fuse: failed to list pull requests: %v", err)
+		return nil, syscall.EIO
+	}
+
+	entries := make([]fuse.DirEntry, 0, len(pulls))
+	for _, pull := range pulls {
+		entries = append(entries, fuse.DirEntry{
+			Name: makeFilename(pull.Title, pull.Number),
+			Ino:  d.inoBase + uint64(pull.Number),
+			Mode: fuse.S_IFREG,
+		})
+	}
+	return fs.NewListDirStream(entries), 0
+}
+
+// Lookup finds a pull request file by name.
+func (d *pullsDirNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	number, ok := parseFilename(name)
+	if !ok {
+		return nil, syscall.ENOENT
+	}
+
+	// Trigger background refresh (non-blocking)
+	if d.refreshProvider != nil {
+		d.refreshProvider.TriggerRefresh(number)
+	}
+
+	pull, content, errno := renderPull(d.cache, d.repo, number)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	out.Mode = 0444
+	out.Size = uint64(len(content))
+	out.Ino = d.inoBase + uint64(number)
+	mtime := parseIssueTime(pull.UpdatedAt)
+	ctime := parseIssueTime(pull.CreatedAt)
+	out.SetTimes(&mtime, &mtime, &ctime)
+
+	fileNode := &pullFileNode{
+		cache:   d.cache,
+		repo:    d.repo,
+		number:  number,
+		inoBase: d.inoBase,
+	}
+	return d.NewInode(ctx, fileNode, fs.StableAttr{
+		Mode: fuse.S_IFREG,
+		Ino:  d.inoBase + uint64(number),
+	}), 0
+}
+
+// renderPull renders a cached pull request with its comments and review
+// comments. It answers ENOENT when number is not a cached pull request.
+func renderPull(db *cache.DB, repo string, number int) (*cache.Issue, string, syscall.Errno) {
+	pull, err := db.GetIssue(repo, number)
+	if err != nil {
+		logger.Warn("fuse: failed to get pull request #%d from cache: %v", number, err)
+		return nil, "", syscall.EIO
+	}
+	if pull == nil || pull.Kind != cache.KindPull {
+		return nil, "", syscall.ENOENT
+	}
+
+	comments, err := db.GetComments(repo, number)
+	if err != nil {
+		logger.Debug("fuse: failed to get comments for pull request #%d: %v", number, err)
+		comments = []cache.Comment{}
+	}
+	reviewComments, err := db.GetReviewComments(repo, number)
+	if err != nil {
+		logger.Debug("fuse: failed to get review comments for pull request #%d: %v", number, err)
+		reviewComments = []cache.ReviewComment{}
+	}
+
+	return pull, md.PullToMarkdown(pull, comments, reviewComments), 0
+}
+
+// pullFileNode is a read-only file holding a pull request.
+type pullFileNode struct {
+	fs.Inode
+	cache   *cache.DB
+	repo    string
+	number  int
+	inoBase uint64
+}
+
+var _ = (fs.NodeGetattrer)((*pullFileNode)(nil))
+var _ = (fs.NodeOpener)((*pullFileNode)(nil))
+var _ = (fs.NodeReader)((*pullFileNode)(nil))
+
+// Getattr returns file attributes.
+func (f *pullFileNode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	pull, content, errno := renderPull(f.cache, f.repo, f.number)
+	if errno != 0 {
+		return errno
+	}
+
+	out.Mode = 0444
+	out.Size = uint64(len(content))
+	out.Ino = f.inoBase + uint64(f.number)
+	mtime := parseIssueTime(pull.UpdatedAt)
+	ctime := parseIssueTime(pull.CreatedAt)
+	out.SetTimes(&mtime, &mtime, &ctime)
+	return 0
+}
+
+// Open opens the pull request for reading.
+func (f *pullFileNode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	// Reject write attempts
+	if flags&(syscall.O_WRONLY|syscall.O_RDWR|syscall.O_TRUNC) != 0 {
+		return nil, 0, syscall.EACCES
+	}
+
+	_, content, errno := renderPull(f.cache, f.repo, f.number)
+	if errno != 0 {
+		return nil, 0, errno
+	}
+	return &pullFileHandle{content: []byte(content)}, fuse.FOPEN_DIRECT_IO, 0
+}
+
+// Read reads the pull request content.
+func (f *pullFileNode) Read(ctx context.Context, fh fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	var content []byte
+	if handle, ok := fh.(*pullFileHandle); ok {
+		content = handle.content
+	} else {
+		// No handle, render directly from cache
+		_, rendered, errno := renderPull(f.cache, f.repo, f.number)
+		if errno != 0 {
+			return nil, errno
+		}
+		content = []byte(rendered)
+	}
+
+	if off >= int64(len(content)) {
+		return fuse.ReadResultData(nil), 0
+	}
+	end := off + int64(len(dest))
+	if end > int64(len(content)) {
+		end = int64(len(content))
+	}
+	return fuse.ReadResultData(content[off:end]), 0
+}
+
+// pullFileHandle holds the content of an open pull request file.
+type pullFileHandle struct {
+	content []byte
+}
+
+var _ = (fs.FileHandle)((*pullFileHandle)(nil))
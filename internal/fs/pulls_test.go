@@ -0,0 +1,159 @@
+package fs
+
+import (
+	"context"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/JohanCodinha/ghissues/internal/cache"
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// populateTestPulls adds an issue and a pull request with a review comment.
+func populateTestPulls(t *testing.T, db *cache.DB, repo string) {
+	t.Helper()
+
+	populateTestIssues(t, db, repo, []cache.Issue{
+		{Number: 1, Title: "An Issue", Body: "Issue body", State: "open"},
+		{Number: 2, Title: "Add feature", Body: "PR body", State: "open", Kind: cache.KindPull,
+			HeadRef: "feature", BaseRef: "main", MergeState: "mergeable"},
+	})
+	err := db.UpsertReviewComments(repo, 2, []cache.ReviewComment{
+		{ID: 7, Author: "bob", Path: "main.go", Line: 12, Body: "Nit: rename this", CreatedAt: "2024-01-15T10:00:00Z"},
+	})
+	if err != nil {
+		t.Fatalf("failed to upsert review comments: %v", err)
+	}
+}
+
+// readDirNames collects the names and modes of a directory listing.
+func readDirNames(t *testing.T, stream fs.DirStream) map[string]uint32 {
+	t.Helper()
+
+	names := map[string]uint32{}
+	for stream.HasNext() {
+		entry, errno := stream.Next()
+		if errno != 0 {
+			t.Fatalf("DirStream.Next returned error: %v", errno)
+		}
+		names[entry.Name] = entry.Mode
+	}
+	return names
+}
+
+func TestRootNode_Readdir_Pulls(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+	repo := "test/repo"
+	populateTestPulls(t, db, repo)
+
+	tests := []struct {
+		name      string
+		showPulls bool
+		want      map[string]uint32
+	}{
+		{"shown", true, map[string]uint32{"an-issue[1].md": fuse.S_IFREG, pullsDirName: fuse.S_IFDIR}},
+		{"hidden", false, map[string]uint32{"an-issue[1].md": fuse.S_IFREG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &rootNode{cache: db, repo: repo, showPulls: tt.showPulls}
+			stream, errno := root.Readdir(context.Background())
+			if errno != 0 {
+				t.Fatalf("Readdir returned error: %v", errno)
+			}
+			got := readDirNames(t, stream)
+			if len(got) != len(tt.want) {
+				t.Errorf("Readdir = %v, want %v", got, tt.want)
+			}
+			for name, mode := range tt.want {
+				if got[name] != mode {
+					t.Errorf("entry %s mode = %v, want %v", name, got[name], mode)
+				}
+			}
+		})
+	}
+}
+
+func TestRootNode_Lookup_PullIsHidden(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+	repo := "test/repo"
+	populateTestPulls(t, db, repo)
+
+	root := &rootNode{cache: db, repo: repo, showPulls: true}
+	if _, errno := root.Lookup(context.Background(), "add-feature[2].md", &fuse.EntryOut{}); errno != syscall.ENOENT {
+		t.Errorf("Lookup of a pull request in the root = %v, want ENOENT", errno)
+	}
+}
+
+func TestPullsDirNode_Readdir(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+	repo := "test/repo"
+	populateTestPulls(t, db, repo)
+
+	dir := &pullsDirNode{cache: db, repo: repo}
+	stream, errno := dir.Readdir(context.Background())
+	if errno != 0 {
+		t.Fatalf("Readdir returned error: %v", errno)
+	}
+	got := readDirNames(t, stream)
+	if len(got) != 1 || got["add-feature[2].md"] != fuse.S_IFREG {
+		t.Errorf("Readdir = %v, want only add-feature[2].md", got)
+	}
+
+	// Issues are not in the pulls directory
+	if _, errno := dir.Lookup(context.Background(), "an-issue[1].md", &fuse.EntryOut{}); errno != syscall.ENOENT {
+		t.Errorf("Lookup of an issue in the pulls directory = %v, want ENOENT", errno)
+	}
+}
+
+func TestPullFileNode_ReadOnly(t *testing.T) {
+	db, _ := setupTestCache(t)
+	defer db.Close()
+	repo := "test/repo"
+	populateTestPulls(t, db, repo)
+
+	ctx := context.Background()
+	file := &pullFileNode{cache: db, repo: repo, number: 2}
+
+	var attr fuse.AttrOut
+	if errno := file.Getattr(ctx, nil, &attr); errno != 0 {
+		t.Fatalf("Getattr returned error: %v", errno)
+	}
+	if attr.Mode != 0444 || attr.Size == 0 {
+		t.Errorf("Getattr mode = %o size = %d, want 0444 and the content size", attr.Mode, attr.Size)
+	}
+
+	for _, flags := range []uint32{syscall.O_WRONLY, syscall.O_RDWR, syscall.O_TRUNC} {
+		if _, _, errno := file.Open(ctx, flags); errno != syscall.EACCES {
+			t.Errorf("Open(%#x) = %v, want EACCES", flags, errno)
+		}
+	}
+
+	fh, _, errno := file.Open(ctx, syscall.O_RDONLY)
+	if errno != 0 {
+		t.Fatalf("Open for reading returned error: %v", errno)
+	}
+	result, errno := file.Read(ctx, fh, make([]byte, 4096), 0)
+	if errno != 0 {
+		t.Fatalf("Read returned error: %v", errno)
+	}
+	data, _ := result.Bytes(nil)
+	content := string(data)
+	for _, want := range []string{"head: feature", "base: main", "merge_state: mergeable", "PR body", "## Review comments", "bob on main.go:12", "Nit: rename this"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content missing %q:\n%s", want, content)
+		}
+	}
+
+	// An issue is not a pull request
+	file.number = 1
+	if errno := file.Getattr(ctx, nil, &attr); errno != syscall.ENOENT {
+		t.Errorf("Getattr of an issue = %v, want ENOENT", errno)
+	}
+}
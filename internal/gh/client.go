@@ -3,14 +3,18 @@ package gh
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -23,8 +27,19 @@ const (
 	apiBaseURL = "https://api.github.com"
 )
 
-// sleepFunc is the function used for sleeping. It can be replaced in tests.
-var sleepFunc = time.Sleep
+// sleepFunc is the function used for sleeping. It returns early when ctx is
+// cancelled. It can be replaced in tests.
+var sleepFunc = sleepContext
+
+// sleepContext sleeps for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
 
 // Label represents a GitHub issue label.
 type Label struct {
@@ -53,11 +68,25 @@ type Issue struct {
 	State            string            `json:"state"`
 	Labels           []Label           `json:"labels"`
 	User             User              `json:"user"`
+	Assignees        []User            `json:"assignees,omitempty"`
 	CreatedAt        time.Time         `json:"created_at"`
 	UpdatedAt        time.Time         `json:"updated_at"`
+	HTMLURL          string            `json:"html_url,omitempty"`
 	ETag             string            `json:"-"` // Not from JSON, set from response header
 	ParentIssueURL   string            `json:"parent_issue_url,omitempty"`
 	SubIssuesSummary *SubIssuesSummary `json:"sub_issues_summary,omitempty"`
+	PullRequest      *PullRequestRef   `json:"pull_request,omitempty"` // set when the issue is a pull request
+}
+
+// PullRequestRef links an issue of the REST API to its pull request.
+type PullRequestRef struct {
+	URL string `json:"url"`
+}
+
+// IsPullRequest reports whether the REST API listed a pull request as this
+// issue.
+func (i *Issue) IsPullRequest() bool {
+	return i.PullRequest != nil
 }
 
 // Comment represents a GitHub issue comment.
@@ -67,13 +96,45 @@ type Comment struct {
 	Body      string    `json:"body"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	IssueURL  string    `json:"issue_url,omitempty"` // API URL of the issue commented on
+}
+
+// Repository represents a GitHub repository.
+type Repository struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	FullName  string `json:"full_name"`
+	HasIssues bool   `json:"has_issues"`
+	Archived  bool   `json:"archived"`
+	Disabled  bool   `json:"disabled"`
+
+	// Permissions of the authenticated user, only set when fetching a
+	// single repository
+	Permissions *RepoPermissions `json:"permissions,omitempty"`
+}
+
+// RepoPermissions are the authenticated user's permissions on a repository.
+type RepoPermissions struct {
+	Admin    bool `json:"admin"`
+	Maintain bool `json:"maintain"`
+	Push     bool `json:"push"`
+	Triage   bool `json:"triage"`
+	Pull     bool `json:"pull"`
+}
+
+// CanEditIssues reports whether the permissions allow editing issues
+// opened by others.
+func (p *RepoPermissions) CanEditIssues() bool {
+	return p.Admin || p.Maintain || p.Push || p.Triage
 }
 
 // Client is a GitHub API client.
 type Client struct {
-	token      string
+	creds      CredentialProvider
 	baseURL    string
 	httpClient *http.Client
+	responses  *cachingTransport
+	retry      RetryPolicy
 }
 
 // ghHostsConfig represents the structure of ~/.config/gh/hosts.yml
@@ -86,48 +147,131 @@ type ghHost struct {
 
 // New creates a new GitHub API client with the given token.
 func New(token string) *Client {
-	return &Client{
-		token:      token,
-		baseURL:    apiBaseURL,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-	}
+	return NewWithBaseURL(token, apiBaseURL)
+}
+
+// NewForHost creates a GitHub API client for host, github.com or the
+// hostname of a GitHub Enterprise Server.
+func NewForHost(token, host string) *Client {
+	return NewWithBaseURL(token, APIBaseURL(host))
 }
 
 // NewWithBaseURL creates a GitHub API client with a custom base URL (for testing).
 func NewWithBaseURL(token, baseURL string) *Client {
+	responses := newCachingTransport(http.DefaultTransport)
 	return &Client{
-		token:      token,
+		creds:      StaticToken(token),
 		baseURL:    baseURL,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: responses},
+		responses:  responses,
+		retry:      DefaultRetryPolicy,
 	}
 }
 
-// GetToken attempts to get a GitHub token from various sources:
+// SetCredentials makes the client authenticate with creds instead of the
+// token it was created with.
+func (c *Client) SetCredentials(creds CredentialProvider) {
+	c.creds = creds
+}
+
+// SetResponseCache makes GET requests about repo ("owner/repo") conditional
+// on the responses stored in cache, and answers them from it when GitHub has
+// nothing new. A nil cache stops caching the responses of repo.
+func (c *Client) SetResponseCache(repo string, cache ResponseCache) {
+	c.responses.setCache(repo, cache)
+}
+
+// SetRetryPolicy sets how the client retries requests that fail
+// transiently.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// Sources a token can come from, as reported by GetTokenWithSource.
+const (
+	TokenSourceGhCLI         = "gh auth token"
+	TokenSourceGhConfig      = "gh hosts.yml"
+	TokenSourceEnv           = "GITHUB_TOKEN"
+	TokenSourceEnterpriseEnv = "GH_ENTERPRISE_TOKEN"
+)
+
+// GetToken attempts to get a github.com token from various sources:
 // 1. Run `gh auth token` command (gh CLI with keyring storage)
 // 2. Read from ~/.config/gh/hosts.yml (older gh CLI format)
 // 3. GITHUB_TOKEN environment variable
 func GetToken() (string, error) {
-	// Try gh auth token command first (handles keyring storage)
-	if token, err := getTokenFromGhCLI(); err == nil && token != "" {
-		return token, nil
+	token, _, err := GetTokenWithSource()
+	return token, err
+}
+
+// GetTokenWithSource is GetToken, also returning which source the token
+// came from (one of the TokenSource constants).
+func GetTokenWithSource() (token, source string, err error) {
+	return GetTokenForHost(DefaultHost)
+}
+
+// GetTokenForHost is GetTokenWithSource for host. A GitHub Enterprise
+// Server token comes from gh for that hostname, or from the
+// GH_ENTERPRISE_TOKEN environment variable instead of GITHUB_TOKEN.
+func GetTokenForHost(host string) (token, source string, err error) {
+	creds, source, err := CredentialsForHost(host)
+	if err != nil {
+		return "", "", err
+	}
+	token, err = creds.Token(context.Background())
+	return token, source, err
+}
+
+// TokenEnv returns the environment variable holding the token of host.
+func TokenEnv(host string) string {
+	if host == DefaultHost {
+		return TokenSourceEnv
+	}
+	return TokenSourceEnterpriseEnv
+}
+
+// GhLogin is a host the gh CLI is logged into.
+type GhLogin struct {
+	Host string
+	User string
+}
+
+// GhLogins lists the hosts the gh CLI is logged into, from its hosts.yml.
+// It returns no logins if gh was never logged in.
+func GhLogins() ([]GhLogin, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
+	return ghLoginsFromPath(filepath.Join(homeDir, ".config", "gh", "hosts.yml"))
+}
 
-	// Try reading from gh hosts.yml config (older format)
-	if token, err := getTokenFromGhConfig(); err == nil && token != "" {
-		return token, nil
+// ghLoginsFromPath reads the logins of the hosts.yml at configPath.
+func ghLoginsFromPath(configPath string) ([]GhLogin, error) {
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gh config: %w", err)
 	}
 
-	// Fall back to GITHUB_TOKEN env var
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		return token, nil
+	var config ghHostsConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse gh config: %w", err)
 	}
 
-	return "", fmt.Errorf("no GitHub token found: install gh CLI and run 'gh auth login', or set GITHUB_TOKEN env var")
+	logins := make([]GhLogin, 0, len(config))
+	for host, h := range config {
+		logins = append(logins, GhLogin{Host: host, User: h.User})
+	}
+	sort.Slice(logins, func(i, j int) bool { return logins[i].Host < logins[j].Host })
+	return logins, nil
 }
 
-// getTokenFromGhCLI runs `gh auth token` to get the token from the gh CLI.
-func getTokenFromGhCLI() (string, error) {
-	cmd := exec.Command("gh", "auth", "token")
+// getTokenFromGhCLI runs `gh auth token` to get the token of host from the gh CLI.
+func getTokenFromGhCLI(ctx context.Context, host string) (string, error) {
+	cmd := exec.CommandContext(ctx, "gh", "auth", "token", "--hostname", host)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("gh auth token failed: %w", err)
@@ -135,20 +279,20 @@ func getTokenFromGhCLI() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// getTokenFromGhConfig reads the token from ~/.config/gh/hosts.yml.
-func getTokenFromGhConfig() (string, error) {
+// getTokenFromGhConfig reads the token of host from ~/.config/gh/hosts.yml.
+func getTokenFromGhConfig(host string) (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
 
 	configPath := filepath.Join(homeDir, ".config", "gh", "hosts.yml")
-	return getTokenFromGhConfigPath(configPath)
+	return getTokenFromGhConfigPath(configPath, host)
 }
 
-// getTokenFromGhConfigPath reads the token from the specified hosts.yml path.
+// getTokenFromGhConfigPath reads the token of host from the specified hosts.yml path.
 // This is split out from getTokenFromGhConfig for testability.
-func getTokenFromGhConfigPath(configPath string) (string, error) {
+func getTokenFromGhConfigPath(configPath, host string) (string, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read gh config: %w", err)
@@ -159,19 +303,25 @@ func getTokenFromGhConfigPath(configPath string) (string, error) {
 		return "", fmt.Errorf("failed to parse gh config: %w", err)
 	}
 
-	// Look for github.com host
-	if host, ok := config["github.com"]; ok {
-		if host.OAuthToken != "" {
-			return host.OAuthToken, nil
+	if h, ok := config[host]; ok {
+		if h.OAuthToken != "" {
+			return h.OAuthToken, nil
 		}
 	}
 
-	return "", fmt.Errorf("no oauth_token found in gh config")
+	return "", fmt.Errorf("no oauth_token found for %s in gh config", host)
 }
 
 // doRequest performs an HTTP request with authentication and returns the response.
-// Handles 429 rate limit responses by sleeping until reset time and retrying.
-func (c *Client) doRequest(method, url string, body io.Reader) (*http.Response, error) {
+// Handles 429 rate limit responses by sleeping until reset time and retrying,
+// and retries a 401 once if the credentials can renew the rejected token.
+// Cancelling ctx aborts the request, including a rate limit wait.
+func (c *Client) doRequest(ctx context.Context, method, url string, body io.Reader) (*http.Response, error) {
+	return c.doRequestWithHeader(ctx, method, url, body, nil)
+}
+
+// doRequestWithHeader is doRequest, also sending header.
+func (c *Client) doRequestWithHeader(ctx context.Context, method, url string, body io.Reader, header http.Header) (*http.Response, error) {
 	// If body is a bytes.Reader, we can retry by seeking back to start
 	var bodyBytes []byte
 	if body != nil {
@@ -182,20 +332,29 @@ func (c *Client) doRequest(method, url string, body io.Reader) (*http.Response,
 		}
 	}
 
-	for {
+	idempotent := isIdempotent(method, url)
+	renewed := false
+	for attempt := 1; ; attempt++ {
 		var reqBody io.Reader
 		if bodyBytes != nil {
 			reqBody = bytes.NewReader(bodyBytes)
 		}
 
-		req, err := http.NewRequest(method, url, reqBody)
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 
-		req.Header.Set("Authorization", "Bearer "+c.token)
+		token, err := c.creds.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credentials: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
 		req.Header.Set("Accept", "application/vnd.github+json")
 		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		for key, values := range header {
+			req.Header[key] = values
+		}
 
 		if bodyBytes != nil {
 			req.Header.Set("Content-Type", "application/json")
@@ -203,28 +362,78 @@ func (c *Client) doRequest(method, url string, body io.Reader) (*http.Response,
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			// A dropped connection may have been handled, so only
+			// requests that are safe to repeat are sent again
+			if idempotent && isConnectionReset(err) && ctx.Err() == nil && c.retry.canRetry(attempt) {
+				wait := c.retry.backoff(attempt)
+				logger.Warn("gh: %s %s failed, retrying in %v: %v", method, req.URL.Path, wait, err)
+				if err := c.wait(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			return nil, fmt.Errorf("request failed: %w", err)
 		}
 
-		// Handle rate limiting - sleep and retry
-		if resp.StatusCode == http.StatusTooManyRequests {
+		// Rate limited requests were not handled: wait and retry,
+		// whatever the method
+		if (resp.StatusCode == http.StatusTooManyRequests || isSecondaryRateLimit(resp)) && c.retry.canRetry(attempt) {
 			resp.Body.Close()
-			if checkRateLimit(resp) {
-				continue // Retry after sleeping
+			if wait, ok := retryAfter(resp); ok {
+				logger.Warn("rate limited, retrying in %v", wait)
+				sleepFunc(ctx, wait)
+			} else if !checkRateLimit(ctx, resp) {
+				// If checkRateLimit didn't sleep (no valid reset header), wait 60s
+				logger.Warn("rate limited without reset header, waiting 60s")
+				sleepFunc(ctx, secondaryRateLimitWait)
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("request cancelled while rate limited: %w", err)
+			}
+			continue // Retry after sleeping
+		}
+
+		if isGatewayError(resp.StatusCode) && idempotent && c.retry.canRetry(attempt) {
+			resp.Body.Close()
+			wait, ok := retryAfter(resp)
+			if !ok {
+				wait = c.retry.backoff(attempt)
+			}
+			logger.Warn("gh: %s %s answered %d, retrying in %v", method, req.URL.Path, resp.StatusCode, wait)
+			if err := c.wait(ctx, wait); err != nil {
+				return nil, err
 			}
-			// If checkRateLimit didn't sleep (no valid reset header), wait 60s
-			logger.Warn("rate limited without reset header, waiting 60s")
-			sleepFunc(60 * time.Second)
 			continue
 		}
 
+		// The token may have expired or been revoked: retry once with a new one
+		if resp.StatusCode == http.StatusUnauthorized && !renewed {
+			c.creds.Invalidate(token)
+			if fresh, err := c.creds.Token(ctx); err == nil && fresh != token {
+				resp.Body.Close()
+				renewed = true
+				logger.Debug("gh: token rejected, retrying with a renewed one")
+				continue
+			}
+		}
+
 		return resp, nil
 	}
 }
 
-// checkRateLimit checks rate limit headers and sleeps if rate limited.
-// Returns true if we were rate limited and slept (caller should retry).
-func checkRateLimit(resp *http.Response) bool {
+// wait sleeps for d before a retry, failing when ctx is cancelled first.
+func (c *Client) wait(ctx context.Context, d time.Duration) error {
+	sleepFunc(ctx, d)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("request cancelled while waiting to retry: %w", err)
+	}
+	return nil
+}
+
+// checkRateLimit checks rate limit headers and sleeps if rate limited, or
+// until ctx is cancelled. Returns true if we were rate limited and slept
+// (caller should retry).
+func checkRateLimit(ctx context.Context, resp *http.Response) bool {
 	remaining := resp.Header.Get("X-RateLimit-Remaining")
 	reset := resp.Header.Get("X-RateLimit-Reset")
 
@@ -235,7 +444,7 @@ func checkRateLimit(resp *http.Response) bool {
 			sleepDuration := time.Until(resetAt)
 			if sleepDuration > 0 {
 				logger.Warn("rate limited, sleeping %v until %s", sleepDuration, resetAt.Format(time.RFC3339))
-				sleepFunc(sleepDuration)
+				sleepFunc(ctx, sleepDuration)
 				return true
 			}
 		}
@@ -243,19 +452,114 @@ func checkRateLimit(resp *http.Response) bool {
 	return false
 }
 
-// ListIssues fetches all open issues from the repository.
+// IsNetworkError reports whether err comes from failing to reach GitHub,
+// as opposed to an error response from GitHub or a cancelled request.
+func IsNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Ping checks that GitHub can be reached. Any response counts, so it also
+// succeeds with a rejected token.
+func (c *Client) Ping(ctx context.Context) error {
+	resp, err := c.doRequest(ctx, http.MethodGet, c.baseURL+"/rate_limit", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// TokenInfo describes the token a client authenticates with.
+type TokenInfo struct {
+	Login string
+	// Scopes are the OAuth scopes of a classic token. Fine-grained tokens
+	// and GitHub App tokens do not list scopes, which ScopesKnown reports.
+	Scopes      []string
+	ScopesKnown bool
+}
+
+// HasScope reports whether a classic token has scope.
+func (t *TokenInfo) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenInfo returns who the token belongs to and, for classic tokens, its
+// scopes as listed in the X-OAuth-Scopes response header.
+func (c *Client) TokenInfo(ctx context.Context) (*TokenInfo, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, c.baseURL+"/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitHub API error: %d %s", resp.StatusCode, string(body))
+	}
+
+	var user User
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("failed to decode user: %w", err)
+	}
+
+	info := &TokenInfo{Login: user.Login}
+	if header, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		info.ScopesKnown = true
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+	}
+	return info, nil
+}
+
+// ListIssues fetches all issues, open and closed, from the repository.
+// Handles pagination automatically.
+func (c *Client) ListIssues(ctx context.Context, owner, repo string) ([]Issue, error) {
+	return c.ListIssuesMatching(ctx, owner, repo, IssueFilter{})
+}
+
+// ListIssuesMatching fetches the issues of the repository that pass filter.
 // Handles pagination automatically.
-func (c *Client) ListIssues(owner, repo string) ([]Issue, error) {
+func (c *Client) ListIssuesMatching(ctx context.Context, owner, repo string, filter IssueFilter) ([]Issue, error) {
+	query, err := filter.query(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query.Set("per_page", "100")
+	return c.listIssues(ctx, owner, repo, query.Encode())
+}
+
+// ListIssuesSince fetches the issues of the repository, open and closed,
+// updated at or after since, least recently updated first.
+func (c *Client) ListIssuesSince(ctx context.Context, owner, repo string, since time.Time) ([]Issue, error) {
+	query := fmt.Sprintf("state=all&sort=updated&direction=asc&per_page=100&since=%s", since.UTC().Format(time.RFC3339))
+	return c.listIssues(ctx, owner, repo, query)
+}
+
+// listIssues fetches every page of the issues of the repository matching
+// the encoded query.
+func (c *Client) listIssues(ctx context.Context, owner, repo, query string) ([]Issue, error) {
 	var allIssues []Issue
-	url := fmt.Sprintf("%s/repos/%s/%s/issues?state=all&per_page=100", c.baseURL, owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/issues?%s", c.baseURL, owner, repo, query)
 
 	for url != "" {
-		resp, err := c.doRequest("GET", url, nil)
+		resp, err := c.doRequest(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list issues for %s/%s: %w", owner, repo, err)
 		}
 
-		checkRateLimit(resp)
+		checkRateLimit(ctx, resp)
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
@@ -298,18 +602,72 @@ func getNextPageURL(linkHeader string) string {
 	return ""
 }
 
+// ListOwnerRepos fetches all repositories of an organization.
+// If owner is not an organization, the user's own repositories are listed instead.
+func (c *Client) ListOwnerRepos(ctx context.Context, owner string) ([]Repository, error) {
+	repos, err := c.listRepos(ctx, fmt.Sprintf("%s/orgs/%s/repos?type=all&per_page=100", c.baseURL, owner), owner)
+	if errors.Is(err, errNotFound) {
+		repos, err = c.listRepos(ctx, fmt.Sprintf("%s/users/%s/repos?type=owner&per_page=100", c.baseURL, owner), owner)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// errNotFound is returned by listRepos when the owner does not exist.
+var errNotFound = errors.New("not found")
+
+// listRepos fetches every page of a repository listing starting at url.
+func (c *Client) listRepos(ctx context.Context, url, owner string) ([]Repository, error) {
+	var allRepos []Repository
+
+	for url != "" {
+		resp, err := c.doRequest(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories for %s: %w", owner, err)
+		}
+
+		checkRateLimit(ctx, resp)
+
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list repositories for %s: %w", owner, errNotFound)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list repositories for %s: API error %s - %s", owner, resp.Status, string(body))
+		}
+
+		var repos []Repository
+		if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decode repositories response for %s: %w", owner, err)
+		}
+
+		url = getNextPageURL(resp.Header.Get("Link"))
+		resp.Body.Close()
+
+		allRepos = append(allRepos, repos...)
+	}
+
+	return allRepos, nil
+}
+
 // GetIssue fetches a single issue by number.
 // Returns the issue, the ETag header value, and any error.
-func (c *Client) GetIssue(owner, repo string, number int) (*Issue, string, error) {
+func (c *Client) GetIssue(ctx context.Context, owner, repo string, number int) (*Issue, string, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", c.baseURL, owner, repo, number)
 
-	resp, err := c.doRequest("GET", url, nil)
+	resp, err := c.doRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get issue #%d for %s/%s: %w", number, owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -327,6 +685,31 @@ func (c *Client) GetIssue(owner, repo string, number int) (*Issue, string, error
 	return &issue, etag, nil
 }
 
+// GetRepository fetches a repository, including the authenticated user's
+// permissions on it.
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*Repository, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s", c.baseURL, owner, repo)
+
+	resp, err := c.doRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository %s/%s: %w", owner, repo, err)
+	}
+	defer resp.Body.Close()
+
+	checkRateLimit(ctx, resp)
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get repository %s/%s: API error %s - %s", owner, repo, resp.Status, string(body))
+	}
+
+	var repository Repository
+	if err := json.NewDecoder(resp.Body).Decode(&repository); err != nil {
+		return nil, fmt.Errorf("failed to decode repository %s/%s: %w", owner, repo, err)
+	}
+	return &repository, nil
+}
+
 // IssueUpdate contains optional fields for updating an issue.
 // Nil fields are not included in the update request.
 type IssueUpdate struct {
@@ -338,7 +721,7 @@ type IssueUpdate struct {
 
 // UpdateIssue updates an issue's fields.
 // Only non-nil fields in the update struct are sent to GitHub.
-func (c *Client) UpdateIssue(owner, repo string, number int, update IssueUpdate) error {
+func (c *Client) UpdateIssue(ctx context.Context, owner, repo string, number int, update IssueUpdate) error {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", c.baseURL, owner, repo, number)
 
 	payload := make(map[string]interface{})
@@ -360,13 +743,13 @@ func (c *Client) UpdateIssue(owner, repo string, number int, update IssueUpdate)
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := c.doRequest("PATCH", url, bytes.NewReader(jsonPayload))
+	resp, err := c.doRequest(ctx, "PATCH", url, bytes.NewReader(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("failed to update issue #%d for %s/%s: %w", number, owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
@@ -378,17 +761,17 @@ func (c *Client) UpdateIssue(owner, repo string, number int, update IssueUpdate)
 
 // ListComments fetches all comments for an issue.
 // Handles pagination automatically.
-func (c *Client) ListComments(owner, repo string, number int) ([]Comment, error) {
+func (c *Client) ListComments(ctx context.Context, owner, repo string, number int) ([]Comment, error) {
 	var allComments []Comment
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments?per_page=100", c.baseURL, owner, repo, number)
 
 	for url != "" {
-		resp, err := c.doRequest("GET", url, nil)
+		resp, err := c.doRequest(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list comments for issue #%d in %s/%s: %w", number, owner, repo, err)
 		}
 
-		checkRateLimit(resp)
+		checkRateLimit(ctx, resp)
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
@@ -414,9 +797,45 @@ func (c *Client) ListComments(owner, repo string, number int) ([]Comment, error)
 	return allComments, nil
 }
 
+// ListRepoCommentsSince fetches the comments on every issue of the
+// repository created or edited at or after since. Each comment's IssueURL
+// tells which issue it is on.
+func (c *Client) ListRepoCommentsSince(ctx context.Context, owner, repo string, since time.Time) ([]Comment, error) {
+	var allComments []Comment
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/comments?sort=updated&direction=asc&per_page=100&since=%s", c.baseURL, owner, repo, since.UTC().Format(time.RFC3339))
+
+	for url != "" {
+		resp, err := c.doRequest(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments in %s/%s: %w", owner, repo, err)
+		}
+
+		checkRateLimit(ctx, resp)
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list comments in %s/%s: API error %s - %s", owner, repo, resp.Status, string(body))
+		}
+
+		var comments []Comment
+		if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decode comments response for %s/%s: %w", owner, repo, err)
+		}
+
+		url = getNextPageURL(resp.Header.Get("Link"))
+		resp.Body.Close()
+
+		allComments = append(allComments, comments...)
+	}
+
+	return allComments, nil
+}
+
 // CreateComment creates a new comment on an issue.
 // Returns the created comment with its assigned ID.
-func (c *Client) CreateComment(owner, repo string, number int, body string) (*Comment, error) {
+func (c *Client) CreateComment(ctx context.Context, owner, repo string, number int, body string) (*Comment, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", c.baseURL, owner, repo, number)
 
 	payload := map[string]string{"body": body}
@@ -425,13 +844,13 @@ func (c *Client) CreateComment(owner, repo string, number int, body string) (*Co
 		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := c.doRequest("POST", url, bytes.NewReader(jsonPayload))
+	resp, err := c.doRequest(ctx, "POST", url, bytes.NewReader(jsonPayload))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create comment on issue #%d in %s/%s: %w", number, owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	if resp.StatusCode != http.StatusCreated {
 		respBody, _ := io.ReadAll(resp.Body)
@@ -447,7 +866,7 @@ func (c *Client) CreateComment(owner, repo string, number int, body string) (*Co
 }
 
 // UpdateComment updates an existing comment.
-func (c *Client) UpdateComment(owner, repo string, commentID int64, body string) error {
+func (c *Client) UpdateComment(ctx context.Context, owner, repo string, commentID int64, body string) error {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/comments/%d", c.baseURL, owner, repo, commentID)
 
 	payload := map[string]string{"body": body}
@@ -456,13 +875,13 @@ func (c *Client) UpdateComment(owner, repo string, commentID int64, body string)
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := c.doRequest("PATCH", url, bytes.NewReader(jsonPayload))
+	resp, err := c.doRequest(ctx, "PATCH", url, bytes.NewReader(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("failed to update comment %d in %s/%s: %w", commentID, owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
@@ -474,7 +893,7 @@ func (c *Client) UpdateComment(owner, repo string, commentID int64, body string)
 
 // CreateIssue creates a new issue in a repository.
 // Returns the created issue with its assigned number.
-func (c *Client) CreateIssue(owner, repo, title, body string, labels []string) (*Issue, error) {
+func (c *Client) CreateIssue(ctx context.Context, owner, repo, title, body string, labels []string) (*Issue, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues", c.baseURL, owner, repo)
 
 	payload := map[string]interface{}{
@@ -490,13 +909,13 @@ func (c *Client) CreateIssue(owner, repo, title, body string, labels []string) (
 		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := c.doRequest("POST", url, bytes.NewReader(jsonPayload))
+	resp, err := c.doRequest(ctx, "POST", url, bytes.NewReader(jsonPayload))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create issue in %s/%s: %w", owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	if resp.StatusCode != http.StatusCreated {
 		respBody, _ := io.ReadAll(resp.Body)
@@ -517,30 +936,22 @@ func (c *Client) CreateIssue(owner, repo, title, body string, labels []string) (
 // GetIssueWithEtag fetches an issue using a conditional request with etag.
 // Returns (nil, "", nil) on 304 Not Modified.
 // Returns (*Issue, newEtag, nil) on 200 OK with new data.
-func (c *Client) GetIssueWithEtag(owner, repo string, number int, etag string) (*Issue, string, error) {
+func (c *Client) GetIssueWithEtag(ctx context.Context, owner, repo string, number int, etag string) (*Issue, string, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", c.baseURL, owner, repo, number)
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
-
 	// Add conditional request header
+	header := http.Header{}
 	if etag != "" {
-		req.Header.Set("If-None-Match", etag)
+		header.Set("If-None-Match", etag)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doRequestWithHeader(ctx, "GET", url, nil, header)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get issue #%d with etag for %s/%s: %w", number, owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	// 304 Not Modified - issue hasn't changed
 	if resp.StatusCode == http.StatusNotModified {
@@ -564,16 +975,16 @@ func (c *Client) GetIssueWithEtag(owner, repo string, number int, etag string) (
 }
 
 // ListSubIssues fetches all sub-issues for an issue.
-func (c *Client) ListSubIssues(owner, repo string, number int) ([]Issue, error) {
+func (c *Client) ListSubIssues(ctx context.Context, owner, repo string, number int) ([]Issue, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/sub_issues", c.baseURL, owner, repo, number)
 
-	resp, err := c.doRequest("GET", url, nil)
+	resp, err := c.doRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sub-issues for issue #%d in %s/%s: %w", number, owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -590,16 +1001,16 @@ func (c *Client) ListSubIssues(owner, repo string, number int) ([]Issue, error)
 
 // GetParentIssue fetches the parent issue for a sub-issue.
 // Returns nil if the issue has no parent.
-func (c *Client) GetParentIssue(owner, repo string, number int) (*Issue, error) {
+func (c *Client) GetParentIssue(ctx context.Context, owner, repo string, number int) (*Issue, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/parent", c.baseURL, owner, repo, number)
 
-	resp, err := c.doRequest("GET", url, nil)
+	resp, err := c.doRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parent issue for #%d in %s/%s: %w", number, owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	// 404 means no parent
 	if resp.StatusCode == http.StatusNotFound {
@@ -621,7 +1032,7 @@ func (c *Client) GetParentIssue(owner, repo string, number int) (*Issue, error)
 
 // AddSubIssue adds a sub-issue to a parent issue.
 // subIssueID is the numeric ID of the issue to add as sub-issue (not the issue number).
-func (c *Client) AddSubIssue(owner, repo string, parentNumber int, subIssueID int64) error {
+func (c *Client) AddSubIssue(ctx context.Context, owner, repo string, parentNumber int, subIssueID int64) error {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/sub_issues", c.baseURL, owner, repo, parentNumber)
 
 	payload := map[string]int64{"sub_issue_id": subIssueID}
@@ -630,13 +1041,13 @@ func (c *Client) AddSubIssue(owner, repo string, parentNumber int, subIssueID in
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := c.doRequest("POST", url, bytes.NewReader(jsonPayload))
+	resp, err := c.doRequest(ctx, "POST", url, bytes.NewReader(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("failed to add sub-issue to #%d in %s/%s: %w", parentNumber, owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -648,7 +1059,7 @@ func (c *Client) AddSubIssue(owner, repo string, parentNumber int, subIssueID in
 
 // RemoveSubIssue removes a sub-issue from its parent.
 // subIssueID is the numeric ID of the issue to remove (not the issue number).
-func (c *Client) RemoveSubIssue(owner, repo string, parentNumber int, subIssueID int64) error {
+func (c *Client) RemoveSubIssue(ctx context.Context, owner, repo string, parentNumber int, subIssueID int64) error {
 	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/sub_issue", c.baseURL, owner, repo, parentNumber)
 
 	payload := map[string]int64{"sub_issue_id": subIssueID}
@@ -657,13 +1068,13 @@ func (c *Client) RemoveSubIssue(owner, repo string, parentNumber int, subIssueID
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := c.doRequest("DELETE", url, bytes.NewReader(jsonPayload))
+	resp, err := c.doRequest(ctx, "DELETE", url, bytes.NewReader(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("failed to remove sub-issue from #%d in %s/%s: %w", parentNumber, owner, repo, err)
 	}
 	defer resp.Body.Close()
 
-	checkRateLimit(resp)
+	checkRateLimit(ctx, resp)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
@@ -2,6 +2,8 @@ package gh
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -38,7 +40,7 @@ func TestUpdateIssue_Success(t *testing.T) {
 
 	// Update the issue (only body)
 	newBody := "New updated body"
-	err := client.UpdateIssue("owner", "repo", 42, IssueUpdate{Body: &newBody})
+	err := client.UpdateIssue(t.Context(), "owner", "repo", 42, IssueUpdate{Body: &newBody})
 	if err != nil {
 		t.Fatalf("UpdateIssue() unexpected error: %v", err)
 	}
@@ -59,7 +61,7 @@ func TestUpdateIssue_NotFound(t *testing.T) {
 
 	// Try to update a non-existent issue
 	body := "Some body"
-	err := client.UpdateIssue("owner", "repo", 999, IssueUpdate{Body: &body})
+	err := client.UpdateIssue(t.Context(), "owner", "repo", 999, IssueUpdate{Body: &body})
 	if err == nil {
 		t.Fatal("UpdateIssue() expected error for non-existent issue, got nil")
 	}
@@ -89,7 +91,7 @@ func TestUpdateIssue_ValidationError(t *testing.T) {
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
 	body := "Invalid body content"
-	err := client.UpdateIssue("owner", "repo", 42, IssueUpdate{Body: &body})
+	err := client.UpdateIssue(t.Context(), "owner", "repo", 42, IssueUpdate{Body: &body})
 	if err == nil {
 		t.Fatal("UpdateIssue() expected validation error, got nil")
 	}
@@ -116,7 +118,7 @@ func TestUpdateIssue_RequestBody(t *testing.T) {
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
 	newBody := "This is the new body content with special chars: <>&\""
-	err := client.UpdateIssue("owner", "repo", 1, IssueUpdate{Body: &newBody})
+	err := client.UpdateIssue(t.Context(), "owner", "repo", 1, IssueUpdate{Body: &newBody})
 	if err != nil {
 		t.Fatalf("UpdateIssue() unexpected error: %v", err)
 	}
@@ -148,7 +150,7 @@ func TestGetIssueWithEtag_NotModified(t *testing.T) {
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
 	// Request with matching ETag should return 304
-	issue, newEtag, err := client.GetIssueWithEtag("owner", "repo", 42, originalEtag)
+	issue, newEtag, err := client.GetIssueWithEtag(t.Context(), "owner", "repo", 42, originalEtag)
 
 	if err != nil {
 		t.Fatalf("GetIssueWithEtag() unexpected error: %v", err)
@@ -184,7 +186,7 @@ func TestGetIssueWithEtag_Modified(t *testing.T) {
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
 	// Request with different ETag should return full issue
-	issue, newEtag, err := client.GetIssueWithEtag("owner", "repo", 42, originalEtag)
+	issue, newEtag, err := client.GetIssueWithEtag(t.Context(), "owner", "repo", 42, originalEtag)
 
 	if err != nil {
 		t.Fatalf("GetIssueWithEtag() unexpected error: %v", err)
@@ -228,7 +230,7 @@ func TestGetIssueWithEtag_NoEtag(t *testing.T) {
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
 	// Request without ETag should always return full issue
-	issue, newEtag, err := client.GetIssueWithEtag("owner", "repo", 42, "")
+	issue, newEtag, err := client.GetIssueWithEtag(t.Context(), "owner", "repo", 42, "")
 
 	if err != nil {
 		t.Fatalf("GetIssueWithEtag() unexpected error: %v", err)
@@ -267,7 +269,7 @@ func TestListIssues_Pagination(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	issues, err := client.ListIssues("owner", "repo")
+	issues, err := client.ListIssues(t.Context(), "owner", "repo")
 	if err != nil {
 		t.Fatalf("ListIssues() unexpected error: %v", err)
 	}
@@ -310,7 +312,7 @@ func TestListIssues_SinglePage(t *testing.T) {
 	// No pagination (default)
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	issues, err := client.ListIssues("owner", "repo")
+	issues, err := client.ListIssues(t.Context(), "owner", "repo")
 	if err != nil {
 		t.Fatalf("ListIssues() unexpected error: %v", err)
 	}
@@ -350,7 +352,7 @@ func TestListComments_Pagination(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	comments, err := client.ListComments("owner", "repo", 1)
+	comments, err := client.ListComments(t.Context(), "owner", "repo", 1)
 	if err != nil {
 		t.Fatalf("ListComments() unexpected error: %v", err)
 	}
@@ -387,7 +389,7 @@ func TestListComments_Empty(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	comments, err := client.ListComments("owner", "repo", 1)
+	comments, err := client.ListComments(t.Context(), "owner", "repo", 1)
 	if err != nil {
 		t.Fatalf("ListComments() unexpected error: %v", err)
 	}
@@ -417,7 +419,7 @@ func TestGetIssue_WithMock(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	issue, etag, err := client.GetIssue("owner", "repo", 42)
+	issue, etag, err := client.GetIssue(t.Context(), "owner", "repo", 42)
 	if err != nil {
 		t.Fatalf("GetIssue() unexpected error: %v", err)
 	}
@@ -446,7 +448,7 @@ func TestGetIssue_NotFound(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	issue, etag, err := client.GetIssue("owner", "repo", 9999)
+	issue, etag, err := client.GetIssue(t.Context(), "owner", "repo", 9999)
 
 	if err == nil {
 		t.Fatal("Expected error for non-existent issue, got nil")
@@ -472,7 +474,7 @@ func TestListIssues_Empty(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	issues, err := client.ListIssues("owner", "repo")
+	issues, err := client.ListIssues(t.Context(), "owner", "repo")
 	if err != nil {
 		t.Fatalf("ListIssues() unexpected error: %v", err)
 	}
@@ -482,6 +484,154 @@ func TestListIssues_Empty(t *testing.T) {
 	}
 }
 
+// TestListOwnerRepos tests listing an organization's repositories
+func TestListOwnerRepos(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+
+	mockGH.AddRepository(&Repository{ID: 1, Name: "a", FullName: "org/a", HasIssues: true})
+	mockGH.AddRepository(&Repository{ID: 2, Name: "b", FullName: "org/b", Archived: true})
+	mockGH.AddRepository(&Repository{ID: 3, Name: "c", FullName: "other/c", HasIssues: true})
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+
+	repos, err := client.ListOwnerRepos(t.Context(), "org")
+	if err != nil {
+		t.Fatalf("ListOwnerRepos() unexpected error: %v", err)
+	}
+
+	if len(repos) != 2 {
+		t.Fatalf("Expected 2 repos, got %d", len(repos))
+	}
+	if repos[0].FullName != "org/a" || !repos[0].HasIssues {
+		t.Errorf("Unexpected first repo: %+v", repos[0])
+	}
+	if repos[1].FullName != "org/b" || !repos[1].Archived {
+		t.Errorf("Unexpected second repo: %+v", repos[1])
+	}
+}
+
+// TestListOwnerRepos_FallsBackToUser tests that a user account is listed
+// through the users endpoint when the owner is not an organization
+func TestListOwnerRepos_FallsBackToUser(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+
+	mockGH.AddRepository(&Repository{ID: 1, Name: "dotfiles", FullName: "alice/dotfiles", HasIssues: true})
+	// The orgs endpoint 404s for users
+	mockGH.SetNextError(http.StatusNotFound, `{"message": "Not Found"}`)
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+
+	repos, err := client.ListOwnerRepos(t.Context(), "alice")
+	if err != nil {
+		t.Fatalf("ListOwnerRepos() unexpected error: %v", err)
+	}
+
+	if len(repos) != 1 || repos[0].FullName != "alice/dotfiles" {
+		t.Errorf("Expected alice/dotfiles, got %+v", repos)
+	}
+}
+
+// TestListOwnerRepos_Error tests that API errors are returned
+func TestListOwnerRepos_Error(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+
+	mockGH.SetNextError(http.StatusInternalServerError, "boom")
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+
+	if _, err := client.ListOwnerRepos(t.Context(), "org"); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected 500 error, got %v", err)
+	}
+}
+
+// TestTokenInfo tests that classic token scopes are read from the response
+// headers, and that their absence is reported as unknown
+func TestTokenInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		scopes      *string
+		wantScopes  []string
+		wantKnown   bool
+		wantHasRepo bool
+	}{
+		{name: "fine-grained token", wantKnown: false},
+		{name: "classic with repo", scopes: strPtr("gist, read:org, repo"), wantScopes: []string{"gist", "read:org", "repo"}, wantKnown: true, wantHasRepo: true},
+		{name: "classic without scopes", scopes: strPtr(""), wantKnown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGH := NewMockServer()
+			defer mockGH.Close()
+			if tt.scopes != nil {
+				mockGH.SetTokenScopes(*tt.scopes)
+			}
+
+			info, err := NewWithBaseURL("test-token", mockGH.URL).TokenInfo(t.Context())
+			if err != nil {
+				t.Fatalf("TokenInfo() unexpected error: %v", err)
+			}
+			if info.Login != "mock-user" {
+				t.Errorf("Login = %q, expected mock-user", info.Login)
+			}
+			if info.ScopesKnown != tt.wantKnown {
+				t.Errorf("ScopesKnown = %v, expected %v", info.ScopesKnown, tt.wantKnown)
+			}
+			if strings.Join(info.Scopes, ",") != strings.Join(tt.wantScopes, ",") {
+				t.Errorf("Scopes = %v, expected %v", info.Scopes, tt.wantScopes)
+			}
+			if info.HasScope("repo") != tt.wantHasRepo {
+				t.Errorf("HasScope(repo) = %v, expected %v", info.HasScope("repo"), tt.wantHasRepo)
+			}
+		})
+	}
+}
+
+// TestTokenInfo_Rejected tests that a rejected token is an error
+func TestTokenInfo_Rejected(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+
+	mockGH.SetNextError(http.StatusUnauthorized, `{"message": "Bad credentials"}`)
+
+	if _, err := NewWithBaseURL("bad-token", mockGH.URL).TokenInfo(t.Context()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected 401 error, got %v", err)
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+// TestGetRepository tests that the user's permissions are decoded
+func TestGetRepository(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+
+	repo, err := client.GetRepository(t.Context(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("GetRepository() unexpected error: %v", err)
+	}
+	if repo.FullName != "owner/repo" || repo.Permissions != nil {
+		t.Errorf("Unexpected repository without permissions: %+v", repo)
+	}
+
+	mockGH.SetRepoPermissions(&RepoPermissions{Pull: true})
+	repo, err = client.GetRepository(t.Context(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("GetRepository() unexpected error: %v", err)
+	}
+	if repo.Permissions == nil || !repo.Permissions.Pull || repo.Permissions.CanEditIssues() {
+		t.Errorf("Expected pull-only permissions, got %+v", repo.Permissions)
+	}
+	if !(&RepoPermissions{Triage: true}).CanEditIssues() {
+		t.Error("triage should allow editing issues")
+	}
+}
+
 // =============================================================================
 // Integration Tests (require real GitHub token)
 // =============================================================================
@@ -511,7 +661,7 @@ func TestListIssues_Integration(t *testing.T) {
 	}
 
 	client := New(token)
-	issues, err := client.ListIssues("JohanCodinha", "ghissues")
+	issues, err := client.ListIssues(t.Context(), "JohanCodinha", "ghissues")
 	if err != nil {
 		t.Fatalf("ListIssues() failed: %v", err)
 	}
@@ -533,7 +683,7 @@ func TestGetIssue_Integration(t *testing.T) {
 	}
 
 	client := New(token)
-	issue, etag, err := client.GetIssue("JohanCodinha", "ghissues", 1)
+	issue, etag, err := client.GetIssue(t.Context(), "JohanCodinha", "ghissues", 1)
 	if err != nil {
 		t.Fatalf("GetIssue() failed: %v", err)
 	}
@@ -567,7 +717,7 @@ func TestListComments_Integration(t *testing.T) {
 	}
 
 	client := New(token)
-	comments, err := client.ListComments("JohanCodinha", "ghissues", 1)
+	comments, err := client.ListComments(t.Context(), "JohanCodinha", "ghissues", 1)
 	if err != nil {
 		t.Fatalf("ListComments() failed: %v", err)
 	}
@@ -614,7 +764,7 @@ func TestGetTokenFromGhConfigPath_ValidConfig(t *testing.T) {
 		t.Fatalf("failed to write hosts.yml: %v", err)
 	}
 
-	token, err := getTokenFromGhConfigPath(configPath)
+	token, err := getTokenFromGhConfigPath(configPath, "github.com")
 	if err != nil {
 		t.Fatalf("getTokenFromGhConfigPath() unexpected error: %v", err)
 	}
@@ -640,7 +790,7 @@ func TestGetTokenFromGhConfigPath_MissingOAuthToken(t *testing.T) {
 		t.Fatalf("failed to write hosts.yml: %v", err)
 	}
 
-	token, err := getTokenFromGhConfigPath(configPath)
+	token, err := getTokenFromGhConfigPath(configPath, "github.com")
 	if err == nil {
 		t.Fatal("expected error for missing oauth_token, got nil")
 	}
@@ -671,7 +821,7 @@ func TestGetTokenFromGhConfigPath_EmptyOAuthToken(t *testing.T) {
 		t.Fatalf("failed to write hosts.yml: %v", err)
 	}
 
-	token, err := getTokenFromGhConfigPath(configPath)
+	token, err := getTokenFromGhConfigPath(configPath, "github.com")
 	if err == nil {
 		t.Fatal("expected error for empty oauth_token, got nil")
 	}
@@ -698,7 +848,7 @@ func TestGetTokenFromGhConfigPath_MalformedYAML(t *testing.T) {
 		t.Fatalf("failed to write hosts.yml: %v", err)
 	}
 
-	token, err := getTokenFromGhConfigPath(configPath)
+	token, err := getTokenFromGhConfigPath(configPath, "github.com")
 	if err == nil {
 		t.Fatal("expected error for malformed YAML, got nil")
 	}
@@ -716,7 +866,7 @@ func TestGetTokenFromGhConfigPath_MissingFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "nonexistent", "hosts.yml")
 
-	token, err := getTokenFromGhConfigPath(configPath)
+	token, err := getTokenFromGhConfigPath(configPath, "github.com")
 	if err == nil {
 		t.Fatal("expected error for missing file, got nil")
 	}
@@ -747,7 +897,7 @@ func TestGetTokenFromGhConfigPath_NoGitHubHost(t *testing.T) {
 		t.Fatalf("failed to write hosts.yml: %v", err)
 	}
 
-	token, err := getTokenFromGhConfigPath(configPath)
+	token, err := getTokenFromGhConfigPath(configPath, "github.com")
 	if err == nil {
 		t.Fatal("expected error for missing github.com host, got nil")
 	}
@@ -777,7 +927,7 @@ github.com:
 		t.Fatalf("failed to write hosts.yml: %v", err)
 	}
 
-	token, err := getTokenFromGhConfigPath(configPath)
+	token, err := getTokenFromGhConfigPath(configPath, "github.com")
 	if err != nil {
 		t.Fatalf("getTokenFromGhConfigPath() unexpected error: %v", err)
 	}
@@ -786,6 +936,43 @@ github.com:
 	if token != "public-token-xyz" {
 		t.Errorf("expected token 'public-token-xyz', got '%s'", token)
 	}
+
+	// The token of an enterprise host comes from its own entry
+	token, err = getTokenFromGhConfigPath(configPath, "github.enterprise.com")
+	if err != nil || token != "enterprise-token" {
+		t.Errorf("getTokenFromGhConfigPath(enterprise) = %q, %v; want enterprise-token", token, err)
+	}
+	if _, err := getTokenFromGhConfigPath(configPath, "ghe.example.com"); err == nil {
+		t.Error("expected an error for a host gh is not logged into")
+	}
+}
+
+func TestGhLoginsFromPath(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "hosts.yml")
+	hostsYml := `github.enterprise.com:
+    oauth_token: enterprise-token
+    user: enterpriseuser
+github.com:
+    user: publicuser
+`
+	if err := os.WriteFile(configPath, []byte(hostsYml), 0644); err != nil {
+		t.Fatalf("failed to write hosts.yml: %v", err)
+	}
+
+	logins, err := ghLoginsFromPath(configPath)
+	if err != nil {
+		t.Fatalf("ghLoginsFromPath() unexpected error: %v", err)
+	}
+	want := []GhLogin{{Host: "github.com", User: "publicuser"}, {Host: "github.enterprise.com", User: "enterpriseuser"}}
+	if len(logins) != len(want) || logins[0] != want[0] || logins[1] != want[1] {
+		t.Errorf("ghLoginsFromPath() = %+v, expected %+v", logins, want)
+	}
+
+	// A missing config means gh was never logged in
+	logins, err = ghLoginsFromPath(filepath.Join(t.TempDir(), "missing.yml"))
+	if err != nil || len(logins) != 0 {
+		t.Errorf("ghLoginsFromPath(missing) = %+v, %v; expected no logins", logins, err)
+	}
 }
 
 // =============================================================================
@@ -797,7 +984,7 @@ func TestCheckRateLimit_LogsWarning(t *testing.T) {
 	var sleepDuration time.Duration
 	sleepCalled := false
 	originalSleep := sleepFunc
-	sleepFunc = func(d time.Duration) {
+	sleepFunc = func(ctx context.Context, d time.Duration) {
 		sleepDuration = d
 		sleepCalled = true
 	}
@@ -822,7 +1009,7 @@ func TestCheckRateLimit_LogsWarning(t *testing.T) {
 	}()
 
 	// Call checkRateLimit - should call our mock sleep
-	slept := checkRateLimit(resp)
+	slept := checkRateLimit(t.Context(), resp)
 
 	output := buf.String()
 
@@ -866,7 +1053,7 @@ func TestCheckRateLimit_NoWarningWhenRemainingPositive(t *testing.T) {
 	}()
 
 	// Call checkRateLimit
-	checkRateLimit(resp)
+	checkRateLimit(t.Context(), resp)
 
 	output := buf.String()
 
@@ -892,7 +1079,7 @@ func TestCheckRateLimit_NoWarningWhenHeaderMissing(t *testing.T) {
 	}()
 
 	// Call checkRateLimit
-	checkRateLimit(resp)
+	checkRateLimit(t.Context(), resp)
 
 	output := buf.String()
 
@@ -920,7 +1107,7 @@ func TestCheckRateLimit_NoWarningWhenResetMissing(t *testing.T) {
 	}()
 
 	// Call checkRateLimit
-	checkRateLimit(resp)
+	checkRateLimit(t.Context(), resp)
 
 	output := buf.String()
 
@@ -949,7 +1136,7 @@ func TestCreateComment_Success(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	comment, err := client.CreateComment("owner", "repo", 42, "This is a new comment")
+	comment, err := client.CreateComment(t.Context(), "owner", "repo", 42, "This is a new comment")
 	if err != nil {
 		t.Fatalf("CreateComment() unexpected error: %v", err)
 	}
@@ -974,7 +1161,7 @@ func TestCreateComment_IssueNotFound(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	_, err := client.CreateComment("owner", "repo", 999, "Comment on non-existent issue")
+	_, err := client.CreateComment(t.Context(), "owner", "repo", 999, "Comment on non-existent issue")
 	if err == nil {
 		t.Fatal("CreateComment() expected error for non-existent issue, got nil")
 	}
@@ -1008,7 +1195,7 @@ func TestUpdateComment_Success(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	err := client.UpdateComment("owner", "repo", 12345, "Updated comment body")
+	err := client.UpdateComment(t.Context(), "owner", "repo", 12345, "Updated comment body")
 	if err != nil {
 		t.Fatalf("UpdateComment() unexpected error: %v", err)
 	}
@@ -1029,7 +1216,7 @@ func TestUpdateComment_NotFound(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	err := client.UpdateComment("owner", "repo", 99999, "Update non-existent comment")
+	err := client.UpdateComment(t.Context(), "owner", "repo", 99999, "Update non-existent comment")
 	if err == nil {
 		t.Fatal("UpdateComment() expected error for non-existent comment, got nil")
 	}
@@ -1049,7 +1236,7 @@ func TestCreateIssue_Success(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	issue, err := client.CreateIssue("owner", "repo", "New Issue Title", "Issue body content", []string{"bug", "p1"})
+	issue, err := client.CreateIssue(t.Context(), "owner", "repo", "New Issue Title", "Issue body content", []string{"bug", "p1"})
 	if err != nil {
 		t.Fatalf("CreateIssue() unexpected error: %v", err)
 	}
@@ -1089,7 +1276,7 @@ func TestCreateIssue_NoLabels(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	issue, err := client.CreateIssue("owner", "repo", "Issue Without Labels", "Body", nil)
+	issue, err := client.CreateIssue(t.Context(), "owner", "repo", "Issue Without Labels", "Body", nil)
 	if err != nil {
 		t.Fatalf("CreateIssue() unexpected error: %v", err)
 	}
@@ -1111,7 +1298,7 @@ func TestCreateIssue_Error(t *testing.T) {
 
 	client := NewWithBaseURL("test-token", mockGH.URL)
 
-	_, err := client.CreateIssue("owner", "repo", "", "Body without title", nil)
+	_, err := client.CreateIssue(t.Context(), "owner", "repo", "", "Body without title", nil)
 	if err == nil {
 		t.Fatal("CreateIssue() expected validation error, got nil")
 	}
@@ -1120,3 +1307,150 @@ func TestCreateIssue_Error(t *testing.T) {
 		t.Errorf("Expected 422/Validation error, got: %v", err)
 	}
 }
+
+// TestPing tests that only an unreachable server is a network error
+func TestPing(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+
+	// The mock has no /rate_limit, and a 404 still means GitHub answered
+	client := NewWithBaseURL("test-token", mockGH.URL)
+	if err := client.Ping(t.Context()); err != nil {
+		t.Errorf("Ping() error = %v, expected any response to count", err)
+	}
+
+	// Dropped connections are retried before giving up
+	originalSleep := sleepFunc
+	sleepFunc = func(ctx context.Context, d time.Duration) {}
+	defer func() { sleepFunc = originalSleep }()
+
+	mockGH.SetUnreachable(true)
+	err := client.Ping(t.Context())
+	if err == nil || !IsNetworkError(err) {
+		t.Errorf("Ping() error = %v, expected a network error", err)
+	}
+	if _, err := client.ListIssues(t.Context(), "owner", "repo"); !IsNetworkError(fmt.Errorf("wrapped: %w", err)) {
+		t.Errorf("ListIssues() error = %v, expected a network error", err)
+	}
+
+	mockGH.SetUnreachable(false)
+	if _, err := client.ListIssues(t.Context(), "owner", "repo"); err != nil {
+		t.Errorf("ListIssues() error = %v after the network came back", err)
+	}
+}
+
+// TestDoRequest_CancelledDuringRateLimit tests that cancelling the context
+// ends a rate limit wait instead of sleeping it out
+func TestDoRequest_CancelledDuringRateLimit(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	// A 429 without a reset header waits 60s before retrying
+	mockGH.SetNextError(http.StatusTooManyRequests, `{"message": "rate limited"}`)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err := NewWithBaseURL("test-token", mockGH.URL).ListIssues(ctx, "owner", "repo")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ListIssues() error = %v, expected the context error", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("ListIssues() returned after %v, expected the wait to be cut short", elapsed)
+	}
+
+	// Cancellation is not GitHub being unreachable
+	cancelled, cancelNow := context.WithCancel(t.Context())
+	cancelNow()
+	if _, err := NewWithBaseURL("test-token", mockGH.URL).ListIssues(cancelled, "owner", "repo"); err == nil || IsNetworkError(err) {
+		t.Errorf("ListIssues() with a cancelled context error = %v, expected a non-network error", err)
+	}
+}
+
+// TestIssueFilter tests filter validation, description and matching
+func TestIssueFilter(t *testing.T) {
+	for _, since := range []string{"90d", "2w", "36h", "2024-01-31"} {
+		if err := (IssueFilter{Since: since}).Validate(); err != nil {
+			t.Errorf("Validate(since %q) error = %v", since, err)
+		}
+	}
+	for _, f := range []IssueFilter{{State: "merged"}, {Since: "90"}, {Since: "0d"}, {Since: "d"}, {Since: "yesterday"}} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected an error", f)
+		}
+	}
+
+	filter := IssueFilter{State: "open", Labels: []string{"bug", "team-infra"}, Since: "90d", Assignee: "alice"}
+	if got := filter.String(); got != "state=open label=bug label=team-infra since=90d assignee=alice" {
+		t.Errorf("String() = %q", got)
+	}
+	if !(IssueFilter{State: "all"}).IsZero() || filter.IsZero() {
+		t.Error("IsZero() is wrong")
+	}
+
+	match := func(mutate func(*Issue)) *Issue {
+		issue := &Issue{
+			State:     "open",
+			Labels:    []Label{{Name: "Bug"}, {Name: "team-infra"}},
+			Assignees: []User{{Login: "alice"}},
+			UpdatedAt: time.Now().Add(-24 * time.Hour),
+		}
+		mutate(issue)
+		return issue
+	}
+
+	tests := []struct {
+		name  string
+		issue *Issue
+		want  bool
+	}{
+		{"matching", match(func(*Issue) {}), true},
+		{"closed", match(func(i *Issue) { i.State = "closed" }), false},
+		{"missing label", match(func(i *Issue) { i.Labels = i.Labels[:1] }), false},
+		{"stale", match(func(i *Issue) { i.UpdatedAt = time.Now().Add(-100 * 24 * time.Hour) }), false},
+		{"other assignee", match(func(i *Issue) { i.Assignees = []User{{Login: "bob"}} }), false},
+	}
+
+	for _, tt := range tests {
+		if got := filter.Matches(tt.issue); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if !(IssueFilter{Assignee: "none"}).Matches(&Issue{}) || (IssueFilter{Assignee: "*"}).Matches(&Issue{}) {
+		t.Error("none and * assignees are wrong")
+	}
+}
+
+// TestListIssuesMatching tests that the filter is sent as query parameters
+// across pages
+func TestListIssuesMatching(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	mockGH.SetIssuesPerPage(1)
+
+	now := time.Now()
+	mockGH.AddIssue(&Issue{Number: 1, State: "open", Labels: []Label{{Name: "bug"}}, UpdatedAt: now})
+	mockGH.AddIssue(&Issue{Number: 2, State: "closed", Labels: []Label{{Name: "bug"}}, UpdatedAt: now})
+	mockGH.AddIssue(&Issue{Number: 3, State: "open", UpdatedAt: now})
+	mockGH.AddIssue(&Issue{Number: 4, State: "open", Labels: []Label{{Name: "bug"}}, UpdatedAt: now.Add(-30 * 24 * time.Hour)})
+	mockGH.AddIssue(&Issue{Number: 5, State: "open", Labels: []Label{{Name: "bug"}}, UpdatedAt: now})
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+	issues, err := client.ListIssuesMatching(t.Context(), "owner", "repo", IssueFilter{State: "open", Labels: []string{"bug"}, Since: "7d"})
+	if err != nil {
+		t.Fatalf("ListIssuesMatching() error = %v", err)
+	}
+
+	var numbers []int
+	for _, issue := range issues {
+		numbers = append(numbers, issue.Number)
+	}
+	if len(numbers) != 2 || numbers[0] != 1 || numbers[1] != 5 {
+		t.Errorf("ListIssuesMatching() = issues %v, want [1 5]", numbers)
+	}
+
+	if _, err := client.ListIssuesMatching(t.Context(), "owner", "repo", IssueFilter{Since: "soon"}); err == nil {
+		t.Error("expected an error for an invalid since")
+	}
+}
@@ -0,0 +1,234 @@
+package gh
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CredentialProvider supplies the tokens a Client authenticates with.
+// Implementations must be safe for concurrent use.
+type CredentialProvider interface {
+	// Token returns the token to send, refreshing it first if it expired.
+	// ctx bounds the refresh.
+	Token(ctx context.Context) (string, error)
+	// Invalidate reports that GitHub rejected token, so that the next Token
+	// call gets a new one. Providers that cannot renew a token ignore it.
+	Invalidate(token string)
+}
+
+// StaticToken is a token that never changes, such as a personal access
+// token from the environment.
+type StaticToken string
+
+// Token returns the token.
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Invalidate does nothing, a static token cannot be renewed.
+func (t StaticToken) Invalidate(string) {}
+
+// ghCLICredentials asks the gh CLI for the token of a host, again after
+// GitHub rejected it, in case gh refreshed or was logged in again since.
+type ghCLICredentials struct {
+	host  string
+	mu    sync.Mutex
+	token string
+}
+
+func (g *ghCLICredentials) Token(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token == "" {
+		token, err := getTokenFromGhCLI(ctx, g.host)
+		if err != nil {
+			return "", err
+		}
+		g.token = token
+	}
+	return g.token, nil
+}
+
+func (g *ghCLICredentials) Invalidate(token string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token == token {
+		g.token = ""
+	}
+}
+
+// CredentialsForHost finds the credentials of host in the same places as
+// GetTokenForHost, and reports which one they came from.
+func CredentialsForHost(host string) (creds CredentialProvider, source string, err error) {
+	// Try gh auth token command first (handles keyring storage)
+	if token, err := getTokenFromGhCLI(context.Background(), host); err == nil && token != "" {
+		return &ghCLICredentials{host: host, token: token}, TokenSourceGhCLI, nil
+	}
+
+	// Try reading from gh hosts.yml config (older format)
+	if token, err := getTokenFromGhConfig(host); err == nil && token != "" {
+		return StaticToken(token), TokenSourceGhConfig, nil
+	}
+
+	// Fall back to the environment
+	env := TokenEnv(host)
+	if token := os.Getenv(env); token != "" {
+		return StaticToken(token), env, nil
+	}
+
+	return nil, "", fmt.Errorf("no GitHub token found for %s: install gh CLI and run 'gh auth login --hostname %s', or set %s env var", host, host, env)
+}
+
+// AppCredentials authenticates as an installation of a GitHub App. It signs
+// a JWT with the app's private key, exchanges it for an installation token
+// and renews that token shortly before it expires.
+type AppCredentials struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	baseURL        string
+	httpClient     *http.Client
+	now            func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// appTokenMargin is how long before its expiry an installation token is renewed.
+const appTokenMargin = 5 * time.Minute
+
+// NewAppCredentials returns the credentials of installation installationID
+// of app appID on host.
+func NewAppCredentials(host string, appID, installationID int64, key *rsa.PrivateKey) *AppCredentials {
+	return newAppCredentials(APIBaseURL(host), appID, installationID, key)
+}
+
+func newAppCredentials(baseURL string, appID, installationID int64, key *rsa.PrivateKey) *AppCredentials {
+	return &AppCredentials{
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		baseURL:        baseURL,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		now:            time.Now,
+	}
+}
+
+// Token returns the installation token, requesting a new one when none was
+// requested yet or the current one is about to expire.
+func (a *AppCredentials) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != "" && a.now().Before(a.expiresAt.Add(-appTokenMargin)) {
+		return a.token, nil
+	}
+
+	token, expiresAt, err := a.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	a.token, a.expiresAt = token, expiresAt
+	return token, nil
+}
+
+// Invalidate drops token, so that the next call to Token requests a new one.
+func (a *AppCredentials) Invalidate(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token == token {
+		a.token = ""
+	}
+}
+
+// requestToken exchanges a JWT of the app for an installation token.
+func (a *AppCredentials) requestToken(ctx context.Context) (string, time.Time, error) {
+	jwt, err := a.jwt()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", a.baseURL, a.installationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to request an installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", time.Time{}, fmt.Errorf("failed to request an installation token of app %d: GitHub API error: %d %s", a.appID, resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode installation token: %w", err)
+	}
+	return result.Token, result.ExpiresAt, nil
+}
+
+// jwt returns an RS256 JSON Web Token identifying the app. It is backdated
+// a minute against clock drift and lives for the 10 minutes GitHub allows.
+func (a *AppCredentials) jwt() (string, error) {
+	now := a.now()
+	header := `{"alg":"RS256","typ":"JWT"}`
+	claims := `{"iat":` + strconv.FormatInt(now.Add(-time.Minute).Unix(), 10) +
+		`,"exp":` + strconv.FormatInt(now.Add(9*time.Minute).Unix(), 10) +
+		`,"iss":"` + strconv.FormatInt(a.appID, 10) + `"}`
+
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString([]byte(claims))
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app JWT: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(signature), nil
+}
+
+// ParseAppPrivateKey parses the PEM private key of a GitHub App, as
+// downloaded from its settings (PKCS#1) or converted to PKCS#8.
+func ParseAppPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse app private key: no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse app private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse app private key: not an RSA key")
+	}
+	return key, nil
+}
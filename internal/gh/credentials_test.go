@@ -0,0 +1,101 @@
+package gh
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppCredentials(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	mockGH.EnableAppAuth(42, &key.PublicKey)
+	mockGH.AddIssue(&Issue{Number: 1, Title: "First", State: "open"})
+
+	now := time.Now()
+	creds := newAppCredentials(mockGH.URL, 42, 7, key)
+	creds.now = func() time.Time { return now }
+	client := NewWithBaseURL("", mockGH.URL)
+	client.SetCredentials(creds)
+
+	getIssue := func(wantIssued int) {
+		t.Helper()
+		if _, _, err := client.GetIssue(t.Context(), "owner", "repo", 1); err != nil {
+			t.Fatalf("GetIssue() error = %v", err)
+		}
+		if got := mockGH.AppTokensIssued(); got != wantIssued {
+			t.Errorf("installation tokens issued = %d, want %d", got, wantIssued)
+		}
+	}
+
+	// The token is requested once and reused
+	getIssue(1)
+	getIssue(1)
+
+	// A revoked token is renewed after the 401
+	mockGH.ExpireAppTokens()
+	getIssue(2)
+
+	// A token about to expire is renewed before use
+	now = now.Add(time.Hour - appTokenMargin)
+	getIssue(3)
+
+	// A JWT of another app is refused
+	other := NewWithBaseURL("", mockGH.URL)
+	other.SetCredentials(newAppCredentials(mockGH.URL, 43, 7, key))
+	if _, _, err := other.GetIssue(t.Context(), "owner", "repo", 1); err == nil || !strings.Contains(err.Error(), "installation token") {
+		t.Errorf("GetIssue() with another app error = %v", err)
+	}
+
+	// A static token rejected by GitHub is not retried
+	if _, _, err := NewWithBaseURL("stale", mockGH.URL).GetIssue(t.Context(), "owner", "repo", 1); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("GetIssue() with a static token error = %v", err)
+	}
+	// A cancelled request does not wait for the token exchange
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := newAppCredentials(mockGH.URL, 42, 7, key).Token(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Token() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestParseAppPrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"PKCS#1", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), false},
+		{"PKCS#8", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), false},
+		{"not PEM", []byte("not a key"), true},
+		{"garbage", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAppPrivateKey(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAppPrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(key) {
+				t.Error("ParseAppPrivateKey() returned another key")
+			}
+		})
+	}
+}
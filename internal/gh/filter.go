@@ -0,0 +1,153 @@
+package gh
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IssueFilter restricts which issues are listed. The zero value matches
+// every issue, open or closed.
+type IssueFilter struct {
+	State    string   `json:"state,omitempty"`    // "open", "closed" or "all"; empty means all
+	Labels   []string `json:"labels,omitempty"`   // issues must have every label
+	Since    string   `json:"since,omitempty"`    // updated within "90d", "2w", "36h", or since a date "2006-01-02"
+	Assignee string   `json:"assignee,omitempty"` // a login, "none" or "*"; empty means any
+}
+
+// IsZero reports whether the filter matches every issue.
+func (f IssueFilter) IsZero() bool {
+	return (f.State == "" || f.State == "all") && len(f.Labels) == 0 && f.Since == "" && f.Assignee == ""
+}
+
+// String describes the filter in the form of the mount flags.
+func (f IssueFilter) String() string {
+	var parts []string
+	if f.State != "" && f.State != "all" {
+		parts = append(parts, "state="+f.State)
+	}
+	for _, label := range f.Labels {
+		parts = append(parts, "label="+label)
+	}
+	if f.Since != "" {
+		parts = append(parts, "since="+f.Since)
+	}
+	if f.Assignee != "" {
+		parts = append(parts, "assignee="+f.Assignee)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Validate checks the state and since values.
+func (f IssueFilter) Validate() error {
+	switch f.State {
+	case "", "open", "closed", "all":
+	default:
+		return fmt.Errorf("invalid state %q: must be open, closed or all", f.State)
+	}
+	_, err := f.sinceTime(time.Now())
+	return err
+}
+
+// sinceTime resolves Since relative to now. The zero time means no limit.
+func (f IssueFilter) sinceTime(now time.Time) (time.Time, error) {
+	if f.Since == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", f.Since, time.Local); err == nil {
+		return t, nil
+	}
+
+	units := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	unit, ok := units[f.Since[len(f.Since)-1]]
+	n, err := strconv.Atoi(f.Since[:len(f.Since)-1])
+	if !ok || err != nil || n <= 0 {
+		return time.Time{}, fmt.Errorf("invalid since %q: use a number of hours, days or weeks like 90d, or a date like 2006-01-02", f.Since)
+	}
+	return now.Add(-time.Duration(n) * unit), nil
+}
+
+// query returns the ListIssues query parameters of the filter.
+func (f IssueFilter) query(now time.Time) (url.Values, error) {
+	since, err := f.sinceTime(now)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("state", "all")
+	if f.State != "" {
+		q.Set("state", f.State)
+	}
+	if len(f.Labels) > 0 {
+		q.Set("labels", strings.Join(f.Labels, ","))
+	}
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	if f.Assignee != "" {
+		q.Set("assignee", f.Assignee)
+	}
+	return q, nil
+}
+
+// graphqlVariables returns the variables of the GraphQL issues query that
+// narrow the listing to the filter. GraphQL matches issues having any of the
+// labels and cannot ask for unassigned issues, so its results still go
+// through Matches.
+func (f IssueFilter) graphqlVariables(now time.Time) (map[string]any, error) {
+	since, err := f.sinceTime(now)
+	if err != nil {
+		return nil, err
+	}
+
+	v := map[string]any{}
+	switch f.State {
+	case "open":
+		v["states"] = []string{"OPEN"}
+	case "closed":
+		v["states"] = []string{"CLOSED"}
+	}
+	if len(f.Labels) > 0 {
+		v["labels"] = f.Labels
+	}
+	if !since.IsZero() {
+		v["since"] = since.UTC().Format(time.RFC3339)
+	}
+	if f.Assignee != "" && f.Assignee != "none" {
+		v["assignee"] = f.Assignee
+	}
+	return v, nil
+}
+
+// Matches reports whether issue passes the filter, as GitHub would decide
+// when listing issues.
+func (f IssueFilter) Matches(issue *Issue) bool {
+	if f.State != "" && f.State != "all" && issue.State != f.State {
+		return false
+	}
+
+	for _, want := range f.Labels {
+		if !slices.ContainsFunc(issue.Labels, func(l Label) bool { return strings.EqualFold(l.Name, want) }) {
+			return false
+		}
+	}
+
+	if since, err := f.sinceTime(time.Now()); err == nil && issue.UpdatedAt.Before(since) {
+		return false
+	}
+
+	switch f.Assignee {
+	case "":
+	case "none":
+		return len(issue.Assignees) == 0
+	case "*":
+		return len(issue.Assignees) > 0
+	default:
+		return slices.ContainsFunc(issue.Assignees, func(u User) bool { return strings.EqualFold(u.Login, f.Assignee) })
+	}
+	return true
+}
@@ -0,0 +1,240 @@
+package gh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// graphqlPageSize is how many issues a page of the bulk query holds. Each
+// issue also brings up to 100 comments and labels, which GitHub counts
+// against the query's node limit.
+const graphqlPageSize = 50
+
+// issuesQuery pages through the issues of a repository along with their
+// comments, labels, assignees, parent and sub-issue summary.
+const issuesQuery = `query($owner: String!, $name: String!, $cursor: String, $pageSize: Int!, $states: [IssueState!], $labels: [String!], $since: DateTime, $assignee: String) {
+  repository(owner: $owner, name: $name) {
+    issues(first: $pageSize, after: $cursor, states: $states, labels: $labels, filterBy: {since: $since, assignee: $assignee}, orderBy: {field: CREATED_AT, direction: ASC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number
+        databaseId
+        title
+        body
+        state
+        url
+        createdAt
+        updatedAt
+        author { login }
+        labels(first: 100) { nodes { name color } }
+        assignees(first: 100) { nodes { login } }
+        parent { number repository { nameWithOwner } }
+        subIssuesSummary { total completed percentCompleted }
+        comments(first: 100) {
+          pageInfo { hasNextPage }
+          nodes { databaseId body createdAt updatedAt author { login } }
+        }
+      }
+    }
+  }
+}`
+
+// ErrGraphQL is wrapped by the errors a GraphQL query answers with, such as
+// a field the server does not know, as opposed to failed requests.
+var ErrGraphQL = errors.New("GraphQL error")
+
+// IssueWithComments is an issue along with all of its comments.
+type IssueWithComments struct {
+	Issue
+	Comments []Comment
+}
+
+// graphqlIssue is an issue node of issuesQuery.
+type graphqlIssue struct {
+	Number     int       `json:"number"`
+	DatabaseID int64     `json:"databaseId"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	State      string    `json:"state"`
+	URL        string    `json:"url"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	Author     *User     `json:"author"` // nil for deleted users
+	Labels     struct {
+		Nodes []Label `json:"nodes"`
+	} `json:"labels"`
+	Assignees struct {
+		Nodes []User `json:"nodes"`
+	} `json:"assignees"`
+	Parent *struct {
+		Number     int `json:"number"`
+		Repository struct {
+			NameWithOwner string `json:"nameWithOwner"`
+		} `json:"repository"`
+	} `json:"parent"`
+	SubIssuesSummary *struct {
+		Total            int `json:"total"`
+		Completed        int `json:"completed"`
+		PercentCompleted int `json:"percentCompleted"`
+	} `json:"subIssuesSummary"`
+	Comments struct {
+		PageInfo struct {
+			HasNextPage bool `json:"hasNextPage"`
+		} `json:"pageInfo"`
+		Nodes []struct {
+			DatabaseID int64     `json:"databaseId"`
+			Body       string    `json:"body"`
+			CreatedAt  time.Time `json:"createdAt"`
+			UpdatedAt  time.Time `json:"updatedAt"`
+			Author     *User     `json:"author"`
+		} `json:"nodes"`
+	} `json:"comments"`
+}
+
+// graphqlURL returns the GraphQL endpoint next to the REST API root:
+// api.github.com/graphql, or /api/graphql on a GitHub Enterprise Server.
+func (c *Client) graphqlURL() string {
+	if root, ok := strings.CutSuffix(c.baseURL, "/api/v3"); ok {
+		return root + "/api/graphql"
+	}
+	return c.baseURL + "/graphql"
+}
+
+// graphql runs query with variables and decodes its data into out.
+func (c *Client) graphql(ctx context.Context, query string, variables map[string]any, out any) error {
+	payload, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("failed to encode GraphQL query: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPost, c.graphqlURL(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %d %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode GraphQL response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("%w: %s", ErrGraphQL, strings.Join(messages, "; "))
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("failed to decode GraphQL data: %w", err)
+	}
+	return nil
+}
+
+// ListIssuesWithComments fetches the issues of the repository that pass
+// filter together with their comments, using one GraphQL request per page
+// of issues instead of one REST request per issue. Issues with more comments
+// than a page holds get theirs from ListComments. Unlike ListIssuesMatching,
+// pull requests are not listed.
+func (c *Client) ListIssuesWithComments(ctx context.Context, owner, repo string, filter IssueFilter) ([]IssueWithComments, error) {
+	variables, err := filter.graphqlVariables(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	variables["owner"] = owner
+	variables["name"] = repo
+	variables["pageSize"] = graphqlPageSize
+
+	var all []IssueWithComments
+	for {
+		var data struct {
+			Repository *struct {
+				Issues struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []graphqlIssue `json:"nodes"`
+				} `json:"issues"`
+			} `json:"repository"`
+		}
+		if err := c.graphql(ctx, issuesQuery, variables, &data); err != nil {
+			return nil, fmt.Errorf("failed to list issues for %s/%s: %w", owner, repo, err)
+		}
+		if data.Repository == nil {
+			return nil, fmt.Errorf("failed to list issues for %s/%s: %w", owner, repo, errNotFound)
+		}
+
+		issues := data.Repository.Issues
+		for _, node := range issues.Nodes {
+			issue := c.issueFromGraphQL(node)
+			// Labels and assignees match loosely on the server, the filter decides
+			if !filter.Matches(&issue.Issue) {
+				continue
+			}
+			if node.Comments.PageInfo.HasNextPage {
+				comments, err := c.ListComments(ctx, owner, repo, node.Number)
+				if err != nil {
+					return nil, err
+				}
+				issue.Comments = comments
+			}
+			all = append(all, issue)
+		}
+
+		if !issues.PageInfo.HasNextPage {
+			return all, nil
+		}
+		variables["cursor"] = issues.PageInfo.EndCursor
+	}
+}
+
+// issueFromGraphQL converts an issue node to the shape of the REST API.
+func (c *Client) issueFromGraphQL(node graphqlIssue) IssueWithComments {
+	issue := Issue{
+		Number:    node.Number,
+		ID:        node.DatabaseID,
+		Title:     node.Title,
+		Body:      node.Body,
+		State:     strings.ToLower(node.State),
+		Labels:    node.Labels.Nodes,
+		Assignees: node.Assignees.Nodes,
+		CreatedAt: node.CreatedAt,
+		UpdatedAt: node.UpdatedAt,
+		HTMLURL:   node.URL,
+	}
+	if node.Author != nil {
+		issue.User = *node.Author
+	}
+	if node.Parent != nil {
+		issue.ParentIssueURL = fmt.Sprintf("%s/repos/%s/issues/%d", c.baseURL, node.Parent.Repository.NameWithOwner, node.Parent.Number)
+	}
+	if s := node.SubIssuesSummary; s != nil {
+		issue.SubIssuesSummary = &SubIssuesSummary{Total: s.Total, Completed: s.Completed, PercentCompleted: s.PercentCompleted}
+	}
+
+	comments := make([]Comment, len(node.Comments.Nodes))
+	for i, n := range node.Comments.Nodes {
+		comments[i] = Comment{ID: n.DatabaseID, Body: n.Body, CreatedAt: n.CreatedAt, UpdatedAt: n.UpdatedAt}
+		if n.Author != nil {
+			comments[i].User = *n.Author
+		}
+	}
+	return IssueWithComments{Issue: issue, Comments: comments}
+}
@@ -0,0 +1,88 @@
+package gh
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListIssuesWithComments(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	mockGH.AddIssue(&Issue{Number: 1, ID: 101, Title: "Parent", State: "open", User: User{Login: "alice"}, CreatedAt: created, UpdatedAt: created,
+		HTMLURL: "https://github.com/owner/repo/issues/1", SubIssuesSummary: &SubIssuesSummary{Total: 1, Completed: 0}})
+	mockGH.AddIssue(&Issue{Number: 2, ID: 102, Title: "Child", State: "open", Labels: []Label{{Name: "bug"}}, CreatedAt: created, UpdatedAt: created,
+		ParentIssueURL: "https://api.github.com/repos/owner/repo/issues/1"})
+	mockGH.AddIssue(&Issue{Number: 3, ID: 103, Title: "Done", State: "closed", CreatedAt: created, UpdatedAt: created})
+	mockGH.AddComment(1, &Comment{ID: 1001, User: User{Login: "bob"}, Body: "First", CreatedAt: created, UpdatedAt: created})
+	mockGH.AddComment(1, &Comment{ID: 1002, User: User{Login: "carol"}, Body: "Second", CreatedAt: created, UpdatedAt: created})
+	mockGH.AddComment(3, &Comment{ID: 1003, Body: "Closing", CreatedAt: created, UpdatedAt: created})
+
+	// Two pages of issues, and more comments on #1 than a page holds
+	mockGH.SetIssuesPerPage(2)
+	mockGH.SetCommentsPerPage(1)
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+	issues, err := client.ListIssuesWithComments(t.Context(), "owner", "repo", IssueFilter{})
+	if err != nil {
+		t.Fatalf("ListIssuesWithComments() error = %v", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3", len(issues))
+	}
+
+	parent, child, done := issues[0], issues[1], issues[2]
+	if parent.ID != 101 || parent.State != "open" || parent.User.Login != "alice" || parent.HTMLURL != "https://github.com/owner/repo/issues/1" {
+		t.Errorf("issue #1 = %+v", parent.Issue)
+	}
+	if parent.SubIssuesSummary == nil || parent.SubIssuesSummary.Total != 1 {
+		t.Errorf("issue #1 sub-issues = %+v, want 1", parent.SubIssuesSummary)
+	}
+	if len(parent.Comments) != 2 || parent.Comments[1].User.Login != "carol" {
+		t.Errorf("issue #1 comments = %+v, want both", parent.Comments)
+	}
+	if !strings.HasSuffix(child.ParentIssueURL, "/repos/owner/repo/issues/1") || len(child.Labels) != 1 || len(child.Comments) != 0 {
+		t.Errorf("issue #2 = %+v", child)
+	}
+	if done.State != "closed" || len(done.Comments) != 1 || done.Comments[0].ID != 1003 {
+		t.Errorf("issue #3 = %+v", done)
+	}
+
+	// The filter applies, including what GraphQL cannot express
+	issues, err = client.ListIssuesWithComments(t.Context(), "owner", "repo", IssueFilter{State: "open", Labels: []string{"bug"}})
+	if err != nil {
+		t.Fatalf("ListIssuesWithComments() with a filter error = %v", err)
+	}
+	if len(issues) != 1 || issues[0].Number != 2 {
+		t.Errorf("filtered issues = %+v, want #2", issues)
+	}
+}
+
+func TestListIssuesWithComments_Error(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	mockGH.SetGraphQLDisabled(true)
+
+	_, err := NewWithBaseURL("test-token", mockGH.URL).ListIssuesWithComments(t.Context(), "owner", "repo", IssueFilter{})
+	if err == nil || !strings.Contains(err.Error(), "GraphQL error") {
+		t.Errorf("ListIssuesWithComments() error = %v, want a GraphQL error", err)
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"github.com", "https://api.github.com/graphql"},
+		{"ghe.example.com", "https://ghe.example.com/api/graphql"},
+	}
+
+	for _, tt := range tests {
+		if got := NewForHost("", tt.host).graphqlURL(); got != tt.want {
+			t.Errorf("graphqlURL() for %s = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package gh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultHost is the host of github.com, used unless a GitHub Enterprise
+// Server host is configured.
+const DefaultHost = "github.com"
+
+// APIBaseURL returns the REST API root of host: api.github.com for
+// github.com, and /api/v3 on a GitHub Enterprise Server.
+func APIBaseURL(host string) string {
+	if host == "" || host == DefaultHost {
+		return apiBaseURL
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// NormalizeHost turns a host given by the user, such as
+// "https://ghe.example.com/" or "api.github.com", into the bare hostname
+// gh uses in its hosts.yml. An empty host is github.com.
+func NormalizeHost(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	host = strings.TrimSuffix(host, "/")
+
+	switch host {
+	case "", "api.github.com", "www.github.com":
+		return DefaultHost, nil
+	}
+	if strings.ContainsAny(host, "/ \\@?#") {
+		return "", fmt.Errorf("invalid host %q: expected a hostname like ghe.example.com", host)
+	}
+	return host, nil
+}
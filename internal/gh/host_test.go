@@ -0,0 +1,39 @@
+package gh
+
+import "testing"
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{"", "github.com", false},
+		{"github.com", "github.com", false},
+		{"api.github.com", "github.com", false},
+		{"GHE.example.com", "ghe.example.com", false},
+		{"https://ghe.example.com/", "ghe.example.com", false},
+		{"ghe.example.com:8443", "ghe.example.com:8443", false},
+		{"ghe.example.com/api/v3", "", true},
+		{"user@ghe.example.com", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeHost(tt.host)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeHost(%q) = %q, %v; want %q, error %v", tt.host, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestAPIBaseURL(t *testing.T) {
+	if got := APIBaseURL("github.com"); got != "https://api.github.com" {
+		t.Errorf("APIBaseURL(github.com) = %q", got)
+	}
+	if got := APIBaseURL("ghe.example.com"); got != "https://ghe.example.com/api/v3" {
+		t.Errorf("APIBaseURL(ghe.example.com) = %q", got)
+	}
+	if got := NewForHost("token", "ghe.example.com").baseURL; got != "https://ghe.example.com/api/v3" {
+		t.Errorf("NewForHost() base URL = %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+package gh
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/JohanCodinha/ghissues/internal/logger"
+)
+
+// ResponseCache stores GET responses by URL, so that requesting a URL again
+// can be made conditional on the stored ETag or Last-Modified.
+type ResponseCache interface {
+	// GetResponse returns the stored header and body of url, or a nil
+	// header when there is none.
+	GetResponse(url string) (http.Header, []byte, error)
+	// PutResponse stores the header and body of a response to url.
+	PutResponse(url string, header http.Header, body []byte) error
+}
+
+// cachingTransport sends the GET requests of repositories that have a
+// response cache conditionally, and answers them from the cache when GitHub
+// replies 304 Not Modified, which does not count against the rate limit.
+type cachingTransport struct {
+	base http.RoundTripper
+
+	mu     sync.RWMutex
+	caches map[string]ResponseCache // "owner/repo" -> cache
+}
+
+// newCachingTransport creates a transport sending requests through base.
+func newCachingTransport(base http.RoundTripper) *cachingTransport {
+	return &cachingTransport{
+		base:   base,
+		caches: make(map[string]ResponseCache),
+	}
+}
+
+// setCache makes the GET requests of repo go through cache, or stops
+// caching them when cache is nil.
+func (t *cachingTransport) setCache(repo string, cache ResponseCache) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cache == nil {
+		delete(t.caches, repo)
+		return
+	}
+	t.caches[repo] = cache
+}
+
+// cacheFor returns the cache of the repository req is about, or nil when
+// req is not a plain GET of a repository with a cache. Requests that are
+// already conditional, like GetIssueWithEtag, manage their own validators.
+// Listings since a sync cursor are skipped, as each cursor is requested
+// once, and so is the repository itself, whose permissions depend on the
+// credentials.
+func (t *cachingTransport) cacheFor(req *http.Request) ResponseCache {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return nil
+	}
+	if req.URL.Query().Has("since") {
+		return nil
+	}
+
+	// Paths are /repos/{owner}/{repo}/..., under /api/v3 on GHES
+	_, rest, ok := strings.Cut(req.URL.Path, "/repos/")
+	if !ok {
+		return nil
+	}
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 3 || parts[2] == "" {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.caches[parts[0]+"/"+parts[1]]
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cache := t.cacheFor(req)
+	if cache == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	header, body, err := cache.GetResponse(key)
+	if err != nil {
+		logger.Debug("gh: %v", err)
+		header = nil
+	}
+	if header != nil {
+		req = req.Clone(req.Context())
+		if etag := header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if modified := header.Get("Last-Modified"); modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && header != nil {
+		resp.Body.Close()
+		return cachedResponse(resp, header, body), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || (resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp, nil
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if err := cache.PutResponse(key, resp.Header, data); err != nil {
+		logger.Debug("gh: %v", err)
+	}
+	return resp, nil
+}
+
+// cachedResponse turns a 304 Not Modified into the stored 200 response,
+// with the headers of the 304, like rate limit counts, taking precedence.
+func cachedResponse(notModified *http.Response, header http.Header, body []byte) *http.Response {
+	merged := header.Clone()
+	for key, values := range notModified.Header {
+		merged[key] = values
+	}
+	merged.Del("Content-Length")
+
+	resp := *notModified
+	resp.Status = "200 OK"
+	resp.StatusCode = http.StatusOK
+	resp.Header = merged
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return &resp
+}
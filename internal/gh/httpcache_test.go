@@ -0,0 +1,134 @@
+package gh
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memResponses is a ResponseCache in memory.
+type memResponses struct {
+	mu       sync.Mutex
+	headers  map[string]http.Header
+	bodies   map[string][]byte
+	getCalls int
+	putCalls int
+}
+
+func newMemResponses() *memResponses {
+	return &memResponses{headers: make(map[string]http.Header), bodies: make(map[string][]byte)}
+}
+
+func (m *memResponses) GetResponse(url string) (http.Header, []byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.getCalls++
+	return m.headers[url], m.bodies[url], nil
+}
+
+func (m *memResponses) PutResponse(url string, header http.Header, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.putCalls++
+	m.headers[url] = header.Clone()
+	m.bodies[url] = body
+	return nil
+}
+
+func TestResponseCache_ConditionalRequests(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	mockGH.AddIssue(&Issue{Number: 1, Title: "Cached", State: "open", CreatedAt: created, UpdatedAt: created})
+	mockGH.AddComment(1, &Comment{ID: 10, Body: "First", CreatedAt: created, UpdatedAt: created})
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+	responses := newMemResponses()
+	client.SetResponseCache("owner/repo", responses)
+
+	// The first request is stored, the second is answered from the cache
+	for i := range 2 {
+		comments, err := client.ListComments(t.Context(), "owner", "repo", 1)
+		if err != nil {
+			t.Fatalf("ListComments() #%d error = %v", i+1, err)
+		}
+		if len(comments) != 1 || comments[0].Body != "First" {
+			t.Fatalf("ListComments() #%d = %+v, want the comment", i+1, comments)
+		}
+	}
+	if got := mockGH.NotModifiedCount(); got != 1 {
+		t.Errorf("304 responses = %d, want 1", got)
+	}
+
+	// A change on GitHub is fetched and stored again
+	mockGH.AddComment(1, &Comment{ID: 11, Body: "Second", CreatedAt: created, UpdatedAt: created})
+	comments, err := client.ListComments(t.Context(), "owner", "repo", 1)
+	if err != nil {
+		t.Fatalf("ListComments() error = %v", err)
+	}
+	if len(comments) != 2 || mockGH.NotModifiedCount() != 1 || responses.putCalls != 2 {
+		t.Errorf("after a change: %d comments, %d 304s, %d stores, want 2, 1, 2", len(comments), mockGH.NotModifiedCount(), responses.putCalls)
+	}
+
+	// An explicit ETag is left to the caller
+	issue, _, err := client.GetIssue(t.Context(), "owner", "repo", 1)
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+	unchanged, _, err := client.GetIssueWithEtag(t.Context(), "owner", "repo", 1, issue.ETag)
+	if err != nil || unchanged != nil {
+		t.Errorf("GetIssueWithEtag() = %+v, %v, want not modified", unchanged, err)
+	}
+}
+
+func TestResponseCache_Scope(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	mockGH.AddIssue(&Issue{Number: 1, Title: "Issue", State: "open"})
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+	other := newMemResponses()
+	client.SetResponseCache("owner/other", other)
+
+	// Requests about repositories without a cache are sent as they are
+	for range 2 {
+		if _, err := client.ListComments(t.Context(), "owner", "repo", 1); err != nil {
+			t.Fatalf("ListComments() error = %v", err)
+		}
+	}
+	if mockGH.NotModifiedCount() != 0 || other.getCalls != 0 {
+		t.Errorf("uncached repo: %d 304s, %d cache reads, want none", mockGH.NotModifiedCount(), other.getCalls)
+	}
+
+	// Writes are never cached
+	responses := newMemResponses()
+	client.SetResponseCache("owner/repo", responses)
+	if _, err := client.CreateComment(t.Context(), "owner", "repo", 1, "Hello"); err != nil {
+		t.Fatalf("CreateComment() error = %v", err)
+	}
+	if responses.getCalls != 0 || responses.putCalls != 0 {
+		t.Errorf("CreateComment() used the cache: %d reads, %d stores", responses.getCalls, responses.putCalls)
+	}
+
+	// Listings since a cursor and the repository itself are never cached
+	mockGH.AddRepository(&Repository{Name: "repo", FullName: "owner/repo"})
+	if _, err := client.ListIssuesSince(t.Context(), "owner", "repo", time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("ListIssuesSince() error = %v", err)
+	}
+	if _, err := client.GetRepository(t.Context(), "owner", "repo"); err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	if responses.getCalls != 0 || responses.putCalls != 0 {
+		t.Errorf("since listing or repository used the cache: %d reads, %d stores", responses.getCalls, responses.putCalls)
+	}
+
+	// Removing the cache stops caching
+	client.SetResponseCache("owner/repo", nil)
+	if _, err := client.ListComments(t.Context(), "owner", "repo", 1); err != nil {
+		t.Fatalf("ListComments() error = %v", err)
+	}
+	if responses.getCalls != 0 {
+		t.Errorf("removed cache was read %d times", responses.getCalls)
+	}
+}
@@ -1,10 +1,15 @@
 package gh
 
 import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,14 +22,32 @@ type MockServer struct {
 	mu       sync.RWMutex
 	issues   map[int]*Issue              // issue number -> issue
 	comments map[int][]*Comment          // issue number -> comments
+	pulls    map[int]*PullRequest        // pull request number -> pull request, also in issues
+	reviews  map[int][]*ReviewComment    // pull request number -> review comments
+	repos    []*Repository               // repositories listed for any owner
 
 	// Pagination settings
 	issuesPerPage   int // 0 means return all in one page
 	commentsPerPage int // 0 means return all in one page
 
 	// Error simulation
-	forceStatusCode int    // If set, return this status code for next request
-	forceErrorBody  string // Error body to return with forceStatusCode
+	forceStatusCode int         // If set, return this status code for next request
+	forceErrorBody  string      // Error body to return with forceStatusCode
+	commentsErrors  map[int]int // issue number -> status code its comments are answered with
+	unreachable     bool        // Drop every connection, as if the network were down
+	graphqlDisabled bool        // Answer GraphQL queries with an error, as servers lacking a field do
+	requests        int         // Requests served, to assert how many a sync takes
+	notModified     int         // Requests answered 304 Not Modified
+
+	// Token simulation
+	tokenScopes *string          // X-OAuth-Scopes header for GET /user, nil omits it
+	permissions *RepoPermissions // permissions in GET /repos/{owner}/{repo}, nil omits them
+
+	// GitHub App simulation
+	appID           int64
+	appKey          *rsa.PublicKey  // nil accepts any token
+	appTokens       map[string]bool // installation tokens currently valid
+	appTokensIssued int
 
 	// Counters for ID generation
 	nextCommentID int64
@@ -36,6 +59,8 @@ func NewMockServer() *MockServer {
 	m := &MockServer{
 		issues:        make(map[int]*Issue),
 		comments:      make(map[int][]*Comment),
+		pulls:         make(map[int]*PullRequest),
+		reviews:       make(map[int][]*ReviewComment),
 		nextCommentID: 1000,
 		nextIssueNum:  1,
 	}
@@ -45,6 +70,12 @@ func NewMockServer() *MockServer {
 	// List issues: GET /repos/{owner}/{repo}/issues
 	mux.HandleFunc("/repos/", func(w http.ResponseWriter, r *http.Request) {
 		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/repos/"), "/")
+
+		// /repos/{owner}/{repo}
+		if len(parts) == 2 && r.Method == http.MethodGet {
+			m.handleGetRepo(w, parts[0]+"/"+parts[1])
+			return
+		}
 		if len(parts) < 3 {
 			http.Error(w, "invalid path", http.StatusBadRequest)
 			return
@@ -63,10 +94,13 @@ func NewMockServer() *MockServer {
 					return
 				}
 			} else if len(parts) == 4 {
-				// Check if this is the /comments endpoint (for updating comments)
+				// Comments of every issue: /repos/{owner}/{repo}/issues/comments
 				if parts[3] == "comments" {
-					// This path shouldn't happen at len==4
-					http.Error(w, "not found", http.StatusNotFound)
+					if r.Method != http.MethodGet {
+						http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+						return
+					}
+					m.handleListRepoComments(w, r, parts[0]+"/"+parts[1])
 					return
 				}
 				// Single issue: /repos/{owner}/{repo}/issues/{number}
@@ -116,13 +150,130 @@ func NewMockServer() *MockServer {
 				return
 			}
 		}
+
+		// /repos/{owner}/{repo}/pulls/...
+		if parts[2] == "pulls" && r.Method == http.MethodGet {
+			switch {
+			case len(parts) == 3:
+				m.handleListPullRequests(w, r)
+				return
+			case len(parts) == 4 && parts[3] == "comments":
+				m.handleListRepoReviewComments(w, r, parts[0]+"/"+parts[1])
+				return
+			case len(parts) == 4 || (len(parts) == 5 && parts[4] == "comments"):
+				number, err := strconv.Atoi(parts[3])
+				if err != nil {
+					http.Error(w, "invalid pull request number", http.StatusBadRequest)
+					return
+				}
+				if len(parts) == 4 {
+					m.handleGetPullRequest(w, number)
+				} else {
+					m.handleListReviewComments(w, number)
+				}
+				return
+			}
+		}
 		http.Error(w, "not found", http.StatusNotFound)
 	})
 
-	m.Server = httptest.NewServer(mux)
+	// List repositories: GET /orgs/{org}/repos and GET /users/{user}/repos
+	reposHandler := func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 3 || parts[2] != "repos" || r.Method != http.MethodGet {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		m.handleListRepos(w, parts[1])
+	}
+	mux.HandleFunc("/orgs/", reposHandler)
+	mux.HandleFunc("/users/", reposHandler)
+
+	// Authenticated user: GET /user
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		m.handleGetUser(w)
+	})
+
+	// GraphQL: POST /graphql
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		m.handleGraphQL(w, r)
+	})
+
+	// Installation token: POST /app/installations/{id}/access_tokens
+	mux.HandleFunc("/app/installations/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 4 || parts[3] != "access_tokens" || r.Method != http.MethodPost {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		m.handleCreateInstallationToken(w, r)
+	})
+
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		unreachable := m.unreachable
+		m.requests++
+		m.mu.Unlock()
+		if unreachable {
+			if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+				conn.Close()
+			}
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/app/") && !m.authorized(r) {
+			http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		m.serveConditional(w, r, mux)
+	}))
 	return m
 }
 
+// serveConditional serves a GET with an ETag, answering 304 Not Modified
+// when it matches If-None-Match, as GitHub does for every GET. Handlers that
+// set their own ETag keep it; others get a hash of the body.
+func (m *MockServer) serveConditional(w http.ResponseWriter, r *http.Request, handler http.Handler) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+
+	code := rec.Code
+	if code == http.StatusOK {
+		etag := rec.Header().Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(rec.Body.Bytes())
+			etag = fmt.Sprintf(`"%x"`, sum[:8])
+			rec.Header().Set("ETag", etag)
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			code = http.StatusNotModified
+		}
+	}
+
+	for key, values := range rec.Header() {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(code)
+	if code == http.StatusNotModified {
+		m.mu.Lock()
+		m.notModified++
+		m.mu.Unlock()
+		return
+	}
+	w.Write(rec.Body.Bytes())
+}
+
 // AddIssue adds an issue to the mock server
 func (m *MockServer) AddIssue(issue *Issue) {
 	m.mu.Lock()
@@ -130,6 +281,32 @@ func (m *MockServer) AddIssue(issue *Issue) {
 	m.issues[issue.Number] = issue
 }
 
+// RemoveIssue deletes an issue and its comments from the mock server, as
+// deleting or transferring it on GitHub does
+func (m *MockServer) RemoveIssue(number int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.issues, number)
+	delete(m.comments, number)
+}
+
+// AddPullRequest adds a pull request to the mock server. Like on GitHub,
+// it is listed among the issues as well.
+func (m *MockServer) AddPullRequest(pr *PullRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pr.PullRequest = &PullRequestRef{URL: fmt.Sprintf("%s/repos/owner/repo/pulls/%d", m.Server.URL, pr.Number)}
+	m.pulls[pr.Number] = pr
+	m.issues[pr.Number] = &pr.Issue
+}
+
+// AddReviewComment adds a review comment to a pull request in the mock server
+func (m *MockServer) AddReviewComment(number int, comment *ReviewComment) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reviews[number] = append(m.reviews[number], comment)
+}
+
 // GetIssue retrieves an issue (for test assertions)
 func (m *MockServer) GetIssue(number int) *Issue {
 	m.mu.RLock()
@@ -143,6 +320,8 @@ func (m *MockServer) Reset() {
 	defer m.mu.Unlock()
 	m.issues = make(map[int]*Issue)
 	m.comments = make(map[int][]*Comment)
+	m.pulls = make(map[int]*PullRequest)
+	m.reviews = make(map[int][]*ReviewComment)
 }
 
 // AddComment adds a comment to an issue in the mock server
@@ -159,6 +338,32 @@ func (m *MockServer) GetComments(issueNumber int) []*Comment {
 	return m.comments[issueNumber]
 }
 
+// AddRepository adds a repository to the mock server, replacing any
+// repository with the same full name
+func (m *MockServer) AddRepository(repo *Repository) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.repos {
+		if existing.FullName == repo.FullName {
+			m.repos[i] = repo
+			return
+		}
+	}
+	m.repos = append(m.repos, repo)
+}
+
+// RemoveRepository removes a repository from the mock server
+func (m *MockServer) RemoveRepository(fullName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.repos {
+		if existing.FullName == fullName {
+			m.repos = append(m.repos[:i], m.repos[i+1:]...)
+			return
+		}
+	}
+}
+
 // SetIssuesPerPage sets pagination for issues (0 = no pagination)
 func (m *MockServer) SetIssuesPerPage(perPage int) {
 	m.mu.Lock()
@@ -181,6 +386,143 @@ func (m *MockServer) SetNextError(statusCode int, body string) {
 	m.forceErrorBody = body
 }
 
+// SetCommentsError makes every listing of the comments of an issue fail
+// with statusCode, 0 makes them succeed again
+func (m *MockServer) SetCommentsError(number, statusCode int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.commentsErrors == nil {
+		m.commentsErrors = make(map[int]int)
+	}
+	m.commentsErrors[number] = statusCode
+}
+
+// SetUnreachable makes the server close every connection without
+// answering, so that clients see network errors
+func (m *MockServer) SetUnreachable(unreachable bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unreachable = unreachable
+}
+
+// SetTokenScopes makes GET /user report scopes in X-OAuth-Scopes, as
+// GitHub does for classic tokens
+func (m *MockServer) SetTokenScopes(scopes string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokenScopes = &scopes
+}
+
+// SetRepoPermissions sets the permissions GET /repos/{owner}/{repo} reports
+func (m *MockServer) SetRepoPermissions(permissions *RepoPermissions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.permissions = permissions
+}
+
+// EnableAppAuth makes the server authenticate GitHub App appID with key:
+// it issues installation tokens for JWTs signed by key, and rejects every
+// other request not carrying one of them
+func (m *MockServer) EnableAppAuth(appID int64, key *rsa.PublicKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.appID = appID
+	m.appKey = key
+	m.appTokens = make(map[string]bool)
+}
+
+// ExpireAppTokens revokes every installation token issued so far
+func (m *MockServer) ExpireAppTokens() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.appTokens = make(map[string]bool)
+}
+
+// AppTokensIssued returns how many installation tokens were issued
+func (m *MockServer) AppTokensIssued() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.appTokensIssued
+}
+
+// authorized reports whether r carries a valid installation token, or
+// always when app authentication is disabled
+func (m *MockServer) authorized(r *http.Request) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.appKey == nil {
+		return true
+	}
+	return m.appTokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+}
+
+func (m *MockServer) handleCreateInstallationToken(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.appKey == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		http.Error(w, `{"message": "A JSON web token could not be decoded"}`, http.StatusUnauthorized)
+		return
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err != nil || rsa.VerifyPKCS1v15(m.appKey, crypto.SHA256, digest[:], signature) != nil {
+		http.Error(w, `{"message": "A JSON web token could not be decoded"}`, http.StatusUnauthorized)
+		return
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Exp int64  `json:"exp"`
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if json.Unmarshal(payload, &claims) != nil || claims.Iss != strconv.FormatInt(m.appID, 10) {
+		http.Error(w, `{"message": "Integration not found"}`, http.StatusUnauthorized)
+		return
+	}
+	if time.Unix(claims.Exp, 0).Before(time.Now()) {
+		http.Error(w, `{"message": "Expiration time' claim ('exp') is too far in the past"}`, http.StatusUnauthorized)
+		return
+	}
+
+	m.appTokensIssued++
+	token := fmt.Sprintf("installation-token-%d", m.appTokensIssued)
+	m.appTokens[token] = true
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"token":      token,
+		"expires_at": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	})
+}
+
+// SetGraphQLDisabled makes GraphQL queries fail, so that clients fall back
+// to the REST API
+func (m *MockServer) SetGraphQLDisabled(disabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.graphqlDisabled = disabled
+}
+
+// RequestCount returns how many requests the server received
+func (m *MockServer) RequestCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.requests
+}
+
+// NotModifiedCount returns how many requests were answered 304 Not Modified
+func (m *MockServer) NotModifiedCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.notModified
+}
+
 // clearError clears any forced error (internal use)
 func (m *MockServer) clearError() (int, string) {
 	code := m.forceStatusCode
@@ -199,15 +541,30 @@ func (m *MockServer) handleListIssues(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter := IssueFilter{
+		State:    r.URL.Query().Get("state"),
+		Assignee: r.URL.Query().Get("assignee"),
+	}
+	if labels := r.URL.Query().Get("labels"); labels != "" {
+		filter.Labels = strings.Split(labels, ",")
+	}
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		since, _ = time.Parse(time.RFC3339, s)
+	}
+
 	issues := make([]*Issue, 0, len(m.issues))
 	for _, issue := range m.issues {
-		issues = append(issues, issue)
+		if filter.Matches(issue) && !issue.UpdatedAt.Before(since) {
+			issues = append(issues, issue)
+		}
 	}
 	perPage := m.issuesPerPage
 	m.mu.Unlock()
 
 	// Sort issues by number for consistent pagination
 	sortIssuesByNumber(issues)
+	total := len(issues)
 
 	// Handle pagination
 	if perPage > 0 {
@@ -230,10 +587,12 @@ func (m *MockServer) handleListIssues(w http.ResponseWriter, r *http.Request) {
 			}
 			issues = issues[start:end]
 
-			// Add Link header for next page if there are more
-			totalPages := (len(m.issues) + perPage - 1) / perPage
+			// Add Link header for next page if there are more, keeping the filter
+			totalPages := (total + perPage - 1) / perPage
 			if page < totalPages {
-				nextURL := fmt.Sprintf("%s%s?page=%d", m.Server.URL, r.URL.Path, page+1)
+				query := r.URL.Query()
+				query.Set("page", strconv.Itoa(page+1))
+				nextURL := fmt.Sprintf("%s%s?%s", m.Server.URL, r.URL.Path, query.Encode())
 				w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, nextURL))
 			}
 		}
@@ -243,6 +602,174 @@ func (m *MockServer) handleListIssues(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(issues)
 }
 
+// handleGraphQL answers the queries of ListIssuesWithComments and
+// ListPullRequestsWithComments. It does not parse the query, only tells
+// them apart and reads their variables.
+func (m *MockServer) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query     string `json:"query"`
+		Variables struct {
+			Cursor   string   `json:"cursor"`
+			PageSize int      `json:"pageSize"`
+			States   []string `json:"states"`
+			Since    string   `json:"since"`
+		} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	vars := request.Variables
+
+	m.mu.Lock()
+	if code, body := m.clearError(); code != 0 {
+		m.mu.Unlock()
+		http.Error(w, body, code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if m.graphqlDisabled {
+		m.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{
+			"errors": []map[string]string{{"message": "Field 'subIssuesSummary' doesn't exist on type 'Issue'"}},
+		})
+		return
+	}
+
+	pulls := strings.Contains(request.Query, "pullRequests(")
+	var filter IssueFilter
+	if len(vars.States) > 0 && !slices.Contains(vars.States, "OPEN") {
+		filter.State = "closed"
+	} else if len(vars.States) == 1 {
+		filter.State = "open"
+	}
+	since, _ := time.Parse(time.RFC3339, vars.Since)
+	issues := make([]*Issue, 0, len(m.issues))
+	for _, issue := range m.issues {
+		if (issue.PullRequest != nil) == pulls && filter.Matches(issue) && !issue.UpdatedAt.Before(since) {
+			issues = append(issues, issue)
+		}
+	}
+	sortIssuesByNumber(issues)
+
+	perPage := vars.PageSize
+	if m.issuesPerPage > 0 {
+		perPage = m.issuesPerPage
+	}
+	start, _ := strconv.Atoi(vars.Cursor)
+	end := min(start+perPage, len(issues))
+	start = min(start, end)
+
+	nodes := make([]map[string]any, 0, end-start)
+	for _, issue := range issues[start:end] {
+		comments := m.comments[issue.Number]
+		moreComments := false
+		if m.commentsPerPage > 0 && len(comments) > m.commentsPerPage {
+			comments, moreComments = comments[:m.commentsPerPage], true
+		}
+		commentNodes := make([]map[string]any, len(comments))
+		for i, c := range comments {
+			commentNodes[i] = map[string]any{
+				"databaseId": c.ID, "body": c.Body, "createdAt": c.CreatedAt, "updatedAt": c.UpdatedAt,
+				"author": map[string]string{"login": c.User.Login},
+			}
+		}
+
+		node := map[string]any{
+			"number": issue.Number, "databaseId": issue.ID, "title": issue.Title, "body": issue.Body,
+			"state": strings.ToUpper(issue.State), "url": issue.HTMLURL,
+			"createdAt": issue.CreatedAt, "updatedAt": issue.UpdatedAt,
+			"author":    map[string]string{"login": issue.User.Login},
+			"labels":    map[string]any{"nodes": issue.Labels},
+			"assignees": map[string]any{"nodes": issue.Assignees},
+			"comments":  map[string]any{"pageInfo": map[string]bool{"hasNextPage": moreComments}, "nodes": commentNodes},
+		}
+		if issue.ParentIssueURL != "" {
+			// .../repos/{owner}/{repo}/issues/{number}
+			parts := strings.Split(issue.ParentIssueURL, "/")
+			number, _ := strconv.Atoi(parts[len(parts)-1])
+			node["parent"] = map[string]any{
+				"number":     number,
+				"repository": map[string]string{"nameWithOwner": parts[len(parts)-4] + "/" + parts[len(parts)-3]},
+			}
+		}
+		if s := issue.SubIssuesSummary; s != nil {
+			node["subIssuesSummary"] = map[string]int{"total": s.Total, "completed": s.Completed, "percentCompleted": s.PercentCompleted}
+		}
+		if pr := m.pulls[issue.Number]; pulls && pr != nil {
+			mergeable := "UNKNOWN"
+			if pr.Mergeable != nil && *pr.Mergeable {
+				mergeable = "MERGEABLE"
+			} else if pr.Mergeable != nil {
+				mergeable = "CONFLICTING"
+			}
+			if pr.Merged {
+				node["state"] = "MERGED"
+			}
+			node["headRefName"], node["baseRefName"] = pr.Head.Ref, pr.Base.Ref
+			node["isDraft"], node["merged"], node["mergeable"] = pr.Draft, pr.Merged, mergeable
+		}
+		nodes = append(nodes, node)
+	}
+	m.mu.Unlock()
+
+	connection := "issues"
+	if pulls {
+		connection = "pullRequests"
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": map[string]any{
+			"repository": map[string]any{
+				connection: map[string]any{
+					"pageInfo": map[string]any{"hasNextPage": end < len(issues), "endCursor": strconv.Itoa(end)},
+					"nodes":    nodes,
+				},
+			},
+		},
+	})
+}
+
+func (m *MockServer) handleListRepos(w http.ResponseWriter, owner string) {
+	m.mu.Lock()
+	if code, body := m.clearError(); code != 0 {
+		m.mu.Unlock()
+		http.Error(w, body, code)
+		return
+	}
+
+	repos := make([]*Repository, 0, len(m.repos))
+	for _, repo := range m.repos {
+		if strings.HasPrefix(repo.FullName, owner+"/") {
+			repos = append(repos, repo)
+		}
+	}
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(repos)
+}
+
+func (m *MockServer) handleGetRepo(w http.ResponseWriter, fullName string) {
+	m.mu.Lock()
+	if code, body := m.clearError(); code != 0 {
+		m.mu.Unlock()
+		http.Error(w, body, code)
+		return
+	}
+
+	repo := Repository{Name: fullName[strings.Index(fullName, "/")+1:], FullName: fullName, HasIssues: true}
+	for _, r := range m.repos {
+		if r.FullName == fullName {
+			repo = *r
+		}
+	}
+	repo.Permissions = m.permissions
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(repo)
+}
+
 // sortIssuesByNumber sorts issues by their number (ascending)
 func sortIssuesByNumber(issues []*Issue) {
 	for i := 0; i < len(issues)-1; i++ {
@@ -317,6 +844,36 @@ func (m *MockServer) handleUpdateIssue(w http.ResponseWriter, r *http.Request, n
 	json.NewEncoder(w).Encode(issue)
 }
 
+func (m *MockServer) handleListRepoComments(w http.ResponseWriter, r *http.Request, fullName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if code, body := m.clearError(); code != 0 {
+		http.Error(w, body, code)
+		return
+	}
+
+	since, _ := time.Parse(time.RFC3339, r.URL.Query().Get("since"))
+	comments := []Comment{}
+	for number, issueComments := range m.comments {
+		for _, c := range issueComments {
+			if c.UpdatedAt.Before(since) {
+				continue
+			}
+			comment := *c
+			comment.IssueURL = fmt.Sprintf("%s/repos/%s/issues/%d", m.Server.URL, fullName, number)
+			comments = append(comments, comment)
+		}
+	}
+	for i := 1; i < len(comments); i++ {
+		for j := i; j > 0 && comments[j].UpdatedAt.Before(comments[j-1].UpdatedAt); j-- {
+			comments[j], comments[j-1] = comments[j-1], comments[j]
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
 func (m *MockServer) handleListComments(w http.ResponseWriter, r *http.Request, number int) {
 	m.mu.Lock()
 	// Check for forced error
@@ -325,6 +882,11 @@ func (m *MockServer) handleListComments(w http.ResponseWriter, r *http.Request,
 		http.Error(w, body, code)
 		return
 	}
+	if code := m.commentsErrors[number]; code != 0 {
+		m.mu.Unlock()
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
 
 	comments := m.comments[number]
 	if comments == nil {
@@ -368,6 +930,87 @@ func (m *MockServer) handleListComments(w http.ResponseWriter, r *http.Request,
 	json.NewEncoder(w).Encode(comments)
 }
 
+func (m *MockServer) handleListPullRequests(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	if code, body := m.clearError(); code != 0 {
+		m.mu.Unlock()
+		http.Error(w, body, code)
+		return
+	}
+	pulls := make([]*PullRequest, 0, len(m.pulls))
+	for _, pr := range m.pulls {
+		listed := *pr
+		// Listings leave out what GitHub computes per pull request
+		listed.Merged, listed.Mergeable = false, nil
+		listed.PullRequest = nil
+		pulls = append(pulls, &listed)
+	}
+	m.mu.Unlock()
+
+	slices.SortFunc(pulls, func(a, b *PullRequest) int { return a.Number - b.Number })
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pulls)
+}
+
+func (m *MockServer) handleGetPullRequest(w http.ResponseWriter, number int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if code, body := m.clearError(); code != 0 {
+		http.Error(w, body, code)
+		return
+	}
+	pr, ok := m.pulls[number]
+	if !ok {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pr)
+}
+
+func (m *MockServer) handleListReviewComments(w http.ResponseWriter, number int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if code, body := m.clearError(); code != 0 {
+		http.Error(w, body, code)
+		return
+	}
+	comments := m.reviews[number]
+	if comments == nil {
+		comments = []*ReviewComment{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+func (m *MockServer) handleListRepoReviewComments(w http.ResponseWriter, r *http.Request, fullName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if code, body := m.clearError(); code != 0 {
+		http.Error(w, body, code)
+		return
+	}
+
+	since, _ := time.Parse(time.RFC3339, r.URL.Query().Get("since"))
+	comments := []ReviewComment{}
+	for number, pullComments := range m.reviews {
+		for _, c := range pullComments {
+			if c.UpdatedAt.Before(since) {
+				continue
+			}
+			comment := *c
+			comment.PullRequestURL = fmt.Sprintf("%s/repos/%s/pulls/%d", m.Server.URL, fullName, number)
+			comments = append(comments, comment)
+		}
+	}
+	slices.SortStableFunc(comments, func(a, b ReviewComment) int { return a.UpdatedAt.Compare(b.UpdatedAt) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
 func (m *MockServer) handleCreateComment(w http.ResponseWriter, r *http.Request, number int) {
 	m.mu.Lock()
 	// Check for forced error first
@@ -511,3 +1154,20 @@ func (m *MockServer) handleCreateIssue(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(issue)
 }
+
+func (m *MockServer) handleGetUser(w http.ResponseWriter) {
+	m.mu.Lock()
+	if code, body := m.clearError(); code != 0 {
+		m.mu.Unlock()
+		http.Error(w, body, code)
+		return
+	}
+	scopes := m.tokenScopes
+	m.mu.Unlock()
+
+	if scopes != nil {
+		w.Header().Set("X-OAuth-Scopes", *scopes)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(User{Login: "mock-user"})
+}
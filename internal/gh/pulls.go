@@ -0,0 +1,301 @@
+package gh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// PullRequest is a pull request along with the fields issues lack.
+type PullRequest struct {
+	Issue
+	Head      PullRequestBranch `json:"head"`
+	Base      PullRequestBranch `json:"base"`
+	Draft     bool              `json:"draft"`
+	Merged    bool              `json:"merged"` // not set by listings, which only have MergedAt
+	MergedAt  *time.Time        `json:"merged_at"`
+	Mergeable *bool             `json:"mergeable"` // nil while GitHub computes it, and in listings
+}
+
+// PullRequestBranch is the head or base of a pull request.
+type PullRequestBranch struct {
+	Ref   string `json:"ref"`
+	Label string `json:"label,omitempty"` // "owner:ref"
+}
+
+// PullRequestWithComments is a pull request along with all of its
+// conversation comments.
+type PullRequestWithComments struct {
+	PullRequest
+	Comments []Comment
+}
+
+// ReviewComment is a comment on a line of the diff of a pull request.
+type ReviewComment struct {
+	ID             int64     `json:"id"`
+	User           User      `json:"user"`
+	Body           string    `json:"body"`
+	Path           string    `json:"path"`
+	Line           int       `json:"line"` // 0 when the line is gone from the diff
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	PullRequestURL string    `json:"pull_request_url,omitempty"` // API URL of the pull request commented on
+}
+
+// MergeState summarizes whether the pull request was merged or could be:
+// "merged", "mergeable", "conflicting", or "unknown" when GitHub has not
+// computed it yet.
+func (p *PullRequest) MergeState() string {
+	switch {
+	case p.Merged || p.MergedAt != nil:
+		return "merged"
+	case p.Mergeable == nil:
+		return "unknown"
+	case *p.Mergeable:
+		return "mergeable"
+	default:
+		return "conflicting"
+	}
+}
+
+// GetPullRequest fetches a single pull request by number.
+func (c *Client) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.baseURL, owner, repo, number)
+
+	resp, err := c.doRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d for %s/%s: %w", number, owner, repo, err)
+	}
+	defer resp.Body.Close()
+
+	checkRateLimit(ctx, resp)
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get pull request #%d for %s/%s: API error %s - %s", number, owner, repo, resp.Status, string(body))
+	}
+
+	var pr PullRequest
+	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
+		return nil, fmt.Errorf("failed to decode pull request #%d response for %s/%s: %w", number, owner, repo, err)
+	}
+	return &pr, nil
+}
+
+// ListPullRequests fetches the pull requests of the repository, open and
+// closed, that pass filter. Handles pagination automatically.
+func (c *Client) ListPullRequests(ctx context.Context, owner, repo string, filter IssueFilter) ([]PullRequest, error) {
+	var all []PullRequest
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls?state=all&per_page=100", c.baseURL, owner, repo)
+
+	for url != "" {
+		resp, err := c.doRequest(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests for %s/%s: %w", owner, repo, err)
+		}
+
+		checkRateLimit(ctx, resp)
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list pull requests for %s/%s: API error %s - %s", owner, repo, resp.Status, string(body))
+		}
+
+		var pulls []PullRequest
+		if err := json.NewDecoder(resp.Body).Decode(&pulls); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decode pull requests response for %s/%s: %w", owner, repo, err)
+		}
+
+		url = getNextPageURL(resp.Header.Get("Link"))
+		resp.Body.Close()
+
+		for _, pr := range pulls {
+			if filter.Matches(&pr.Issue) {
+				all = append(all, pr)
+			}
+		}
+	}
+
+	return all, nil
+}
+
+// ListReviewComments fetches the review comments of a pull request.
+// Handles pagination automatically.
+func (c *Client) ListReviewComments(ctx context.Context, owner, repo string, number int) ([]ReviewComment, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments?per_page=100", c.baseURL, owner, repo, number)
+	return c.listReviewComments(ctx, url, fmt.Sprintf("pull request #%d in %s/%s", number, owner, repo))
+}
+
+// ListRepoReviewCommentsSince fetches the review comments on every pull
+// request of the repository created or edited at or after since, or all of
+// them when since is zero. Each comment's PullRequestURL tells which pull
+// request it is on.
+func (c *Client) ListRepoReviewCommentsSince(ctx context.Context, owner, repo string, since time.Time) ([]ReviewComment, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/comments?sort=updated&direction=asc&per_page=100", c.baseURL, owner, repo)
+	if !since.IsZero() {
+		url += "&since=" + since.UTC().Format(time.RFC3339)
+	}
+	return c.listReviewComments(ctx, url, fmt.Sprintf("%s/%s", owner, repo))
+}
+
+// listReviewComments fetches every page of a review comment listing
+// starting at url. what names the listing in errors.
+func (c *Client) listReviewComments(ctx context.Context, url, what string) ([]ReviewComment, error) {
+	var all []ReviewComment
+
+	for url != "" {
+		resp, err := c.doRequest(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list review comments for %s: %w", what, err)
+		}
+
+		checkRateLimit(ctx, resp)
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list review comments for %s: API error %s - %s", what, resp.Status, string(body))
+		}
+
+		var comments []ReviewComment
+		if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decode review comments response for %s: %w", what, err)
+		}
+
+		url = getNextPageURL(resp.Header.Get("Link"))
+		resp.Body.Close()
+
+		all = append(all, comments...)
+	}
+
+	return all, nil
+}
+
+// pullRequestsQuery pages through the pull requests of a repository along
+// with their comments, labels, assignees, branches and merge state.
+const pullRequestsQuery = `query($owner: String!, $name: String!, $cursor: String, $pageSize: Int!, $states: [PullRequestState!], $labels: [String!]) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(first: $pageSize, after: $cursor, states: $states, labels: $labels, orderBy: {field: CREATED_AT, direction: ASC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number
+        databaseId
+        title
+        body
+        state
+        url
+        createdAt
+        updatedAt
+        author { login }
+        labels(first: 100) { nodes { name color } }
+        assignees(first: 100) { nodes { login } }
+        headRefName
+        baseRefName
+        isDraft
+        merged
+        mergeable
+        comments(first: 100) {
+          pageInfo { hasNextPage }
+          nodes { databaseId body createdAt updatedAt author { login } }
+        }
+      }
+    }
+  }
+}`
+
+// graphqlPullRequest is a pull request node of pullRequestsQuery.
+type graphqlPullRequest struct {
+	graphqlIssue
+	HeadRefName string `json:"headRefName"`
+	BaseRefName string `json:"baseRefName"`
+	IsDraft     bool   `json:"isDraft"`
+	Merged      bool   `json:"merged"`
+	Mergeable   string `json:"mergeable"` // MERGEABLE, CONFLICTING or UNKNOWN
+}
+
+// ListPullRequestsWithComments fetches the pull requests of the repository
+// that pass filter together with their conversation comments, one GraphQL
+// request per page like ListIssuesWithComments.
+func (c *Client) ListPullRequestsWithComments(ctx context.Context, owner, repo string, filter IssueFilter) ([]PullRequestWithComments, error) {
+	variables := map[string]any{"owner": owner, "name": repo, "pageSize": graphqlPageSize}
+	switch filter.State {
+	case "open":
+		variables["states"] = []string{"OPEN"}
+	case "closed":
+		variables["states"] = []string{"CLOSED", "MERGED"}
+	}
+	if len(filter.Labels) > 0 {
+		variables["labels"] = filter.Labels
+	}
+
+	var all []PullRequestWithComments
+	for {
+		var data struct {
+			Repository *struct {
+				PullRequests struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []graphqlPullRequest `json:"nodes"`
+				} `json:"pullRequests"`
+			} `json:"repository"`
+		}
+		if err := c.graphql(ctx, pullRequestsQuery, variables, &data); err != nil {
+			return nil, fmt.Errorf("failed to list pull requests for %s/%s: %w", owner, repo, err)
+		}
+		if data.Repository == nil {
+			return nil, fmt.Errorf("failed to list pull requests for %s/%s: %w", owner, repo, errNotFound)
+		}
+
+		pulls := data.Repository.PullRequests
+		for _, node := range pulls.Nodes {
+			pr := c.pullRequestFromGraphQL(owner, repo, node)
+			if !filter.Matches(&pr.Issue) {
+				continue
+			}
+			if node.Comments.PageInfo.HasNextPage {
+				comments, err := c.ListComments(ctx, owner, repo, node.Number)
+				if err != nil {
+					return nil, err
+				}
+				pr.Comments = comments
+			}
+			all = append(all, pr)
+		}
+
+		if !pulls.PageInfo.HasNextPage {
+			return all, nil
+		}
+		variables["cursor"] = pulls.PageInfo.EndCursor
+	}
+}
+
+// pullRequestFromGraphQL converts a pull request node to the shape of the
+// REST API, where merged pull requests are closed.
+func (c *Client) pullRequestFromGraphQL(owner, repo string, node graphqlPullRequest) PullRequestWithComments {
+	issue := c.issueFromGraphQL(node.graphqlIssue)
+	if node.Merged {
+		issue.State = "closed"
+	}
+
+	pr := PullRequest{
+		Issue:  issue.Issue,
+		Head:   PullRequestBranch{Ref: node.HeadRefName},
+		Base:   PullRequestBranch{Ref: node.BaseRefName},
+		Draft:  node.IsDraft,
+		Merged: node.Merged,
+	}
+	pr.PullRequest = &PullRequestRef{URL: fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.baseURL, owner, repo, node.Number)}
+	if node.Mergeable != "UNKNOWN" {
+		mergeable := node.Mergeable == "MERGEABLE"
+		pr.Mergeable = &mergeable
+	}
+	return PullRequestWithComments{PullRequest: pr, Comments: issue.Comments}
+}
@@ -0,0 +1,167 @@
+package gh
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// addTestPulls adds an issue, an open mergeable pull request with a review
+// comment, and a merged one.
+func addTestPulls(mockGH *MockServer) {
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	mergeable := true
+	mockGH.AddIssue(&Issue{Number: 1, Title: "An issue", State: "open", CreatedAt: created, UpdatedAt: created})
+	mockGH.AddPullRequest(&PullRequest{
+		Issue:     Issue{Number: 2, ID: 202, Title: "Add feature", State: "open", User: User{Login: "alice"}, CreatedAt: created, UpdatedAt: created},
+		Head:      PullRequestBranch{Ref: "feature"},
+		Base:      PullRequestBranch{Ref: "main"},
+		Draft:     true,
+		Mergeable: &mergeable,
+	})
+	mockGH.AddPullRequest(&PullRequest{
+		Issue:  Issue{Number: 3, ID: 203, Title: "Fix bug", State: "closed", CreatedAt: created, UpdatedAt: created},
+		Head:   PullRequestBranch{Ref: "fix"},
+		Base:   PullRequestBranch{Ref: "main"},
+		Merged: true,
+	})
+	mockGH.AddComment(2, &Comment{ID: 1001, Body: "Looks good", CreatedAt: created, UpdatedAt: created})
+	mockGH.AddReviewComment(2, &ReviewComment{ID: 5001, User: User{Login: "bob"}, Body: "Nit", Path: "main.go", Line: 12, CreatedAt: created, UpdatedAt: created})
+}
+
+func TestMergeState(t *testing.T) {
+	yes, no := true, false
+	merged := time.Now()
+
+	tests := []struct {
+		name string
+		pr   PullRequest
+		want string
+	}{
+		{"merged", PullRequest{Merged: true}, "merged"},
+		{"merged in a listing", PullRequest{MergedAt: &merged}, "merged"},
+		{"mergeable", PullRequest{Mergeable: &yes}, "mergeable"},
+		{"conflicting", PullRequest{Mergeable: &no}, "conflicting"},
+		{"not computed yet", PullRequest{}, "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pr.MergeState(); got != tt.want {
+				t.Errorf("MergeState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPullRequest(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	addTestPulls(mockGH)
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+	pr, err := client.GetPullRequest(t.Context(), "owner", "repo", 2)
+	if err != nil {
+		t.Fatalf("GetPullRequest() error = %v", err)
+	}
+	if pr.Head.Ref != "feature" || pr.Base.Ref != "main" || !pr.Draft || pr.MergeState() != "mergeable" || !pr.IsPullRequest() {
+		t.Errorf("GetPullRequest() = %+v", pr)
+	}
+
+	if _, err := client.GetPullRequest(t.Context(), "owner", "repo", 99); err == nil {
+		t.Error("GetPullRequest() of a missing pull request should fail")
+	}
+}
+
+func TestListPullRequests(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	addTestPulls(mockGH)
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+	pulls, err := client.ListPullRequests(t.Context(), "owner", "repo", IssueFilter{})
+	if err != nil {
+		t.Fatalf("ListPullRequests() error = %v", err)
+	}
+	if len(pulls) != 2 || pulls[0].Number != 2 || pulls[1].Number != 3 {
+		t.Fatalf("ListPullRequests() = %+v, want #2 and #3", pulls)
+	}
+	// Listings do not compute mergeability
+	if pulls[0].Mergeable != nil || pulls[0].Head.Ref != "feature" {
+		t.Errorf("pull request #2 = %+v", pulls[0])
+	}
+
+	pulls, err = client.ListPullRequests(t.Context(), "owner", "repo", IssueFilter{State: "closed"})
+	if err != nil {
+		t.Fatalf("ListPullRequests() with a filter error = %v", err)
+	}
+	if len(pulls) != 1 || pulls[0].Number != 3 {
+		t.Errorf("closed pull requests = %+v, want #3", pulls)
+	}
+}
+
+func TestListPullRequestsWithComments(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	addTestPulls(mockGH)
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+	pulls, err := client.ListPullRequestsWithComments(t.Context(), "owner", "repo", IssueFilter{})
+	if err != nil {
+		t.Fatalf("ListPullRequestsWithComments() error = %v", err)
+	}
+	if len(pulls) != 2 {
+		t.Fatalf("got %d pull requests, want 2", len(pulls))
+	}
+
+	open, merged := pulls[0], pulls[1]
+	if !open.IsPullRequest() || open.Head.Ref != "feature" || !open.Draft || open.MergeState() != "mergeable" {
+		t.Errorf("pull request #2 = %+v", open.PullRequest)
+	}
+	if len(open.Comments) != 1 || open.Comments[0].ID != 1001 {
+		t.Errorf("pull request #2 comments = %+v", open.Comments)
+	}
+	if merged.State != "closed" || merged.MergeState() != "merged" {
+		t.Errorf("pull request #3 state = %q, merge state = %q, want closed and merged", merged.State, merged.MergeState())
+	}
+
+	// Merged pull requests are closed ones
+	pulls, err = client.ListPullRequestsWithComments(t.Context(), "owner", "repo", IssueFilter{State: "closed"})
+	if err != nil {
+		t.Fatalf("ListPullRequestsWithComments() with a filter error = %v", err)
+	}
+	if len(pulls) != 1 || pulls[0].Number != 3 {
+		t.Errorf("closed pull requests = %+v, want #3", pulls)
+	}
+}
+
+func TestListReviewComments(t *testing.T) {
+	mockGH := NewMockServer()
+	defer mockGH.Close()
+	addTestPulls(mockGH)
+
+	client := NewWithBaseURL("test-token", mockGH.URL)
+	comments, err := client.ListReviewComments(t.Context(), "owner", "repo", 2)
+	if err != nil {
+		t.Fatalf("ListReviewComments() error = %v", err)
+	}
+	if len(comments) != 1 || comments[0].Path != "main.go" || comments[0].Line != 12 || comments[0].User.Login != "bob" {
+		t.Errorf("ListReviewComments() = %+v", comments)
+	}
+
+	all, err := client.ListRepoReviewCommentsSince(t.Context(), "owner", "repo", time.Time{})
+	if err != nil {
+		t.Fatalf("ListRepoReviewCommentsSince() error = %v", err)
+	}
+	if len(all) != 1 || !strings.HasSuffix(all[0].PullRequestURL, "/pulls/2") {
+		t.Errorf("ListRepoReviewCommentsSince() = %+v, want the comment on #2", all)
+	}
+
+	recent, err := client.ListRepoReviewCommentsSince(t.Context(), "owner", "repo", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ListRepoReviewCommentsSince() error = %v", err)
+	}
+	if len(recent) != 0 {
+		t.Errorf("ListRepoReviewCommentsSince() after the comment = %+v, want none", recent)
+	}
+}
@@ -0,0 +1,115 @@
+package gh
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy says how many times and how long apart a request is retried
+// after a transient failure: a rate limit, a 502, 503 or 504 from GitHub's
+// gateway, or a dropped connection.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts per request, the first one
+	// included. Values below 1 mean a single attempt.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry. It doubles with
+	// every retry up to MaxDelay, and each wait is jittered so that
+	// clients failing together do not retry together.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of new clients.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// secondaryRateLimitWait is how long to wait out a secondary rate limit
+// that does not say for how long, as GitHub recommends.
+const secondaryRateLimitWait = 60 * time.Second
+
+// canRetry reports whether a request that already made attempts may be
+// sent again.
+func (p RetryPolicy) canRetry(attempts int) bool {
+	return attempts < p.MaxAttempts
+}
+
+// backoff returns the jittered wait before retry number n, counting from 1.
+// It is picked between half and all of the exponential delay.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < n && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxDelay)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isIdempotent reports whether sending a request twice does no more than
+// sending it once. A POST creates an issue or a comment, except for a
+// GraphQL query which only reads.
+func isIdempotent(method, url string) bool {
+	if method != http.MethodPost {
+		return true
+	}
+	return strings.HasSuffix(url, "/graphql")
+}
+
+// isConnectionReset reports whether err is the connection being dropped
+// while the request was in flight, which long syncs see now and then. It
+// is not known whether GitHub handled the request.
+func isConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isGatewayError reports whether status is GitHub's gateway failing to
+// get an answer in time. The request may still have been handled.
+func isGatewayError(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// isSecondaryRateLimit reports whether resp is a 403 for a secondary rate
+// limit rather than a lack of permission. Such requests were rejected
+// before being handled, so they can always be retried. The body of resp
+// is kept readable.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return true
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+// retryAfter returns the wait asked for by the Retry-After header of resp,
+// given in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,290 @@
+package gh
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedServer answers requests with the statuses of script in turn, and
+// 200 once the script is over. A status of 0 drops the connection.
+type scriptedServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	script   []scriptedResponse
+	requests int
+}
+
+type scriptedResponse struct {
+	status int
+	header map[string]string
+	body   string
+}
+
+func newScriptedServer(t *testing.T, script ...scriptedResponse) *scriptedServer {
+	t.Helper()
+
+	s := &scriptedServer{script: script}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		var next scriptedResponse
+		if s.requests < len(s.script) {
+			next = s.script[s.requests]
+		} else {
+			next = scriptedResponse{status: http.StatusOK, body: `{"number": 1, "title": "Created"}`}
+		}
+		s.requests++
+		s.mu.Unlock()
+
+		if next.status == 0 {
+			if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+				conn.Close()
+			}
+			return
+		}
+		for key, value := range next.header {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(next.status)
+		w.Write([]byte(next.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Requests returns the number of requests served.
+func (s *scriptedServer) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// recordSleeps replaces sleepFunc for the test and returns the waits asked for.
+func recordSleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+
+	var sleeps []time.Duration
+	originalSleep := sleepFunc
+	sleepFunc = func(ctx context.Context, d time.Duration) {
+		sleeps = append(sleeps, d)
+	}
+	t.Cleanup(func() { sleepFunc = originalSleep })
+	return &sleeps
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		retry    int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{4, 2500 * time.Millisecond, 5 * time.Second},
+		{9, 2500 * time.Millisecond, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			if got := policy.backoff(tt.retry); got < tt.min || got > tt.max {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", tt.retry, got, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"seconds", "30", 30 * time.Second, true},
+		{"past date", "Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+		{"missing", "", 0, false},
+		{"invalid", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: make(http.Header)}
+			if tt.value != "" {
+				resp.Header.Set("Retry-After", tt.value)
+			}
+			got, ok := retryAfter(resp)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDoRequest_Retries(t *testing.T) {
+	secondary := scriptedResponse{
+		status: http.StatusForbidden,
+		body:   `{"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`,
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		script       []scriptedResponse
+		wantStatus   int // 0 expects a request error
+		wantRequests int
+		wantSleeps   []time.Duration // exact waits, or nil to count wantSleepN backoffs
+		wantSleepN   int
+	}{
+		{
+			name:         "gateway error on a read",
+			method:       http.MethodGet,
+			path:         "/repos/owner/repo/issues/1",
+			script:       []scriptedResponse{{status: http.StatusBadGateway}, {status: http.StatusGatewayTimeout}},
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+			wantSleepN:   2,
+		},
+		{
+			name:         "gateway error on a create",
+			method:       http.MethodPost,
+			path:         "/repos/owner/repo/issues",
+			script:       []scriptedResponse{{status: http.StatusBadGateway}},
+			wantStatus:   http.StatusBadGateway,
+			wantRequests: 1,
+		},
+		{
+			name:         "gateway error on a GraphQL query",
+			method:       http.MethodPost,
+			path:         "/graphql",
+			script:       []scriptedResponse{{status: http.StatusServiceUnavailable, header: map[string]string{"Retry-After": "3"}}},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+			wantSleeps:   []time.Duration{3 * time.Second},
+		},
+		{
+			name:         "secondary rate limit on a create",
+			method:       http.MethodPost,
+			path:         "/repos/owner/repo/issues",
+			script:       []scriptedResponse{secondary},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+			wantSleeps:   []time.Duration{secondaryRateLimitWait},
+		},
+		{
+			name:   "secondary rate limit with Retry-After",
+			method: http.MethodPatch,
+			path:   "/repos/owner/repo/issues/1",
+			script: []scriptedResponse{{
+				status: http.StatusForbidden,
+				header: map[string]string{"Retry-After": "10"},
+				body:   `{"message": "You have exceeded a secondary rate limit."}`,
+			}},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+			wantSleeps:   []time.Duration{10 * time.Second},
+		},
+		{
+			name:         "forbidden",
+			method:       http.MethodGet,
+			path:         "/repos/owner/repo/issues/1",
+			script:       []scriptedResponse{{status: http.StatusForbidden, body: `{"message": "Resource not accessible by integration"}`}},
+			wantStatus:   http.StatusForbidden,
+			wantRequests: 1,
+		},
+		{
+			name:         "connection reset on a read",
+			method:       http.MethodGet,
+			path:         "/repos/owner/repo/issues/1",
+			script:       []scriptedResponse{{status: 0}},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+			wantSleepN:   1,
+		},
+		{
+			name:         "connection reset on a create",
+			method:       http.MethodPost,
+			path:         "/repos/owner/repo/issues",
+			script:       []scriptedResponse{{status: 0}},
+			wantRequests: 1,
+		},
+		{
+			name:   "attempts exhausted",
+			method: http.MethodGet,
+			path:   "/repos/owner/repo/issues/1",
+			script: []scriptedResponse{
+				{status: http.StatusServiceUnavailable},
+				{status: http.StatusServiceUnavailable},
+				{status: http.StatusServiceUnavailable},
+				{status: http.StatusServiceUnavailable},
+			},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 4,
+			wantSleepN:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sleeps := recordSleeps(t)
+			server := newScriptedServer(t, tt.script...)
+			client := NewWithBaseURL("test-token", server.URL)
+
+			var body io.Reader
+			if tt.method != http.MethodGet {
+				body = strings.NewReader(`{"title": "New"}`)
+			}
+			resp, err := client.doRequest(t.Context(), tt.method, server.URL+tt.path, body)
+
+			if tt.wantStatus == 0 {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("doRequest() status = %d, want an error", resp.StatusCode)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("doRequest() error = %v", err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("doRequest() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+			}
+
+			if got := server.Requests(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if tt.wantSleeps != nil {
+				if !slices.Equal(*sleeps, tt.wantSleeps) {
+					t.Errorf("sleeps = %v, want %v", *sleeps, tt.wantSleeps)
+				}
+			} else if len(*sleeps) != tt.wantSleepN {
+				t.Errorf("sleeps = %v, want %d backoffs", *sleeps, tt.wantSleepN)
+			}
+		})
+	}
+}
+
+func TestDoRequest_RetryPolicy(t *testing.T) {
+	recordSleeps(t)
+	server := newScriptedServer(t, scriptedResponse{status: http.StatusBadGateway})
+
+	client := NewWithBaseURL("test-token", server.URL)
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+	resp, err := client.doRequest(t.Context(), http.MethodGet, server.URL+"/repos/owner/repo/issues/1", nil)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || server.Requests() != 1 {
+		t.Errorf("status = %d after %d requests, want a single 502", resp.StatusCode, server.Requests())
+	}
+}
@@ -0,0 +1,316 @@
+// Package importer reads issues to create from CSV and JSON files and
+// validates them before they are queued as pending issues.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Supported import formats.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// MaxTitleLength is the longest issue title GitHub accepts.
+const MaxTitleLength = 256
+
+// Row is an issue read from an import file.
+type Row struct {
+	Line   int    // line (CSV, JSON Lines) or element (JSON array) number, from 1
+	Key    string // identifies the row across runs of the same import
+	Title  string
+	Body   string
+	Labels []string
+}
+
+// RowError is a validation error for one row.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// FormatFromPath guesses the import format from a file extension.
+func FormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json", ".jsonl", ".ndjson":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("cannot tell the format of %s: use --format csv or json", path)
+	}
+}
+
+// Parse reads every row of r in the given format and validates them. If any
+// row is invalid, the returned error joins a *RowError for each of them and
+// no rows are returned.
+func Parse(r io.Reader, format string) ([]Row, error) {
+	var rows []Row
+	var err error
+
+	switch format {
+	case FormatCSV:
+		rows, err = parseCSV(r)
+	case FormatJSON:
+		rows, err = parseJSON(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q: must be csv or json", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validate(rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseCSV reads a CSV file whose header names the columns. "title" is
+// required; "body", "labels" (separated by ";") and "id" are optional.
+func parseCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("empty CSV file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("CSV header has no title column")
+	}
+	for name := range columns {
+		switch name {
+		case "id", "title", "body", "labels":
+		default:
+			return nil, fmt.Errorf("unknown CSV column %q: expected id, title, body or labels", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, Row{
+			Line:   line,
+			Key:    field(record, "id"),
+			Title:  field(record, "title"),
+			Body:   field(record, "body"),
+			Labels: splitLabels(field(record, "labels")),
+		})
+	}
+
+	return rows, nil
+}
+
+// splitLabels splits a ";"-separated CSV labels cell.
+func splitLabels(cell string) []string {
+	if strings.TrimSpace(cell) == "" {
+		return nil
+	}
+	var labels []string
+	for _, label := range strings.Split(cell, ";") {
+		labels = append(labels, strings.TrimSpace(label))
+	}
+	return labels
+}
+
+// jsonRow is an issue in a JSON import file.
+type jsonRow struct {
+	ID     json.RawMessage `json:"id"`
+	Title  string          `json:"title"`
+	Body   string          `json:"body"`
+	Labels []string        `json:"labels"`
+}
+
+// parseJSON reads either a JSON array of issues or one issue per line
+// (JSON Lines).
+func parseJSON(r io.Reader) ([]Row, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON: %w", err)
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return parseJSONArray(trimmed)
+	}
+	return parseJSONLines(data)
+}
+
+func parseJSONArray(data []byte) ([]Row, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	var rows []Row
+	var errs []error
+	for i, item := range raw {
+		row, err := decodeJSONRow(item, i+1)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return rows, nil
+}
+
+func parseJSONLines(data []byte) ([]Row, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+
+	var rows []Row
+	var errs []error
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		row, err := decodeJSONRow(text, line)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read JSON Lines: %w", err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return rows, nil
+}
+
+func decodeJSONRow(data []byte, line int) (Row, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var jr jsonRow
+	if err := dec.Decode(&jr); err != nil {
+		return Row{}, &RowError{Line: line, Err: err}
+	}
+
+	row := Row{Line: line, Title: jr.Title, Body: jr.Body, Labels: jr.Labels}
+	if len(jr.ID) > 0 && string(jr.ID) != "null" {
+		var s string
+		if err := json.Unmarshal(jr.ID, &s); err == nil {
+			row.Key = s
+		} else {
+			// Numeric ids are kept as written
+			row.Key = string(jr.ID)
+		}
+	}
+	return row, nil
+}
+
+// validate checks every row and fills in the keys of rows without an id. It
+// reports all invalid rows at once.
+func validate(rows []Row) error {
+	var errs []error
+	seen := make(map[string]int)
+
+	for i := range rows {
+		row := &rows[i]
+		row.Title = strings.TrimSpace(row.Title)
+
+		var problems []string
+		if row.Title == "" {
+			problems = append(problems, "title is empty")
+		} else if utf8.RuneCountInString(row.Title) > MaxTitleLength {
+			problems = append(problems, fmt.Sprintf("title is longer than %d characters", MaxTitleLength))
+		}
+		for _, label := range row.Labels {
+			if strings.TrimSpace(label) == "" {
+				problems = append(problems, "empty label")
+				break
+			}
+		}
+
+		key := strings.TrimSpace(row.Key)
+		if key != "" {
+			row.Key = "id:" + key
+		} else {
+			row.Key = contentKey(*row)
+		}
+		if first, dup := seen[row.Key]; dup {
+			if key != "" {
+				problems = append(problems, fmt.Sprintf("id %q already used by row %d", key, first))
+			} else {
+				problems = append(problems, fmt.Sprintf("duplicate of row %d", first))
+			}
+		} else {
+			seen[row.Key] = row.Line
+		}
+
+		if len(problems) > 0 {
+			errs = append(errs, &RowError{Line: row.Line, Err: errors.New(strings.Join(problems, ", "))})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// contentKey identifies a row without an id by its title, body and labels.
+func contentKey(row Row) string {
+	h := sha256.New()
+	for _, s := range append([]string{row.Title, row.Body}, row.Labels...) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse_CSV(t *testing.T) {
+	input := "id,title,body,labels\n" +
+		"A-1,First issue,\"multi\nline body\",bug; ui\n" +
+		",Second issue,,\n"
+
+	rows, err := Parse(strings.NewReader(input), FormatCSV)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	first := rows[0]
+	if first.Line != 2 || first.Key != "id:A-1" || first.Title != "First issue" || first.Body != "multi\nline body" {
+		t.Errorf("unexpected first row: %+v", first)
+	}
+	if len(first.Labels) != 2 || first.Labels[0] != "bug" || first.Labels[1] != "ui" {
+		t.Errorf("labels = %q, expected [bug ui]", first.Labels)
+	}
+
+	second := rows[1]
+	if second.Line != 4 || !strings.HasPrefix(second.Key, "sha256:") || second.Labels != nil {
+		t.Errorf("unexpected second row: %+v", second)
+	}
+}
+
+func TestParse_JSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "array",
+			input: `[{"id": 7, "title": "First", "labels": ["bug"]}, {"title": "Second", "body": "text"}]`,
+		},
+		{
+			name:  "json lines",
+			input: "{\"id\": 7, \"title\": \"First\", \"labels\": [\"bug\"]}\n\n{\"title\": \"Second\", \"body\": \"text\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Parse(strings.NewReader(tt.input), FormatJSON)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(rows) != 2 {
+				t.Fatalf("expected 2 rows, got %d", len(rows))
+			}
+			if rows[0].Key != "id:7" || rows[0].Title != "First" || len(rows[0].Labels) != 1 {
+				t.Errorf("unexpected first row: %+v", rows[0])
+			}
+			if rows[1].Title != "Second" || rows[1].Body != "text" || !strings.HasPrefix(rows[1].Key, "sha256:") {
+				t.Errorf("unexpected second row: %+v", rows[1])
+			}
+		})
+	}
+}
+
+func TestParse_ReportsEveryInvalidRow(t *testing.T) {
+	input := "id,title,labels\n" +
+		"1,Fine,\n" +
+		"2,,\n" +
+		"3," + strings.Repeat("x", MaxTitleLength+1) + ",\n" +
+		"1,Reused id,\n" +
+		"4,Bad labels,bug;;ui\n"
+
+	rows, err := Parse(strings.NewReader(input), FormatCSV)
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	if rows != nil {
+		t.Errorf("no rows should be returned when validation fails, got %d", len(rows))
+	}
+
+	for _, want := range []string{
+		"row 3: title is empty",
+		"row 4: title is longer than 256 characters",
+		`row 5: id "1" already used by row 2`,
+		"row 6: empty label",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "row 2:") {
+		t.Errorf("valid row reported as invalid:\n%v", err)
+	}
+
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) {
+		t.Error("expected errors.As to find a *RowError")
+	}
+}
+
+func TestParse_DuplicateContentWithoutID(t *testing.T) {
+	input := `[{"title": "Same"}, {"title": "Same"}, {"title": "Same", "body": "different"}]`
+
+	_, err := Parse(strings.NewReader(input), FormatJSON)
+	if err == nil || !strings.Contains(err.Error(), "row 2: duplicate of row 1") {
+		t.Errorf("expected a duplicate error for row 2, got %v", err)
+	}
+	if strings.Contains(err.Error(), "row 3") {
+		t.Errorf("row 3 differs and should be accepted:\n%v", err)
+	}
+}
+
+func TestParse_StructuralErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		format  string
+		wantErr string
+	}{
+		{"no title column", "name,body\nx,y\n", FormatCSV, "no title column"},
+		{"unknown column", "title,assignee\nx,y\n", FormatCSV, `unknown CSV column "assignee"`},
+		{"empty csv", "", FormatCSV, "empty CSV file"},
+		{"unknown json field", `{"title": "x", "assignee": "me"}`, FormatJSON, "row 1:"},
+		{"bad json line", "{\"title\": \"x\"}\n{oops\n", FormatJSON, "row 2:"},
+		{"unknown format", "", "xml", "unknown format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input), tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, expected it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContentKey_StableAndDistinct(t *testing.T) {
+	a := contentKey(Row{Title: "T", Body: "B", Labels: []string{"x"}})
+	if a != contentKey(Row{Line: 9, Title: "T", Body: "B", Labels: []string{"x"}}) {
+		t.Error("content key should not depend on the row position")
+	}
+	// Field boundaries matter
+	if contentKey(Row{Title: "TB"}) == contentKey(Row{Title: "T", Body: "B"}) {
+		t.Error("content key should separate fields")
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"backlog.csv", FormatCSV, false},
+		{"backlog.CSV", FormatCSV, false},
+		{"backlog.json", FormatJSON, false},
+		{"backlog.jsonl", FormatJSON, false},
+		{"backlog.txt", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := FormatFromPath(tt.path)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("FormatFromPath(%q) = %q, %v", tt.path, got, err)
+		}
+	}
+}
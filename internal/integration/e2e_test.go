@@ -67,7 +67,7 @@ func TestE2E_MountReadWrite(t *testing.T) {
 	defer engine.Stop()
 
 	// Initial sync
-	if err := engine.InitialSync(); err != nil {
+	if err := engine.InitialSync(t.Context()); err != nil {
 		t.Fatalf("initial sync failed: %v", err)
 	}
 
@@ -227,7 +227,7 @@ func TestE2E_IssueWithComments(t *testing.T) {
 	defer engine.Stop()
 
 	// Initial sync
-	if err := engine.InitialSync(); err != nil {
+	if err := engine.InitialSync(t.Context()); err != nil {
 		t.Fatalf("initial sync failed: %v", err)
 	}
 
@@ -336,7 +336,7 @@ func TestE2E_OfflineMode(t *testing.T) {
 	cacheDB, _ := cache.InitDB(cachePath)
 	client := gh.NewWithBaseURL("test-token", mockGH.URL)
 	engine, _ := sync.NewEngine(cacheDB, client, "test/repo", 100)
-	engine.InitialSync()
+	engine.InitialSync(t.Context())
 
 	// Shut down mock server (simulate offline)
 	mockGH.Close()
@@ -420,7 +420,7 @@ func TestE2E_LargeIssueWithManyComments(t *testing.T) {
 	}
 	defer engine.Stop()
 
-	if err := engine.InitialSync(); err != nil {
+	if err := engine.InitialSync(t.Context()); err != nil {
 		t.Fatalf("initial sync failed: %v", err)
 	}
 
@@ -558,7 +558,7 @@ func TestE2E_SimilarTitles(t *testing.T) {
 	}
 	defer engine.Stop()
 
-	if err := engine.InitialSync(); err != nil {
+	if err := engine.InitialSync(t.Context()); err != nil {
 		t.Fatalf("initial sync failed: %v", err)
 	}
 
@@ -658,7 +658,7 @@ func TestE2E_EmptyIssue(t *testing.T) {
 	}
 	defer engine.Stop()
 
-	if err := engine.InitialSync(); err != nil {
+	if err := engine.InitialSync(t.Context()); err != nil {
 		t.Fatalf("initial sync failed: %v", err)
 	}
 
@@ -779,7 +779,7 @@ func TestE2E_GrepAcrossFiles(t *testing.T) {
 	}
 	defer engine.Stop()
 
-	if err := engine.InitialSync(); err != nil {
+	if err := engine.InitialSync(t.Context()); err != nil {
 		t.Fatalf("initial sync failed: %v", err)
 	}
 
@@ -854,7 +854,7 @@ func TestE2E_AddNewComment(t *testing.T) {
 	}
 	defer engine.Stop()
 
-	if err := engine.InitialSync(); err != nil {
+	if err := engine.InitialSync(t.Context()); err != nil {
 		t.Fatalf("initial sync failed: %v", err)
 	}
 
@@ -952,7 +952,7 @@ func TestE2E_EditExistingComment(t *testing.T) {
 	}
 	defer engine.Stop()
 
-	if err := engine.InitialSync(); err != nil {
+	if err := engine.InitialSync(t.Context()); err != nil {
 		t.Fatalf("initial sync failed: %v", err)
 	}
 
@@ -1135,7 +1135,7 @@ func TestE2E_FindCommand(t *testing.T) {
 	}
 	defer engine.Stop()
 
-	if err := engine.InitialSync(); err != nil {
+	if err := engine.InitialSync(t.Context()); err != nil {
 		t.Fatalf("initial sync failed: %v", err)
 	}
 
@@ -1172,3 +1172,142 @@ func TestE2E_FindCommand(t *testing.T) {
 		}
 	})
 }
+
+// TestE2E_MultiRepoMount tests that several repositories share one mount
+// under owner/repo subdirectories, each synced by its own engine
+func TestE2E_MultiRepoMount(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("FUSE tests require root or CAP_SYS_ADMIN")
+	}
+
+	tmpDir := t.TempDir()
+	mountpoint := filepath.Join(tmpDir, "mount")
+	if err := os.MkdirAll(mountpoint, 0755); err != nil {
+		t.Fatalf("failed to create mountpoint: %v", err)
+	}
+
+	// Each repo has its own issue #1
+	repos := []string{"org/a", "org/b"}
+	mocks := make([]*gh.MockServer, len(repos))
+	engines := make([]*sync.Engine, len(repos))
+	fsRepos := make([]fs.Repo, len(repos))
+
+	for i, repo := range repos {
+		mockGH := gh.NewMockServer()
+		defer mockGH.Close()
+		mockGH.AddIssue(&gh.Issue{
+			Number:    1,
+			Title:     "Issue in " + repo,
+			Body:      "Body of " + repo,
+			State:     "open",
+			User:      gh.User{Login: "testuser"},
+			CreatedAt: time.Now().Add(-24 * time.Hour),
+			UpdatedAt: time.Now().Add(-1 * time.Hour),
+		})
+		mocks[i] = mockGH
+
+		cacheDB, err := cache.InitDB(filepath.Join(tmpDir, fmt.Sprintf("cache%d.db", i)))
+		if err != nil {
+			t.Fatalf("failed to init cache: %v", err)
+		}
+		defer cacheDB.Close()
+
+		engine, err := sync.NewEngine(cacheDB, gh.NewWithBaseURL("test-token", mockGH.URL), repo, 100)
+		if err != nil {
+			t.Fatalf("failed to create sync engine: %v", err)
+		}
+		defer engine.Stop()
+		if err := engine.InitialSync(t.Context()); err != nil {
+			t.Fatalf("initial sync failed: %v", err)
+		}
+		engines[i] = engine
+
+		fsRepos[i] = fs.Repo{
+			Name:           repo,
+			Cache:          cacheDB,
+			OnDirty:        func() { engine.TriggerSync() },
+			StatusProvider: engine,
+		}
+	}
+
+	filesystem := fs.NewMultiFS(fsRepos, mountpoint)
+	mountErr := make(chan error, 1)
+	go func() {
+		mountErr <- filesystem.Mount()
+	}()
+	time.Sleep(500 * time.Millisecond)
+
+	t.Run("Layout", func(t *testing.T) {
+		for _, repo := range repos {
+			entries, err := os.ReadDir(filepath.Join(mountpoint, repo))
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", repo, err)
+			}
+			found := false
+			for _, e := range entries {
+				if strings.HasSuffix(e.Name(), "[1].md") {
+					found = true
+					content, err := os.ReadFile(filepath.Join(mountpoint, repo, e.Name()))
+					if err != nil {
+						t.Fatalf("failed to read issue: %v", err)
+					}
+					if !strings.Contains(string(content), "Body of "+repo) {
+						t.Errorf("%s/%s shows the wrong issue:\n%s", repo, e.Name(), content)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("issue #1 missing from %s", repo)
+			}
+		}
+	})
+
+	t.Run("AggregatedStatus", func(t *testing.T) {
+		content, err := os.ReadFile(filepath.Join(mountpoint, ".status"))
+		if err != nil {
+			t.Fatalf("failed to read .status: %v", err)
+		}
+		if !strings.Contains(string(content), "Repositories: 2") {
+			t.Errorf("unexpected .status:\n%s", content)
+		}
+	})
+
+	t.Run("WriteSyncsOwnRepo", func(t *testing.T) {
+		entries, _ := os.ReadDir(filepath.Join(mountpoint, "org", "b"))
+		var filePath string
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), "[1].md") {
+				filePath = filepath.Join(mountpoint, "org", "b", e.Name())
+			}
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		newContent := strings.Replace(string(content), "Body of org/b", "Edited in b", 1)
+		if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+
+		time.Sleep(300 * time.Millisecond)
+
+		if body := mocks[1].GetIssue(1).Body; !strings.Contains(body, "Edited in b") {
+			t.Errorf("org/b was not synced: %s", body)
+		}
+		if body := mocks[0].GetIssue(1).Body; body != "Body of org/a" {
+			t.Errorf("org/a should be untouched, got %s", body)
+		}
+	})
+
+	if err := filesystem.Unmount(); err != nil {
+		t.Logf("unmount warning: %v", err)
+	}
+	select {
+	case err := <-mountErr:
+		if err != nil {
+			t.Logf("mount returned: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Log("mount did not return in time")
+	}
+}
@@ -74,6 +74,10 @@ type frontmatter struct {
 	ParentIssue        int      `yaml:"parent_issue,omitempty"`
 	SubIssuesTotal     int      `yaml:"sub_issues_total,omitempty"`
 	SubIssuesCompleted int      `yaml:"sub_issues_completed,omitempty"`
+	Head               string   `yaml:"head,omitempty"`
+	Base               string   `yaml:"base,omitempty"`
+	MergeState         string   `yaml:"merge_state,omitempty"`
+	Draft              bool     `yaml:"draft,omitempty"`
 }
 
 // ToMarkdown converts a cache.Issue to markdown format with YAML frontmatter.
@@ -87,11 +91,20 @@ func ToMarkdown(issue *cache.Issue, comments ...[]cache.Comment) string {
 		issueComments = comments[0]
 	}
 
+	// The URL comes from GitHub, so it points at the right host; caches
+	// synced before it was recorded fall back to github.com
+	url := issue.URL
+	if url == "" && issue.Kind == cache.KindPull {
+		url = fmt.Sprintf("https://github.com/%s/pull/%d", issue.Repo, issue.Number)
+	} else if url == "" {
+		url = fmt.Sprintf("https://github.com/%s/issues/%d", issue.Repo, issue.Number)
+	}
+
 	// Build frontmatter
 	fm := frontmatter{
 		ID:                 issue.Number,
 		Repo:               issue.Repo,
-		URL:                fmt.Sprintf("https://github.com/%s/issues/%d", issue.Repo, issue.Number),
+		URL:                url,
 		State:              issue.State,
 		Labels:             issue.Labels,
 		Author:             issue.Author,
@@ -102,6 +115,10 @@ func ToMarkdown(issue *cache.Issue, comments ...[]cache.Comment) string {
 		ParentIssue:        issue.ParentIssueNumber,
 		SubIssuesTotal:     issue.SubIssuesTotal,
 		SubIssuesCompleted: issue.SubIssuesCompleted,
+		Head:               issue.HeadRef,
+		Base:               issue.BaseRef,
+		MergeState:         issue.MergeState,
+		Draft:              issue.Draft,
 	}
 
 	// Marshal frontmatter to YAML
@@ -161,6 +178,34 @@ func ToMarkdown(issue *cache.Issue, comments ...[]cache.Comment) string {
 	return sb.String()
 }
 
+// PullToMarkdown converts a cached pull request to markdown like ToMarkdown,
+// followed by a ## Review comments section holding its review comments.
+func PullToMarkdown(pull *cache.Issue, comments []cache.Comment, reviewComments []cache.ReviewComment) string {
+	var sb strings.Builder
+	sb.WriteString(ToMarkdown(pull, comments))
+	if len(reviewComments) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("\n## Review comments\n")
+	for _, comment := range reviewComments {
+		// Format: ### 2026-01-10T14:12:00Z - username on path/to/file.go:42
+		sb.WriteString(fmt.Sprintf("\n### %s - %s on %s", comment.CreatedAt, comment.Author, comment.Path))
+		if comment.Line > 0 {
+			sb.WriteString(fmt.Sprintf(":%d", comment.Line))
+		}
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("<!-- review_comment_id: %d -->\n", comment.ID))
+
+		sb.WriteString("\n")
+		sb.WriteString(comment.Body)
+		if len(comment.Body) > 0 && !strings.HasSuffix(comment.Body, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
 // FromMarkdown parses markdown content and extracts issue data.
 // Returns an error if the content is malformed or missing required fields.
 func FromMarkdown(content string) (*ParsedIssue, error) {
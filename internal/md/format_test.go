@@ -51,6 +51,13 @@ func TestToMarkdown_ProducesValidFrontmatter(t *testing.T) {
 	}
 }
 
+func TestToMarkdown_URLFromGitHub(t *testing.T) {
+	issue := &cache.Issue{Number: 7, Repo: "owner/repo", Title: "On GHES", URL: "https://ghe.example.com/owner/repo/issues/7"}
+	if result := ToMarkdown(issue); !strings.Contains(result, "url: https://ghe.example.com/owner/repo/issues/7\n") {
+		t.Errorf("expected the URL recorded from GitHub, got:\n%s", result)
+	}
+}
+
 // Test 2: ToMarkdown includes all expected fields
 func TestToMarkdown_IncludesAllExpectedFields(t *testing.T) {
 	issue := &cache.Issue{
@@ -1636,3 +1643,47 @@ Test body`
 		t.Errorf("expected state 'closed', got %q", parsed.State)
 	}
 }
+
+func TestPullToMarkdown(t *testing.T) {
+	pull := &cache.Issue{
+		Number:     12,
+		Repo:       "owner/repo",
+		Title:      "Add feature",
+		Body:       "PR body",
+		State:      "closed",
+		Kind:       cache.KindPull,
+		HeadRef:    "feature",
+		BaseRef:    "main",
+		MergeState: "merged",
+		Draft:      true,
+	}
+	reviewComments := []cache.ReviewComment{
+		{ID: 7, Author: "bob", Path: "main.go", Line: 42, Body: "Nit", CreatedAt: "2026-01-10T14:12:00Z"},
+		{ID: 8, Author: "carol", Path: "old.go", Body: "Outdated", CreatedAt: "2026-01-11T09:00:00Z"},
+	}
+
+	result := PullToMarkdown(pull, nil, reviewComments)
+
+	for _, want := range []string{
+		"url: https://github.com/owner/repo/pull/12\n",
+		"head: feature\n",
+		"base: main\n",
+		"merge_state: merged\n",
+		"draft: true\n",
+		"## Review comments\n",
+		"### 2026-01-10T14:12:00Z - bob on main.go:42\n<!-- review_comment_id: 7 -->\n\nNit\n",
+		"### 2026-01-11T09:00:00Z - carol on old.go\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("PullToMarkdown() missing %q:\n%s", want, result)
+		}
+	}
+
+	// Issues carry none of the pull request fields
+	issue := ToMarkdown(&cache.Issue{Number: 1, Repo: "owner/repo", Title: "Issue"})
+	for _, key := range []string{"head:", "base:", "merge_state:", "draft:"} {
+		if strings.Contains(issue, key) {
+			t.Errorf("issue markdown should not contain %q:\n%s", key, issue)
+		}
+	}
+}
@@ -49,3 +49,54 @@ func (e *Engine) backupConflict(issue cache.Issue) error {
 	logger.Info("sync: backed up local changes to %s", filePath)
 	return nil
 }
+
+// backupDeletedCommentEdits backs up local edits of comments on an issue that
+// no longer exist on GitHub. The cache drops such comments on the next upsert,
+// so this is the last chance to keep the edited text.
+func (e *Engine) backupDeletedCommentEdits(number int, remote []cache.Comment) {
+	dirty, err := e.cache.GetDirtyComments(e.repo)
+	if err != nil {
+		logger.Warn("sync: failed to get dirty comments: %v", err)
+		return
+	}
+
+	remoteIDs := make(map[int64]bool, len(remote))
+	for _, c := range remote {
+		remoteIDs[c.ID] = true
+	}
+
+	for _, c := range dirty {
+		if c.IssueNumber != number || remoteIDs[c.ID] {
+			continue
+		}
+		logger.Warn("sync: comment %d on issue #%d was deleted on GitHub, dropping local edit", c.ID, number)
+		if err := e.backupCommentConflict(c); err != nil {
+			logger.Warn("sync: failed to back up edit of comment %d: %v", c.ID, err)
+		}
+	}
+}
+
+// backupCommentConflict saves the local body of a comment to the .conflicts directory.
+// Files are saved to ~/.cache/ghissues/.conflicts/{repo}/comment_{id}_{timestamp}.md
+func (e *Engine) backupCommentConflict(comment cache.DirtyComment) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	conflictDir := filepath.Join(homeDir, ".cache", "ghissues", ".conflicts", e.repo)
+	if err := os.MkdirAll(conflictDir, 0755); err != nil {
+		return fmt.Errorf("failed to create conflict directory: %w", err)
+	}
+
+	timestamp := time.Now().Format("20060102_150405")
+	filename := fmt.Sprintf("comment_%d_%s.md", comment.ID, timestamp)
+	filePath := filepath.Join(conflictDir, filename)
+
+	if err := os.WriteFile(filePath, []byte(comment.Body), 0644); err != nil {
+		return fmt.Errorf("failed to write conflict file: %w", err)
+	}
+
+	logger.Info("sync: backed up local changes to %s", filePath)
+	return nil
+}
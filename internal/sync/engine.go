@@ -2,6 +2,9 @@
 package sync
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	gosync "sync"
@@ -25,11 +28,22 @@ type Engine struct {
 	// internal state
 	mu     gosync.Mutex
 	timer  *time.Timer
-	stopCh chan struct{}
+	ctx    context.Context // cancelled by Stop, ending background and in-flight requests
+	cancel context.CancelFunc
+	syncMu gosync.Mutex // serializes push passes
 
 	// status tracking
 	lastSyncTime time.Time
 	lastError    error
+	readOnly     bool // only refresh, never push
+	filter       gh.IssueFilter
+	hidePulls    bool // neither sync nor keep pull requests
+
+	// offline state: GitHub is not contacted, changes stay queued
+	offline       bool
+	offlineForced bool          // offline on request, the network is never probed
+	probeMin      time.Duration // first delay between connectivity probes
+	probeMax      time.Duration // cap of the doubling delay
 
 	// background refresh state
 	refreshTimes map[int]time.Time // last refresh time per issue
@@ -46,6 +60,9 @@ func (e *Engine) GetStatus() fs.SyncStatus {
 
 	status := fs.SyncStatus{
 		LastSyncTime: e.lastSyncTime,
+		ReadOnly:     e.readOnly,
+		Offline:      e.offline,
+		Filter:       e.filter.String(),
 	}
 	if e.lastError != nil {
 		status.LastError = e.lastError.Error()
@@ -71,24 +88,189 @@ func (e *Engine) GetStatus() fs.SyncStatus {
 // NewEngine creates a new sync engine.
 // repo should be in "owner/repo" format.
 // debounceMs is the debounce delay in milliseconds for write syncs.
+// Until Stop, client keeps its responses about repo in cacheDB.
 func NewEngine(cacheDB *cache.DB, client *gh.Client, repo string, debounceMs int) (*Engine, error) {
 	owner, repoName, err := parseRepo(repo)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Engine{
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Engine{
+		ctx:          ctx,
+		cancel:       cancel,
 		cache:        cacheDB,
 		client:       client,
 		repo:         repo,
 		owner:        owner,
 		repoName:     repoName,
 		debounceMs:   debounceMs,
-		stopCh:       make(chan struct{}),
 		refreshTimes: make(map[int]time.Time),
 		refreshing:   make(map[int]bool),
 		refreshTTL:   30 * time.Second,
-	}, nil
+		probeMin:     5 * time.Second,
+		probeMax:     5 * time.Minute,
+	}
+
+	// Keep syncing the issues the cache was filled with
+	if stored, err := cacheDB.IssueFilter(repo); err != nil {
+		logger.Warn("sync: %v", err)
+	} else if stored != "" {
+		if err := json.Unmarshal([]byte(stored), &e.filter); err != nil {
+			logger.Warn("sync: ignoring the invalid issue filter of %s: %v", repo, err)
+		}
+	}
+
+	// Refreshing unchanged issues then costs free 304s
+	if client != nil {
+		client.SetResponseCache(repo, cacheDB)
+	}
+
+	return e, nil
+}
+
+// bind returns a context cancelled with ctx or when the engine is stopped,
+// so that Stop also ends the requests of callers.
+func (e *Engine) bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(e.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// SetRefreshTTL sets how long a refreshed issue is left alone before
+// reading it triggers another background refresh.
+func (e *Engine) SetRefreshTTL(ttl time.Duration) {
+	e.refreshMu.Lock()
+	defer e.refreshMu.Unlock()
+	e.refreshTTL = ttl
+}
+
+// SetReadOnly makes the engine keep refreshing issues but never push local
+// changes, which stay queued in the cache.
+func (e *Engine) SetReadOnly(readOnly bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.readOnly = readOnly
+}
+
+// ReadOnly reports whether the engine never pushes.
+func (e *Engine) ReadOnly() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.readOnly
+}
+
+// SetFilter restricts the synced issues to those passing filter. The next
+// InitialSync records it with the cache and drops the issues that fail it.
+func (e *Engine) SetFilter(filter gh.IssueFilter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.filter = filter
+}
+
+// Filter returns the filter of the synced issues.
+func (e *Engine) Filter() gh.IssueFilter {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.filter
+}
+
+// SetHidePulls makes the engine leave pull requests out of its syncs. The
+// next InitialSync drops the pull requests already cached.
+func (e *Engine) SetHidePulls(hide bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.hidePulls = hide
+}
+
+// HidePulls reports whether pull requests are left out.
+func (e *Engine) HidePulls() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.hidePulls
+}
+
+// ErrOffline is returned when GitHub is not contacted because the engine
+// was put offline with SetOffline.
+var ErrOffline = errors.New("offline: changes stay queued in the cache")
+
+// SetOffline puts the engine offline for good: it stops refreshing and
+// pushing, and local changes stay queued in the cache. Unlike the offline
+// state entered on network errors, it is never left automatically.
+func (e *Engine) SetOffline(offline bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.offline = offline
+	e.offlineForced = offline
+}
+
+// Offline reports whether the engine is offline.
+func (e *Engine) Offline() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.offline
+}
+
+// checkNetworkError takes the engine offline if err shows that GitHub
+// cannot be reached, and reports whether it did.
+func (e *Engine) checkNetworkError(err error) bool {
+	if !gh.IsNetworkError(err) {
+		return false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.offline {
+		return true
+	}
+	e.offline = true
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	logger.Warn("sync: %s is offline, queuing changes until GitHub is reachable: %v", e.repo, err)
+	go e.probe()
+	return true
+}
+
+// probe checks GitHub's reachability with exponential backoff until it
+// answers, then brings the engine back online, fetches what changed on
+// GitHub meanwhile and pushes the queued changes.
+func (e *Engine) probe() {
+	ctx := e.ctx
+	delay := e.probeMin
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		if err := e.client.Ping(ctx); err != nil {
+			logger.Debug("sync: %s still offline: %v", e.repo, err)
+			delay = min(delay*2, e.probeMax)
+			continue
+		}
+
+		e.mu.Lock()
+		e.offline = false
+		e.mu.Unlock()
+
+		logger.Info("sync: %s is back online, refreshing and pushing queued changes", e.repo)
+		if err := e.InitialSync(ctx); err != nil {
+			logger.Warn("sync: %v", err)
+			if e.Offline() {
+				return // lost again, checkNetworkError started a new probe
+			}
+		}
+		if err := e.SyncNow(ctx); err != nil {
+			logger.Warn("sync: %v", err)
+		}
+		return
+	}
 }
 
 // parseRepo splits "owner/repo" into owner and repo name.
@@ -121,6 +303,11 @@ func (e *Engine) ghIssueToCacheIssue(ghIssue *gh.Issue) cache.Issue {
 		subIssuesCompleted = ghIssue.SubIssuesSummary.Completed
 	}
 
+	kind := cache.KindIssue
+	if ghIssue.IsPullRequest() {
+		kind = cache.KindPull
+	}
+
 	return cache.Issue{
 		Number:             ghIssue.Number,
 		ID:                 ghIssue.ID,
@@ -137,6 +324,65 @@ func (e *Engine) ghIssueToCacheIssue(ghIssue *gh.Issue) cache.Issue {
 		ParentIssueNumber:  parentIssueNumber,
 		SubIssuesTotal:     subIssuesTotal,
 		SubIssuesCompleted: subIssuesCompleted,
+		URL:                ghIssue.HTMLURL,
+		Kind:               kind,
+	}
+}
+
+// ghPullToCacheIssue converts a GitHub pull request to a cache issue.
+func (e *Engine) ghPullToCacheIssue(pr *gh.PullRequest) cache.Issue {
+	issue := e.ghIssueToCacheIssue(&pr.Issue)
+	issue.Kind = cache.KindPull
+	issue.HeadRef = pr.Head.Ref
+	issue.BaseRef = pr.Base.Ref
+	issue.MergeState = pr.MergeState()
+	issue.Draft = pr.Draft
+	return issue
+}
+
+// remoteCacheIssue converts an issue fetched from GitHub to a cache issue.
+// The issues endpoints lack the branches and merge state of pull requests,
+// so those are fetched again from the pulls endpoint.
+func (e *Engine) remoteCacheIssue(ctx context.Context, ghIssue *gh.Issue) (cache.Issue, error) {
+	if !ghIssue.IsPullRequest() {
+		return e.ghIssueToCacheIssue(ghIssue), nil
+	}
+	pr, err := e.client.GetPullRequest(ctx, e.owner, e.repoName, ghIssue.Number)
+	if err != nil {
+		return cache.Issue{}, fmt.Errorf("failed to fetch pull request: %w", err)
+	}
+	issue := e.ghPullToCacheIssue(pr)
+	issue.ETag = ghIssue.ETag
+	return issue, nil
+}
+
+// ghReviewCommentToCache converts a GitHub review comment on pull request
+// number to a cache review comment.
+func (e *Engine) ghReviewCommentToCache(number int, c gh.ReviewComment) cache.ReviewComment {
+	return cache.ReviewComment{
+		ID:         c.ID,
+		PullNumber: number,
+		Repo:       e.repo,
+		Author:     c.User.Login,
+		Path:       c.Path,
+		Line:       c.Line,
+		Body:       c.Body,
+		CreatedAt:  c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  c.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ghCommentToCacheComment converts a GitHub comment on issue number to a
+// cache comment.
+func (e *Engine) ghCommentToCacheComment(number int, ghComment gh.Comment) cache.Comment {
+	return cache.Comment{
+		ID:          ghComment.ID,
+		IssueNumber: number,
+		Repo:        e.repo,
+		Author:      ghComment.User.Login,
+		Body:        ghComment.Body,
+		CreatedAt:   ghComment.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   ghComment.UpdatedAt.Format(time.RFC3339),
 	}
 }
 
@@ -155,10 +401,10 @@ func parseIssueNumberFromURL(url string) int {
 
 // syncParentIssue syncs the parent-child relationship for an issue.
 // It adds or removes the sub-issue relationship based on the local vs remote state.
-func (e *Engine) syncParentIssue(issue cache.Issue, remoteParentNumber int) error {
+func (e *Engine) syncParentIssue(ctx context.Context, issue cache.Issue, remoteParentNumber int) error {
 	// Get the issue's numeric ID (needed for sub-issue API)
 	// We need to fetch the full issue to get its ID
-	ghIssue, _, err := e.client.GetIssue(e.owner, e.repoName, issue.Number)
+	ghIssue, _, err := e.client.GetIssue(ctx, e.owner, e.repoName, issue.Number)
 	if err != nil {
 		return fmt.Errorf("failed to get issue ID: %w", err)
 	}
@@ -167,7 +413,7 @@ func (e *Engine) syncParentIssue(issue cache.Issue, remoteParentNumber int) erro
 	// If removing parent (was set, now 0)
 	if issue.ParentIssueNumber == 0 && remoteParentNumber > 0 {
 		logger.Debug("sync: removing issue #%d from parent #%d", issue.Number, remoteParentNumber)
-		if err := e.client.RemoveSubIssue(e.owner, e.repoName, remoteParentNumber, issueID); err != nil {
+		if err := e.client.RemoveSubIssue(ctx, e.owner, e.repoName, remoteParentNumber, issueID); err != nil {
 			return fmt.Errorf("failed to remove sub-issue: %w", err)
 		}
 		return nil
@@ -178,7 +424,7 @@ func (e *Engine) syncParentIssue(issue cache.Issue, remoteParentNumber int) erro
 		// If there was an old parent, remove from it first
 		if remoteParentNumber > 0 && remoteParentNumber != issue.ParentIssueNumber {
 			logger.Debug("sync: removing issue #%d from old parent #%d", issue.Number, remoteParentNumber)
-			if err := e.client.RemoveSubIssue(e.owner, e.repoName, remoteParentNumber, issueID); err != nil {
+			if err := e.client.RemoveSubIssue(ctx, e.owner, e.repoName, remoteParentNumber, issueID); err != nil {
 				logger.Warn("sync: failed to remove from old parent: %v", err)
 				// Continue to try adding to new parent
 			}
@@ -186,7 +432,7 @@ func (e *Engine) syncParentIssue(issue cache.Issue, remoteParentNumber int) erro
 
 		// Add to new parent
 		logger.Debug("sync: adding issue #%d as sub-issue of #%d", issue.Number, issue.ParentIssueNumber)
-		if err := e.client.AddSubIssue(e.owner, e.repoName, issue.ParentIssueNumber, issueID); err != nil {
+		if err := e.client.AddSubIssue(ctx, e.owner, e.repoName, issue.ParentIssueNumber, issueID); err != nil {
 			return fmt.Errorf("failed to add sub-issue: %w", err)
 		}
 	}
@@ -194,57 +440,445 @@ func (e *Engine) syncParentIssue(issue cache.Issue, remoteParentNumber int) erro
 	return nil
 }
 
-// InitialSync fetches all issues from GitHub and populates the cache.
-// This should be called on mount.
-func (e *Engine) InitialSync() error {
+// fullSyncInterval is how often InitialSync fetches every issue, which
+// drops the deleted and transferred issues incremental syncs cannot see.
+const fullSyncInterval = 24 * time.Hour
+
+// syncCursorOverlap is how far before the sync cursor an incremental sync
+// starts, so that changes made while the previous sync ran or hidden by
+// clock skew with GitHub are fetched again rather than missed.
+const syncCursorOverlap = 5 * time.Minute
+
+// InitialSync brings the cache up to date with GitHub. The first sync, a
+// sync with another issue filter and one a day fetch every issue and pull
+// request; the others only fetch the ones changed since the last sync,
+// along with the changed comments. This should be called on mount.
+func (e *Engine) InitialSync(ctx context.Context) error {
+	ctx, cancel := e.bind(ctx)
+	defer cancel()
+
 	logger.Debug("sync: starting initial sync for %s", e.repo)
 
-	issues, err := e.client.ListIssues(e.owner, e.repoName)
+	e.mu.Lock()
+	forced := e.offlineForced
+	filter := e.filter
+	hidePulls := e.hidePulls
+	e.mu.Unlock()
+	if forced {
+		return ErrOffline
+	}
+	if hidePulls {
+		e.dropPulls(nil)
+	}
+
+	started := time.Now()
+	since, incremental := e.incrementalSince(filter)
+	var failed int
+	var err error
+	if incremental {
+		failed, err = e.incrementalSync(ctx, filter, since)
+	} else {
+		failed, err = e.fullSync(ctx, filter)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to list issues: %w", err)
+		e.checkNetworkError(err)
+		return err
+	}
+
+	// An incremental sync would not list what failed again, unless it
+	// changed once more, so the next sync has to fetch everything
+	if failed > 0 {
+		logger.Warn("sync: %d items of %s failed to sync, the next sync fetches every issue again", failed, e.repo)
+		err = e.cache.ClearSyncCursor(e.repo)
+	} else {
+		err = e.cache.SetSyncCursor(e.repo, started, !incremental)
+	}
+	if err != nil {
+		logger.Warn("sync: %v", err)
+	}
+	if err := e.cache.RecordSync(e.repo, time.Now()); err != nil {
+		logger.Warn("sync: %v", err)
+	}
+
+	logger.Debug("sync: initial sync complete")
+	return nil
+}
+
+// incrementalSince returns where an incremental sync with filter starts,
+// or false if the cache needs a full sync.
+func (e *Engine) incrementalSince(filter gh.IssueFilter) (time.Time, bool) {
+	cursor, fullSync, err := e.cache.SyncCursor(e.repo)
+	if err != nil {
+		logger.Warn("sync: %v", err)
+		return time.Time{}, false
+	}
+	if cursor.IsZero() || time.Since(fullSync) > fullSyncInterval {
+		return time.Time{}, false
+	}
+
+	stored, err := e.cache.IssueFilter(e.repo)
+	if err != nil {
+		logger.Warn("sync: %v", err)
+		return time.Time{}, false
+	}
+	if encoded, err := encodeFilter(filter); err != nil || encoded != stored {
+		return time.Time{}, false
+	}
+	return cursor.Add(-syncCursorOverlap), true
+}
+
+// fullSync fetches every issue passing filter and its comments, and drops
+// the cached issues no longer listed. It returns how many issues failed to sync.
+func (e *Engine) fullSync(ctx context.Context, filter gh.IssueFilter) (failed int, err error) {
+	issues, withComments, err := e.listIssues(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list issues: %w", err)
 	}
 
 	logger.Debug("sync: fetched %d issues from GitHub", len(issues))
 
 	for _, ghIssue := range issues {
-		cacheIssue := e.ghIssueToCacheIssue(&ghIssue)
-		if err := e.cache.UpsertIssue(cacheIssue); err != nil {
-			logger.Warn("sync: failed to upsert issue #%d: %v", ghIssue.Number, err)
-			// Continue with other issues
+		// Don't overwrite dirty issues - their local changes are still waiting to be pushed
+		cachedIssue, err := e.cache.GetIssue(e.repo, ghIssue.Number)
+		if err != nil {
+			logger.Warn("sync: failed to get cached issue #%d: %v", ghIssue.Number, err)
+		}
+		if cachedIssue != nil && cachedIssue.Dirty {
+			logger.Debug("sync: keeping local changes of dirty issue #%d", ghIssue.Number)
+		} else {
+			cacheIssue := e.ghIssueToCacheIssue(&ghIssue.Issue)
+			if err := e.cache.UpsertIssue(cacheIssue); err != nil {
+				logger.Warn("sync: failed to upsert issue #%d: %v", ghIssue.Number, err)
+				failed++
+				// Continue with other issues
+			}
 		}
 
-		// Fetch comments for this issue
-		if err := e.syncComments(ghIssue.Number); err != nil {
+		// Store the comments that came with the issue, or fetch them
+		if withComments {
+			err = e.storeComments(ghIssue.Number, ghIssue.Comments)
+		} else {
+			err = e.syncComments(ctx, ghIssue.Number)
+		}
+		if err != nil {
 			logger.Warn("sync: failed to sync comments for issue #%d: %v", ghIssue.Number, err)
+			failed++
 			// Continue with other issues
 		}
 	}
 
-	logger.Debug("sync: initial sync complete")
-	return nil
+	e.dropUnlisted(issues)
+	if !e.HidePulls() {
+		pullsFailed, err := e.fullSyncPulls(ctx, filter)
+		if err != nil {
+			return failed, err
+		}
+		failed += pullsFailed
+	}
+	if err := e.recordFilter(filter); err != nil {
+		logger.Warn("sync: %v", err)
+	}
+	return failed, nil
+}
+
+// fullSyncPulls fetches every pull request passing filter with its comments
+// and review comments, and drops the cached ones no longer listed. It
+// returns how many pull requests failed to sync.
+func (e *Engine) fullSyncPulls(ctx context.Context, filter gh.IssueFilter) (failed int, err error) {
+	pulls, withComments, err := e.listPulls(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	reviewComments, err := e.client.ListRepoReviewCommentsSince(ctx, e.owner, e.repoName, time.Time{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list review comments: %w", err)
+	}
+	logger.Debug("sync: fetched %d pull requests and %d review comments from GitHub", len(pulls), len(reviewComments))
+
+	byPull := make(map[int][]gh.ReviewComment)
+	for _, c := range reviewComments {
+		number := parseIssueNumberFromURL(c.PullRequestURL)
+		byPull[number] = append(byPull[number], c)
+	}
+
+	listed := make(map[int]bool, len(pulls))
+	for _, pr := range pulls {
+		listed[pr.Number] = true
+		cachedIssue, err := e.cache.GetIssue(e.repo, pr.Number)
+		if err != nil {
+			logger.Warn("sync: failed to get cached pull request #%d: %v", pr.Number, err)
+		}
+		if cachedIssue != nil && cachedIssue.Dirty {
+			logger.Debug("sync: keeping local changes of dirty pull request #%d", pr.Number)
+		} else if err := e.cache.UpsertIssue(e.ghPullToCacheIssue(&pr.PullRequest)); err != nil {
+			logger.Warn("sync: failed to upsert pull request #%d: %v", pr.Number, err)
+			failed++
+			continue
+		}
+
+		if withComments {
+			err = e.storeComments(pr.Number, pr.Comments)
+		} else {
+			err = e.syncComments(ctx, pr.Number)
+		}
+		if err != nil {
+			logger.Warn("sync: failed to sync comments for pull request #%d: %v", pr.Number, err)
+			failed++
+		}
+		if err := e.storeReviewComments(pr.Number, byPull[pr.Number]); err != nil {
+			logger.Warn("sync: %v", err)
+			failed++
+		}
+	}
+
+	e.dropPulls(listed)
+	return failed, nil
+}
+
+// listPulls lists the pull requests passing filter together with their
+// comments over GraphQL, falling back to REST like listIssues.
+func (e *Engine) listPulls(ctx context.Context, filter gh.IssueFilter) (pulls []gh.PullRequestWithComments, withComments bool, err error) {
+	pulls, err = e.client.ListPullRequestsWithComments(ctx, e.owner, e.repoName, filter)
+	if err == nil {
+		return pulls, true, nil
+	}
+	if !errors.Is(err, gh.ErrGraphQL) {
+		return nil, false, err
+	}
+	logger.Info("sync: bulk fetch of the pull requests of %s failed, falling back to REST: %v", e.repo, err)
+
+	listed, err := e.client.ListPullRequests(ctx, e.owner, e.repoName, filter)
+	if err != nil {
+		return nil, false, err
+	}
+	pulls = make([]gh.PullRequestWithComments, len(listed))
+	for i := range listed {
+		pulls[i].PullRequest = listed[i]
+	}
+	return pulls, false, nil
+}
+
+// dropPulls removes the cached pull requests missing from keep, all of them
+// for a nil keep. Pull requests with local changes are kept until pushed.
+func (e *Engine) dropPulls(keep map[int]bool) {
+	cached, err := e.cache.ListPulls(e.repo)
+	if err != nil {
+		logger.Warn("sync: %v", err)
+		return
+	}
+
+	dropped := 0
+	for _, pull := range cached {
+		if keep[pull.Number] {
+			continue
+		}
+		removed, err := e.cache.RemoveIssue(e.repo, pull.Number)
+		if err != nil {
+			logger.Warn("sync: %v", err)
+		} else if removed {
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		logger.Debug("sync: dropped %d pull requests that are no longer listed", dropped)
+	}
+}
+
+// incrementalSync fetches the issues and comments changed since since and
+// applies them to the cache. Changed issues that no longer pass filter are
+// dropped, and issues that now pass it are fetched with all their comments.
+// It returns how many changed issues and comments failed to sync.
+func (e *Engine) incrementalSync(ctx context.Context, filter gh.IssueFilter, since time.Time) (failed int, err error) {
+	issues, err := e.client.ListIssuesSince(ctx, e.owner, e.repoName, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list issues: %w", err)
+	}
+	comments, err := e.client.ListRepoCommentsSince(ctx, e.owner, e.repoName, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list comments: %w", err)
+	}
+	hidePulls := e.HidePulls()
+	var reviewComments []gh.ReviewComment
+	if !hidePulls {
+		reviewComments, err = e.client.ListRepoReviewCommentsSince(ctx, e.owner, e.repoName, since)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list review comments: %w", err)
+		}
+	}
+	logger.Debug("sync: %d issues, %d comments and %d review comments changed since %s", len(issues), len(comments), len(reviewComments), since.Format(time.RFC3339))
+
+	for _, ghIssue := range issues {
+		if ghIssue.IsPullRequest() && hidePulls {
+			continue
+		}
+		cachedIssue, err := e.cache.GetIssue(e.repo, ghIssue.Number)
+		if err != nil {
+			logger.Warn("sync: failed to get cached issue #%d: %v", ghIssue.Number, err)
+			failed++
+			continue
+		}
+
+		switch {
+		case cachedIssue != nil && cachedIssue.Dirty:
+			logger.Debug("sync: keeping local changes of dirty issue #%d", ghIssue.Number)
+		case !filter.Matches(&ghIssue):
+			if cachedIssue != nil {
+				if _, err := e.cache.RemoveIssue(e.repo, ghIssue.Number); err != nil {
+					logger.Warn("sync: %v", err)
+					failed++
+				}
+			}
+		default:
+			cacheIssue, err := e.remoteCacheIssue(ctx, &ghIssue)
+			if err != nil {
+				logger.Warn("sync: %v", err)
+				failed++
+				continue
+			}
+			if err := e.cache.UpsertIssue(cacheIssue); err != nil {
+				logger.Warn("sync: failed to upsert issue #%d: %v", ghIssue.Number, err)
+				failed++
+				continue
+			}
+			// The comments listings only hold the recent comments of a new issue
+			if cachedIssue == nil {
+				if err := e.syncComments(ctx, ghIssue.Number); err != nil {
+					logger.Warn("sync: failed to sync comments for issue #%d: %v", ghIssue.Number, err)
+					failed++
+				}
+				if cacheIssue.Kind == cache.KindPull {
+					if err := e.syncReviewComments(ctx, ghIssue.Number); err != nil {
+						logger.Warn("sync: %v", err)
+						failed++
+					}
+				}
+			}
+		}
+	}
+
+	for _, ghComment := range comments {
+		number := parseIssueNumberFromURL(ghComment.IssueURL)
+		// Comments on issues outside the filter and on hidden pull requests are not cached
+		if cachedIssue, err := e.cache.GetIssue(e.repo, number); err != nil || cachedIssue == nil {
+			continue
+		}
+		if err := e.cache.UpsertComment(e.ghCommentToCacheComment(number, ghComment)); err != nil {
+			logger.Warn("sync: %v", err)
+			failed++
+		}
+	}
+
+	for _, c := range reviewComments {
+		number := parseIssueNumberFromURL(c.PullRequestURL)
+		if cachedIssue, err := e.cache.GetIssue(e.repo, number); err != nil || cachedIssue == nil || cachedIssue.Kind != cache.KindPull {
+			continue
+		}
+		if err := e.cache.UpsertReviewComment(e.ghReviewCommentToCache(number, c)); err != nil {
+			logger.Warn("sync: %v", err)
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// listIssues lists the issues passing filter together with their comments,
+// in one paginated GraphQL query. When GitHub rejects the query, as servers
+// lacking one of its fields do, it falls back to listing the issues over
+// REST and reports that their comments were not fetched.
+func (e *Engine) listIssues(ctx context.Context, filter gh.IssueFilter) (issues []gh.IssueWithComments, withComments bool, err error) {
+	issues, err = e.client.ListIssuesWithComments(ctx, e.owner, e.repoName, filter)
+	if err == nil {
+		logger.Debug("sync: fetched %d issues with their comments over GraphQL", len(issues))
+		return issues, true, nil
+	}
+	if !errors.Is(err, gh.ErrGraphQL) {
+		return nil, false, err
+	}
+	logger.Info("sync: bulk fetch of %s failed, falling back to REST: %v", e.repo, err)
+
+	listed, err := e.client.ListIssuesMatching(ctx, e.owner, e.repoName, filter)
+	if err != nil {
+		return nil, false, err
+	}
+	issues = make([]gh.IssueWithComments, len(listed))
+	for i := range listed {
+		issues[i].Issue = listed[i]
+	}
+	return issues, false, nil
+}
+
+// dropUnlisted removes the cached issues missing from a complete listing,
+// as they were deleted, transferred or no longer pass the filter. Issues
+// with local changes are kept until they are pushed.
+func (e *Engine) dropUnlisted(listed []gh.IssueWithComments) {
+	numbers := make(map[int]bool, len(listed))
+	for _, issue := range listed {
+		numbers[issue.Number] = true
+	}
+
+	cached, err := e.cache.ListIssues(e.repo)
+	if err != nil {
+		logger.Warn("sync: %v", err)
+		return
+	}
+
+	dropped := 0
+	for _, issue := range cached {
+		if numbers[issue.Number] {
+			continue
+		}
+		removed, err := e.cache.RemoveIssue(e.repo, issue.Number)
+		if err != nil {
+			logger.Warn("sync: %v", err)
+		} else if removed {
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		logger.Debug("sync: dropped %d issues that are no longer listed", dropped)
+	}
+}
+
+// recordFilter stores filter with the cache for later engines of the repo.
+func (e *Engine) recordFilter(filter gh.IssueFilter) error {
+	encoded, err := encodeFilter(filter)
+	if err != nil {
+		return err
+	}
+	return e.cache.SetIssueFilter(e.repo, encoded)
+}
+
+// encodeFilter encodes filter as it is recorded in the cache, "" for the
+// filter matching every issue.
+func encodeFilter(filter gh.IssueFilter) (string, error) {
+	if filter.IsZero() {
+		return "", nil
+	}
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode issue filter: %w", err)
+	}
+	return string(data), nil
 }
 
 // syncComments fetches and caches comments for an issue.
-func (e *Engine) syncComments(number int) error {
-	ghComments, err := e.client.ListComments(e.owner, e.repoName, number)
+func (e *Engine) syncComments(ctx context.Context, number int) error {
+	ghComments, err := e.client.ListComments(ctx, e.owner, e.repoName, number)
 	if err != nil {
 		return fmt.Errorf("failed to list comments: %w", err)
 	}
+	return e.storeComments(number, ghComments)
+}
 
-	// Convert gh.Comment to cache.Comment
+// storeComments replaces the cached comments of an issue with ghComments.
+func (e *Engine) storeComments(number int, ghComments []gh.Comment) error {
 	cacheComments := make([]cache.Comment, len(ghComments))
 	for i, ghComment := range ghComments {
-		cacheComments[i] = cache.Comment{
-			ID:          ghComment.ID,
-			IssueNumber: number,
-			Repo:        e.repo,
-			Author:      ghComment.User.Login,
-			Body:        ghComment.Body,
-			CreatedAt:   ghComment.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   ghComment.UpdatedAt.Format(time.RFC3339),
-		}
+		cacheComments[i] = e.ghCommentToCacheComment(number, ghComment)
 	}
 
+	e.backupDeletedCommentEdits(number, cacheComments)
+
 	if err := e.cache.UpsertComments(e.repo, number, cacheComments); err != nil {
 		return fmt.Errorf("failed to upsert comments: %w", err)
 	}
@@ -253,27 +887,53 @@ func (e *Engine) syncComments(number int) error {
 	return nil
 }
 
+// syncReviewComments fetches and caches the review comments of a pull request.
+func (e *Engine) syncReviewComments(ctx context.Context, number int) error {
+	ghComments, err := e.client.ListReviewComments(ctx, e.owner, e.repoName, number)
+	if err != nil {
+		return err
+	}
+	return e.storeReviewComments(number, ghComments)
+}
+
+// storeReviewComments replaces the cached review comments of a pull request
+// with ghComments.
+func (e *Engine) storeReviewComments(number int, ghComments []gh.ReviewComment) error {
+	comments := make([]cache.ReviewComment, len(ghComments))
+	for i, c := range ghComments {
+		comments[i] = e.ghReviewCommentToCache(number, c)
+	}
+	return e.cache.UpsertReviewComments(e.repo, number, comments)
+}
+
 // RefreshIssue fetches a single issue if the etag has changed (background refresh).
 // Returns true if the issue was updated in cache, false if unchanged or error.
-// This uses conditional requests with If-None-Match header.
-func (e *Engine) RefreshIssue(number int) (bool, error) {
+// This uses conditional requests with If-None-Match header. An issue that is not
+// cached yet (e.g. created on GitHub after the mount started) is fetched unconditionally.
+func (e *Engine) RefreshIssue(ctx context.Context, number int) (bool, error) {
+	ctx, cancel := e.bind(ctx)
+	defer cancel()
+
 	// Get the current cached issue to get its etag
 	cachedIssue, err := e.cache.GetIssue(e.repo, number)
 	if err != nil {
 		return false, fmt.Errorf("failed to get cached issue: %w", err)
 	}
-	if cachedIssue == nil {
-		return false, fmt.Errorf("issue #%d not found in cache", number)
-	}
 
-	// Don't refresh dirty issues - local changes take precedence
-	if cachedIssue.Dirty {
-		logger.Debug("sync: skipping refresh for dirty issue #%d", number)
-		return false, nil
+	etag := ""
+	if cachedIssue != nil {
+		// Don't refresh dirty issues - local changes take precedence
+		if cachedIssue.Dirty {
+			logger.Debug("sync: skipping refresh for dirty issue #%d", number)
+			return false, nil
+		}
+		etag = cachedIssue.ETag
+	} else {
+		logger.Debug("sync: issue #%d not in cache, fetching it", number)
 	}
 
 	// Fetch with etag for conditional request
-	ghIssue, _, err := e.client.GetIssueWithEtag(e.owner, e.repoName, number, cachedIssue.ETag)
+	ghIssue, _, err := e.client.GetIssueWithEtag(ctx, e.owner, e.repoName, number, etag)
 	if err != nil {
 		return false, fmt.Errorf("failed to fetch issue: %w", err)
 	}
@@ -283,18 +943,44 @@ func (e *Engine) RefreshIssue(number int) (bool, error) {
 		return false, nil
 	}
 
+	// An issue that left the filter drops out of the cache
+	if filter := e.Filter(); !filter.Matches(ghIssue) {
+		if cachedIssue == nil {
+			return false, nil
+		}
+		removed, err := e.cache.RemoveIssue(e.repo, number)
+		if err != nil {
+			return false, err
+		}
+		if removed {
+			logger.Debug("sync: issue #%d no longer matches %q, dropped it", number, filter.String())
+		}
+		return removed, nil
+	}
+
 	// Issue was updated - update cache
 	// Note: ghIssue.ETag is set by GetIssueWithEtag, newEtag is the same value
-	cacheIssue := e.ghIssueToCacheIssue(ghIssue)
+	if ghIssue.IsPullRequest() && e.HidePulls() {
+		return false, nil
+	}
+	cacheIssue, err := e.remoteCacheIssue(ctx, ghIssue)
+	if err != nil {
+		return false, err
+	}
 	if err := e.cache.UpsertIssue(cacheIssue); err != nil {
 		return false, fmt.Errorf("failed to update cache: %w", err)
 	}
 
 	// Also refresh comments for this issue
-	if err := e.syncComments(number); err != nil {
+	if err := e.syncComments(ctx, number); err != nil {
 		logger.Warn("sync: failed to refresh comments for issue #%d: %v", number, err)
 		// Don't fail the whole refresh - issue update succeeded
 	}
+	if cacheIssue.Kind == cache.KindPull {
+		if err := e.syncReviewComments(ctx, number); err != nil {
+			logger.Warn("sync: failed to refresh review comments: %v", err)
+		}
+	}
 
 	logger.Debug("sync: refreshed issue #%d from GitHub", number)
 	return true, nil
@@ -305,6 +991,10 @@ func (e *Engine) RefreshIssue(number int) (bool, error) {
 // 2. A refresh isn't already in flight for this issue
 // This method returns immediately and doesn't block the caller.
 func (e *Engine) TriggerRefresh(number int) {
+	if e.Offline() {
+		return
+	}
+
 	e.refreshMu.Lock()
 
 	// Check TTL - skip if recently refreshed
@@ -335,15 +1025,14 @@ func (e *Engine) TriggerRefresh(number int) {
 			e.refreshMu.Unlock()
 		}()
 
-		// Check stopCh before making API call
-		select {
-		case <-e.stopCh:
+		// Check for Stop before making API call
+		if e.ctx.Err() != nil {
 			return
-		default:
 		}
 
-		updated, err := e.RefreshIssue(number)
+		updated, err := e.RefreshIssue(e.ctx, number)
 		if err != nil {
+			e.checkNetworkError(err)
 			logger.Debug("sync: background refresh failed for #%d: %v", number, err)
 		} else if updated {
 			logger.Debug("sync: background refresh updated #%d", number)
@@ -357,6 +1046,10 @@ func (e *Engine) TriggerSync() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if e.readOnly || e.offline {
+		return
+	}
+
 	// Stop existing timer if any
 	if e.timer != nil {
 		e.timer.Stop()
@@ -364,21 +1057,8 @@ func (e *Engine) TriggerSync() {
 
 	// Start new timer
 	e.timer = time.AfterFunc(time.Duration(e.debounceMs)*time.Millisecond, func() {
-		// Sync pending new issues first (so they get issue numbers before comments are added)
-		if err := e.syncPendingIssues(); err != nil {
-			logger.Error("sync: error syncing pending issues: %v", err)
-		}
-		// Sync pending new comments (can now reference correct issue numbers)
-		if err := e.syncPendingComments(); err != nil {
-			logger.Error("sync: error syncing pending comments: %v", err)
-		}
-		// Sync dirty (edited) comments
-		if err := e.syncDirtyComments(); err != nil {
-			logger.Error("sync: error syncing dirty comments: %v", err)
-		}
-		// Sync dirty issues
-		if err := e.syncDirtyIssues(); err != nil {
-			logger.Error("sync: error syncing dirty issues: %v", err)
+		for _, err := range e.push(e.ctx) {
+			logger.Error("sync: error syncing %v", err)
 		}
 	})
 
@@ -387,35 +1067,32 @@ func (e *Engine) TriggerSync() {
 
 // SyncNow immediately syncs all dirty issues, comments, and pending items.
 // This should be called on unmount to ensure all changes are pushed.
-func (e *Engine) SyncNow() error {
+func (e *Engine) SyncNow(ctx context.Context) error {
+	ctx, cancel := e.bind(ctx)
+	defer cancel()
+
 	e.mu.Lock()
 	// Stop any pending timer
 	if e.timer != nil {
 		e.timer.Stop()
 		e.timer = nil
 	}
+	readOnly := e.readOnly
+	forced := e.offlineForced
 	e.mu.Unlock()
 
-	var errs []error
-
-	// Sync pending new issues first (so they get issue numbers before comments are added)
-	if err := e.syncPendingIssues(); err != nil {
-		errs = append(errs, fmt.Errorf("pending issues: %w", err))
-	}
-
-	// Sync pending new comments (can now reference correct issue numbers)
-	if err := e.syncPendingComments(); err != nil {
-		errs = append(errs, fmt.Errorf("pending comments: %w", err))
+	if readOnly {
+		logger.Debug("sync: read-only, not pushing %s", e.repo)
+		return nil
 	}
-
-	// Sync dirty (edited) comments
-	if err := e.syncDirtyComments(); err != nil {
-		errs = append(errs, fmt.Errorf("dirty comments: %w", err))
+	if forced {
+		return ErrOffline
 	}
 
-	// Sync dirty issues
-	if err := e.syncDirtyIssues(); err != nil {
-		errs = append(errs, fmt.Errorf("dirty issues: %w", err))
+	errs := e.push(ctx)
+	if err := ctx.Err(); err != nil {
+		// An interrupted push leaves the rest queued, it did not fail
+		return fmt.Errorf("push of %s interrupted: %w", e.repo, err)
 	}
 
 	// Update status tracking
@@ -437,8 +1114,61 @@ func (e *Engine) SyncNow() error {
 	return nil
 }
 
+// push runs one full pass over the outbox: pending issues, pending comments,
+// dirty comments, then dirty issues. Passes are serialized so a debounced
+// sync and an explicit SyncNow never push the same item twice. A pass that
+// finds GitHub unreachable or is cancelled stops early, as the remaining
+// steps would fail too.
+func (e *Engine) push(ctx context.Context) []error {
+	e.syncMu.Lock()
+	defer e.syncMu.Unlock()
+
+	steps := []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		// Pending new issues first (so they get issue numbers before comments are added)
+		{"pending issues", e.syncPendingIssues},
+		// Pending new comments (can now reference correct issue numbers)
+		{"pending comments", e.syncPendingComments},
+		{"dirty comments", e.syncDirtyComments},
+		{"dirty issues", e.syncDirtyIssues},
+	}
+
+	var errs []error
+	for _, step := range steps {
+		if err := step.run(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
+			if e.Offline() || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// recordPushError remembers why a queued operation failed to push, for
+// "ghissues pending". Failing to record it only loses that detail.
+func (e *Engine) recordPushError(kind string, id int64, pushErr error) {
+	// A cancelled push says nothing about the operation
+	if errors.Is(pushErr, context.Canceled) {
+		return
+	}
+	if err := e.cache.RecordPushError(e.repo, kind, id, pushErr); err != nil {
+		logger.Warn("sync: %v", err)
+	}
+}
+
+// clearPushError forgets the error of an operation that was pushed.
+func (e *Engine) clearPushError(kind string, id int64) {
+	if err := e.cache.ClearPushError(e.repo, kind, id); err != nil {
+		logger.Warn("sync: %v", err)
+	}
+}
+
 // syncDirtyIssues syncs all dirty issues to GitHub.
-func (e *Engine) syncDirtyIssues() error {
+func (e *Engine) syncDirtyIssues(ctx context.Context) error {
 	dirtyIssues, err := e.cache.GetDirtyIssues(e.repo)
 	if err != nil {
 		return fmt.Errorf("failed to get dirty issues: %w", err)
@@ -453,9 +1183,15 @@ func (e *Engine) syncDirtyIssues() error {
 
 	var syncErrors []error
 	for _, issue := range dirtyIssues {
-		if err := e.syncIssue(issue); err != nil {
+		if err := e.syncIssue(ctx, issue); err != nil {
+			e.recordPushError(cache.KindIssueEdit, int64(issue.Number), err)
 			syncErrors = append(syncErrors, fmt.Errorf("issue #%d: %w", issue.Number, err))
+			if e.checkNetworkError(err) || ctx.Err() != nil {
+				break
+			}
+			continue
 		}
+		e.clearPushError(cache.KindIssueEdit, int64(issue.Number))
 	}
 
 	if len(syncErrors) > 0 {
@@ -471,11 +1207,11 @@ func (e *Engine) syncDirtyIssues() error {
 
 // syncIssue syncs a single dirty issue to GitHub.
 // Implements conflict detection: local wins UNLESS remote was updated more recently.
-func (e *Engine) syncIssue(issue cache.Issue) error {
+func (e *Engine) syncIssue(ctx context.Context, issue cache.Issue) error {
 	logger.Debug("sync: checking conflict for issue #%d", issue.Number)
 
 	// Fetch remote to check updated_at
-	remoteIssue, _, err := e.client.GetIssue(e.owner, e.repoName, issue.Number)
+	remoteIssue, _, err := e.client.GetIssue(ctx, e.owner, e.repoName, issue.Number)
 	if err != nil {
 		return fmt.Errorf("failed to fetch remote issue: %w", err)
 	}
@@ -500,14 +1236,17 @@ func (e *Engine) syncIssue(issue cache.Issue) error {
 		}
 
 		// 2. Fetch comments from remote
-		ghComments, err := e.client.ListComments(e.owner, e.repoName, issue.Number)
+		ghComments, err := e.client.ListComments(ctx, e.owner, e.repoName, issue.Number)
 		if err != nil {
 			logger.Warn("sync: failed to fetch remote comments for conflict resolution: %v", err)
 			// Continue with issue update only
 		}
 
 		// 3. Overwrite cache with remote version
-		cacheIssue := e.ghIssueToCacheIssue(remoteIssue)
+		cacheIssue, err := e.remoteCacheIssue(ctx, remoteIssue)
+		if err != nil {
+			return err
+		}
 		if err := e.cache.UpsertIssue(cacheIssue); err != nil {
 			return fmt.Errorf("failed to apply remote issue: %w", err)
 		}
@@ -570,7 +1309,7 @@ func (e *Engine) syncIssue(issue cache.Issue) error {
 	if hasChanges {
 		logger.Debug("sync: pushing issue #%d to GitHub (title: %v, body: %v, state: %v, labels: %v)",
 			issue.Number, update.Title != nil, update.Body != nil, update.State != nil, update.Labels != nil)
-		if err := e.client.UpdateIssue(e.owner, e.repoName, issue.Number, update); err != nil {
+		if err := e.client.UpdateIssue(ctx, e.owner, e.repoName, issue.Number, update); err != nil {
 			return fmt.Errorf("failed to update issue on GitHub: %w", err)
 		}
 	} else {
@@ -580,7 +1319,7 @@ func (e *Engine) syncIssue(issue cache.Issue) error {
 	// Handle parent issue changes (sub-issue relationships are managed via separate API)
 	remoteParentNumber := parseIssueNumberFromURL(remoteIssue.ParentIssueURL)
 	if issue.ParentIssueNumber != remoteParentNumber {
-		if err := e.syncParentIssue(issue, remoteParentNumber); err != nil {
+		if err := e.syncParentIssue(ctx, issue, remoteParentNumber); err != nil {
 			logger.Warn("sync: failed to sync parent issue for #%d: %v", issue.Number, err)
 			// Don't fail the whole sync - the main issue update succeeded
 		}
@@ -592,7 +1331,7 @@ func (e *Engine) syncIssue(issue cache.Issue) error {
 	}
 
 	// Refresh the issue to get updated etag and updated_at
-	if _, err := e.RefreshIssue(issue.Number); err != nil {
+	if _, err := e.RefreshIssue(ctx, issue.Number); err != nil {
 		// Log but don't fail - the sync itself succeeded
 		logger.Warn("sync: failed to refresh issue #%d after sync: %v", issue.Number, err)
 	}
@@ -618,7 +1357,8 @@ func labelsEqual(a, b []string) bool {
 	return true
 }
 
-// Stop stops the sync engine and any pending timers.
+// Stop stops the sync engine and any pending timers, and cancels the
+// requests in flight, including those waiting out a rate limit.
 func (e *Engine) Stop() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -628,12 +1368,10 @@ func (e *Engine) Stop() {
 		e.timer = nil
 	}
 
-	// Signal stop (for any future background goroutines)
-	select {
-	case <-e.stopCh:
-		// Already closed
-	default:
-		close(e.stopCh)
+	// Cancel background goroutines and the requests of every caller
+	e.cancel()
+	if e.client != nil {
+		e.client.SetResponseCache(e.repo, nil)
 	}
 
 	logger.Debug("sync: engine stopped")
@@ -641,7 +1379,10 @@ func (e *Engine) Stop() {
 
 // HasConflict checks if an issue has a conflict (remote is newer than local).
 // Returns true if remote updated_at > local local_updated_at.
-func (e *Engine) HasConflict(number int) (bool, error) {
+func (e *Engine) HasConflict(ctx context.Context, number int) (bool, error) {
+	ctx, cancel := e.bind(ctx)
+	defer cancel()
+
 	cachedIssue, err := e.cache.GetIssue(e.repo, number)
 	if err != nil {
 		return false, fmt.Errorf("failed to get cached issue: %w", err)
@@ -655,7 +1396,7 @@ func (e *Engine) HasConflict(number int) (bool, error) {
 	}
 
 	// Fetch remote
-	remoteIssue, _, err := e.client.GetIssue(e.owner, e.repoName, number)
+	remoteIssue, _, err := e.client.GetIssue(ctx, e.owner, e.repoName, number)
 	if err != nil {
 		return false, fmt.Errorf("failed to fetch remote issue: %w", err)
 	}
@@ -668,8 +1409,34 @@ func (e *Engine) HasConflict(number int) (bool, error) {
 	return remoteIssue.UpdatedAt.After(localUpdatedAt), nil
 }
 
+// DiscardIssueEdits replaces a locally edited issue with its current version
+// on GitHub, dropping the edits instead of pushing them.
+func (e *Engine) DiscardIssueEdits(ctx context.Context, number int) error {
+	ctx, cancel := e.bind(ctx)
+	defer cancel()
+
+	e.syncMu.Lock()
+	defer e.syncMu.Unlock()
+
+	remoteIssue, _, err := e.client.GetIssue(ctx, e.owner, e.repoName, number)
+	if err != nil {
+		return fmt.Errorf("failed to fetch remote issue: %w", err)
+	}
+
+	cacheIssue, err := e.remoteCacheIssue(ctx, remoteIssue)
+	if err != nil {
+		return err
+	}
+	if err := e.cache.ResetIssue(cacheIssue); err != nil {
+		return fmt.Errorf("failed to reset issue #%d: %w", number, err)
+	}
+
+	logger.Debug("sync: discarded local edits of issue #%d", number)
+	return nil
+}
+
 // syncPendingComments syncs all pending (new) comments to GitHub.
-func (e *Engine) syncPendingComments() error {
+func (e *Engine) syncPendingComments(ctx context.Context) error {
 	pendingComments, err := e.cache.GetPendingComments(e.repo)
 	if err != nil {
 		return fmt.Errorf("failed to get pending comments: %w", err)
@@ -685,11 +1452,16 @@ func (e *Engine) syncPendingComments() error {
 	var syncErrors []error
 	for _, pc := range pendingComments {
 		// Create the comment on GitHub
-		_, err := e.client.CreateComment(e.owner, e.repoName, pc.IssueNumber, pc.Body)
+		_, err := e.client.CreateComment(ctx, e.owner, e.repoName, pc.IssueNumber, pc.Body)
 		if err != nil {
+			e.recordPushError(cache.KindNewComment, pc.ID, err)
 			syncErrors = append(syncErrors, fmt.Errorf("comment for issue #%d: %w", pc.IssueNumber, err))
+			if e.checkNetworkError(err) || ctx.Err() != nil {
+				break
+			}
 			continue
 		}
+		e.clearPushError(cache.KindNewComment, pc.ID)
 
 		// Remove from pending after successful sync
 		if err := e.cache.RemovePendingComment(pc.ID); err != nil {
@@ -697,7 +1469,7 @@ func (e *Engine) syncPendingComments() error {
 		}
 
 		// Refresh comments for this issue to get the new comment in cache
-		if err := e.syncComments(pc.IssueNumber); err != nil {
+		if err := e.syncComments(ctx, pc.IssueNumber); err != nil {
 			logger.Warn("sync: failed to refresh comments for issue #%d: %v", pc.IssueNumber, err)
 		}
 
@@ -716,7 +1488,7 @@ func (e *Engine) syncPendingComments() error {
 }
 
 // syncDirtyComments syncs all dirty (edited) comments to GitHub.
-func (e *Engine) syncDirtyComments() error {
+func (e *Engine) syncDirtyComments(ctx context.Context) error {
 	dirtyComments, err := e.cache.GetDirtyComments(e.repo)
 	if err != nil {
 		return fmt.Errorf("failed to get dirty comments: %w", err)
@@ -732,11 +1504,16 @@ func (e *Engine) syncDirtyComments() error {
 	var syncErrors []error
 	for _, dc := range dirtyComments {
 		// Update the comment on GitHub
-		err := e.client.UpdateComment(e.owner, e.repoName, dc.ID, dc.Body)
+		err := e.client.UpdateComment(ctx, e.owner, e.repoName, dc.ID, dc.Body)
 		if err != nil {
+			e.recordPushError(cache.KindCommentEdit, dc.ID, err)
 			syncErrors = append(syncErrors, fmt.Errorf("comment %d: %w", dc.ID, err))
+			if e.checkNetworkError(err) || ctx.Err() != nil {
+				break
+			}
 			continue
 		}
+		e.clearPushError(cache.KindCommentEdit, dc.ID)
 
 		// Clear dirty flag after successful sync
 		if err := e.cache.ClearCommentDirty(e.repo, dc.ID); err != nil {
@@ -758,7 +1535,7 @@ func (e *Engine) syncDirtyComments() error {
 }
 
 // syncPendingIssues syncs all pending (new) issues to GitHub.
-func (e *Engine) syncPendingIssues() error {
+func (e *Engine) syncPendingIssues(ctx context.Context) error {
 	pendingIssues, err := e.cache.GetPendingIssues(e.repo)
 	if err != nil {
 		return fmt.Errorf("failed to get pending issues: %w", err)
@@ -774,16 +1551,24 @@ func (e *Engine) syncPendingIssues() error {
 	var syncErrors []error
 	for _, pi := range pendingIssues {
 		// Create the issue on GitHub
-		ghIssue, err := e.client.CreateIssue(e.owner, e.repoName, pi.Title, pi.Body, pi.Labels)
+		ghIssue, err := e.client.CreateIssue(ctx, e.owner, e.repoName, pi.Title, pi.Body, pi.Labels)
 		if err != nil {
+			e.recordPushError(cache.KindNewIssue, pi.ID, err)
 			syncErrors = append(syncErrors, fmt.Errorf("issue %q: %w", pi.Title, err))
+			if e.checkNetworkError(err) || ctx.Err() != nil {
+				break
+			}
 			continue
 		}
+		e.clearPushError(cache.KindNewIssue, pi.ID)
 
 		// Remove from pending after successful sync
 		if err := e.cache.RemovePendingIssue(pi.ID); err != nil {
 			logger.Warn("sync: failed to remove pending issue %d: %v", pi.ID, err)
 		}
+		if err := e.cache.RecordCreatedIssue(pi.ID, ghIssue.Number); err != nil {
+			logger.Warn("sync: %v", err)
+		}
 
 		// Add the newly created issue to the cache
 		cacheIssue := e.ghIssueToCacheIssue(ghIssue)
@@ -1,8 +1,13 @@
 package sync
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -310,7 +315,7 @@ func TestSyncNowStopsTimer(t *testing.T) {
 
 	// Immediately call SyncNow - this should stop the pending timer
 	// Since we have no dirty issues, this should complete quickly
-	err = engine.SyncNow()
+	err = engine.SyncNow(t.Context())
 	if err != nil {
 		t.Errorf("SyncNow() error = %v", err)
 	}
@@ -495,7 +500,7 @@ func TestInitialSync_MultipleIssues(t *testing.T) {
 	})
 
 	// Perform initial sync
-	err := engine.InitialSync()
+	err := engine.InitialSync(t.Context())
 	if err != nil {
 		t.Fatalf("InitialSync() error = %v", err)
 	}
@@ -560,7 +565,7 @@ func TestInitialSync_EmptyRepository(t *testing.T) {
 	// Don't add any issues to mock server
 
 	// Perform initial sync
-	err := engine.InitialSync()
+	err := engine.InitialSync(t.Context())
 	if err != nil {
 		t.Fatalf("InitialSync() error = %v", err)
 	}
@@ -576,6 +581,285 @@ func TestInitialSync_EmptyRepository(t *testing.T) {
 	}
 }
 
+// TestInitialSync_RecordsSyncTime tests that only a successful sync is recorded
+func TestInitialSync_RecordsSyncTime(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	mockGH.SetNextError(http.StatusInternalServerError, "boom")
+	if err := engine.InitialSync(t.Context()); err == nil {
+		t.Fatal("expected InitialSync() to fail")
+	}
+	if stats, _ := cacheDB.Stats("owner/repo"); stats == nil || !stats.LastSync.IsZero() {
+		t.Errorf("failed sync should not be recorded, got %+v", stats)
+	}
+
+	before := time.Now().Add(-time.Second)
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+	stats, err := cacheDB.Stats("owner/repo")
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.LastSync.Before(before) {
+		t.Errorf("LastSync = %v, want a time after %v", stats.LastSync, before)
+	}
+}
+
+// TestInitialSync_BulkFetch tests that issues and their comments come from
+// one GraphQL page, and from REST when GraphQL fails
+func TestInitialSync_BulkFetch(t *testing.T) {
+	for _, graphqlDisabled := range []bool{false, true} {
+		engine, cacheDB, mockGH := setupTestEngine(t)
+		baseTime := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+		for n := 1; n <= 30; n++ {
+			mockGH.AddIssue(&gh.Issue{Number: n, Title: fmt.Sprintf("Issue %d", n), State: "open", CreatedAt: baseTime, UpdatedAt: baseTime})
+			mockGH.AddComment(n, &gh.Comment{ID: int64(100 + n), Body: "Comment", CreatedAt: baseTime, UpdatedAt: baseTime})
+		}
+		mockGH.SetGraphQLDisabled(graphqlDisabled)
+
+		if err := engine.InitialSync(t.Context()); err != nil {
+			t.Fatalf("InitialSync() error = %v", err)
+		}
+
+		// GraphQL takes one request; the fallback one more to list and one per
+		// issue. Pull requests take as many, plus one for their review comments.
+		wantRequests := 1 + 1 + 1
+		if graphqlDisabled {
+			wantRequests = 1 + 1 + 30 + 1 + 1 + 1
+		}
+		if got := mockGH.RequestCount(); got != wantRequests {
+			t.Errorf("GraphQL disabled %v: %d requests, want %d", graphqlDisabled, got, wantRequests)
+		}
+		if comments, _ := cacheDB.GetComments("owner/repo", 30); len(comments) != 1 {
+			t.Errorf("GraphQL disabled %v: issue #30 has %d comments cached, want 1", graphqlDisabled, len(comments))
+		}
+
+		engine.Stop()
+		cacheDB.Close()
+		mockGH.Close()
+	}
+}
+
+// TestInitialSync_Incremental tests that a sync after the first one only
+// fetches what changed since, and that changing the filter fetches everything
+func TestInitialSync_Incremental(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	old := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	for n := 1; n <= 3; n++ {
+		mockGH.AddIssue(&gh.Issue{Number: n, Title: fmt.Sprintf("Issue %d", n), State: "open", CreatedAt: old, UpdatedAt: old})
+	}
+	mockGH.AddComment(1, &gh.Comment{ID: 101, Body: "Comment", CreatedAt: old, UpdatedAt: old})
+	engine.SetFilter(gh.IssueFilter{State: "open"})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("first InitialSync() error = %v", err)
+	}
+
+	// Changes made on GitHub after the first sync
+	now := time.Now()
+	mockGH.AddIssue(&gh.Issue{Number: 2, Title: "Renamed", State: "open", CreatedAt: old, UpdatedAt: now})
+	mockGH.AddIssue(&gh.Issue{Number: 3, Title: "Issue 3", State: "closed", CreatedAt: old, UpdatedAt: now})
+	mockGH.AddIssue(&gh.Issue{Number: 4, Title: "New", State: "open", CreatedAt: now, UpdatedAt: now})
+	mockGH.AddComment(4, &gh.Comment{ID: 401, Body: "Old comment", CreatedAt: old, UpdatedAt: old})
+	mockGH.GetComments(1)[0].Body = "Edited"
+	mockGH.GetComments(1)[0].UpdatedAt = now
+
+	before := mockGH.RequestCount()
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("second InitialSync() error = %v", err)
+	}
+
+	// Changed issues, changed comments and review comments, and the
+	// comments of the new issue
+	if got := mockGH.RequestCount() - before; got != 4 {
+		t.Errorf("incremental sync took %d requests, want 4", got)
+	}
+	if issue, _ := cacheDB.GetIssue("owner/repo", 2); issue == nil || issue.Title != "Renamed" {
+		t.Errorf("issue #2 = %+v, want it renamed", issue)
+	}
+	if issue, _ := cacheDB.GetIssue("owner/repo", 3); issue != nil {
+		t.Errorf("issue #3 was closed and should be dropped, got %+v", issue)
+	}
+	if comments, _ := cacheDB.GetComments("owner/repo", 4); len(comments) != 1 {
+		t.Errorf("new issue #4 has %d comments cached, want 1", len(comments))
+	}
+	if comments, _ := cacheDB.GetComments("owner/repo", 1); len(comments) != 1 || comments[0].Body != "Edited" {
+		t.Errorf("comments of #1 = %+v, want the edit", comments)
+	}
+
+	// Another filter needs every issue again
+	engine.SetFilter(gh.IssueFilter{})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("third InitialSync() error = %v", err)
+	}
+	if issue, _ := cacheDB.GetIssue("owner/repo", 3); issue == nil {
+		t.Error("issue #3 should be synced once the filter no longer excludes it")
+	}
+}
+
+// TestInitialSync_DropsDeletedIssues tests that a full sync of an unfiltered
+// repository drops the issues deleted or transferred on GitHub
+func TestInitialSync_DropsDeletedIssues(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Kept", State: "open"})
+	mockGH.AddIssue(&gh.Issue{Number: 2, Title: "Deleted", State: "closed"})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("first InitialSync() error = %v", err)
+	}
+
+	mockGH.RemoveIssue(2)
+	if err := cacheDB.ClearSyncCursor("owner/repo"); err != nil {
+		t.Fatalf("ClearSyncCursor() error = %v", err)
+	}
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("second InitialSync() error = %v", err)
+	}
+	if issue, _ := cacheDB.GetIssue("owner/repo", 2); issue != nil {
+		t.Errorf("deleted issue #2 is still cached: %+v", issue)
+	}
+	if issue, _ := cacheDB.GetIssue("owner/repo", 1); issue == nil {
+		t.Error("issue #1 should still be cached")
+	}
+}
+
+// TestInitialSync_FailedCommentsForceFullSync tests that comments that
+// failed to sync keep the sync cursor from advancing, so that the next sync
+// fetches them again
+func TestInitialSync_FailedCommentsForceFullSync(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	mockGH.SetGraphQLDisabled(true)
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Issue", State: "open"})
+	mockGH.AddComment(1, &gh.Comment{ID: 101, Body: "Comment"})
+	mockGH.SetCommentsError(1, http.StatusUnprocessableEntity)
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("first InitialSync() error = %v", err)
+	}
+	if cursor, _, _ := cacheDB.SyncCursor("owner/repo"); !cursor.IsZero() {
+		t.Errorf("sync cursor = %v after failed comments, want none", cursor)
+	}
+
+	mockGH.SetCommentsError(1, 0)
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("second InitialSync() error = %v", err)
+	}
+	if comments, _ := cacheDB.GetComments("owner/repo", 1); len(comments) != 1 {
+		t.Errorf("issue #1 has %d comments cached, want 1", len(comments))
+	}
+	if cursor, _, _ := cacheDB.SyncCursor("owner/repo"); cursor.IsZero() {
+		t.Error("sync cursor should be recorded once every comment synced")
+	}
+}
+
+// TestInitialSync_UnchangedRepo tests that a full sync of a repository
+// nothing changed in is answered with 304s from the response cache
+func TestInitialSync_UnchangedRepo(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	// GraphQL queries are POSTs, which are never cached
+	mockGH.SetGraphQLDisabled(true)
+	old := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Issue", State: "open", CreatedAt: old, UpdatedAt: old})
+	mockGH.AddComment(1, &gh.Comment{ID: 101, Body: "Comment", CreatedAt: old, UpdatedAt: old})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("first InitialSync() error = %v", err)
+	}
+
+	cacheDB.ClearSyncCursor("owner/repo")
+	requests, notModified := mockGH.RequestCount(), mockGH.NotModifiedCount()
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+	// Every GET, that is all but the rejected issues and pulls queries
+	requests, notModified = mockGH.RequestCount()-requests, mockGH.NotModifiedCount()-notModified
+	if requests <= 2 || notModified != requests-2 {
+		t.Errorf("refresh took %d requests of which %d were 304s, want all but 2", requests, notModified)
+	}
+	if issue, _ := cacheDB.GetIssue("owner/repo", 1); issue == nil || issue.Title != "Issue" {
+		t.Errorf("issue #1 = %+v after a refresh from the response cache", issue)
+	}
+}
+
+// TestInitialSync_PullRequests tests that pull requests are cached apart
+// from issues with their branches, merge state and review comments, and
+// dropped once hidden
+func TestInitialSync_PullRequests(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	old := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	mergeable := true
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Issue", State: "open", CreatedAt: old, UpdatedAt: old})
+	pr := &gh.PullRequest{
+		Issue: gh.Issue{Number: 2, Title: "Fix", State: "open", CreatedAt: old, UpdatedAt: old},
+		Head:  gh.PullRequestBranch{Ref: "fix"}, Base: gh.PullRequestBranch{Ref: "main"}, Mergeable: &mergeable,
+	}
+	mockGH.AddPullRequest(pr)
+	mockGH.AddComment(2, &gh.Comment{ID: 201, Body: "Looks good", CreatedAt: old, UpdatedAt: old})
+	mockGH.AddReviewComment(2, &gh.ReviewComment{ID: 301, Body: "Typo", Path: "main.go", Line: 3, CreatedAt: old, UpdatedAt: old})
+
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("first InitialSync() error = %v", err)
+	}
+	if issues, _ := cacheDB.ListIssues("owner/repo"); len(issues) != 1 || issues[0].Number != 1 {
+		t.Errorf("cached issues = %+v, want #1 only", issues)
+	}
+	pull, _ := cacheDB.GetIssue("owner/repo", 2)
+	if pull == nil || pull.Kind != cache.KindPull || pull.HeadRef != "fix" || pull.BaseRef != "main" || pull.MergeState != "mergeable" {
+		t.Fatalf("pull request #2 = %+v", pull)
+	}
+	if comments, _ := cacheDB.GetComments("owner/repo", 2); len(comments) != 1 {
+		t.Errorf("pull request #2 has %d comments cached, want 1", len(comments))
+	}
+	if reviews, _ := cacheDB.GetReviewComments("owner/repo", 2); len(reviews) != 1 || reviews[0].Path != "main.go" {
+		t.Errorf("review comments of #2 = %+v", reviews)
+	}
+
+	// Merged, and reviewed again, after the first sync
+	now := time.Now()
+	pr.State, pr.Merged, pr.UpdatedAt = "closed", true, now
+	mockGH.AddReviewComment(2, &gh.ReviewComment{ID: 302, Body: "Nit", Path: "main.go", Line: 9, CreatedAt: now, UpdatedAt: now})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("second InitialSync() error = %v", err)
+	}
+	if pull, _ := cacheDB.GetIssue("owner/repo", 2); pull == nil || pull.State != "closed" || pull.MergeState != "merged" {
+		t.Errorf("pull request #2 = %+v, want it merged", pull)
+	}
+	if reviews, _ := cacheDB.GetReviewComments("owner/repo", 2); len(reviews) != 2 {
+		t.Errorf("pull request #2 has %d review comments cached, want 2", len(reviews))
+	}
+
+	engine.SetHidePulls(true)
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("third InitialSync() error = %v", err)
+	}
+	if pulls, _ := cacheDB.ListPulls("owner/repo"); len(pulls) != 0 {
+		t.Errorf("hidden pull requests are still cached: %+v", pulls)
+	}
+	if issues, _ := cacheDB.ListIssues("owner/repo"); len(issues) != 1 {
+		t.Errorf("cached issues = %+v, want #1 kept", issues)
+	}
+}
+
 // TestSyncIssue_ConflictResolution tests conflict detection during sync
 func TestSyncIssue_ConflictResolution(t *testing.T) {
 	baseTime := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
@@ -650,7 +934,7 @@ func TestSyncIssue_ConflictResolution(t *testing.T) {
 			}
 
 			// Trigger sync
-			err := engine.SyncNow()
+			err := engine.SyncNow(t.Context())
 			if err != nil {
 				// Errors are aggregated but shouldn't fail for conflict scenarios
 				t.Logf("SyncNow returned error (may be expected): %v", err)
@@ -726,7 +1010,7 @@ func TestRefreshIssue_NotModified(t *testing.T) {
 	}
 
 	// Refresh issue - should get 304
-	updated, err := engine.RefreshIssue(1)
+	updated, err := engine.RefreshIssue(t.Context(), 1)
 	if err != nil {
 		t.Fatalf("RefreshIssue() error = %v", err)
 	}
@@ -788,7 +1072,7 @@ func TestRefreshIssue_WithChanges(t *testing.T) {
 	}
 
 	// Refresh issue - should get 200 with new data
-	updated, err := engine.RefreshIssue(1)
+	updated, err := engine.RefreshIssue(t.Context(), 1)
 	if err != nil {
 		t.Fatalf("RefreshIssue() error = %v", err)
 	}
@@ -861,7 +1145,7 @@ func TestRefreshIssue_DirtySkipped(t *testing.T) {
 	}
 
 	// Refresh issue - should skip because dirty
-	updated, err := engine.RefreshIssue(1)
+	updated, err := engine.RefreshIssue(t.Context(), 1)
 	if err != nil {
 		t.Fatalf("RefreshIssue() error = %v", err)
 	}
@@ -1258,7 +1542,7 @@ func TestHasConflict(t *testing.T) {
 			}
 
 			// Check for conflict
-			hasConflict, err := engine.HasConflict(1)
+			hasConflict, err := engine.HasConflict(t.Context(), 1)
 			if err != nil {
 				t.Fatalf("HasConflict() error = %v", err)
 			}
@@ -1328,7 +1612,7 @@ func TestSyncDirtyIssues_PartialFailure(t *testing.T) {
 	}
 
 	// Trigger sync - should report error but continue
-	err := engine.SyncNow()
+	err := engine.SyncNow(t.Context())
 	if err == nil {
 		t.Error("expected error due to missing issue 2")
 	}
@@ -1422,7 +1706,7 @@ func TestSyncIssue_TitleChange(t *testing.T) {
 	}
 
 	// Trigger sync
-	err := engine.SyncNow()
+	err := engine.SyncNow(t.Context())
 	if err != nil {
 		t.Logf("SyncNow returned error (may be expected): %v", err)
 	}
@@ -1493,7 +1777,7 @@ func TestSyncIssue_TitleAndBodyChange(t *testing.T) {
 	}
 
 	// Trigger sync
-	err := engine.SyncNow()
+	err := engine.SyncNow(t.Context())
 	if err != nil {
 		t.Logf("SyncNow returned error (may be expected): %v", err)
 	}
@@ -1574,7 +1858,7 @@ func TestSyncPendingComments(t *testing.T) {
 	}
 
 	// Sync
-	err = engine.SyncNow()
+	err = engine.SyncNow(t.Context())
 	if err != nil {
 		t.Fatalf("SyncNow() unexpected error: %v", err)
 	}
@@ -1644,7 +1928,7 @@ func TestSyncDirtyComments(t *testing.T) {
 	}
 
 	// Initial sync to get comment in cache
-	err = engine.InitialSync()
+	err = engine.InitialSync(t.Context())
 	if err != nil {
 		t.Fatalf("InitialSync() failed: %v", err)
 	}
@@ -1656,7 +1940,7 @@ func TestSyncDirtyComments(t *testing.T) {
 	}
 
 	// Sync
-	err = engine.SyncNow()
+	err = engine.SyncNow(t.Context())
 	if err != nil {
 		t.Fatalf("SyncNow() unexpected error: %v", err)
 	}
@@ -1722,7 +2006,7 @@ func TestSyncPendingIssues(t *testing.T) {
 	}
 
 	// Sync
-	err = engine.SyncNow()
+	err = engine.SyncNow(t.Context())
 	if err != nil {
 		t.Fatalf("SyncNow() unexpected error: %v", err)
 	}
@@ -1760,3 +2044,502 @@ func TestSyncPendingIssues(t *testing.T) {
 		t.Errorf("cached issue title mismatch: expected 'New Feature Request', got %q", cachedIssue.Title)
 	}
 }
+
+// TestInitialSync_KeepsDirtyLocalChanges tests that a pull does not clobber
+// edits that are still waiting to be pushed
+func TestInitialSync_KeepsDirtyLocalChanges(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	baseTime := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	mockGH.AddIssue(&gh.Issue{
+		Number:    1,
+		Title:     "Issue 1",
+		Body:      "Remote body",
+		State:     "open",
+		User:      gh.User{Login: "user1"},
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	})
+	mockGH.AddComment(1, &gh.Comment{
+		ID:        101,
+		User:      gh.User{Login: "commenter1"},
+		Body:      "Remote comment",
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	})
+
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+
+	localBody := "Local body"
+	if err := cacheDB.MarkDirty("owner/repo", 1, cache.IssueUpdate{Body: &localBody}); err != nil {
+		t.Fatalf("MarkDirty() error = %v", err)
+	}
+	if err := cacheDB.MarkCommentDirty("owner/repo", 101, "Local comment"); err != nil {
+		t.Fatalf("MarkCommentDirty() error = %v", err)
+	}
+
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("second InitialSync() error = %v", err)
+	}
+
+	issue, err := cacheDB.GetIssue("owner/repo", 1)
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+	if !issue.Dirty || issue.Body != localBody {
+		t.Errorf("dirty issue was overwritten: dirty=%v body=%q", issue.Dirty, issue.Body)
+	}
+
+	comments, err := cacheDB.GetComments("owner/repo", 1)
+	if err != nil {
+		t.Fatalf("GetComments() error = %v", err)
+	}
+	if len(comments) != 1 || comments[0].Body != "Local comment" {
+		t.Errorf("dirty comment was overwritten: %+v", comments)
+	}
+}
+
+// TestInitialSync_DropsEditOfRemotelyDeletedComment tests that an edit of a
+// comment deleted on GitHub is backed up and no longer retried
+func TestInitialSync_DropsEditOfRemotelyDeletedComment(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	baseTime := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	mockGH.AddIssue(&gh.Issue{
+		Number:    1,
+		Title:     "Issue 1",
+		State:     "open",
+		User:      gh.User{Login: "user1"},
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	})
+
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+
+	// A comment that exists locally but has since been deleted on GitHub
+	if err := cacheDB.UpsertComments("owner/repo", 1, []cache.Comment{
+		{ID: 101, Author: "commenter1", Body: "Remote comment", CreatedAt: baseTime.Format(time.RFC3339)},
+	}); err != nil {
+		t.Fatalf("UpsertComments() error = %v", err)
+	}
+	if err := cacheDB.MarkCommentDirty("owner/repo", 101, "Local comment"); err != nil {
+		t.Fatalf("MarkCommentDirty() error = %v", err)
+	}
+
+	// Deleted comments are only seen by a full sync
+	if err := cacheDB.SetSyncCursor("owner/repo", time.Time{}, false); err != nil {
+		t.Fatalf("SetSyncCursor() error = %v", err)
+	}
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("second InitialSync() error = %v", err)
+	}
+
+	dirty, err := cacheDB.GetDirtyComments("owner/repo")
+	if err != nil {
+		t.Fatalf("GetDirtyComments() error = %v", err)
+	}
+	if len(dirty) != 0 {
+		t.Errorf("edit of deleted comment should be dropped, got %+v", dirty)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(os.Getenv("HOME"), ".cache", "ghissues", ".conflicts", "owner", "repo", "comment_101_*.md"))
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup of the local edit, got %v", backups)
+	}
+	content, _ := os.ReadFile(backups[0])
+	if string(content) != "Local comment" {
+		t.Errorf("backup content = %q, want the local edit", content)
+	}
+}
+
+// TestRefreshIssue_NotCached tests that an issue missing from the cache is fetched
+func TestRefreshIssue_NotCached(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	baseTime := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+
+	// Created on GitHub after the initial sync
+	mockGH.AddIssue(&gh.Issue{
+		Number:    7,
+		Title:     "New remote issue",
+		Body:      "Created elsewhere",
+		State:     "open",
+		User:      gh.User{Login: "user1"},
+		Labels:    []gh.Label{},
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	})
+	mockGH.AddComment(7, &gh.Comment{
+		ID:        701,
+		User:      gh.User{Login: "commenter1"},
+		Body:      "First!",
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	})
+
+	updated, err := engine.RefreshIssue(t.Context(), 7)
+	if err != nil {
+		t.Fatalf("RefreshIssue() error = %v", err)
+	}
+	if !updated {
+		t.Error("expected updated=true for an issue fetched for the first time")
+	}
+
+	issue, err := cacheDB.GetIssue("owner/repo", 7)
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+	if issue == nil || issue.Title != "New remote issue" {
+		t.Fatalf("issue #7 should be cached, got %+v", issue)
+	}
+
+	comments, err := cacheDB.GetComments("owner/repo", 7)
+	if err != nil {
+		t.Fatalf("GetComments() error = %v", err)
+	}
+	if len(comments) != 1 {
+		t.Errorf("expected 1 comment, got %d", len(comments))
+	}
+
+	// An issue that exists nowhere is still an error
+	if _, err := engine.RefreshIssue(t.Context(), 99); err == nil {
+		t.Error("RefreshIssue() should fail for an issue that does not exist on GitHub")
+	}
+}
+
+// TestSyncNow_RecordsPushErrors tests that failed operations keep their last
+// error until they are pushed
+func TestSyncNow_RecordsPushErrors(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	id, err := cacheDB.AddPendingIssue("owner/repo", "New issue", "", []string{"no-such-label"})
+	if err != nil {
+		t.Fatalf("failed to add pending issue: %v", err)
+	}
+
+	mockGH.SetNextError(http.StatusUnprocessableEntity, `{"message": "Validation Failed"}`)
+	if err := engine.SyncNow(t.Context()); err == nil {
+		t.Fatal("expected SyncNow to fail")
+	}
+
+	errs, err := cacheDB.GetPushErrors("owner/repo")
+	if err != nil {
+		t.Fatalf("GetPushErrors() error = %v", err)
+	}
+	pe, ok := errs[cache.PushErrorKey{Kind: cache.KindNewIssue, ID: id}]
+	if !ok || pe.Attempts != 1 || !strings.Contains(pe.Message, "422") {
+		t.Fatalf("expected a recorded 422 for the pending issue, got %+v", errs)
+	}
+
+	if err := engine.SyncNow(t.Context()); err != nil {
+		t.Fatalf("SyncNow() retry error = %v", err)
+	}
+	if errs, _ := cacheDB.GetPushErrors("owner/repo"); len(errs) != 0 {
+		t.Errorf("push error should be cleared once pushed, got %+v", errs)
+	}
+}
+
+// TestStop_InterruptsSync tests that Stop cuts short a push waiting out a
+// rate limit and leaves the queue untouched
+func TestStop_InterruptsSync(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	id, err := cacheDB.AddPendingIssue("owner/repo", "New issue", "", nil)
+	if err != nil {
+		t.Fatalf("failed to add pending issue: %v", err)
+	}
+
+	// A 429 without a reset header waits a minute before retrying
+	mockGH.SetNextError(http.StatusTooManyRequests, `{"message": "rate limited"}`)
+	done := make(chan error, 1)
+	go func() { done <- engine.SyncNow(t.Context()) }()
+
+	time.Sleep(100 * time.Millisecond)
+	engine.Stop()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("SyncNow() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SyncNow() still running after Stop")
+	}
+
+	pending, err := cacheDB.GetPendingIssues("owner/repo")
+	if err != nil {
+		t.Fatalf("GetPendingIssues() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != id {
+		t.Errorf("pending issues = %+v, want the issue still queued", pending)
+	}
+	if errs, _ := cacheDB.GetPushErrors("owner/repo"); len(errs) != 0 {
+		t.Errorf("an interrupted push recorded errors: %+v", errs)
+	}
+	if engine.GetStatus().LastError != "" {
+		t.Errorf("LastError = %q, want none for an interrupted push", engine.GetStatus().LastError)
+	}
+}
+
+// TestDiscardIssueEdits tests resetting a dirty issue to its remote version
+func TestDiscardIssueEdits(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Remote", State: "open", UpdatedAt: time.Now()})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+
+	title := "Local"
+	cacheDB.MarkDirty("owner/repo", 1, cache.IssueUpdate{Title: &title})
+
+	if err := engine.DiscardIssueEdits(t.Context(), 1); err != nil {
+		t.Fatalf("DiscardIssueEdits() error = %v", err)
+	}
+	issue, _ := cacheDB.GetIssue("owner/repo", 1)
+	if issue.Dirty || issue.Title != "Remote" {
+		t.Errorf("issue after discard = %+v", issue)
+	}
+	if mockGH.GetIssue(1).Title != "Remote" {
+		t.Error("discarding must not push the local edit")
+	}
+}
+
+// TestRemoteWins_KeepsPullRequestFields tests that a pull request replaced
+// by its GitHub version, after a conflict or a discard, keeps its branches,
+// merge state and draft flag
+func TestRemoteWins_KeepsPullRequestFields(t *testing.T) {
+	for _, name := range []string{"conflict", "discard"} {
+		t.Run(name, func(t *testing.T) {
+			engine, cacheDB, mockGH := setupTestEngine(t)
+			defer engine.Stop()
+			defer cacheDB.Close()
+			defer mockGH.Close()
+
+			old := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+			pr := &gh.PullRequest{
+				Issue: gh.Issue{Number: 2, Title: "Remote", State: "open", CreatedAt: old, UpdatedAt: old},
+				Head:  gh.PullRequestBranch{Ref: "fix"}, Base: gh.PullRequestBranch{Ref: "main"}, Draft: true,
+			}
+			mockGH.AddPullRequest(pr)
+			if err := engine.InitialSync(t.Context()); err != nil {
+				t.Fatalf("InitialSync() error = %v", err)
+			}
+
+			title := "Local"
+			cacheDB.MarkDirty("owner/repo", 2, cache.IssueUpdate{Title: &title})
+			if name == "conflict" {
+				// Edited on GitHub after the local edit, so the remote version wins
+				pr.UpdatedAt = time.Now().Add(time.Hour)
+				engine.SyncNow(t.Context())
+			} else if err := engine.DiscardIssueEdits(t.Context(), 2); err != nil {
+				t.Fatalf("DiscardIssueEdits() error = %v", err)
+			}
+
+			pull, _ := cacheDB.GetIssue("owner/repo", 2)
+			if pull == nil || pull.Dirty || pull.Title != "Remote" || pull.Kind != cache.KindPull || pull.HeadRef != "fix" || pull.BaseRef != "main" || !pull.Draft {
+				t.Errorf("pull request #2 = %+v, want the remote version with its branches and draft flag", pull)
+			}
+		})
+	}
+}
+
+// TestReadOnly_NeverPushes tests that a read-only engine refreshes but
+// leaves local changes queued
+func TestReadOnly_NeverPushes(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Remote", State: "open", UpdatedAt: time.Now()})
+	engine.SetReadOnly(true)
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+
+	title := "Local"
+	cacheDB.MarkDirty("owner/repo", 1, cache.IssueUpdate{Title: &title})
+	engine.TriggerSync()
+	if err := engine.SyncNow(t.Context()); err != nil {
+		t.Fatalf("SyncNow() error = %v", err)
+	}
+	time.Sleep(200 * time.Millisecond) // past the debounce
+
+	if mockGH.GetIssue(1).Title != "Remote" {
+		t.Error("a read-only engine must not push")
+	}
+	status := engine.GetStatus()
+	if !status.ReadOnly || status.DirtyIssues != 1 {
+		t.Errorf("status = %+v, expected read-only with the edit still queued", status)
+	}
+}
+
+func TestOffline_Forced(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Remote", State: "open", UpdatedAt: time.Now()})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Changed remotely", State: "open", UpdatedAt: time.Now()})
+
+	engine.SetOffline(true)
+	if err := engine.InitialSync(t.Context()); !errors.Is(err, ErrOffline) {
+		t.Errorf("InitialSync() error = %v, expected ErrOffline", err)
+	}
+
+	title := "Local"
+	cacheDB.MarkDirty("owner/repo", 1, cache.IssueUpdate{Title: &title})
+	engine.TriggerSync()
+	if err := engine.SyncNow(t.Context()); !errors.Is(err, ErrOffline) {
+		t.Errorf("SyncNow() error = %v, expected ErrOffline", err)
+	}
+	engine.SetRefreshTTL(0)
+	engine.TriggerRefresh(1)
+	time.Sleep(200 * time.Millisecond) // past the debounce and any refresh
+
+	if mockGH.GetIssue(1).Title != "Changed remotely" {
+		t.Error("an offline engine must not push")
+	}
+	if cached, _ := cacheDB.GetIssue("owner/repo", 1); cached.Title != "Local" {
+		t.Errorf("cached title = %q, expected the queued edit", cached.Title)
+	}
+	status := engine.GetStatus()
+	if !status.Offline || status.DirtyIssues != 1 {
+		t.Errorf("status = %+v, expected offline with the edit still queued", status)
+	}
+}
+
+func TestOffline_DrainsWhenBackOnline(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+	engine.probeMin = 10 * time.Millisecond
+	engine.probeMax = 40 * time.Millisecond
+	// Give up on the dropped connections below at once
+	engine.client.SetRetryPolicy(gh.RetryPolicy{MaxAttempts: 1})
+
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Remote", State: "open", UpdatedAt: time.Now().Add(-time.Hour)})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+
+	// Losing the network takes the engine offline and keeps the edit queued
+	mockGH.SetUnreachable(true)
+	title := "Local"
+	cacheDB.MarkDirty("owner/repo", 1, cache.IssueUpdate{Title: &title})
+	if err := engine.SyncNow(t.Context()); err == nil {
+		t.Fatal("SyncNow() expected an error while unreachable")
+	}
+	if !engine.Offline() {
+		t.Fatal("engine should be offline after a network error")
+	}
+	if status := engine.GetStatus(); !status.Offline || status.DirtyIssues != 1 {
+		t.Errorf("status = %+v, expected offline with the edit queued", status)
+	}
+	mockGH.AddIssue(&gh.Issue{Number: 2, Title: "Opened while offline", State: "open", UpdatedAt: time.Now()})
+
+	// Once GitHub answers again, the probe refreshes and drains the queue
+	mockGH.SetUnreachable(false)
+	deadline := time.Now().Add(5 * time.Second)
+	for mockGH.GetIssue(1).Title != "Local" {
+		if time.Now().After(deadline) {
+			t.Fatal("the queued edit was not pushed after the network came back")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if engine.Offline() {
+		t.Error("engine should be back online")
+	}
+	if issue, _ := cacheDB.GetIssue("owner/repo", 2); issue == nil {
+		t.Error("the issue opened while offline should be fetched before pushing")
+	}
+}
+
+func TestFilter_DropsIssuesOutsideFilter(t *testing.T) {
+	engine, cacheDB, mockGH := setupTestEngine(t)
+	defer engine.Stop()
+	defer cacheDB.Close()
+	defer mockGH.Close()
+
+	now := time.Now()
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Open", State: "open", UpdatedAt: now})
+	mockGH.AddIssue(&gh.Issue{Number: 2, Title: "Closed", State: "closed", UpdatedAt: now})
+	mockGH.AddIssue(&gh.Issue{Number: 3, Title: "Closed but edited", State: "closed", UpdatedAt: now})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+	title := "Local edit"
+	cacheDB.MarkDirty("owner/repo", 3, cache.IssueUpdate{Title: &title})
+
+	// Narrowing the filter drops the closed issue but keeps the local edit
+	engine.SetFilter(gh.IssueFilter{State: "open"})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+	issues, _ := cacheDB.ListIssues("owner/repo")
+	if len(issues) != 2 || issues[0].Number != 1 || issues[1].Number != 3 {
+		t.Errorf("cached issues = %+v, expected #1 and the dirty #3", issues)
+	}
+	if status := engine.GetStatus(); status.Filter != "state=open" {
+		t.Errorf("status filter = %q", status.Filter)
+	}
+
+	// A new engine for the cache keeps the filter
+	next, err := NewEngine(cacheDB, engine.client, "owner/repo", 100)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	if got := next.Filter(); got.State != "open" {
+		t.Errorf("Filter() = %+v, expected the stored filter", got)
+	}
+
+	// An issue leaving the filter drops out on refresh
+	mockGH.AddIssue(&gh.Issue{Number: 1, Title: "Open", State: "closed", UpdatedAt: time.Now()})
+	if updated, err := engine.RefreshIssue(t.Context(), 1); err != nil || !updated {
+		t.Fatalf("RefreshIssue() = %v, %v", updated, err)
+	}
+	if issue, _ := cacheDB.GetIssue("owner/repo", 1); issue != nil {
+		t.Error("issue #1 should have dropped out of the cache")
+	}
+	if updated, err := engine.RefreshIssue(t.Context(), 2); err != nil || updated {
+		t.Errorf("RefreshIssue() of an issue outside the filter = %v, %v; expected it not to be cached", updated, err)
+	}
+
+	// Clearing the filter forgets it
+	engine.SetFilter(gh.IssueFilter{})
+	if err := engine.InitialSync(t.Context()); err != nil {
+		t.Fatalf("InitialSync() error = %v", err)
+	}
+	if stored, _ := cacheDB.IssueFilter("owner/repo"); stored != "" {
+		t.Errorf("stored filter = %q, expected it cleared", stored)
+	}
+}